@@ -3,20 +3,42 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"minfo"
 	"minfo/internal/app"
+	"minfo/internal/cli"
+	"minfo/internal/media"
 	"minfo/internal/version"
 )
 
-// main 会启动命令行入口，并把应用运行在当前进程中。
+// main 会启动命令行入口：不带参数或使用 serve 时启动 HTTP 服务，其余参数交给命令行子命令处理。
 func main() {
+	if len(os.Args) > 1 && os.Args[1] != "serve" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	server, err := app.NewServer(minfo.EmbeddedWebUI())
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			roots := media.ReloadConfiguredRoots()
+			log.Printf("reloaded %d media roots", len(roots))
+		}
+	}()
+
 	log.Printf("minfo %s listening on http://localhost%s", version.Version, server.Addr)
 	log.Fatal(server.ListenAndServe())
 }
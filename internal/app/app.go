@@ -14,7 +14,7 @@ import (
 	"minfo/internal/media"
 )
 
-// NewServer 会根据当前配置创建 HTTP Server，并在启动前预加载截图流程依赖的 UDF 模块。
+// NewServer 会根据当前配置创建 HTTP Server，并在启动前预加载 UDF 模块、恢复持久化的任务历史。
 func NewServer(staticFS fs.FS) (*http.Server, error) {
 	port := config.Getenv("PORT", config.DefaultPort)
 
@@ -24,6 +24,10 @@ func NewServer(staticFS fs.FS) (*http.Server, error) {
 	}
 	cancel()
 
+	if err := httpapi.ConfigureStorage(config.DataDir); err != nil {
+		log.Printf("job persistence disabled: %v", err)
+	}
+
 	assets, err := fs.Sub(staticFS, "webui/dist")
 	if err != nil {
 		return nil, err
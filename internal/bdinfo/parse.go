@@ -0,0 +1,460 @@
+// Package bdinfo 提供把 BDInfo 文本报告解析成结构化数据的能力。
+
+package bdinfo
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrEmptyReport 表示报告中既没有光盘信息也没有任何播放列表。
+var ErrEmptyReport = errors.New("bdinfo: report contains no disc or playlist information")
+
+var columnSeparator = regexp.MustCompile(`\s{2,}`)
+
+// Report 表示解析后的 BDInfo 报告。
+type Report struct {
+	DiscTitle     string     `json:"disc_title,omitempty"`
+	DiscLabel     string     `json:"disc_label,omitempty"`
+	DiscSize      int64      `json:"disc_size,omitempty"`
+	Protection    string     `json:"protection,omitempty"`
+	Extras        string     `json:"extras,omitempty"`
+	BDInfoVersion string     `json:"bdinfo_version,omitempty"`
+	Playlists     []Playlist `json:"playlists"`
+}
+
+// Playlist 表示报告中的一个播放列表区块。
+type Playlist struct {
+	Name             string           `json:"name"`
+	Length           string           `json:"length,omitempty"`
+	LengthSeconds    float64          `json:"length_seconds,omitempty"`
+	Size             int64            `json:"size,omitempty"`
+	TotalBitrateMbps float64          `json:"total_bitrate_mbps,omitempty"`
+	Video            []VideoStream    `json:"video"`
+	Audio            []AudioStream    `json:"audio"`
+	Subtitles        []SubtitleStream `json:"subtitles"`
+	Chapters         []Chapter        `json:"chapters,omitempty"`
+	Files            []StreamFile     `json:"files,omitempty"`
+}
+
+// VideoStream 表示一条视频流；Hidden 对应报告中以 * 标记、播放列表未引用的流。
+type VideoStream struct {
+	Codec       string  `json:"codec"`
+	BitrateKbps float64 `json:"bitrate_kbps,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Hidden      bool    `json:"hidden,omitempty"`
+}
+
+// AudioStream 表示一条音频流。
+type AudioStream struct {
+	Codec       string  `json:"codec"`
+	Language    string  `json:"language,omitempty"`
+	BitrateKbps float64 `json:"bitrate_kbps,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Hidden      bool    `json:"hidden,omitempty"`
+}
+
+// SubtitleStream 表示一条字幕流。
+type SubtitleStream struct {
+	Codec       string  `json:"codec"`
+	Language    string  `json:"language,omitempty"`
+	BitrateKbps float64 `json:"bitrate_kbps,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Hidden      bool    `json:"hidden,omitempty"`
+}
+
+// Chapter 表示一个章节。
+type Chapter struct {
+	Number        int     `json:"number"`
+	TimeIn        string  `json:"time_in"`
+	TimeInSeconds float64 `json:"time_in_seconds"`
+	Length        string  `json:"length,omitempty"`
+	AvgVideoRate  string  `json:"avg_video_rate,omitempty"`
+}
+
+// StreamFile 表示播放列表引用的一个 M2TS 文件。
+type StreamFile struct {
+	Name             string  `json:"name"`
+	TimeIn           string  `json:"time_in,omitempty"`
+	Length           string  `json:"length,omitempty"`
+	LengthSeconds    float64 `json:"length_seconds,omitempty"`
+	Size             int64   `json:"size,omitempty"`
+	TotalBitrateKbps float64 `json:"total_bitrate_kbps,omitempty"`
+}
+
+// reportSection 表示报告中当前所在的表格区段。
+type reportSection int
+
+const (
+	sectionNone reportSection = iota
+	sectionVideo
+	sectionAudio
+	sectionSubtitles
+	sectionFiles
+	sectionChapters
+)
+
+// reportParser 保存逐行解析时的状态。
+type reportParser struct {
+	report  Report
+	current *Playlist
+	section reportSection
+	// summaryOnly 表示当前播放列表已经从完整表格中读到了流信息，QUICK SUMMARY 中重复的流行需要跳过。
+	summaryOnly bool
+}
+
+// ParseReport 把 BDInfo 的完整报告或精简报告解析成结构化数据。
+//
+// 完整报告中的 PLAYLIST REPORT 表格和末尾 QUICK SUMMARY 描述同一个播放列表时只保留一份；
+// 只有 QUICK SUMMARY 的精简报告也能解析出流信息。
+func ParseReport(text string) (*Report, error) {
+	parser := &reportParser{}
+	for _, line := range splitLines(text) {
+		parser.parseLine(line)
+	}
+
+	report := parser.report
+	if report.Playlists == nil {
+		report.Playlists = []Playlist{}
+	}
+	if report.DiscTitle == "" && report.DiscLabel == "" && report.DiscSize == 0 && len(report.Playlists) == 0 {
+		return nil, ErrEmptyReport
+	}
+	return &report, nil
+}
+
+// parseLine 处理报告中的一行。
+func (p *reportParser) parseLine(raw string) {
+	line := strings.TrimSpace(strings.NewReplacer("[code]", "", "[/code]", "").Replace(raw))
+	if line == "" {
+		return
+	}
+
+	switch strings.ToUpper(line) {
+	case "VIDEO:":
+		p.section = sectionVideo
+		return
+	case "AUDIO:":
+		p.section = sectionAudio
+		return
+	case "SUBTITLES:":
+		p.section = sectionSubtitles
+		return
+	case "FILES:":
+		p.section = sectionFiles
+		return
+	case "CHAPTERS:":
+		p.section = sectionChapters
+		return
+	}
+	if strings.HasSuffix(line, ":") && strings.ToUpper(line) == line {
+		// DISC INFO:、PLAYLIST REPORT:、STREAM DIAGNOSTICS:、QUICK SUMMARY: 等其它区段标题。
+		p.section = sectionNone
+		return
+	}
+
+	if key, value, ok := splitReportField(line); ok && p.parseField(key, value) {
+		return
+	}
+	if p.section != sectionNone && p.current != nil {
+		p.parseTableRow(line)
+	}
+}
+
+// parseField 处理 "Key: value" 形式的字段，返回该行是否已被识别。
+func (p *reportParser) parseField(key, value string) bool {
+	switch strings.ToLower(key) {
+	case "disc title":
+		setIfEmpty(&p.report.DiscTitle, value)
+	case "disc label":
+		setIfEmpty(&p.report.DiscLabel, value)
+	case "disc size":
+		if p.report.DiscSize == 0 {
+			p.report.DiscSize = parseReportInt(value)
+		}
+	case "protection":
+		setIfEmpty(&p.report.Protection, value)
+	case "extras":
+		setIfEmpty(&p.report.Extras, value)
+	case "bdinfo":
+		setIfEmpty(&p.report.BDInfoVersion, value)
+	case "name", "playlist":
+		if !isPlaylistName(value) {
+			return false
+		}
+		p.selectPlaylist(value)
+	case "length":
+		if p.current == nil {
+			return false
+		}
+		setIfEmpty(&p.current.Length, stripLengthSuffix(value))
+		if p.current.LengthSeconds == 0 {
+			p.current.LengthSeconds = parseReportDuration(value)
+		}
+	case "size":
+		if p.current == nil {
+			return false
+		}
+		if p.current.Size == 0 {
+			p.current.Size = parseReportInt(value)
+		}
+	case "total bitrate":
+		if p.current == nil {
+			return false
+		}
+		if p.current.TotalBitrateMbps == 0 {
+			p.current.TotalBitrateMbps = parseReportRate(value)
+		}
+	case "video", "* video":
+		p.parseSummaryVideo(value, strings.HasPrefix(key, "*"))
+	case "audio", "* audio":
+		p.parseSummaryAudio(value, strings.HasPrefix(key, "*"))
+	case "subtitle", "* subtitle":
+		p.parseSummarySubtitle(value, strings.HasPrefix(key, "*"))
+	default:
+		return false
+	}
+	p.section = sectionNone
+	return true
+}
+
+// selectPlaylist 切换到名为 name 的播放列表；已存在时复用，以合并完整表格和 QUICK SUMMARY。
+func (p *reportParser) selectPlaylist(name string) {
+	name = strings.ToUpper(name)
+	for index := range p.report.Playlists {
+		if p.report.Playlists[index].Name == name {
+			p.current = &p.report.Playlists[index]
+			p.summaryOnly = len(p.current.Video)+len(p.current.Audio)+len(p.current.Subtitles) > 0
+			return
+		}
+	}
+	p.report.Playlists = append(p.report.Playlists, Playlist{
+		Name:      name,
+		Video:     []VideoStream{},
+		Audio:     []AudioStream{},
+		Subtitles: []SubtitleStream{},
+	})
+	p.current = &p.report.Playlists[len(p.report.Playlists)-1]
+	p.summaryOnly = false
+}
+
+// parseTableRow 按当前区段解析以两个以上空格分隔的表格行；表头和分隔线会被跳过。
+func (p *reportParser) parseTableRow(line string) {
+	if strings.Trim(line, "- ") == "" {
+		return
+	}
+	hidden := strings.HasPrefix(line, "*")
+	if hidden {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+	}
+	columns := columnSeparator.Split(line, -1)
+	column := func(index int) string {
+		if index < len(columns) {
+			return strings.TrimSpace(columns[index])
+		}
+		return ""
+	}
+
+	switch p.section {
+	case sectionVideo:
+		if column(0) == "Codec" || len(columns) < 2 {
+			return
+		}
+		p.current.Video = append(p.current.Video, VideoStream{
+			Codec:       column(0),
+			BitrateKbps: parseReportRate(column(1)),
+			Description: column(2),
+			Hidden:      hidden,
+		})
+	case sectionAudio:
+		if column(0) == "Codec" || len(columns) < 3 {
+			return
+		}
+		p.current.Audio = append(p.current.Audio, AudioStream{
+			Codec:       column(0),
+			Language:    column(1),
+			BitrateKbps: parseReportRate(column(2)),
+			Description: column(3),
+			Hidden:      hidden,
+		})
+	case sectionSubtitles:
+		if column(0) == "Codec" || len(columns) < 3 {
+			return
+		}
+		p.current.Subtitles = append(p.current.Subtitles, SubtitleStream{
+			Codec:       column(0),
+			Language:    column(1),
+			BitrateKbps: parseReportRate(column(2)),
+			Description: column(3),
+			Hidden:      hidden,
+		})
+	case sectionFiles:
+		if column(0) == "Name" || len(columns) < 4 {
+			return
+		}
+		p.current.Files = append(p.current.Files, StreamFile{
+			Name:             column(0),
+			TimeIn:           column(1),
+			Length:           column(2),
+			LengthSeconds:    parseReportDuration(column(2)),
+			Size:             parseReportInt(column(3)),
+			TotalBitrateKbps: parseReportRate(column(4)),
+		})
+	case sectionChapters:
+		number, err := strconv.Atoi(column(0))
+		if err != nil {
+			return
+		}
+		p.current.Chapters = append(p.current.Chapters, Chapter{
+			Number:        number,
+			TimeIn:        column(1),
+			TimeInSeconds: parseReportDuration(column(1)),
+			Length:        column(2),
+			AvgVideoRate:  column(3),
+		})
+	}
+}
+
+// parseSummaryVideo 解析 QUICK SUMMARY 中的 "Video: 编码 / 码率 / 描述..." 行。
+func (p *reportParser) parseSummaryVideo(value string, hidden bool) {
+	if p.current == nil || p.summaryOnly {
+		return
+	}
+	parts := splitSummary(value)
+	stream := VideoStream{Codec: parts[0], Hidden: hidden}
+	if len(parts) > 1 {
+		stream.BitrateKbps = parseReportRate(parts[1])
+		stream.Description = strings.Join(parts[2:], " / ")
+	}
+	p.current.Video = append(p.current.Video, stream)
+}
+
+// parseSummaryAudio 解析 QUICK SUMMARY 中的 "Audio: 语言 / 编码 / 描述..." 行，码率取描述中第一个 kbps 字段。
+func (p *reportParser) parseSummaryAudio(value string, hidden bool) {
+	if p.current == nil || p.summaryOnly {
+		return
+	}
+	parts := splitSummary(value)
+	stream := AudioStream{Language: parts[0], Hidden: hidden}
+	if len(parts) > 1 {
+		stream.Codec = parts[1]
+		stream.Description = strings.Join(parts[2:], " / ")
+		for _, part := range parts[2:] {
+			if !strings.HasSuffix(strings.ToLower(part), "kbps") {
+				continue
+			}
+			if rate := parseReportRate(part); rate > 0 {
+				stream.BitrateKbps = rate
+				break
+			}
+		}
+	}
+	p.current.Audio = append(p.current.Audio, stream)
+}
+
+// parseSummarySubtitle 解析 QUICK SUMMARY 中的 "Subtitle: 语言 / 码率" 行；QUICK SUMMARY 只列出 PG 字幕。
+func (p *reportParser) parseSummarySubtitle(value string, hidden bool) {
+	if p.current == nil || p.summaryOnly {
+		return
+	}
+	parts := splitSummary(value)
+	stream := SubtitleStream{Codec: "Presentation Graphics", Language: parts[0], Hidden: hidden}
+	if len(parts) > 1 {
+		stream.BitrateKbps = parseReportRate(parts[1])
+		stream.Description = strings.Join(parts[2:], " / ")
+	}
+	p.current.Subtitles = append(p.current.Subtitles, stream)
+}
+
+// splitReportField 把 "Key: value" 拆成键和值；键中不能包含两个以上连续空格，以免误判表格行。
+func splitReportField(line string) (string, string, bool) {
+	index := strings.Index(line, ":")
+	if index <= 0 {
+		return "", "", false
+	}
+	key := strings.TrimSpace(line[:index])
+	if columnSeparator.MatchString(key) {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(line[index+1:]), true
+}
+
+// splitSummary 按 " / " 拆分 QUICK SUMMARY 字段，至少返回一个元素。
+func splitSummary(value string) []string {
+	parts := strings.Split(value, " / ")
+	for index := range parts {
+		parts[index] = strings.TrimSpace(parts[index])
+	}
+	return parts
+}
+
+// isPlaylistName 判断值是否是 MPLS 播放列表文件名。
+func isPlaylistName(value string) bool {
+	return strings.HasSuffix(strings.ToUpper(strings.TrimSpace(value)), ".MPLS")
+}
+
+// setIfEmpty 只在目标为空时写入非空值，保证同名字段以第一次出现为准。
+func setIfEmpty(target *string, value string) {
+	if *target == "" && value != "" {
+		*target = value
+	}
+}
+
+// stripLengthSuffix 去掉时长后面的 "(h:m:s.ms)" 说明。
+func stripLengthSuffix(value string) string {
+	if index := strings.Index(value, "("); index >= 0 {
+		value = value[:index]
+	}
+	return strings.TrimSpace(value)
+}
+
+// parseReportInt 解析带千位分隔符的整数，例如 "46,112,232,448 bytes"。
+func parseReportInt(value string) int64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	parsed, err := strconv.ParseInt(strings.ReplaceAll(fields[0], ",", ""), 10, 64)
+	if err != nil || parsed < 0 {
+		return 0
+	}
+	return parsed
+}
+
+// parseReportRate 解析 "29999 kbps"、"45.31 Mbps" 或不带单位的 "45,305" 形式的码率，返回数值部分。
+func parseReportRate(value string) float64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	if len(fields) > 1 {
+		unit := strings.ToLower(fields[1])
+		if unit != "kbps" && unit != "mbps" {
+			return 0
+		}
+	}
+	parsed, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", ""), 64)
+	if err != nil || parsed < 0 {
+		return 0
+	}
+	return parsed
+}
+
+// parseReportDuration 解析 "2:08:19.482" 形式的时长，返回秒数。
+func parseReportDuration(value string) float64 {
+	parts := strings.Split(stripLengthSuffix(value), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0
+	}
+	var seconds float64
+	for _, part := range parts {
+		parsed, err := strconv.ParseFloat(part, 64)
+		if err != nil || parsed < 0 {
+			return 0
+		}
+		seconds = seconds*60 + parsed
+	}
+	return seconds
+}
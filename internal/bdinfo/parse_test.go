@@ -0,0 +1,175 @@
+// Package bdinfo 验证 BDInfo 报告的结构化解析。
+
+package bdinfo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testFullReport = `Disc Title:     MOVIE_TITLE
+Disc Label:     MOVIE
+Disc Size:      46,112,232,448 bytes
+Protection:     AACS
+Extras:         BD-Java
+BDInfo:         0.7.5.5
+
+********************
+PLAYLIST: 00800.MPLS
+********************
+
+<--- BEGIN FORUMS PASTE --->
+[code]
+                                                                                                                Total   Video
+Title                                                           Codec   Length  Movie Size       Disc Size       Bitrate  Bitrate
+-----                                                           ------  -------  --------------  --------------  -------  -------
+00800.MPLS                                                      AVC     2:08:19  43,598,272,512  46,112,232,448  45.31    29.99
+[/code]
+
+[code]
+
+DISC INFO:
+
+Disc Title:     MOVIE_TITLE
+Disc Label:     MOVIE
+Disc Size:      46,112,232,448 bytes
+Protection:     AACS
+Extras:         BD-Java
+BDInfo:         0.7.5.5
+
+PLAYLIST REPORT:
+
+Name:                   00800.MPLS
+Length:                 2:08:19.482 (h:m:s.ms)
+Size:                   43,598,272,512 bytes
+Total Bitrate:          45.31 Mbps
+
+VIDEO:
+
+Codec                   Bitrate             Description
+-----                   -------             -----------
+MPEG-4 AVC Video        29999 kbps          1080p / 23.976 fps / 16:9 / High Profile 4.1
+* MPEG-4 MVC Video      8500 kbps           1080p / 23.976 fps / 16:9 / Stereo High Profile 4.1
+
+AUDIO:
+
+Codec                           Language        Bitrate         Description
+-----                           --------        -------         -----------
+DTS-HD Master Audio             English         3849 kbps       5.1 / 48 kHz / 3849 kbps / 24-bit (DTS Core: 5.1 / 48 kHz / 1509 kbps / 24-bit)
+Dolby Digital Audio             French          224 kbps        2.0 / 48 kHz / 224 kbps
+
+SUBTITLES:
+
+Codec                           Language        Bitrate         Description
+-----                           --------        -------         -----------
+Presentation Graphics           English         34.227 kbps
+Presentation Graphics           Chinese         21.5 kbps
+
+FILES:
+
+Name            Time In         Length          Size            Total Bitrate
+----            -------         ------          ----            -------------
+00055.M2TS      0:00:00.000     2:08:19.482     43,598,272,512  45,305
+
+CHAPTERS:
+
+Number          Time In         Length          Avg Video Rate  Max 1-Sec Rate  Max 1-Sec Time
+------          -------         ------          --------------  --------------  --------------
+1               0:00:00.000     0:05:50.599     29,421 kbps     48,236 kbps     00:03:12.567
+2               0:05:50.599     0:06:01.820     30,112 kbps     47,011 kbps     00:09:40.372
+
+STREAM DIAGNOSTICS:
+
+File            PID             Type            Codec           Language        Seconds         Bitrate         Bytes           Packets
+----            ---             ----            -----           --------        --------------  --------------  -------------   -----
+00055.M2TS      4113 (0x1011)   0x1B            AVC                             7699.482        29,999          28,874,000,000  153,000,000
+[/code]
+<---- END FORUMS PASTE ---->
+
+QUICK SUMMARY:
+
+Disc Title: MOVIE_TITLE
+Disc Size: 46,112,232,448 bytes
+Protection: AACS
+Playlist: 00800.MPLS
+Size: 43,598,272,512 bytes
+Length: 2:08:19.482
+Total Bitrate: 45.31 Mbps
+Video: MPEG-4 AVC Video / 29999 kbps / 1080p / 23.976 fps / 16:9 / High Profile 4.1
+Audio: English / DTS-HD Master Audio / 5.1 / 48 kHz / 3849 kbps / 24-bit
+Subtitle: English / 34.227 kbps
+`
+
+// TestParseReportReadsFullReport 验证完整报告的光盘信息、流表、文件和章节都能解析，且 QUICK SUMMARY 不会重复添加流。
+func TestParseReportReadsFullReport(t *testing.T) {
+	report, err := ParseReport(testFullReport)
+	if err != nil {
+		t.Fatalf("ParseReport() error: %v", err)
+	}
+
+	if report.DiscTitle != "MOVIE_TITLE" || report.DiscLabel != "MOVIE" || report.DiscSize != 46112232448 || report.Protection != "AACS" || report.BDInfoVersion != "0.7.5.5" {
+		t.Fatalf("disc info = %+v", report)
+	}
+	if len(report.Playlists) != 1 {
+		t.Fatalf("len(Playlists) = %d, want 1", len(report.Playlists))
+	}
+
+	playlist := report.Playlists[0]
+	if playlist.Name != "00800.MPLS" || playlist.Length != "2:08:19.482" || playlist.Size != 43598272512 || playlist.TotalBitrateMbps != 45.31 {
+		t.Fatalf("playlist = %+v", playlist)
+	}
+	if playlist.LengthSeconds < 7699.48 || playlist.LengthSeconds > 7699.49 {
+		t.Fatalf("LengthSeconds = %v, want 7699.482", playlist.LengthSeconds)
+	}
+	if len(playlist.Video) != 2 || playlist.Video[0].BitrateKbps != 29999 || playlist.Video[0].Description != "1080p / 23.976 fps / 16:9 / High Profile 4.1" || !playlist.Video[1].Hidden {
+		t.Fatalf("Video = %+v, want AVC plus hidden MVC", playlist.Video)
+	}
+	if len(playlist.Audio) != 2 || playlist.Audio[0].Codec != "DTS-HD Master Audio" || playlist.Audio[0].Language != "English" || playlist.Audio[1].BitrateKbps != 224 {
+		t.Fatalf("Audio = %+v, want DTS-HD MA English and AC3 French", playlist.Audio)
+	}
+	if len(playlist.Subtitles) != 2 || playlist.Subtitles[1].Language != "Chinese" || playlist.Subtitles[0].BitrateKbps != 34.227 {
+		t.Fatalf("Subtitles = %+v, want English and Chinese PG", playlist.Subtitles)
+	}
+	if len(playlist.Files) != 1 || playlist.Files[0].Name != "00055.M2TS" || playlist.Files[0].Size != 43598272512 || playlist.Files[0].TotalBitrateKbps != 45305 {
+		t.Fatalf("Files = %+v", playlist.Files)
+	}
+	if len(playlist.Chapters) != 2 || playlist.Chapters[1].TimeIn != "0:05:50.599" || playlist.Chapters[1].AvgVideoRate != "30,112 kbps" {
+		t.Fatalf("Chapters = %+v", playlist.Chapters)
+	}
+
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+}
+
+// TestParseReportReadsQuickSummary 验证只有 QUICK SUMMARY 的报告也能解析出播放列表和流信息。
+func TestParseReportReadsQuickSummary(t *testing.T) {
+	report, err := ParseReport(`Disc Label: MOVIE
+Playlist: 00001.MPLS
+Length: 1:30:00.000
+Video: MPEG-H HEVC Video / 60000 kbps / 2160p / 23.976 fps / 16:9 / Main 10 @ Level 5.1 @ High / 10 bits / HDR10
+Audio: Japanese / Dolby TrueHD/Atmos Audio / 7.1 / 48 kHz / 4500 kbps / 24-bit (AC3 Embedded: 5.1 / 48 kHz / 640 kbps)
+* Subtitle: Japanese / 40.1 kbps
+`)
+	if err != nil {
+		t.Fatalf("ParseReport() error: %v", err)
+	}
+	if len(report.Playlists) != 1 {
+		t.Fatalf("len(Playlists) = %d, want 1", len(report.Playlists))
+	}
+
+	playlist := report.Playlists[0]
+	if playlist.LengthSeconds != 5400 || len(playlist.Video) != 1 || playlist.Video[0].Codec != "MPEG-H HEVC Video" || playlist.Video[0].BitrateKbps != 60000 {
+		t.Fatalf("playlist = %+v", playlist)
+	}
+	if len(playlist.Audio) != 1 || playlist.Audio[0].Language != "Japanese" || playlist.Audio[0].Codec != "Dolby TrueHD/Atmos Audio" || playlist.Audio[0].BitrateKbps != 4500 {
+		t.Fatalf("Audio = %+v", playlist.Audio)
+	}
+	if len(playlist.Subtitles) != 1 || !playlist.Subtitles[0].Hidden || playlist.Subtitles[0].BitrateKbps != 40.1 {
+		t.Fatalf("Subtitles = %+v", playlist.Subtitles)
+	}
+
+	if _, err := ParseReport("no report here"); err != ErrEmptyReport {
+		t.Fatalf("ParseReport(garbage) error = %v, want ErrEmptyReport", err)
+	}
+}
@@ -0,0 +1,59 @@
+// Package bdinfo 负责调用 BDInfo 可执行文件并整理输出结果。
+
+package bdinfo
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"minfo/internal/system"
+	"minfo/internal/taskprogress"
+)
+
+// BDInfo 流程对外发出的进度阶段；step 型事件的 total 固定为 StageCount。
+const (
+	StageResolve  = "解析输入源"
+	StagePrepare  = "准备扫描目录"
+	StageScan     = "扫描蓝光目录"
+	StageGenerate = "生成报告"
+	StageReport   = "读取报告"
+
+	StageCount = 5
+)
+
+var scanProgressPattern = regexp.MustCompile(`^Scanning\s+(\d+)%\s+-\s+(.+)$`)
+
+// progress 会在可选进度回调存在时发出一条 step 型事件。
+func (o RunOptions) progress(stage string, current int, detail string) {
+	o.Progress.Emit(taskprogress.Step(stage, current, StageCount, detail))
+}
+
+// commandOutput 会包装命令输出回调：原样转发每一行，并把 BDInfo 的扫描与报告输出转换为进度事件。
+func (o RunOptions) commandOutput() system.OutputLineHandler {
+	return func(stream, line string) {
+		if o.CommandOutput != nil {
+			o.CommandOutput(stream, line)
+		}
+		if o.Progress == nil {
+			return
+		}
+
+		text := strings.TrimSpace(line)
+		switch {
+		case scanProgressPattern.MatchString(text):
+			matches := scanProgressPattern.FindStringSubmatch(text)
+			percent, _ := strconv.Atoi(matches[1])
+			o.Progress.Emit(taskprogress.Event{
+				Kind:    taskprogress.KindPercent,
+				Stage:   StageScan,
+				Current: percent,
+				Total:   100,
+				Percent: float64(percent),
+				Detail:  strings.TrimSpace(matches[2]),
+			})
+		case strings.HasPrefix(text, "Please wait while we generate the report"):
+			o.progress(StageGenerate, 4, "BDInfo 已完成扫描，正在生成报告。")
+		}
+	}
+}
@@ -11,14 +11,16 @@ import (
 
 	"minfo/internal/media"
 	"minfo/internal/system"
+	"minfo/internal/taskprogress"
 )
 
 const defaultBinaryPath = system.BDInfoBinaryPath
 
-// RunOptions 定义 BDInfo 执行过程中的可选日志回调。
+// RunOptions 定义 BDInfo 执行过程中的可选日志与进度回调。
 type RunOptions struct {
 	CommandOutput system.OutputLineHandler
 	Logf          func(format string, args ...any)
+	Progress      taskprogress.Handler
 }
 
 // Result 表示一次 BDInfo 运行返回的最终结果。
@@ -38,6 +40,7 @@ func Run(ctx context.Context, inputPath string, options RunOptions) (Result, err
 	if resolved.Playlist != "" {
 		options.logf("[bdinfo] 指定 playlist: %s", resolved.Playlist)
 	}
+	options.progress(StageResolve, 1, "已确定 BDInfo 实际检测路径。")
 
 	binaryPath, err := resolveBinary()
 	if err != nil {
@@ -50,6 +53,7 @@ func Run(ctx context.Context, inputPath string, options RunOptions) (Result, err
 		return Result{}, err
 	}
 	defer staged.cleanup()
+	options.progress(StagePrepare, 2, "已准备好 BDInfo 扫描目录。")
 
 	args, err := buildCommandArgs(staged.scanInput, staged.workDir, resolved.Playlist)
 	if err != nil {
@@ -57,7 +61,9 @@ func Run(ctx context.Context, inputPath string, options RunOptions) (Result, err
 	}
 	options.logf("[bdinfo] 执行命令: cwd=%s | %s", staged.workDir, formatCommand(binaryPath, args...))
 
-	stdout, stderr, err := system.RunCommandInDirLive(ctx, staged.workDir, binaryPath, options.CommandOutput, args...)
+	options.progress(StageScan, 3, "BDInfo 已启动扫描，正在读取蓝光文件。")
+
+	stdout, stderr, err := system.RunCommandInDirLive(ctx, staged.workDir, binaryPath, options.commandOutput(), args...)
 	if err != nil {
 		return Result{}, fmt.Errorf(system.BestErrorMessage(err, stderr, stdout))
 	}
@@ -71,6 +77,7 @@ func Run(ctx context.Context, inputPath string, options RunOptions) (Result, err
 		return Result{}, err
 	}
 	options.logf("[bdinfo] 输出报告: %s", reportPath)
+	options.progress(StageReport, 5, "已生成报告文件，正在读取结果。")
 
 	reportBytes, err := os.ReadFile(reportPath)
 	if err != nil {
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"minfo/internal/taskprogress"
 )
 
 // TestDefaultBinaryPath 验证默认 BDInfo 路径已经统一到 /usr/local/bin/bdinfo。
@@ -127,3 +129,35 @@ func TestFindReportFilePrefersTextReport(t *testing.T) {
 		t.Fatalf("findReportFile() = %q, want %q", got, reportPath)
 	}
 }
+
+// TestCommandOutputConvertsScanAndReportLinesToProgressEvents 验证扫描百分比和生成报告提示会转换成结构化进度事件，原始输出仍会转发。
+func TestCommandOutputConvertsScanAndReportLinesToProgressEvents(t *testing.T) {
+	var lines []string
+	var events []taskprogress.Event
+	options := RunOptions{
+		CommandOutput: func(stream, line string) { lines = append(lines, line) },
+		Progress:      func(event taskprogress.Event) { events = append(events, event) },
+	}
+
+	output := options.commandOutput()
+	output("stdout", "Please wait while we scan the disc...")
+	output("stdout", "Scanning  42% - 00010.M2TS     00:00:12  |  00:00:15")
+	output("stdout", "Please wait while we generate the report...")
+
+	if len(lines) != 3 {
+		t.Fatalf("forwarded lines = %d, want 3", len(lines))
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %#v, want 2 events", events)
+	}
+	scan := events[0]
+	if scan.Kind != taskprogress.KindPercent || scan.Stage != StageScan || scan.Percent != 42 || scan.Current != 42 || scan.Total != 100 {
+		t.Fatalf("scan event = %#v, want 42%% scan progress", scan)
+	}
+	if scan.Detail != "00010.M2TS     00:00:12  |  00:00:15" {
+		t.Fatalf("scan detail = %q, want playlist detail", scan.Detail)
+	}
+	if report := events[1]; report.Kind != taskprogress.KindStep || report.Stage != StageGenerate || report.Current != 4 || report.Total != StageCount {
+		t.Fatalf("report event = %#v, want generate step 4/%d", report, StageCount)
+	}
+}
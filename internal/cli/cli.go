@@ -0,0 +1,233 @@
+// Package cli 提供 minfo 的命令行子命令，在本机直接运行 MediaInfo、BDInfo、截图和制种流程而不启动 HTTP 服务。
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+
+	"minfo/internal/system"
+	"minfo/internal/taskprogress"
+	"minfo/internal/version"
+)
+
+// 命令行进程的退出码。
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command 描述一个子命令的名称、用法说明和执行入口。
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, s *session, args []string) int
+}
+
+// commands 是全部子命令，按帮助信息中的展示顺序排列。
+var commands = []command{
+	{name: "mediainfo", summary: "输出媒体文件或原盘的 MediaInfo 信息", run: runMediaInfoCommand},
+	{name: "bdinfo", summary: "扫描蓝光原盘并输出 BDInfo 报告", run: runBDInfoCommand},
+	{name: "shots", summary: "生成截图，可选上传到图床", run: runShotsCommand},
+	{name: "torrent", summary: "使用 mkbrr 制作种子", run: runTorrentCommand},
+	{name: "remote", summary: "通过运行中的 minfo 服务处理服务器上的媒体并下载结果", run: runRemoteCommand},
+}
+
+// Run 会执行 args 指定的子命令并返回进程退出码：结果写入 stdout，进度和日志写入 stderr。
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+		return exitOK
+	case "version", "-version", "--version":
+		fmt.Fprintf(stdout, "minfo %s\n", version.Version)
+		return exitOK
+	}
+
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "minfo: 未知子命令 %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+	return cmd.run(ctx, &session{stdout: stdout, stderr: stderr}, args[1:])
+}
+
+// lookupCommand 按名称查找子命令。
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printUsage 会输出全部子命令的概览。
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: minfo [serve]            启动 HTTP 服务")
+	fmt.Fprintln(w, "      minfo <子命令> [参数] <路径>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "子命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "使用 minfo <子命令> -h 查看各子命令的参数。")
+}
+
+// session 保存一次子命令调用的输出目标和通用参数。
+type session struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	json    bool
+	quiet   bool
+	verbose bool
+
+	mu sync.Mutex
+}
+
+// errorResult 是 --json 模式下失败时输出的结果。
+type errorResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+// newFlagSet 会创建子命令的参数解析器，并注册所有子命令共用的 --json、--quiet 和 --verbose。
+func (s *session) newFlagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(s.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(s.stderr, "用法: minfo %s %s\n\n参数:\n", name, usage)
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&s.json, "json", false, "以 JSON 格式输出结果")
+	flags.BoolVar(&s.quiet, "quiet", false, "不在 stderr 输出进度")
+	flags.BoolVar(&s.verbose, "verbose", false, "在 stderr 输出详细日志和外部命令输出")
+	return flags
+}
+
+// parseInput 会解析参数并返回唯一的输入路径；参数可以出现在路径前后。
+// 解析失败或请求帮助时 ok 为 false，code 是应返回的退出码。
+func (s *session) parseInput(flags *flag.FlagSet, args []string) (path string, code int, ok bool) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return "", exitOK, false
+			}
+			return "", exitUsage, false
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(positional) != 1 || strings.TrimSpace(positional[0]) == "" {
+		fmt.Fprintf(s.stderr, "minfo %s: 需要指定且只能指定一个输入路径\n", flags.Name())
+		flags.Usage()
+		return "", exitUsage, false
+	}
+	return positional[0], exitOK, true
+}
+
+// logf 会在 --verbose 模式下把一行日志写入 stderr。
+func (s *session) logf(format string, args ...any) {
+	if s.verbose {
+		s.writeLine(fmt.Sprintf(format, args...))
+	}
+}
+
+// logLine 会在 --verbose 模式下把截图流程的一行日志写入 stderr。
+func (s *session) logLine(line string) {
+	s.logf("%s", line)
+}
+
+// commandOutput 返回外部命令输出回调，只在 --verbose 模式下转发到 stderr。
+func (s *session) commandOutput(scope string) system.OutputLineHandler {
+	if !s.verbose {
+		return nil
+	}
+	return func(stream, line string) {
+		s.logf("[%s][%s] %s", scope, stream, line)
+	}
+}
+
+// progress 返回把结构化进度写入 stderr 的回调；百分比按整数去重，避免刷屏。--quiet 时返回 nil。
+func (s *session) progress() taskprogress.Handler {
+	if s.quiet {
+		return nil
+	}
+
+	var last string
+	return func(event taskprogress.Event) {
+		var line string
+		if event.Kind == taskprogress.KindPercent {
+			line = taskprogress.FormatPercent(event.Stage, math.Floor(event.Percent), event.Detail)
+		} else {
+			line = taskprogress.FormatStep(event.Stage, event.Current, event.Total, event.Detail)
+		}
+
+		s.mu.Lock()
+		duplicate := line == last
+		last = line
+		s.mu.Unlock()
+		if !duplicate {
+			s.writeLine(line)
+		}
+	}
+}
+
+// writeLine 会串行地向 stderr 写入一行文本。
+func (s *session) writeLine(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(s.stderr, strings.TrimRight(line, "\r\n"))
+}
+
+// writeJSON 会把结果以缩进 JSON 写入 stdout。
+func (s *session) writeJSON(payload any) int {
+	encoder := json.NewEncoder(s.stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(payload); err != nil {
+		fmt.Fprintf(s.stderr, "minfo: %s\n", err.Error())
+		return exitFailure
+	}
+	return exitOK
+}
+
+// writeText 会把文本结果写入 stdout，并保证以换行结尾。
+func (s *session) writeText(text string) int {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if _, err := io.WriteString(s.stdout, text); err != nil {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// fail 会报告子命令失败：--json 模式向 stdout 输出 {"ok":false}，否则把错误写入 stderr。
+func (s *session) fail(name string, err error) int {
+	if s.json {
+		s.writeJSON(errorResult{OK: false, Error: err.Error()})
+	} else {
+		s.writeLine(fmt.Sprintf("minfo %s: %s", name, err.Error()))
+	}
+	return exitFailure
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseInputAcceptsFlagsAroundPath 验证参数写在输入路径前后都能被解析。
+func TestParseInputAcceptsFlagsAroundPath(t *testing.T) {
+	s := &session{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	flags := s.newFlagSet("bdinfo", "<路径>")
+	full := flags.Bool("full", false, "")
+
+	path, _, ok := s.parseInput(flags, []string{"--json", "/media/Movie", "--full"})
+	if !ok || path != "/media/Movie" {
+		t.Fatalf("parseInput() = %q, %v, want path", path, ok)
+	}
+	if !*full || !s.json {
+		t.Fatalf("full = %v, json = %v, want both set", *full, s.json)
+	}
+}
+
+// TestRunRejectsUsageErrors 验证未知子命令、缺少路径和多个路径都返回用法错误退出码。
+func TestRunRejectsUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"mediainfo"},
+		{"bdinfo", "/a", "/b"},
+		{"torrent", "--piece-length", "1000", "/a"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(context.Background(), args, &stdout, &stderr); code != exitUsage {
+			t.Fatalf("Run(%q) = %d, want %d; stderr = %s", args, code, exitUsage, stderr.String())
+		}
+		if stdout.Len() != 0 {
+			t.Fatalf("Run(%q) stdout = %q, want empty", args, stdout.String())
+		}
+	}
+}
+
+// TestRunReportsJSONError 验证 --json 模式下输入路径不存在时向 stdout 输出 ok=false 的结果并返回失败退出码。
+func TestRunReportsJSONError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	missing := filepath.Join(t.TempDir(), "missing.mkv")
+	if code := Run(context.Background(), []string{"mediainfo", "--json", missing}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("Run() = %d, want %d", code, exitFailure)
+	}
+
+	var payload errorResult
+	if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+		t.Fatalf("decode stdout %q: %v", stdout.String(), err)
+	}
+	if payload.OK || !strings.Contains(payload.Error, "path not found") {
+		t.Fatalf("payload = %+v, want path not found error", payload)
+	}
+}
@@ -0,0 +1,151 @@
+// Package cli 提供 mediainfo 和 bdinfo 子命令。
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
+	"minfo/internal/mediainfo"
+	"minfo/internal/system"
+	"minfo/internal/taskprogress"
+)
+
+// runMediaInfoCommand 实现 minfo mediainfo：依次尝试各候选源，输出第一个成功的 MediaInfo 结果。
+func runMediaInfoCommand(ctx context.Context, s *session, args []string) int {
+	flags := s.newFlagSet("mediainfo", "[--mode text|json|xml|html] [--json] <路径>")
+	mode := flags.String("mode", mediainfo.ModeText, "MediaInfo 输出格式：text、json、xml 或 html")
+	input, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	path, cleanup, err := media.ResolveLocalInputPath(ctx, input)
+	if err != nil {
+		return s.fail("mediainfo", err)
+	}
+	defer cleanup()
+
+	bin, err := system.ResolveBin(system.MediaInfoBinaryPath)
+	if err != nil {
+		return s.fail("mediainfo", err)
+	}
+
+	outputMode := mediainfo.NormalizeMode(*mode)
+	output, summary, err := runMediaInfo(ctx, s, bin, path, outputMode)
+	if err != nil {
+		return s.fail("mediainfo", err)
+	}
+	if s.json {
+		return s.writeJSON(transport.InfoResponse{OK: true, Output: output, MediaInfo: summary, OutputFormat: outputMode})
+	}
+	return s.writeText(output)
+}
+
+// runMediaInfo 会对每个候选源先以 JSON 输出探测并解析摘要，非 json 模式再按所选格式运行一次，返回第一个成功的结果。
+func runMediaInfo(ctx context.Context, s *session, bin, path, mode string) (string, *mediainfo.Summary, error) {
+	progress := s.progress()
+	progress.Emit(taskprogress.Step("解析输入源", 1, 2, "正在查找可供 MediaInfo 读取的候选源。"))
+	candidates, cleanup, err := media.ResolveMediaInfoCandidates(ctx, path, media.MediaInfoCandidateLimit)
+	if err != nil {
+		return "", nil, err
+	}
+	defer cleanup()
+
+	var lastErr string
+	for idx, sourcePath := range candidates {
+		sourceDir := filepath.Dir(sourcePath)
+		sourceName := filepath.Base(sourcePath)
+		if media.IsISOServerURL(sourcePath) {
+			sourceDir, sourceName = "", sourcePath
+		}
+		progress.Emit(taskprogress.Step("读取媒体信息", 2, 2, fmt.Sprintf("尝试候选源 %d/%d: %s", idx+1, len(candidates), sourcePath)))
+
+		jsonOutput, errMessage := runMediaInfoOnce(ctx, s, bin, sourceDir, sourceName, mediainfo.ModeJSON)
+		if errMessage != "" {
+			lastErr = errMessage
+			continue
+		}
+		summary, err := mediainfo.ParseSummary([]byte(jsonOutput))
+		if err != nil {
+			lastErr = fmt.Sprintf("mediainfo returned no usable tracks for: %s", sourcePath)
+			continue
+		}
+
+		output := jsonOutput
+		if mode != mediainfo.ModeJSON {
+			if output, errMessage = runMediaInfoOnce(ctx, s, bin, sourceDir, sourceName, mode); errMessage != "" {
+				lastErr = errMessage
+				continue
+			}
+		}
+		return output, summary, nil
+	}
+
+	if lastErr == "" {
+		lastErr = "mediainfo returned empty output"
+	}
+	return "", nil, fmt.Errorf("%s", lastErr)
+}
+
+// runMediaInfoOnce 以指定输出模式对单个候选源运行一次 mediainfo，失败或输出为空时返回错误描述。
+func runMediaInfoOnce(ctx context.Context, s *session, bin, sourceDir, sourceName, mode string) (string, string) {
+	args := append(mediainfo.OutputArgs(mode), sourceName)
+	stdout, stderr, err := system.RunCommandInDirLive(ctx, sourceDir, bin, s.commandOutput("mediainfo"), args...)
+	if err != nil {
+		return "", system.BestErrorMessage(err, stderr, stdout)
+	}
+
+	output := strings.TrimSpace(stdout)
+	if mode == mediainfo.ModeText {
+		output = system.CombineCommandOutput(stdout, stderr)
+	}
+	if output == "" {
+		return "", fmt.Sprintf("mediainfo returned empty output for: %s", sourceName)
+	}
+	return output, ""
+}
+
+// runBDInfoCommand 实现 minfo bdinfo：默认只输出精简代码块，--full 输出完整报告。
+func runBDInfoCommand(ctx context.Context, s *session, args []string) int {
+	flags := s.newFlagSet("bdinfo", "[--full] [--json] <路径>")
+	full := flags.Bool("full", false, "输出完整报告而不是精简代码块")
+	input, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	path, cleanup, err := media.ResolveLocalInputPath(ctx, input)
+	if err != nil {
+		return s.fail("bdinfo", err)
+	}
+	defer cleanup()
+
+	result, err := bdinfo.Run(ctx, path, bdinfo.RunOptions{
+		CommandOutput: s.commandOutput("bdinfo"),
+		Logf:          s.logf,
+		Progress:      s.progress(),
+	})
+	if err != nil {
+		return s.fail("bdinfo", err)
+	}
+
+	output := result.Output
+	if !*full {
+		output = bdinfo.ExtractCodeBlock(output)
+	}
+	if s.json {
+		report, err := bdinfo.ParseReport(result.Output)
+		if err != nil {
+			s.logf("[bdinfo] 结构化解析失败: %s", err.Error())
+			report = nil
+		}
+		return s.writeJSON(transport.InfoResponse{OK: true, Output: output, BDInfo: report})
+	}
+	return s.writeText(output)
+}
@@ -0,0 +1,330 @@
+// Package cli 提供 remote 子命令，通过运行中的 minfo 服务的后台任务接口处理服务器上的媒体。
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"minfo/internal/config"
+	"minfo/internal/mediainfo"
+	"minfo/internal/screenshot"
+	"minfo/internal/torrent"
+)
+
+// defaultRemoteServer 是未指定 --server 和 MINFO_SERVER 时连接的服务地址。
+const defaultRemoteServer = "http://localhost:" + config.DefaultPort
+
+// remoteCommand 描述一个远程子命令的名称、用法说明和执行入口。
+type remoteCommand struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, s *session, client *remoteClient, args []string) int
+}
+
+// remoteCommands 是全部远程子命令，按帮助信息中的展示顺序排列。
+var remoteCommands = []remoteCommand{
+	{name: "mediainfo", summary: "在服务器上运行 MediaInfo 并输出结果", run: runRemoteMediaInfo},
+	{name: "bdinfo", summary: "在服务器上运行 BDInfo 并输出报告", run: runRemoteBDInfo},
+	{name: "shots", summary: "在服务器上截图，下载压缩包或输出图床直链", run: runRemoteShots},
+	{name: "torrent", summary: "在服务器上制作种子并下载 .torrent", run: runRemoteTorrent},
+	{name: "release", summary: "在服务器上生成发布包并下载全部产物", run: runRemoteRelease},
+}
+
+// remoteResult 是远程子命令 --json 模式的输出：Job 是服务端最终的任务响应，Files 是下载到本地的文件。
+type remoteResult struct {
+	OK    bool            `json:"ok"`
+	Job   json.RawMessage `json:"job"`
+	Files []string        `json:"files,omitempty"`
+}
+
+// runRemoteCommand 实现 minfo remote：解析服务地址和认证参数后分发到远程子命令。
+// 认证默认读取 WEB_USERNAME / WEB_PASSWORD 和 API_TOKEN 环境变量，与服务端使用同一组配置。
+func runRemoteCommand(ctx context.Context, s *session, args []string) int {
+	flags := flag.NewFlagSet("remote", flag.ContinueOnError)
+	flags.SetOutput(s.stderr)
+	flags.Usage = func() { printRemoteUsage(s.stderr, flags) }
+	server := flags.String("server", config.Getenv("MINFO_SERVER", defaultRemoteServer), "minfo 服务地址，默认读取 MINFO_SERVER")
+	username := flags.String("user", config.Getenv("WEB_USERNAME", ""), "基础认证用户名，默认读取 WEB_USERNAME")
+	password := flags.String("password", config.Getenv("WEB_PASSWORD", ""), "基础认证密码，默认读取 WEB_PASSWORD")
+	token := flags.String("token", config.Getenv("API_TOKEN", ""), "API 令牌，设置后优先于基础认证，默认读取 API_TOKEN")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	cmd, ok := lookupRemoteCommand(flags.Arg(0))
+	if !ok {
+		fmt.Fprintf(s.stderr, "minfo remote: 未知子命令 %q\n\n", flags.Arg(0))
+		flags.Usage()
+		return exitUsage
+	}
+	client, err := newRemoteClient(*server, *username, *password, *token)
+	if err != nil {
+		fmt.Fprintf(s.stderr, "minfo remote: %s\n", err.Error())
+		return exitUsage
+	}
+	return cmd.run(ctx, s, client, flags.Args()[1:])
+}
+
+// lookupRemoteCommand 按名称查找远程子命令。
+func lookupRemoteCommand(name string) (remoteCommand, bool) {
+	for _, cmd := range remoteCommands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return remoteCommand{}, false
+}
+
+// printRemoteUsage 会输出 remote 的用法、全部远程子命令和连接参数。
+func printRemoteUsage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "用法: minfo remote [--server URL] [--user 用户名] [--password 密码] [--token 令牌] <子命令> [参数] <服务器上的路径>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "子命令:")
+	for _, cmd := range remoteCommands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "连接参数:")
+	flags.PrintDefaults()
+}
+
+// remoteFail 会报告远程子命令失败，错误信息带上 remote 前缀。
+func (s *session) remoteFail(name string, err error) int {
+	return s.fail("remote "+name, err)
+}
+
+// writeRemoteResult 会在 --json 模式下输出服务端任务响应和本地文件，否则输出 text。
+func (s *session) writeRemoteResult(job remoteJob, files []string, text string) int {
+	if s.json {
+		return s.writeJSON(remoteResult{OK: true, Job: job.raw, Files: files})
+	}
+	return s.writeText(text)
+}
+
+// runRemoteMediaInfo 实现 minfo remote mediainfo：提交 MediaInfo 信息任务并输出结果。
+func runRemoteMediaInfo(ctx context.Context, s *session, client *remoteClient, args []string) int {
+	flags := s.newFlagSet("remote mediainfo", "[--mode text|json|xml|html] [--json] <服务器上的路径>")
+	mode := flags.String("mode", mediainfo.ModeText, "MediaInfo 输出格式：text、json、xml 或 html")
+	path, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	form := url.Values{"path": {path}, "kind": {"mediainfo"}, "mediainfo_mode": {mediainfo.NormalizeMode(*mode)}}
+	job, err := client.runJob(ctx, s, "/api/info-jobs", form)
+	if err != nil {
+		return s.remoteFail("mediainfo", err)
+	}
+	return s.writeRemoteResult(job, nil, job.Output)
+}
+
+// runRemoteBDInfo 实现 minfo remote bdinfo：提交 BDInfo 信息任务并输出精简或完整报告。
+func runRemoteBDInfo(ctx context.Context, s *session, client *remoteClient, args []string) int {
+	flags := s.newFlagSet("remote bdinfo", "[--full] [--json] <服务器上的路径>")
+	full := flags.Bool("full", false, "输出完整报告而不是精简代码块")
+	path, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	form := url.Values{"path": {path}, "kind": {"bdinfo"}}
+	if *full {
+		form.Set("bdinfo_mode", "full")
+	}
+	job, err := client.runJob(ctx, s, "/api/info-jobs", form)
+	if err != nil {
+		return s.remoteFail("bdinfo", err)
+	}
+	return s.writeRemoteResult(job, nil, job.Output)
+}
+
+// runRemoteShots 实现 minfo remote shots：默认下载截图压缩包；开启 --upload 时输出图床直链，指定 --out 时同时保存为文本文件。
+func runRemoteShots(ctx context.Context, s *session, client *remoteClient, args []string) int {
+	flags := s.newFlagSet("remote shots", "[--preset 名称] [--count N] [--variant png|jpg|webp|webp-lossy|avif] [--subtitle auto|off] [--hdr libplacebo|zscale] [--upload] [--host 图床] [--proxy URL] [--out 路径] [--json] <服务器上的路径>")
+	preset := flags.String("preset", "", "服务端截图预设名，预设设置的数量和格式优先于 --count 和 --variant")
+	count := flags.Int("count", 0, "截图数量，0 表示使用默认数量")
+	variant := flags.String("variant", screenshot.VariantPNG, "截图格式："+strings.Join(screenshot.VariantNames(), "、"))
+	subtitleMode := flags.String("subtitle", screenshot.SubtitleModeAuto, "字幕模式：auto 或 off")
+	hdrProcessor := flags.String("hdr", screenshot.HDRProcessorLibplacebo, "HDR 处理器：libplacebo 或 zscale")
+	upload := flags.Bool("upload", false, "由服务器上传到图床并输出直链")
+	imageHost := flags.String("host", "", "服务器上传使用的图床，默认使用服务端配置")
+	proxyURL := flags.String("proxy", "", "服务器上传图床时使用的代理地址")
+	out := flags.String("out", "", "压缩包或直链文本的保存路径；压缩包默认保存到当前目录")
+	path, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	form := url.Values{
+		"path":          {path},
+		"mode":          {screenshot.ModeZip},
+		"variant":       {screenshot.NormalizeVariant(*variant)},
+		"subtitle_mode": {screenshot.NormalizeSubtitleMode(*subtitleMode)},
+		"hdr_processor": {screenshot.NormalizeHDRProcessor(*hdrProcessor)},
+		"count":         {strconv.Itoa(screenshot.NormalizeCount(strconv.Itoa(*count)))},
+	}
+	if name := strings.TrimSpace(*preset); name != "" {
+		form.Set("preset", name)
+	}
+	if *upload {
+		form.Set("mode", screenshot.ModeLinks)
+		if name := strings.TrimSpace(*imageHost); name != "" {
+			form.Set("host", name)
+		}
+		if proxy := strings.TrimSpace(*proxyURL); proxy != "" {
+			form.Set("proxy_url", proxy)
+		}
+	}
+	job, err := client.runJob(ctx, s, "/api/screenshot-jobs", form)
+	if err != nil {
+		return s.remoteFail("shots", err)
+	}
+
+	target := strings.TrimSpace(*out)
+	if *upload {
+		links := strings.TrimSpace(job.Output)
+		if target == "" {
+			return s.writeRemoteResult(job, nil, links)
+		}
+		if err := os.WriteFile(target, []byte(links+"\n"), 0o644); err != nil {
+			return s.remoteFail("shots", err)
+		}
+		return s.writeRemoteResult(job, []string{target}, target)
+	}
+
+	if job.DownloadURL == "" {
+		return s.remoteFail("shots", errors.New("server returned no download url"))
+	}
+	saved, err := client.download(ctx, job.DownloadURL, target, "screenshots.zip")
+	if err != nil {
+		return s.remoteFail("shots", err)
+	}
+	return s.writeRemoteResult(job, []string{saved}, saved)
+}
+
+// runRemoteTorrent 实现 minfo remote torrent：在服务器上制种，下载 .torrent 并输出保存路径和 info hash。
+func runRemoteTorrent(ctx context.Context, s *session, client *remoteClient, args []string) int {
+	flags := s.newFlagSet("remote torrent", "[--tracker URL]... [--web-seed URL]... [--private=false] [--comment 文本] [--source 标签] [--piece-length 字节] [--out 路径] [--json] <服务器上的路径>")
+	var trackers, webSeeds stringList
+	flags.Var(&trackers, "tracker", "Tracker 地址，可重复指定")
+	flags.Var(&webSeeds, "web-seed", "Web Seed 地址，可重复指定")
+	private := flags.Bool("private", true, "标记为私有种子")
+	comment := flags.String("comment", "", "种子注释")
+	source := flags.String("source", "", "种子 source 字段")
+	pieceLength := flags.Int64("piece-length", torrent.DefaultPieceLength, "分块大小（字节），必须是 2 的幂")
+	out := flags.String("out", "", "种子保存路径，默认按服务端返回的文件名保存到当前目录")
+	path, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	form := url.Values{"path": {path}}
+	addRemoteTorrentFields(form, trackers, webSeeds, *private, *comment, *source, *pieceLength)
+	job, err := client.runJob(ctx, s, "/api/torrent-jobs", form)
+	if err != nil {
+		return s.remoteFail("torrent", err)
+	}
+	if job.DownloadURL == "" {
+		return s.remoteFail("torrent", errors.New("server returned no download url"))
+	}
+
+	saved, err := client.download(ctx, job.DownloadURL, strings.TrimSpace(*out), torrent.TorrentFilename(path, ""))
+	if err != nil {
+		return s.remoteFail("torrent", err)
+	}
+	return s.writeRemoteResult(job, []string{saved}, fmt.Sprintf("%s\n%s", saved, job.InfoHash))
+}
+
+// runRemoteRelease 实现 minfo remote release：在服务器上生成发布包，并把包内全部文件下载到本地目录。
+func runRemoteRelease(ctx context.Context, s *session, client *remoteClient, args []string) int {
+	flags := s.newFlagSet("remote release", "[--info mediainfo|bdinfo] [--template 名称] [--upload=false] [--host 图床] [--preset 名称] [--count N] [--variant png|jpg|webp|webp-lossy|avif] [--tracker URL]... [--out 目录] [--json] <服务器上的路径>")
+	infoKind := flags.String("info", "mediainfo", "媒体信息类型：mediainfo 或 bdinfo")
+	template := flags.String("template", "", "发布描述模板，默认使用服务端默认模板")
+	upload := flags.Bool("upload", true, "由服务器把截图上传到图床")
+	imageHost := flags.String("host", "", "服务器上传使用的图床，默认使用服务端配置")
+	preset := flags.String("preset", "", "服务端截图预设名，预设设置的数量和格式优先于 --count 和 --variant")
+	count := flags.Int("count", 0, "截图数量，0 表示使用默认数量")
+	variant := flags.String("variant", screenshot.VariantPNG, "截图格式："+strings.Join(screenshot.VariantNames(), "、"))
+	var trackers, webSeeds stringList
+	flags.Var(&trackers, "tracker", "Tracker 地址，可重复指定")
+	flags.Var(&webSeeds, "web-seed", "Web Seed 地址，可重复指定")
+	private := flags.Bool("private", true, "标记为私有种子")
+	comment := flags.String("comment", "", "种子注释")
+	source := flags.String("source", "", "种子 source 字段")
+	pieceLength := flags.Int64("piece-length", torrent.DefaultPieceLength, "分块大小（字节），必须是 2 的幂")
+	out := flags.String("out", "", "发布包保存目录，默认在当前目录按任务 ID 新建")
+	path, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	form := url.Values{
+		"path":      {path},
+		"info_kind": {strings.TrimSpace(*infoKind)},
+		"upload":    {strconv.FormatBool(*upload)},
+		"variant":   {screenshot.NormalizeVariant(*variant)},
+		"count":     {strconv.Itoa(screenshot.NormalizeCount(strconv.Itoa(*count)))},
+	}
+	if name := strings.TrimSpace(*template); name != "" {
+		form.Set("template", name)
+	}
+	if name := strings.TrimSpace(*preset); name != "" {
+		form.Set("preset", name)
+	}
+	if name := strings.TrimSpace(*imageHost); name != "" && *upload {
+		form.Set("host", name)
+	}
+	addRemoteTorrentFields(form, trackers, webSeeds, *private, *comment, *source, *pieceLength)
+	job, err := client.runJob(ctx, s, "/api/release-jobs", form)
+	if err != nil {
+		return s.remoteFail("release", err)
+	}
+
+	dir := strings.TrimSpace(*out)
+	if dir == "" {
+		dir = "release-" + job.JobID
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return s.remoteFail("release", err)
+	}
+	saved := make([]string, 0, len(job.Files))
+	for _, file := range job.Files {
+		target := filepath.Join(dir, filepath.Base(file.Name))
+		if _, err := client.download(ctx, file.URL, target, file.Name); err != nil {
+			return s.remoteFail("release", err)
+		}
+		saved = append(saved, target)
+	}
+	return s.writeRemoteResult(job, saved, strings.Join(saved, "\n"))
+}
+
+// addRemoteTorrentFields 会把制种参数写入任务表单，字段名与服务端制种任务一致。
+func addRemoteTorrentFields(form url.Values, trackers, webSeeds []string, private bool, comment, source string, pieceLength int64) {
+	form["tracker_url"] = trackers
+	form["web_seed_url"] = webSeeds
+	form.Set("private", strconv.FormatBool(private))
+	form.Set("piece_length", strconv.FormatInt(pieceLength, 10))
+	if comment = strings.TrimSpace(comment); comment != "" {
+		form.Set("comment", comment)
+	}
+	if source = strings.TrimSpace(source); source != "" {
+		form.Set("source", source)
+	}
+}
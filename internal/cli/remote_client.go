@@ -0,0 +1,290 @@
+// Package cli 提供远程命令行客户端访问 minfo 服务的 HTTP 调用、任务跟踪和结果下载。
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/taskprogress"
+)
+
+// remotePollInterval 是跟踪远程任务时轮询任务状态的间隔。
+const remotePollInterval = time.Second
+
+// remoteCancelTimeout 是本地中断后向服务端发送取消请求的超时时间。
+const remoteCancelTimeout = 10 * time.Second
+
+// remoteClient 保存远程 minfo 服务的地址和认证信息。
+type remoteClient struct {
+	baseURL  string
+	username string
+	password string
+	token    string
+	http     *http.Client
+}
+
+// remoteJob 是各类任务状态响应中客户端需要的公共字段；raw 保存最近一次响应的原始 JSON。
+type remoteJob struct {
+	OK            bool                    `json:"ok"`
+	JobID         string                  `json:"job_id"`
+	Status        string                  `json:"status"`
+	Output        string                  `json:"output"`
+	DownloadURL   string                  `json:"download_url"`
+	InfoHash      string                  `json:"info_hash"`
+	Files         []transport.ReleaseFile `json:"files"`
+	Error         string                  `json:"error"`
+	LogEntries    []transport.LogEntry    `json:"log_entries"`
+	Progress      *transport.TaskProgress `json:"progress"`
+	QueuePosition int                     `json:"queue_position"`
+
+	raw json.RawMessage
+}
+
+// isFinishedRemoteStatus 判断远程任务是否已经结束。
+func isFinishedRemoteStatus(status string) bool {
+	switch status {
+	case "succeeded", "failed", "canceled", "interrupted":
+		return true
+	default:
+		return false
+	}
+}
+
+// newRemoteClient 会校验服务地址并创建远程客户端。
+func newRemoteClient(server, username, password, token string) (*remoteClient, error) {
+	server = strings.TrimRight(strings.TrimSpace(server), "/")
+	parsed, err := url.Parse(server)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid server url: %q", server)
+	}
+	return &remoteClient{
+		baseURL:  server,
+		username: username,
+		password: password,
+		token:    strings.TrimSpace(token),
+		http:     &http.Client{},
+	}, nil
+}
+
+// newRequest 会创建指向服务端相对路径的请求，并附上令牌或基础认证。
+func (c *remoteClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	case c.password != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+// doJSON 会发送请求并解码任务响应；非 2xx 响应会转换为带服务端错误信息的 error。
+func (c *remoteClient) doJSON(req *http.Request) (remoteJob, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return remoteJob{}, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return remoteJob{}, err
+	}
+	var job remoteJob
+	decodeErr := json.Unmarshal(data, &job)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return remoteJob{}, remoteStatusError(resp.StatusCode, job.Error)
+	}
+	if decodeErr != nil {
+		return remoteJob{}, fmt.Errorf("decode server response: %w", decodeErr)
+	}
+	job.raw = json.RawMessage(data)
+	return job, nil
+}
+
+// remoteStatusError 会把服务端的错误状态码和错误信息整理为 error。
+func remoteStatusError(status int, message string) error {
+	if status == http.StatusUnauthorized {
+		return errors.New("unauthorized: 请检查 --user/--password 或 --token")
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return fmt.Errorf("server returned %d: %s", status, message)
+}
+
+// submit 会以 multipart/form-data 提交任务参数，服务端的任务接口只接受这种编码。
+func (c *remoteClient) submit(ctx context.Context, endpoint string, form url.Values) (remoteJob, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, values := range form {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				return remoteJob{}, err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return remoteJob{}, err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return remoteJob{}, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return c.doJSON(req)
+}
+
+// getJob 会查询一次任务状态。
+func (c *remoteClient) getJob(ctx context.Context, jobPath string) (remoteJob, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, jobPath, nil)
+	if err != nil {
+		return remoteJob{}, err
+	}
+	return c.doJSON(req)
+}
+
+// cancelJob 会请求服务端取消任务；使用独立的超时，避免已取消的本地 context 让请求无法发出。
+func (c *remoteClient) cancelJob(jobPath string) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCancelTimeout)
+	defer cancel()
+	req, err := c.newRequest(ctx, http.MethodDelete, jobPath, nil)
+	if err != nil {
+		return
+	}
+	_, _ = c.doJSON(req)
+}
+
+// runJob 会提交任务并跟踪到结束：进度和新增日志写入 stderr，本地中断时同时取消远程任务。
+// 任务没有成功结束时返回带服务端错误信息的 error。
+func (c *remoteClient) runJob(ctx context.Context, s *session, endpoint string, form url.Values) (remoteJob, error) {
+	job, err := c.submit(ctx, endpoint, form)
+	if err != nil {
+		return remoteJob{}, err
+	}
+	jobPath := endpoint + "/" + url.PathEscape(job.JobID)
+	s.logf("[remote] 已提交任务: %s", job.JobID)
+
+	progress := s.progress()
+	logged := 0
+	ticker := time.NewTicker(remotePollInterval)
+	defer ticker.Stop()
+	for {
+		logged = s.remoteLogs(job.LogEntries, logged)
+		if event, ok := remoteProgressEvent(job); ok {
+			progress.Emit(event)
+		}
+		if isFinishedRemoteStatus(job.Status) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			c.cancelJob(jobPath)
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+		if job, err = c.getJob(ctx, jobPath); err != nil {
+			if ctx.Err() != nil {
+				c.cancelJob(jobPath)
+			}
+			return job, err
+		}
+	}
+
+	if job.Status != "succeeded" {
+		message := job.Error
+		if message == "" {
+			message = "job " + job.Status
+		}
+		return job, errors.New(message)
+	}
+	return job, nil
+}
+
+// remoteLogs 会在 --verbose 模式下输出 logged 之后新增的任务日志，返回已输出的条数。
+func (s *session) remoteLogs(entries []transport.LogEntry, logged int) int {
+	if len(entries) < logged {
+		logged = 0
+	}
+	for _, entry := range entries[logged:] {
+		s.logf("%s", entry.Message)
+	}
+	return len(entries)
+}
+
+// remoteProgressEvent 会把任务响应中的进度快照转换为本地进度事件；排队中的任务显示排队位置，没有进度时返回 false。
+func remoteProgressEvent(job remoteJob) (taskprogress.Event, bool) {
+	if job.QueuePosition > 0 {
+		return taskprogress.Percent("排队", 0, fmt.Sprintf("任务排队中，排在第 %d 位。", job.QueuePosition)), true
+	}
+	if job.Progress == nil {
+		return taskprogress.Event{}, false
+	}
+	return taskprogress.Percent(job.Progress.Stage, job.Progress.Percent, job.Progress.Detail), true
+}
+
+// download 会下载服务端相对路径上的文件并保存到本地，返回保存路径。
+// target 为空时保存到当前目录；target 是已存在的目录时保存到该目录下。文件名取自 Content-Disposition，缺失时使用 fallback。
+func (c *remoteClient) download(ctx context.Context, path, target, fallback string) (string, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var payload remoteJob
+		_ = json.NewDecoder(resp.Body).Decode(&payload)
+		return "", remoteStatusError(resp.StatusCode, payload.Error)
+	}
+
+	name := fallback
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if filename := filepath.Base(params["filename"]); filename != "." && filename != string(filepath.Separator) && filename != "" {
+			name = filename
+		}
+	}
+	destination := target
+	if destination == "" {
+		destination = name
+	} else if info, err := os.Stat(destination); err == nil && info.IsDir() {
+		destination = filepath.Join(destination, name)
+	}
+
+	file, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(destination)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return destination, nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRemoteMediaInfoFollowsJob 验证远程 mediainfo 以 multipart 表单提交任务、携带令牌轮询到结束，并输出任务结果。
+func TestRemoteMediaInfoFollowsJob(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/info-jobs":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("ParseMultipartForm() error = %v", err)
+			}
+			if r.FormValue("path") != "/media/Movie.mkv" || r.FormValue("kind") != "mediainfo" || r.FormValue("mediainfo_mode") != "json" {
+				t.Errorf("form = %v, want path, kind and mode", r.Form)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"ok":true,"job_id":"job-1","status":"pending"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/info-jobs/job-1":
+			polls++
+			_, _ = w.Write([]byte(`{"ok":true,"job_id":"job-1","status":"succeeded","output":"{\"media\":{}}"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"remote", "--server", server.URL, "--token", "secret", "mediainfo", "--quiet", "--mode", "json", "/media/Movie.mkv"}
+	if code := Run(context.Background(), args, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+	if polls != 1 || stdout.String() != "{\"media\":{}}\n" {
+		t.Fatalf("polls = %d, stdout = %q, want job output", polls, stdout.String())
+	}
+}
+
+// TestRemoteTorrentDownloadsFile 验证远程制种会按 Content-Disposition 的文件名把种子保存到 --out 目录，失败任务返回服务端错误。
+func TestRemoteTorrentDownloadsFile(t *testing.T) {
+	status := "succeeded"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/torrent-jobs":
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "job_id": "t1", "status": "pending"})
+		case "/api/torrent-jobs/t1":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"ok": true, "job_id": "t1", "status": status, "error": "mkbrr failed",
+				"info_hash": "abc", "download_url": "/api/torrent-jobs/t1/download",
+			})
+		case "/api/torrent-jobs/t1/download":
+			w.Header().Set("Content-Disposition", `attachment; filename="Movie.torrent"`)
+			_, _ = w.Write([]byte("d4:infodee"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	args := []string{"remote", "--server", server.URL, "torrent", "--quiet", "--out", dir, "/media/Movie"}
+	if code := Run(context.Background(), args, &stdout, &stderr); code != exitOK {
+		t.Fatalf("Run() = %d, stderr = %s", code, stderr.String())
+	}
+	saved := filepath.Join(dir, "Movie.torrent")
+	if data, err := os.ReadFile(saved); err != nil || string(data) != "d4:infodee" {
+		t.Fatalf("saved torrent = %q, %v", data, err)
+	}
+	if stdout.String() != saved+"\nabc\n" {
+		t.Fatalf("stdout = %q, want path and info hash", stdout.String())
+	}
+
+	status = "failed"
+	stdout.Reset()
+	stderr.Reset()
+	if code := Run(context.Background(), args, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("Run() = %d, want %d for failed job", code, exitFailure)
+	}
+	if !strings.Contains(stderr.String(), "mkbrr failed") {
+		t.Fatalf("stderr = %q, want server error", stderr.String())
+	}
+}
@@ -0,0 +1,168 @@
+// Package cli 提供 shots 截图子命令。
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"minfo/internal/config"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
+	"minfo/internal/screenshot"
+)
+
+// shotsResult 是 minfo shots --json 的输出：Files 是保存到本地的截图，LinkItems 是上传后的图床链接，FailedItems 是重试后仍上传失败的截图。
+type shotsResult struct {
+	OK            bool                        `json:"ok"`
+	Files         []string                    `json:"files,omitempty"`
+	Output        string                      `json:"output,omitempty"`
+	LinkItems     []transport.ImageLinkItem   `json:"link_items,omitempty"`
+	FailedItems   []transport.FailedImageItem `json:"failed_items,omitempty"`
+	PNGLossyFiles []string                    `json:"png_lossy_files,omitempty"`
+}
+
+// runShotsCommand 实现 minfo shots：截图先写入临时目录，再移动到 --out；开启 --upload 时上传到图床并输出直链。
+func runShotsCommand(ctx context.Context, s *session, args []string) int {
+	flags := s.newFlagSet("shots", "[--preset 名称] [--count N] [--variant png|jpg|webp|webp-lossy|avif] [--subtitle auto|off] [--hdr libplacebo|zscale] [--upload] [--host 图床] [--proxy URL] [--out 目录] [--json] <路径>")
+	preset := flags.String("preset", "", "截图预设名，从 PRESET_DIR 读取；预设设置的数量和格式优先于 --count 和 --variant")
+	count := flags.Int("count", 0, "截图数量，0 表示使用默认数量")
+	variant := flags.String("variant", screenshot.VariantPNG, "截图格式："+strings.Join(screenshot.VariantNames(), "、"))
+	subtitleMode := flags.String("subtitle", screenshot.SubtitleModeAuto, "字幕模式：auto 或 off")
+	hdrProcessor := flags.String("hdr", screenshot.HDRProcessorLibplacebo, "HDR 处理器：libplacebo 或 zscale")
+	upload := flags.Bool("upload", false, "把截图上传到图床并输出直链")
+	imageHost := flags.String("host", "", "上传使用的图床："+strings.Join(screenshot.ImageHostNames(), "、")+"，默认使用 IMAGE_HOST")
+	proxyURL := flags.String("proxy", "", "上传图床时使用的代理地址")
+	outDir := flags.String("out", "", "截图保存目录；未上传时默认为当前目录，上传时默认不保留截图")
+	input, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+
+	shotPreset := screenshot.Preset{}
+	if name := screenshot.NormalizePresetName(*preset); name != "" {
+		loaded, err := screenshot.LoadPreset(config.PresetDir, name)
+		if err != nil {
+			return s.fail("shots", fmt.Errorf("preset %s: %w", name, err))
+		}
+		shotPreset = loaded
+	}
+
+	path, cleanup, err := media.ResolveLocalInputPath(ctx, input)
+	if err != nil {
+		return s.fail("shots", err)
+	}
+	defer cleanup()
+
+	// 截图流程会清空输出目录，因此总是先写入独立的临时目录。
+	tempDir, err := os.MkdirTemp("", "minfo-cli-screenshot-*")
+	if err != nil {
+		return s.fail("shots", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	uploadOptions := screenshot.UploadOptions{ProxyURL: strings.TrimSpace(*proxyURL), Host: *imageHost, Render: shotPreset.RenderOptions()}
+	normalizedVariant := shotPreset.ResolveVariant(*variant)
+	normalizedSubtitleMode := screenshot.NormalizeSubtitleMode(*subtitleMode)
+	normalizedHDRProcessor := screenshot.NormalizeHDRProcessor(*hdrProcessor)
+	normalizedCount := shotPreset.ResolveCount(strconv.Itoa(*count))
+	var result screenshot.ScreenshotsResult
+	if *upload {
+		// 上传时按所选图床的大小上限压缩截图，图床未知或未配置时在截图前就失败。
+		result, err = screenshot.RunScreenshotsForUploadWithLiveLogs(ctx, path, tempDir, normalizedVariant, normalizedSubtitleMode, normalizedHDRProcessor, normalizedCount, uploadOptions, s.logLine, s.progress())
+	} else {
+		result, err = screenshot.RunScreenshotsWithRenderOptions(ctx, path, tempDir, normalizedVariant, normalizedSubtitleMode, normalizedHDRProcessor, normalizedCount, uploadOptions.Render, s.logLine, s.progress())
+	}
+	if err != nil {
+		return s.fail("shots", err)
+	}
+
+	payload := shotsResult{OK: true}
+	if *upload {
+		uploaded, err := screenshot.UploadScreenshots(ctx, result, uploadOptions, s.logLine, s.progress(), nil)
+		if err != nil {
+			return s.fail("shots", err)
+		}
+		payload.Output = strings.TrimSpace(uploaded.Output)
+		payload.PNGLossyFiles = uploaded.LossyPNGFiles
+		for _, item := range uploaded.Items {
+			payload.LinkItems = append(payload.LinkItems, transport.ImageLinkItem{
+				URL:          strings.TrimSpace(item.URL),
+				ThumbnailURL: strings.TrimSpace(item.ThumbnailURL),
+				Filename:     item.Filename,
+				Size:         item.Size,
+				Width:        item.Width,
+				Height:       item.Height,
+			})
+		}
+		for _, item := range uploaded.Failed {
+			payload.FailedItems = append(payload.FailedItems, transport.FailedImageItem{Filename: item.Filename, Error: item.Error})
+		}
+	} else {
+		payload.PNGLossyFiles = result.LossyPNGFiles
+	}
+
+	target := strings.TrimSpace(*outDir)
+	if target == "" && !*upload {
+		target = "."
+	}
+	if target != "" {
+		if payload.Files, err = moveScreenshots(result.Files, target); err != nil {
+			return s.fail("shots", err)
+		}
+	}
+	for index, file := range payload.PNGLossyFiles {
+		payload.PNGLossyFiles[index] = filepath.Base(file)
+	}
+
+	if s.json {
+		return s.writeJSON(payload)
+	}
+	if *upload {
+		return s.writeText(payload.Output)
+	}
+	return s.writeText(strings.Join(payload.Files, "\n"))
+}
+
+// moveScreenshots 会把临时目录中的截图移动到 dir，并返回移动后的路径；跨文件系统时改为复制。
+func moveScreenshots(files []string, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	moved := make([]string, 0, len(files))
+	for _, file := range files {
+		target := filepath.Join(dir, filepath.Base(file))
+		if err := os.Rename(file, target); err != nil {
+			if err := copyFile(file, target); err != nil {
+				return moved, err
+			}
+		}
+		moved = append(moved, target)
+	}
+	return moved, nil
+}
+
+// copyFile 会把 src 的内容复制到 dst，已存在的目标文件会被覆盖。
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
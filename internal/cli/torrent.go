@@ -0,0 +1,104 @@
+// Package cli 提供 torrent 制种子命令。
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"minfo/internal/media"
+	"minfo/internal/torrent"
+)
+
+// torrentResult 是 minfo torrent --json 的输出。
+type torrentResult struct {
+	OK         bool   `json:"ok"`
+	Path       string `json:"path"`
+	Name       string `json:"name,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+	InfoHash   string `json:"info_hash,omitempty"`
+	InfoHashV2 string `json:"info_hash_v2,omitempty"`
+}
+
+// stringList 是可重复指定的字符串参数，例如多个 --tracker。
+type stringList []string
+
+// String 返回参数的当前取值，供 flag 包输出默认值。
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set 会追加一次参数取值，空白值会被忽略。
+func (l *stringList) Set(value string) error {
+	if value = strings.TrimSpace(value); value != "" {
+		*l = append(*l, value)
+	}
+	return nil
+}
+
+// runTorrentCommand 实现 minfo torrent：调用 mkbrr 制作种子，输出种子文件路径和 info hash。
+func runTorrentCommand(ctx context.Context, s *session, args []string) int {
+	flags := s.newFlagSet("torrent", "[--tracker URL]... [--web-seed URL]... [--private=false] [--comment 文本] [--source 标签] [--piece-length 字节] [--name 名称] [--out 文件] [--json] <路径>")
+	var trackers, webSeeds stringList
+	flags.Var(&trackers, "tracker", "Tracker 地址，可重复指定")
+	flags.Var(&webSeeds, "web-seed", "Web Seed 地址，可重复指定")
+	private := flags.Bool("private", true, "标记为私有种子")
+	comment := flags.String("comment", "", "种子注释")
+	source := flags.String("source", "", "种子 source 字段")
+	pieceLength := flags.Int64("piece-length", torrent.DefaultPieceLength, "分块大小（字节），必须是 2 的幂")
+	name := flags.String("name", "", "种子名称，默认使用输入文件或目录名")
+	outPath := flags.String("out", "", "种子输出路径，默认在当前目录按种子名称生成")
+	input, code, ok := s.parseInput(flags, args)
+	if !ok {
+		return code
+	}
+	if _, err := torrent.PieceLengthExponent(*pieceLength); err != nil {
+		fmt.Fprintf(s.stderr, "minfo torrent: %s\n", err.Error())
+		return exitUsage
+	}
+
+	path, cleanup, err := media.ResolveLocalInputPath(ctx, input)
+	if err != nil {
+		return s.fail("torrent", err)
+	}
+	defer cleanup()
+
+	output := strings.TrimSpace(*outPath)
+	if output == "" {
+		output = torrent.TorrentFilename(path, *name)
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return s.fail("torrent", err)
+	}
+
+	options := torrent.Options{
+		PieceLength: *pieceLength,
+		Private:     *private,
+		Trackers:    trackers,
+		WebSeeds:    webSeeds,
+		Comment:     strings.TrimSpace(*comment),
+		Source:      strings.TrimSpace(*source),
+		Name:        strings.TrimSpace(*name),
+	}
+	if _, err := torrent.Create(ctx, path, output, options, s.commandOutput("mkbrr"), s.progress()); err != nil {
+		return s.fail("torrent", err)
+	}
+
+	meta, err := torrent.ReadMetaInfo(output)
+	if err != nil {
+		return s.fail("torrent", err)
+	}
+	if s.json {
+		return s.writeJSON(torrentResult{
+			OK:         true,
+			Path:       output,
+			Name:       meta.Name,
+			Size:       meta.TotalSize,
+			InfoHash:   meta.InfoHash,
+			InfoHashV2: meta.InfoHashV2,
+		})
+	}
+	return s.writeText(fmt.Sprintf("%s\n%s", output, meta.InfoHash))
+}
@@ -5,6 +5,8 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,11 +19,71 @@ const (
 	MountTimeout          = 30 * time.Second
 	UmountTimeout         = 30 * time.Second
 	DefaultRequestTimeout = 20 * time.Minute
+	DefaultDataDir        = "/data"
+	DefaultJobRetention   = 24 * time.Hour
+	DefaultResultCacheTTL = 30 * 24 * time.Hour
+
+	DefaultMaxJobs           = 4
+	DefaultMaxMediaInfoJobs  = 4
+	DefaultMaxBDInfoJobs     = 1
+	DefaultMaxScreenshotJobs = 2
+	DefaultMaxTorrentJobs    = 1
+	DefaultMaxReleaseJobs    = 1
 )
 
 // RequestTimeout 保存当前服务处理单个请求时使用的统一超时时间。
 var RequestTimeout = DurationFromEnv("REQUEST_TIMEOUT", DefaultRequestTimeout)
 
+// DataDir 保存任务记录、下载缓存等需要跨重启保留的数据目录。
+var DataDir = Getenv("DATA_DIR", DefaultDataDir)
+
+// ConfigDir 保存用户自定义模板等配置文件所在的目录，默认与 DataDir 相同。
+var ConfigDir = Getenv("CONFIG_DIR", DataDir)
+
+// TemplateDir 保存用户自定义发布描述模板（*.tmpl）所在的目录，默认是 ConfigDir 下的 templates。
+var TemplateDir = Getenv("TEMPLATE_DIR", filepath.Join(ConfigDir, "templates"))
+
+// PresetDir 保存截图预设（*.json）所在的目录，默认是 ConfigDir 下的 presets。
+var PresetDir = Getenv("PRESET_DIR", filepath.Join(ConfigDir, "presets"))
+
+// MediaRoots 保存通过 MEDIA_ROOTS 显式配置的媒体根目录条目；为空时改用挂载点自动探测结果。
+var MediaRoots = ListFromEnv("MEDIA_ROOTS")
+
+// MediaRootsFile 指向 JSON 格式的媒体根目录配置文件；设置后优先于 MEDIA_ROOTS。
+var MediaRootsFile = Getenv("MEDIA_ROOTS_FILE", "")
+
+// JobRetention 保存已完成后台任务在内存和持久化存储中的保留时长。
+var JobRetention = DurationFromEnv("JOB_RETENTION", DefaultJobRetention)
+
+// ResultCacheEnabled 控制是否把 MediaInfo 和 BDInfo 结果按源文件指纹缓存到数据目录，默认开启。
+var ResultCacheEnabled = BoolFromEnv("RESULT_CACHE", true)
+
+// ResultCacheTTL 保存结果缓存条目的有效期，过期条目会在读取或启动时删除。
+var ResultCacheTTL = DurationFromEnv("RESULT_CACHE_TTL", DefaultResultCacheTTL)
+
+// MaxJobs 限制所有后台任务同时运行的总数；0 表示不限制。
+var MaxJobs = IntFromEnv("MAX_JOBS", DefaultMaxJobs)
+
+// MaxMediaInfoJobs、MaxBDInfoJobs、MaxScreenshotJobs、MaxTorrentJobs 和 MaxReleaseJobs 分别限制各类后台任务的并发数；0 表示不限制。
+var (
+	MaxMediaInfoJobs  = IntFromEnv("MAX_MEDIAINFO_JOBS", DefaultMaxMediaInfoJobs)
+	MaxBDInfoJobs     = IntFromEnv("MAX_BDINFO_JOBS", DefaultMaxBDInfoJobs)
+	MaxScreenshotJobs = IntFromEnv("MAX_SCREENSHOT_JOBS", DefaultMaxScreenshotJobs)
+	MaxTorrentJobs    = IntFromEnv("MAX_TORRENT_JOBS", DefaultMaxTorrentJobs)
+	MaxReleaseJobs    = IntFromEnv("MAX_RELEASE_JOBS", DefaultMaxReleaseJobs)
+)
+
+// ISO 内部文件交给外部工具时的访问方式。
+const (
+	ISOAccessAuto  = "auto"
+	ISOAccessMount = "mount"
+	ISOAccessHTTP  = "http"
+)
+
+// ISOAccessMode 控制 ISO 内部文件交给 ffmpeg、mediainfo 时的访问方式：
+// auto 先尝试挂载，失败后改用本机 HTTP Range 服务；mount 只挂载；http 只使用 HTTP 服务。
+var ISOAccessMode = isoAccessModeFromEnv("ISO_ACCESS_MODE")
+
 // FFmpegSSECompat 控制是否为 FFmpeg 注入 SSE 兼容环境变量，默认关闭。
 var FFmpegSSECompat = BoolFromEnv("FFMPEG_SSE_COMPAT", false)
 
@@ -34,6 +96,23 @@ func Getenv(key, fallback string) string {
 	return value
 }
 
+// ListFromEnv 会把环境变量 key 按英文逗号拆分成去除空白后的非空列表。
+func ListFromEnv(key string) []string {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
 // DurationFromEnv 解析时长环境变量；当变量缺失、格式非法或结果非正数时返回 fallback。
 func DurationFromEnv(key string, fallback time.Duration) time.Duration {
 	value := strings.TrimSpace(os.Getenv(key))
@@ -49,6 +128,21 @@ func DurationFromEnv(key string, fallback time.Duration) time.Duration {
 	return duration
 }
 
+// IntFromEnv 解析非负整数环境变量；当变量缺失、格式非法或结果为负数时返回 fallback。
+func IntFromEnv(key string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("invalid %s=%q; fallback to %d", key, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
 // BoolFromEnv 解析布尔环境变量；缺失或非法时返回 fallback。
 func BoolFromEnv(key string, fallback bool) bool {
 	value := strings.TrimSpace(strings.ToLower(os.Getenv(key)))
@@ -64,3 +158,15 @@ func BoolFromEnv(key string, fallback bool) bool {
 		return fallback
 	}
 }
+
+// isoAccessModeFromEnv 解析 ISO 访问方式；缺失或非法时返回 auto。
+func isoAccessModeFromEnv(key string) string {
+	value := strings.ToLower(Getenv(key, ISOAccessAuto))
+	switch value {
+	case ISOAccessAuto, ISOAccessMount, ISOAccessHTTP:
+		return value
+	default:
+		log.Printf("invalid %s=%q; fallback to %s", key, value, ISOAccessAuto)
+		return ISOAccessAuto
+	}
+}
@@ -0,0 +1,66 @@
+// Package handlers 提供蓝光播放列表清单接口。
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
+)
+
+// BlurayPlaylistsHandler 列出 path 对应蓝光光盘中的全部播放列表，并标记最可能的正片。
+func BlurayPlaylistsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeBlurayPlaylistResponse(w, http.StatusMethodNotAllowed, transport.BlurayPlaylistResponse{Error: "method not allowed"})
+		return
+	}
+
+	path := strings.TrimSpace(r.URL.Query().Get("path"))
+	listing, err := media.ListBlurayPlaylists(r.Context(), path)
+	if err != nil {
+		writeBlurayPlaylistResponse(w, transport.InputPathStatus(err), transport.BlurayPlaylistResponse{Error: err.Error()})
+		return
+	}
+
+	items := make([]transport.BlurayPlaylistItem, 0, len(listing.Playlists))
+	for _, playlist := range listing.Playlists {
+		items = append(items, transport.BlurayPlaylistItem{
+			Playlist:          playlist.Playlist,
+			Path:              playlist.Path,
+			Duration:          playlist.DurationText,
+			DurationSeconds:   playlist.Duration.Seconds(),
+			Clips:             playlist.Clips,
+			Size:              playlist.Size,
+			Chapters:          playlist.Chapters,
+			AudioLanguages:    nonNilStrings(playlist.AudioLanguages()),
+			SubtitleLanguages: nonNilStrings(playlist.SubtitleLanguages()),
+			MainFeature:       playlist.MainFeature,
+			Suspicious:        playlist.Suspicious,
+			Duplicates:        playlist.Duplicates,
+		})
+	}
+	writeBlurayPlaylistResponse(w, http.StatusOK, transport.BlurayPlaylistResponse{
+		OK:        true,
+		Root:      listing.Root,
+		Playlists: items,
+	})
+}
+
+// writeBlurayPlaylistResponse 把播放列表清单响应编码为 JSON。
+func writeBlurayPlaylistResponse(w http.ResponseWriter, status int, payload transport.BlurayPlaylistResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// nonNilStrings 把 nil 切片替换成空切片，使 JSON 中始终输出数组。
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
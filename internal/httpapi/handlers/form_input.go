@@ -0,0 +1,48 @@
+// Package handlers 提供表单输入在提交时的校验与开始处理时的路径解析。
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
+)
+
+// resolveInputPath 是任务开始处理时解析表单路径的入口；测试可替换它以确认 ISO 只在执行期间挂载或共享。
+var resolveInputPath = media.ResolveInputPathFor
+
+// formInput 是表单提交的媒体输入：source 是用户提交的原始路径，upload 是上传文件保存后的临时路径，二者只有一个非空。
+//
+// 提交时只校验路径和根目录权限；ISO 虚拟路径要等 resolve 时才挂载或共享，排队中的任务不会占用挂载点。
+type formInput struct {
+	source     string
+	upload     string
+	permission media.Permission
+}
+
+// parseFormInput 会校验表单 path 是否存在且所在根目录允许 permission 对应的操作，或保存上传文件；返回的清理函数负责删除上传的临时文件。
+func parseFormInput(r *http.Request, permission media.Permission) (formInput, func(), error) {
+	source, upload, cleanup, err := transport.CheckInputFor(r, permission)
+	if err != nil {
+		return formInput{}, func() {}, err
+	}
+	return formInput{source: source, upload: upload, permission: permission}, cleanup, nil
+}
+
+// resolve 会把输入解析成外部工具可读取的实际路径；ISO 虚拟路径此时才挂载或共享，调用方需在处理结束时调用返回的释放函数。
+func (in formInput) resolve(ctx context.Context) (string, func(), error) {
+	if in.source == "" {
+		return in.upload, func() {}, nil
+	}
+	return resolveInputPath(ctx, in.source, in.permission)
+}
+
+// name 返回推导发布名称使用的路径；上传文件的临时路径保留了原始文件名。
+func (in formInput) name() string {
+	if in.source != "" {
+		return in.source
+	}
+	return in.upload
+}
@@ -0,0 +1,55 @@
+// Package handlers 提供本地图床图片的公开访问接口。
+
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"minfo/internal/screenshot/imagehost"
+)
+
+// localImageCacheMaxAge 是不过期的本地图床图片允许客户端缓存的时长；图片按内容哈希命名，内容不会变化。
+const localImageCacheMaxAge = "31536000"
+
+// LocalImageHandler 处理 GET /i/<hash>.<ext>：返回本地图床中的原图或缩略图，启用有效期时校验链接签名。
+func LocalImageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, imagehost.LocalImagePathPrefix)
+	query := r.URL.Query()
+	secret := imagehost.LocalImageSecret()
+	file, err := imagehost.OpenLocalImage(name, query.Get("expires"), query.Get("signature"), secret, time.Now())
+	switch {
+	case errors.Is(err, imagehost.ErrLocalImageNotFound):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, imagehost.ErrLocalImageExpired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		log.Printf("open local image %s: %v", name, err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if secret == "" {
+		w.Header().Set("Cache-Control", "public, max-age="+localImageCacheMaxAge+", immutable")
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
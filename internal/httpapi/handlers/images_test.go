@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"minfo/internal/screenshot/imagehost"
+)
+
+// TestLocalImageHandlerServesStoredImages 验证本地图床接口返回已保存的图片，并对无效文件名返回 404。
+func TestLocalImageHandlerServesStoredImages(t *testing.T) {
+	dir := t.TempDir()
+	if err := imagehost.ConfigureLocalStore(dir); err != nil {
+		t.Fatalf("ConfigureLocalStore() error = %v", err)
+	}
+	t.Setenv("LOCAL_IMAGE_TTL", "")
+	name := "0123456789abcdef0123456789abcdef.png"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("\x89PNG\r\n\x1a\n"), 0o644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	LocalImageHandler(recorder, httptest.NewRequest(http.MethodGet, "/i/"+name, nil))
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("status = %d, content type = %q, want 200 image/png", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+
+	for _, path := range []string{"/i/missing.png", "/i/0123456789abcdef0123456789abcdef.txt", "/i/fedcba98765432100123456789abcdef.png"} {
+		recorder = httptest.NewRecorder()
+		LocalImageHandler(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusNotFound {
+			t.Fatalf("GET %s status = %d, want 404", path, recorder.Code)
+		}
+	}
+}
@@ -14,7 +14,10 @@ import (
 	"minfo/internal/config"
 	"minfo/internal/httpapi/transport"
 	"minfo/internal/media"
+	"minfo/internal/mediainfo"
+	"minfo/internal/resultcache"
 	"minfo/internal/system"
+	"minfo/internal/taskprogress"
 )
 
 // MediaInfoHandler 返回处理 MediaInfo 请求的 HTTP Handler，并在候选源之间重试直到拿到有效输出。
@@ -34,7 +37,7 @@ func MediaInfoHandler() http.HandlerFunc {
 
 		path, cleanup, err := transport.InputPath(r)
 		if err != nil {
-			writeInfoError(w, http.StatusBadRequest, err.Error(), logger)
+			writeInfoError(w, transport.InputPathStatus(err), err.Error(), logger)
 			return
 		}
 		defer cleanup()
@@ -51,17 +54,20 @@ func MediaInfoHandler() http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), config.RequestTimeout)
 		defer cancel()
 
-		output, err := runMediaInfo(ctx, path, logger, bin)
+		mode := mediainfo.NormalizeMode(r.FormValue("mediainfo_mode"))
+		output, summary, err := runMediaInfo(ctx, path, mode, logger, bin, newInfoCacheRequest(r))
 		if err != nil {
 			writeInfoError(w, http.StatusInternalServerError, err.Error(), logger)
 			return
 		}
 
 		transport.WriteJSON(w, http.StatusOK, transport.InfoResponse{
-			OK:         true,
-			Output:     output,
-			Logs:       logger.String(),
-			LogEntries: logger.Entries(),
+			OK:           true,
+			Output:       output,
+			MediaInfo:    summary,
+			OutputFormat: mode,
+			Logs:         logger.String(),
+			LogEntries:   logger.Entries(),
 		})
 	}
 }
@@ -83,7 +89,7 @@ func BDInfoHandler() http.HandlerFunc {
 
 		path, cleanup, err := transport.InputPath(r)
 		if err != nil {
-			writeInfoError(w, http.StatusBadRequest, err.Error(), logger)
+			writeInfoError(w, transport.InputPathStatus(err), err.Error(), logger)
 			return
 		}
 		defer cleanup()
@@ -92,7 +98,7 @@ func BDInfoHandler() http.HandlerFunc {
 		ctx, cancel := context.WithTimeout(r.Context(), config.RequestTimeout)
 		defer cancel()
 
-		output, err := runBDInfo(ctx, path, r.FormValue("bdinfo_mode"), logger)
+		output, report, err := runBDInfo(ctx, path, r.FormValue("bdinfo_mode"), logger, nil, newInfoCacheRequest(r))
 		if err != nil {
 			writeInfoError(w, http.StatusInternalServerError, err.Error(), logger)
 			return
@@ -100,6 +106,7 @@ func BDInfoHandler() http.HandlerFunc {
 		transport.WriteJSON(w, http.StatusOK, transport.InfoResponse{
 			OK:         true,
 			Output:     output,
+			BDInfo:     report,
 			Logs:       logger.String(),
 			LogEntries: logger.Entries(),
 		})
@@ -111,68 +118,125 @@ func shouldExtractBDInfoCode(mode string) bool {
 	return strings.TrimSpace(strings.ToLower(mode)) != "full"
 }
 
-// runMediaInfo 会执行完整的 MediaInfo 探测流程，并返回最终输出文本。
-func runMediaInfo(ctx context.Context, path string, logger *infoLogger, bin string) (string, error) {
+// runMediaInfo 会执行完整的 MediaInfo 探测流程，返回 mode 对应格式的输出文本和从 JSON 输出提取的规范化摘要。
+//
+// 每个候选源都会先以 JSON 输出探测并解析摘要，非 json 模式再按所选格式运行一次；源文件指纹命中结果缓存时直接返回缓存结果。
+func runMediaInfo(ctx context.Context, path, mode string, logger *infoLogger, bin string, cache infoCacheRequest) (string, *mediainfo.Summary, error) {
+	mode = mediainfo.NormalizeMode(mode)
+	slot, entry, hit := lookupInfoCache(infoKindMediaInfo, mode, cache, logger)
+	if hit {
+		return entry.Output, entry.MediaInfo, nil
+	}
+
 	candidates, sourceCleanup, err := media.ResolveMediaInfoCandidates(ctx, path, media.MediaInfoCandidateLimit)
 	if err != nil {
 		logger.Logf("[mediainfo] 解析候选源失败: %s", err.Error())
-		return "", err
+		return "", nil, err
 	}
 	defer sourceCleanup()
 	logger.Logf("[mediainfo] 候选源数量: %d", len(candidates))
+	logger.Logf("[mediainfo] 输出模式: %s", mode)
 
 	var lastErr string
 	for idx, sourcePath := range candidates {
 		sourceDir := filepath.Dir(sourcePath)
 		sourceName := filepath.Base(sourcePath)
+		if media.IsISOServerURL(sourcePath) {
+			sourceDir, sourceName = "", sourcePath
+		}
 		logger.Logf("[mediainfo] 尝试 %d/%d: %s", idx+1, len(candidates), sourcePath)
-		logger.Logf("[mediainfo] 执行命令: cwd=%s | %s", sourceDir, formatCommand(bin, sourceName))
 
-		stdout, stderr, err := system.RunCommandInDirLive(ctx, sourceDir, bin, logger.CommandOutput("mediainfo"), sourceName)
+		jsonOutput, errMessage := runMediaInfoCommand(ctx, bin, sourceDir, sourceName, mediainfo.ModeJSON, logger)
+		if errMessage != "" {
+			lastErr = errMessage
+			continue
+		}
+		summary, err := mediainfo.ParseSummary([]byte(jsonOutput))
 		if err != nil {
-			lastErr = system.BestErrorMessage(err, stderr, stdout)
-			logger.LogMultiline("[mediainfo][error] ", lastErr)
+			lastErr = fmt.Sprintf("mediainfo returned no usable tracks for: %s", sourcePath)
+			logger.Logf("[mediainfo] 解析 JSON 输出失败: %s", err.Error())
 			continue
 		}
 
-		output := system.CombineCommandOutput(stdout, stderr)
-		if output == "" {
-			lastErr = fmt.Sprintf("mediainfo returned empty output for: %s", sourcePath)
-			logger.Logf("[mediainfo] 返回空输出: %s", sourcePath)
-			continue
+		output := jsonOutput
+		if mode != mediainfo.ModeJSON {
+			if output, errMessage = runMediaInfoCommand(ctx, bin, sourceDir, sourceName, mode, logger); errMessage != "" {
+				lastErr = errMessage
+				continue
+			}
 		}
 
 		logger.Logf("[mediainfo] 完成: %s", sourcePath)
-		return output, nil
+		slot.store(resultcache.Entry{Output: output, MediaInfo: summary}, logger)
+		return output, summary, nil
 	}
 
 	if lastErr == "" {
 		lastErr = "mediainfo returned empty output"
 	}
-	return "", fmt.Errorf("%s", lastErr)
+	return "", nil, fmt.Errorf("%s", lastErr)
 }
 
-// runBDInfo 会执行完整的 BDInfo 探测流程，并按请求模式返回精简或完整输出。
-func runBDInfo(ctx context.Context, path, mode string, logger *infoLogger) (string, error) {
-	result, err := bdinfo.Run(ctx, path, bdinfo.RunOptions{
-		CommandOutput: logger.CommandOutput("bdinfo"),
-		Logf:          logger.Logf,
-	})
+// runMediaInfoCommand 以指定输出模式对单个候选源运行一次 mediainfo，失败或输出为空时返回错误描述。
+//
+// text 模式沿用 stdout 与 stderr 合并后的输出；结构化模式只取 stdout，避免警告信息破坏 JSON/XML/HTML。
+func runMediaInfoCommand(ctx context.Context, bin, sourceDir, sourceName, mode string, logger *infoLogger) (string, string) {
+	args := append(mediainfo.OutputArgs(mode), sourceName)
+	logger.Logf("[mediainfo] 执行命令: cwd=%s | %s", sourceDir, formatCommand(bin, args...))
+
+	stdout, stderr, err := system.RunCommandInDirLive(ctx, sourceDir, bin, logger.CommandOutput("mediainfo"), args...)
 	if err != nil {
-		logger.LogMultiline("[bdinfo][error] ", err.Error())
-		return "", err
+		message := system.BestErrorMessage(err, stderr, stdout)
+		logger.LogMultiline("[mediainfo][error] ", message)
+		return "", message
 	}
 
-	output := result.Output
+	output := strings.TrimSpace(stdout)
+	if mode == mediainfo.ModeText {
+		output = system.CombineCommandOutput(stdout, stderr)
+	}
+	if output == "" {
+		logger.Logf("[mediainfo] 返回空输出: %s", sourceName)
+		return "", fmt.Sprintf("mediainfo returned empty output for: %s", sourceName)
+	}
+	return output, ""
+}
+
+// runBDInfo 会执行完整的 BDInfo 探测流程，并按请求模式返回精简或完整输出，以及从完整报告解析出的结构化结果。
+//
+// 结果缓存保存完整报告，命中时跳过扫描直接按模式整理；结构化解析失败不会让任务失败，只会记录日志并返回 nil 报告。
+func runBDInfo(ctx context.Context, path, mode string, logger *infoLogger, onProgress taskprogress.Handler, cache infoCacheRequest) (string, *bdinfo.Report, error) {
+	slot, entry, hit := lookupInfoCache(infoKindBDInfo, "", cache, logger)
+	output, report := entry.Output, entry.BDInfo
+	if !hit {
+		result, err := bdinfo.Run(ctx, path, bdinfo.RunOptions{
+			CommandOutput: logger.CommandOutput("bdinfo"),
+			Logf:          logger.Logf,
+			Progress:      onProgress,
+		})
+		if err != nil {
+			logger.LogMultiline("[bdinfo][error] ", err.Error())
+			return "", nil, err
+		}
+		logger.Logf("[bdinfo] 完成: %s", result.ResolvedPath)
+
+		output = result.Output
+		report, err = bdinfo.ParseReport(output)
+		if err != nil {
+			logger.Logf("[bdinfo] 结构化解析失败: %s", err.Error())
+			report = nil
+		}
+		slot.store(resultcache.Entry{Output: output, BDInfo: report}, logger)
+	}
+
+	onProgress.Emit(taskprogress.Step(bdinfoStageFormat, bdinfo.StageCount, bdinfo.StageCount, "正在按所选模式整理报告内容。"))
 	if shouldExtractBDInfoCode(mode) {
 		logger.Logf("[bdinfo] 输出模式: 精简报告")
 		output = bdinfo.ExtractCodeBlock(output)
 	} else {
 		logger.Logf("[bdinfo] 输出模式: 完整报告")
 	}
-
-	logger.Logf("[bdinfo] 完成: %s", result.ResolvedPath)
-	return output, nil
+	return output, report, nil
 }
 
 // formatCommand 会把命令和参数格式化成便于日志展示的可读字符串。
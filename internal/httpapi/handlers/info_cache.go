@@ -0,0 +1,161 @@
+// Package handlers 提供 MediaInfo 和 BDInfo 结果缓存的查询、写入与管理接口。
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
+	"minfo/internal/resultcache"
+)
+
+// resultCache 保存当前使用的结果缓存；为 nil 时不读写缓存。
+var resultCache *resultcache.Store
+
+// ConfigureResultCache 设置 MediaInfo 和 BDInfo 使用的结果缓存；传入 nil 会关闭缓存。
+func ConfigureResultCache(store *resultcache.Store) {
+	resultCache = store
+}
+
+// infoCacheRequest 描述一次信息探测的缓存参数。
+type infoCacheRequest struct {
+	// source 是用户提交的原始路径；上传文件没有稳定路径，此时为空且不使用缓存。
+	source  string
+	refresh bool
+}
+
+// newInfoCacheRequest 从请求表单读取原始路径和 refresh 参数。
+func newInfoCacheRequest(r *http.Request) infoCacheRequest {
+	return infoCacheRequest{
+		source:  transport.FormPath(r),
+		refresh: transport.WantsRefresh(r),
+	}
+}
+
+// infoCacheSlot 记录一次探测对应的缓存键，探测成功后用于写回结果；key 为空表示本次不写缓存。
+type infoCacheSlot struct {
+	kind        string
+	mode        string
+	source      string
+	key         string
+	fingerprint string
+}
+
+// lookupInfoCache 计算源文件指纹并查找 kind、mode 对应的缓存；命中时返回缓存条目和 true。
+//
+// refresh 请求会跳过读取但仍返回可写回的槽位，使新结果覆盖旧缓存；指纹计算失败只记录日志，不影响探测本身。
+func lookupInfoCache(kind, mode string, request infoCacheRequest, logger *infoLogger) (infoCacheSlot, resultcache.Entry, bool) {
+	slot := infoCacheSlot{kind: kind, mode: mode, source: request.source}
+	if resultCache == nil || request.source == "" {
+		return slot, resultcache.Entry{}, false
+	}
+
+	fingerprint, err := media.Fingerprint(request.source)
+	if err != nil {
+		logger.Logf("[%s] 计算缓存指纹失败，跳过缓存: %s", kind, err.Error())
+		return slot, resultcache.Entry{}, false
+	}
+	slot.fingerprint = fingerprint
+	slot.key = resultcache.Key(kind, mode, fingerprint)
+
+	if request.refresh {
+		logger.Logf("[%s] 已要求刷新，跳过结果缓存", kind)
+		return slot, resultcache.Entry{}, false
+	}
+	entry, ok := resultCache.Get(slot.key)
+	if !ok {
+		return slot, resultcache.Entry{}, false
+	}
+	logger.Logf("[%s] 命中结果缓存: %s (生成于 %s)", kind, slot.key[:12], formatJobTime(entry.CreatedAt))
+	return slot, entry, true
+}
+
+// store 会把探测成功的结果写入缓存，entry 只需填写输出和结构化结果；写入失败只记录日志。
+func (s infoCacheSlot) store(entry resultcache.Entry, logger *infoLogger) {
+	if resultCache == nil || s.key == "" {
+		return
+	}
+	entry.Key = s.key
+	entry.Kind = s.kind
+	entry.Mode = s.mode
+	entry.Source = s.source
+	entry.Fingerprint = s.fingerprint
+	if err := resultCache.Put(entry); err != nil {
+		logger.Logf("[%s] 写入结果缓存失败: %s", s.kind, err.Error())
+		return
+	}
+	logger.Logf("[%s] 已写入结果缓存: %s", s.kind, s.key[:12])
+}
+
+// ResultCacheHandler 负责结果缓存的管理：GET 列出全部条目，DELETE 按 key、path、kind 清理条目，不带参数时清空缓存。
+func ResultCacheHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		handleResultCacheList(w)
+	case http.MethodDelete:
+		handleResultCachePurge(w, r)
+	default:
+		writeResultCacheResponse(w, http.StatusMethodNotAllowed, transport.ResultCacheResponse{Error: "method not allowed"})
+	}
+}
+
+// handleResultCacheList 返回全部有效缓存条目的摘要。
+func handleResultCacheList(w http.ResponseWriter) {
+	entries, err := resultCache.List()
+	if err != nil {
+		writeResultCacheResponse(w, http.StatusInternalServerError, transport.ResultCacheResponse{Error: err.Error()})
+		return
+	}
+
+	items := make([]transport.ResultCacheItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, transport.ResultCacheItem{
+			Key:        entry.Key,
+			Kind:       entry.Kind,
+			Mode:       entry.Mode,
+			Source:     entry.Source,
+			OutputSize: len(entry.Output),
+			BDInfo:     entry.BDInfo != nil,
+			CreatedAt:  formatJobTime(entry.CreatedAt),
+		})
+	}
+	writeResultCacheResponse(w, http.StatusOK, transport.ResultCacheResponse{OK: true, Entries: items})
+}
+
+// handleResultCachePurge 按查询参数清理缓存条目，并返回删除数量。
+func handleResultCachePurge(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	key := strings.TrimSpace(query.Get("key"))
+	path := strings.TrimSpace(query.Get("path"))
+	kind := normalizeInfoJobKind(query.Get("kind"))
+	if strings.TrimSpace(query.Get("kind")) != "" && kind == "" {
+		writeResultCacheResponse(w, http.StatusBadRequest, transport.ResultCacheResponse{Error: "invalid cache kind"})
+		return
+	}
+
+	var underPath func(resultcache.Entry) bool
+	if path != "" {
+		underPath = resultcache.UnderPath(path)
+	}
+	purged, err := resultCache.Purge(func(entry resultcache.Entry) bool {
+		return (key == "" || entry.Key == key) &&
+			(kind == "" || entry.Kind == kind) &&
+			(underPath == nil || underPath(entry))
+	})
+	if err != nil {
+		writeResultCacheResponse(w, http.StatusInternalServerError, transport.ResultCacheResponse{Error: err.Error()})
+		return
+	}
+	writeResultCacheResponse(w, http.StatusOK, transport.ResultCacheResponse{OK: true, Purged: &purged})
+}
+
+// writeResultCacheResponse 把结果缓存管理响应编码为 JSON，并显式关闭缓存。
+func writeResultCacheResponse(w http.ResponseWriter, status int, payload transport.ResultCacheResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
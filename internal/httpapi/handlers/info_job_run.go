@@ -4,33 +4,18 @@ import (
 	"context"
 	"errors"
 
-	"minfo/internal/config"
 	"minfo/internal/system"
 )
 
-// run 会在后台执行具体的信息类任务，并在结束后更新任务状态和结果。
-func (j *infoJob) run() {
-	defer func() {
-		if j.cancel != nil {
-			j.cancel()
-		}
-		if j.cleanup != nil {
-			j.cleanup()
-		}
-		if j.logger != nil {
-			j.logger.Close()
-		}
-	}()
-
-	if !j.beginRun() {
-		if j.isCancellationRequested() {
-			j.finishCanceled()
-		}
+// execute 会在调度器分配到槽位后解析输入路径并执行具体的信息类任务，ISO 在任务结束时释放；同时更新任务状态和结果。
+func (j *infoJob) execute(ctx context.Context) {
+	inputPath, release, err := j.input.resolve(ctx)
+	if err != nil {
+		j.fail(err)
 		return
 	}
-
-	ctx, cancel := context.WithTimeout(j.taskContext, config.RequestTimeout)
-	defer cancel()
+	defer release()
+	j.inputPath = inputPath
 
 	switch j.kind {
 	case infoKindMediaInfo:
@@ -43,20 +28,20 @@ func (j *infoJob) run() {
 		j.logger.Logf("[mediainfo] 输入路径: %s", j.inputPath)
 		j.logger.Logf("[mediainfo] 使用命令: %s", bin)
 
-		output, err := runMediaInfo(ctx, j.inputPath, j.logger, bin)
+		output, summary, err := runMediaInfo(ctx, j.inputPath, j.mediainfoMode, j.logger, bin, j.cache)
 		if err != nil {
 			j.fail(err)
 			return
 		}
-		j.succeed(output)
+		j.succeedMediaInfo(output, summary)
 	case infoKindBDInfo:
 		j.logger.Logf("[bdinfo] 输入路径: %s", j.inputPath)
-		output, err := runBDInfo(ctx, j.inputPath, j.bdinfoMode, j.logger)
+		output, report, err := runBDInfo(ctx, j.inputPath, j.bdinfoMode, j.logger, j.progressHandler(j.progressState.apply), j.cache)
 		if err != nil {
 			j.fail(err)
 			return
 		}
-		j.succeed(output)
+		j.succeedBDInfo(output, report)
 	default:
 		j.fail(errors.New("unsupported info job kind"))
 	}
@@ -1,165 +1,114 @@
 package handlers
 
 import (
-	"context"
-	"errors"
-	"time"
-
+	"minfo/internal/bdinfo"
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/mediainfo"
+	"minfo/internal/release"
 )
 
 // snapshot 会生成当前任务的安全快照，供 HTTP 接口直接返回。
 func (j *infoJob) snapshot() transport.InfoJobResponse {
 	j.mu.RLock()
 	response := transport.InfoJobResponse{
-		OK:     true,
-		JobID:  j.id,
-		Status: j.status,
-		Kind:   j.kind,
-		Output: j.output,
-		Error:  j.errMessage,
+		OK:           true,
+		JobID:        j.id,
+		Status:       j.status,
+		Kind:         j.kind,
+		Output:       j.output,
+		BDInfo:       j.bdinfoReport,
+		MediaInfo:    j.mediainfoSummary,
+		OutputFormat: j.mediainfoMode,
+		Error:        j.errMessage,
+		Progress:     j.progressLocked(),
+		Template:     j.template,
+		Description:  j.description,
 	}
 	logger := j.logger
 	j.mu.RUnlock()
 
-	var entries []transport.LogEntry
 	if logger != nil {
 		response.Logs = logger.String()
-		entries = logger.Entries()
-		response.LogEntries = entries
+		response.LogEntries = logger.Entries()
+	}
+	if response.Status == jobStatusPending {
+		response.QueuePosition = jobQueuePosition(response.JobID)
 	}
-	response.Progress = buildInfoTaskProgress(response.Kind, response.Status, entries)
+	applyQueueProgress(response.Progress, response.QueuePosition)
 	return response
 }
 
-// expired 会判断后台任务是否已经完成且超过保留时间。
-func (j *infoJob) expired(now time.Time) bool {
+// describe 会生成不含日志的任务记录，供任务列表和持久化共用。
+func (j *infoJob) describe() jobstore.Record {
 	j.mu.RLock()
 	defer j.mu.RUnlock()
 
-	if j.completedAt.IsZero() {
-		return false
+	record := j.recordLocked(jobstore.TypeInfo)
+	record.Kind = j.kind
+	record.InputPath = j.input.source
+	record.Output = j.output
+	record.BDInfo = j.bdinfoReport
+	record.MediaInfo = j.mediainfoSummary
+	if j.bdinfoMode != "" || j.mediainfoMode != "" {
+		record.Options = make(map[string]string, 2)
+		if j.bdinfoMode != "" {
+			record.Options["bdinfo_mode"] = j.bdinfoMode
+		}
+		if j.mediainfoMode != "" {
+			record.Options["mediainfo_mode"] = j.mediainfoMode
+		}
 	}
-	return now.Sub(j.completedAt) > infoJobTTL
+	record.Progress = recordProgress(j.progressLocked())
+	return record
 }
 
-// beginRun 会把任务从 pending 切换到 running；如果任务已被取消，则返回 false。
-func (j *infoJob) beginRun() bool {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	if j.status != infoJobStatusPending {
-		return false
-	}
-	if j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled) {
-		return false
+// progressLocked 会根据已收到的进度事件推导当前进度；重启恢复的任务直接沿用持久化进度。调用方需持有锁。
+func (j *infoJob) progressLocked() *transport.TaskProgress {
+	if j.restoredProgress != nil {
+		return cloneTaskProgress(j.restoredProgress)
 	}
-
-	j.status = infoJobStatusRunning
-	j.updatedAt = time.Now()
-	return true
+	return buildInfoTaskProgress(j.kind, j.status, &j.progressState)
 }
 
-// requestCancel 会请求取消当前任务，并立刻把状态推进到 canceling。
-func (j *infoJob) requestCancel() {
-	var cancel context.CancelFunc
-
-	j.mu.Lock()
-	switch j.status {
-	case infoJobStatusSucceeded, infoJobStatusFailed, infoJobStatusCanceled:
-		j.mu.Unlock()
-		return
-	case infoJobStatusCanceling:
-		j.mu.Unlock()
-		return
-	default:
-		j.cancelRequested = true
-		j.status = infoJobStatusCanceling
-		j.errMessage = "任务取消中。"
-		j.updatedAt = time.Now()
-		cancel = j.cancel
-		j.mu.Unlock()
-	}
-
-	if cancel != nil {
-		cancel()
-	}
+// record 会生成当前任务的持久化快照。
+func (j *infoJob) record() jobstore.Record {
+	record := j.describe()
+	record.LogEntries = recordLogEntries(j.logger.Entries())
+	return record
 }
 
-// succeed 会记录后台任务成功产出的最终输出。
-func (j *infoJob) succeed(output string) {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	now := time.Now()
-	if j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled) {
-		j.status = infoJobStatusCanceled
-		j.output = ""
-		j.errMessage = "任务已取消。"
-		j.updatedAt = now
-		j.completedAt = now
-		return
-	}
-
-	j.status = infoJobStatusSucceeded
-	j.output = output
-	j.errMessage = ""
-	j.updatedAt = now
-	j.completedAt = now
+// persist 会把当前任务快照写入任务仓库。
+func (j *infoJob) persist() {
+	saveJobRecord(j.record())
 }
 
-// fail 会记录后台任务失败原因，并把状态切换为 failed。
-func (j *infoJob) fail(err error) {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	now := time.Now()
-	if j.cancelRequested || isInfoJobCanceledError(err) || errors.Is(j.taskContext.Err(), context.Canceled) {
-		j.status = infoJobStatusCanceled
-		j.output = ""
-		j.errMessage = "任务已取消。"
-		j.updatedAt = now
-		j.completedAt = now
-		return
-	}
-
-	j.status = infoJobStatusFailed
-	j.output = ""
-	if err != nil {
-		j.errMessage = err.Error()
-	} else {
-		j.errMessage = "job failed"
-	}
-	j.updatedAt = now
-	j.completedAt = now
-}
-
-// finishCanceled 会把任务最终标记为 canceled，并记录完成时间。
-func (j *infoJob) finishCanceled() {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	if j.status == infoJobStatusSucceeded || j.status == infoJobStatusFailed || j.status == infoJobStatusCanceled {
-		return
-	}
-
-	now := time.Now()
-	j.status = infoJobStatusCanceled
-	j.output = ""
-	j.errMessage = "任务已取消。"
-	j.updatedAt = now
-	j.completedAt = now
+// succeedMediaInfo 会记录 MediaInfo 任务的最终输出和规范化摘要。
+func (j *infoJob) succeedMediaInfo(output string, summary *mediainfo.Summary) {
+	j.jobBase.succeed(func() {
+		j.output = output
+		j.mediainfoSummary = summary
+	})
 }
 
-// isCancellationRequested 会判断当前任务是否已经收到了取消请求。
-func (j *infoJob) isCancellationRequested() bool {
-	j.mu.RLock()
-	defer j.mu.RUnlock()
-
-	return j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled)
+// succeedBDInfo 会记录 BDInfo 任务的最终输出和结构化报告。
+func (j *infoJob) succeedBDInfo(output string, report *bdinfo.Report) {
+	j.jobBase.succeed(func() {
+		j.output = output
+		j.bdinfoReport = report
+	})
 }
 
-// isInfoJobCanceledError 会判断错误是否来自主动取消而不是普通失败。
-func isInfoJobCanceledError(err error) bool {
-	return errors.Is(err, context.Canceled)
+// releaseDataLocked 会把任务产出整理成发布描述模板数据：MediaInfo 任务填写摘要和原文，BDInfo 任务填写代码块和报告。调用方需持有锁。
+func (j *infoJob) releaseDataLocked() release.Data {
+	data := release.Data{Name: release.NameFromPath(j.input.name())}
+	switch j.kind {
+	case infoKindMediaInfo:
+		data.MediaInfo = j.mediainfoSummary
+		data.MediaInfoText = j.output
+	case infoKindBDInfo:
+		data.BDInfo = bdinfo.ExtractCodeBlock(j.output)
+		data.BDInfoReport = j.bdinfoReport
+	}
+	return data
 }
@@ -1,118 +1,126 @@
 package handlers
 
 import (
-	"context"
-	"crypto/rand"
-	"encoding/hex"
-	"sync"
-	"time"
+	"net/http"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/mediainfo"
 )
 
 const (
 	infoKindMediaInfo = "mediainfo"
 	infoKindBDInfo    = "bdinfo"
-
-	infoJobStatusPending   = "pending"
-	infoJobStatusRunning   = "running"
-	infoJobStatusCanceling = "canceling"
-	infoJobStatusSucceeded = "succeeded"
-	infoJobStatusFailed    = "failed"
-	infoJobStatusCanceled  = "canceled"
-	infoJobTTL             = 30 * time.Minute
 )
 
-var infoJobs = struct {
-	mu    sync.Mutex
-	items map[string]*infoJob
-}{
-	items: make(map[string]*infoJob),
-}
-
 type infoJob struct {
-	mu          sync.RWMutex
-	id          string
-	kind        string
-	inputPath   string
-	bdinfoMode  string
-	status      string
-	output      string
-	errMessage  string
-	createdAt   time.Time
-	updatedAt   time.Time
-	completedAt time.Time
-	logger      *infoLogger
-	cleanup     func()
-	taskContext context.Context
-	cancel      context.CancelFunc
-
-	cancelRequested bool
+	jobBase
+	// input 是提交时的输入，其中用户提交的原始路径会持久化并用于任务列表筛选和发布名称。
+	input formInput
+	// inputPath 是 execute 开始时解析出的实际路径，可能是 ISO 挂载目录、本机 HTTP 地址或上传临时文件，只在执行期间使用。
+	inputPath string
+
+	kind       string
+	bdinfoMode string
+	// mediainfoMode 是 MediaInfo 任务的输出模式（text、json、xml、html）；BDInfo 任务为空。
+	mediainfoMode string
+	// cache 保存提交时的原始路径和 refresh 参数，执行时据此查找或写入结果缓存。
+	cache  infoCacheRequest
+	output string
+	// bdinfoReport 是 BDInfo 任务从完整报告解析出的结构化结果；MediaInfo 任务为空。
+	bdinfoReport *bdinfo.Report
+	// mediainfoSummary 是 MediaInfo 任务从 JSON 输出提取的规范化摘要；BDInfo 任务为空。
+	mediainfoSummary *mediainfo.Summary
+
+	// progressState 保存运行期间收到的结构化进度；restoredProgress 保存重启前持久化的最终进度。
+	progressState    bdinfoProgressState
+	restoredProgress *transport.TaskProgress
 }
 
-// createInfoJob 会创建一个新的信息类后台任务，并立即启动后台执行流程。
-func createInfoJob(kind, inputPath string, cleanup func(), bdinfoMode string) (*infoJob, error) {
-	pruneInfoJobs(time.Now())
+// infoJobOptions 汇总创建信息类任务时从表单读取的可选参数。
+type infoJobOptions struct {
+	bdinfoMode    string
+	mediainfoMode string
+	cache         infoCacheRequest
+	template      string
+}
 
-	jobID, err := buildInfoJobID()
+// newInfoJobOptions 从请求表单读取 BDInfo 报告模式、MediaInfo 输出模式、缓存参数和发布描述模板；模板不存在时返回错误。
+func newInfoJobOptions(r *http.Request) (infoJobOptions, error) {
+	template, err := parseReleaseTemplate(r)
 	if err != nil {
-		return nil, err
+		return infoJobOptions{}, err
 	}
+	return infoJobOptions{
+		bdinfoMode:    r.FormValue("bdinfo_mode"),
+		mediainfoMode: r.FormValue("mediainfo_mode"),
+		cache:         newInfoCacheRequest(r),
+		template:      template,
+	}, nil
+}
 
-	taskContext, cancel := context.WithCancel(context.Background())
-	now := time.Now()
+// createInfoJob 会创建一个新的信息类后台任务，并交给任务管理器排队执行。
+func createInfoJob(kind string, input formInput, cleanup func(), options infoJobOptions) (*infoJob, error) {
 	job := &infoJob{
-		id:          jobID,
-		kind:        kind,
-		inputPath:   inputPath,
-		bdinfoMode:  bdinfoMode,
-		status:      infoJobStatusPending,
-		createdAt:   now,
-		updatedAt:   now,
-		logger:      newInfoLogger(),
-		cleanup:     cleanup,
-		taskContext: taskContext,
-		cancel:      cancel,
+		kind:       kind,
+		input:      input,
+		bdinfoMode: options.bdinfoMode,
+		cache:      options.cache,
 	}
+	if kind == infoKindMediaInfo {
+		job.mediainfoMode = mediainfo.NormalizeMode(options.mediainfoMode)
+	}
+	if err := initJobBase(&job.jobBase, kind, cleanup); err != nil {
+		return nil, err
+	}
+	job.onStatus = job.applyStatusLocked
+	job.template = options.template
+	job.releaseData = job.releaseDataLocked
 
-	infoJobs.mu.Lock()
-	infoJobs.items[jobID] = job
-	infoJobs.mu.Unlock()
-
-	go job.run()
+	submitJob(job)
 	return job, nil
 }
 
-// getInfoJob 返回指定任务；如果任务不存在或已过期，则返回 false。
-func getInfoJob(jobID string) (*infoJob, bool) {
-	pruneInfoJobs(time.Now())
-
-	infoJobs.mu.Lock()
-	defer infoJobs.mu.Unlock()
+// restoreInfoJob 会把持久化记录恢复为一个已结束的信息类任务，供重启后继续查询。
+func restoreInfoJob(record jobstore.Record) {
+	job := &infoJob{
+		kind:             record.Kind,
+		input:            formInput{source: record.InputPath},
+		bdinfoMode:       record.Options["bdinfo_mode"],
+		mediainfoMode:    record.Options["mediainfo_mode"],
+		output:           record.Output,
+		bdinfoReport:     record.BDInfo,
+		mediainfoSummary: record.MediaInfo,
+	}
+	restoreJobBase(&job.jobBase, record.Kind, record)
+	job.onStatus = job.applyStatusLocked
+	job.releaseData = job.releaseDataLocked
+	job.restoredProgress = restoreTaskProgress(transportProgress(record.Progress), job.status)
+	storeRestoredJob(job)
+}
 
-	job, ok := infoJobs.items[jobID]
+// getInfoJob 返回指定任务；如果任务不存在、已过期或不是信息类任务，则返回 false。
+func getInfoJob(jobID string) (*infoJob, bool) {
+	job, ok := lookupJob(jobID)
 	if !ok {
 		return nil, false
 	}
-	return job, true
+	infoJob, ok := job.(*infoJob)
+	return infoJob, ok
 }
 
-// pruneInfoJobs 会删除已完成且超过保留时间的后台任务记录。
-func pruneInfoJobs(now time.Time) {
-	infoJobs.mu.Lock()
-	defer infoJobs.mu.Unlock()
-
-	for jobID, job := range infoJobs.items {
-		if !job.expired(now) {
-			continue
-		}
-		delete(infoJobs.items, jobID)
+// applyStatusLocked 会在任务失败或取消时清空已有输出；调用方需持有写锁。
+func (j *infoJob) applyStatusLocked(status string) {
+	switch status {
+	case jobStatusFailed, jobStatusCanceled:
+		j.output = ""
+		j.bdinfoReport = nil
+		j.mediainfoSummary = nil
+		j.description = ""
 	}
 }
 
-// buildInfoJobID 生成适合 URL 使用的随机任务 ID。
-func buildInfoJobID() (string, error) {
-	buf := make([]byte, 16)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
-	}
-	return hex.EncodeToString(buf), nil
+// discard 会在任务过期时释放额外资源；信息类任务没有需要清理的产物。
+func (j *infoJob) discard() {
 }
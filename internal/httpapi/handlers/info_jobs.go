@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
 )
 
 // InfoJobsHandler 负责创建新的 MediaInfo 或 BDInfo 后台任务，并立即返回任务 ID。
@@ -27,13 +28,19 @@ func InfoJobsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	inputPath, cleanup, err := transport.InputPath(r)
+	options, err := newInfoJobOptions(r)
 	if err != nil {
 		writeInfoJobError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	job, err := createInfoJob(kind, inputPath, cleanup, r.FormValue("bdinfo_mode"))
+	input, cleanup, err := parseFormInput(r, media.PermissionRead)
+	if err != nil {
+		writeInfoJobError(w, transport.InputPathStatus(err), err.Error())
+		return
+	}
+
+	job, err := createInfoJob(kind, input, cleanup, options)
 	if err != nil {
 		if cleanup != nil {
 			cleanup()
@@ -88,7 +95,7 @@ func handleInfoJobDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job.requestCancel()
+	cancelJob(job)
 	writeInfoJobResponse(w, http.StatusOK, job.snapshot())
 }
 
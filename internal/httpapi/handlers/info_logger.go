@@ -14,6 +14,9 @@ import (
 type infoLogger struct {
 	mu    sync.Mutex
 	lines []timedLogLine
+
+	// onEntry 会在持有锁时按写入顺序收到每条新日志，供后台任务同步推送事件。
+	onEntry func(entry transport.LogEntry)
 }
 
 type timedLogLine struct {
@@ -35,11 +38,25 @@ func (l *infoLogger) Logf(format string, args ...any) {
 	}
 	now := time.Now()
 	line := fmt.Sprintf(format, args...)
-	l.mu.Lock()
-	l.lines = append(l.lines, timedLogLine{
+	entry := timedLogLine{
 		timestamp: now,
 		message:   line,
-	})
+	}
+	l.mu.Lock()
+	l.lines = append(l.lines, entry)
+	if l.onEntry != nil {
+		l.onEntry(entry.logEntry())
+	}
+	l.mu.Unlock()
+}
+
+// setEntryListener 会为日志记录器设置新日志回调；nil 表示取消订阅。
+func (l *infoLogger) setEntryListener(listener func(entry transport.LogEntry)) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.onEntry = listener
 	l.mu.Unlock()
 }
 
@@ -111,17 +128,22 @@ func (l *infoLogger) Entries() []transport.LogEntry {
 
 	entries := make([]transport.LogEntry, 0, len(l.lines))
 	for _, line := range l.lines {
-		entry := transport.LogEntry{
-			Message: line.message,
-		}
-		if !line.timestamp.IsZero() {
-			entry.Timestamp = line.timestamp.UTC().Format(time.RFC3339Nano)
-		}
-		entries = append(entries, entry)
+		entries = append(entries, line.logEntry())
 	}
 	return entries
 }
 
+// logEntry 会把带时间戳的日志行转换为结构化日志记录。
+func (line timedLogLine) logEntry() transport.LogEntry {
+	entry := transport.LogEntry{
+		Message: line.message,
+	}
+	if !line.timestamp.IsZero() {
+		entry.Timestamp = line.timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return entry
+}
+
 // Close 会释放当前日志记录器持有的资源。
 func (l *infoLogger) Close() {
 }
@@ -0,0 +1,341 @@
+// Package handlers 提供三类后台任务共用的状态机实现。
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"minfo/internal/config"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/release"
+	"minfo/internal/taskprogress"
+)
+
+const (
+	jobStatusPending     = "pending"
+	jobStatusRunning     = "running"
+	jobStatusCanceling   = "canceling"
+	jobStatusSucceeded   = "succeeded"
+	jobStatusFailed      = "failed"
+	jobStatusCanceled    = "canceled"
+	jobStatusInterrupted = jobstore.StatusInterrupted
+)
+
+// jobBase 保存所有后台任务共有的状态、时间戳、日志和取消控制，由具体任务类型嵌入使用。
+type jobBase struct {
+	mu          sync.RWMutex
+	id          string
+	class       string
+	status      string
+	errMessage  string
+	createdAt   time.Time
+	updatedAt   time.Time
+	startedAt   time.Time
+	completedAt time.Time
+	logger      *infoLogger
+	events      *jobEventStream
+	cleanup     func()
+	taskContext context.Context
+	cancel      context.CancelFunc
+
+	cancelRequested bool
+
+	// onStatus 会在持有锁时收到新状态，供具体任务同步清理或更新自身字段。
+	onStatus func(status string)
+
+	// template 是提交时选择的发布描述模板，description 是任务成功后用它渲染出的描述；未选择模板时均为空。
+	template    string
+	description string
+	// releaseData 会在持有锁时返回任务产出对应的模板数据，由具体任务类型设置。
+	releaseData func() release.Data
+}
+
+// initJobBase 会为新提交的任务分配 ID、日志记录器和可取消上下文。
+func initJobBase(b *jobBase, class string, cleanup func()) error {
+	jobID, err := buildJobID()
+	if err != nil {
+		return err
+	}
+
+	taskContext, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	b.id = jobID
+	b.class = class
+	b.status = jobStatusPending
+	b.createdAt = now
+	b.updatedAt = now
+	b.events = newJobEventStream()
+	b.logger = newInfoLogger()
+	b.logger.setEntryListener(b.events.publishLogEntry)
+	b.cleanup = cleanup
+	b.taskContext = taskContext
+	b.cancel = cancel
+	b.publishStatusLocked()
+	return nil
+}
+
+// restoreJobBase 会用持久化记录填充一个已结束任务的公共字段。
+func restoreJobBase(b *jobBase, class string, record jobstore.Record) {
+	b.id = record.ID
+	b.class = class
+	b.status = record.Status
+	b.errMessage = record.Error
+	b.createdAt = record.CreatedAt
+	b.updatedAt = record.UpdatedAt
+	b.startedAt = record.StartedAt
+	b.completedAt = record.CompletedAt
+	b.template = record.Template
+	b.description = record.Description
+	entries := transportLogEntries(record.LogEntries)
+	b.logger = restoreInfoLogger(entries)
+	b.taskContext = context.Background()
+
+	b.events = newJobEventStream()
+	for _, entry := range entries {
+		b.events.publishLogEntry(entry)
+	}
+	b.logger.setEntryListener(b.events.publishLogEntry)
+	b.publishStatusLocked()
+}
+
+// base 返回任务嵌入的公共状态，供任务管理器统一调度。
+func (b *jobBase) base() *jobBase {
+	return b
+}
+
+// eventStream 会在持有读锁时返回任务的事件流。
+func (b *jobBase) eventStream() *jobEventStream {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.events
+}
+
+// recordLocked 会生成只包含公共字段的持久化记录，具体任务再补充自身参数和结果；调用方需持有锁。
+func (b *jobBase) recordLocked(recordType string) jobstore.Record {
+	return jobstore.Record{
+		ID:          b.id,
+		Type:        recordType,
+		Status:      b.status,
+		Error:       b.errMessage,
+		CreatedAt:   b.createdAt,
+		UpdatedAt:   b.updatedAt,
+		StartedAt:   b.startedAt,
+		CompletedAt: b.completedAt,
+		Template:    b.template,
+		Description: b.description,
+	}
+}
+
+// setStatusLocked 会切换任务状态并通知具体任务；调用方需持有写锁。
+func (b *jobBase) setStatusLocked(status string, now time.Time) {
+	b.status = status
+	b.updatedAt = now
+	if b.onStatus != nil {
+		b.onStatus(status)
+	}
+	b.publishStatusLocked()
+}
+
+// publishStatusLocked 会把当前状态写入事件流；任务进入终态后事件流随之结束。调用方需持有锁。
+func (b *jobBase) publishStatusLocked() {
+	b.events.publish(jobEventStatus, transport.JobStatusEvent{
+		Status: b.status,
+		Error:  b.errMessage,
+	})
+	if b.isFinishedLocked() {
+		b.events.finish()
+	}
+}
+
+// isFinishedLocked 会判断任务是否已处于结束态；调用方需持有锁。
+func (b *jobBase) isFinishedLocked() bool {
+	switch b.status {
+	case jobStatusSucceeded, jobStatusFailed, jobStatusCanceled, jobStatusInterrupted:
+		return true
+	default:
+		return false
+	}
+}
+
+// expired 会判断后台任务是否已经完成且超过保留时间。
+func (b *jobBase) expired(now time.Time) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.completedAt.IsZero() {
+		return false
+	}
+	return now.Sub(b.completedAt) > config.JobRetention
+}
+
+// beginRun 会把任务从 pending 切换到 running；如果任务已被取消，则返回 false。
+func (b *jobBase) beginRun() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.status != jobStatusPending {
+		return false
+	}
+	if b.cancelRequested || errors.Is(b.taskContext.Err(), context.Canceled) {
+		return false
+	}
+
+	now := time.Now()
+	b.startedAt = now
+	b.setStatusLocked(jobStatusRunning, now)
+	return true
+}
+
+// reopenLocked 会把已结束的任务重置为 pending，并换上新的可取消上下文，供任务重新排队执行；事件流保持不变，订阅者会收到新的 pending 状态事件。调用方需持有写锁。
+func (b *jobBase) reopenLocked() {
+	taskContext, cancel := context.WithCancel(context.Background())
+	b.taskContext = taskContext
+	b.cancel = cancel
+	b.cancelRequested = false
+	b.errMessage = ""
+	b.startedAt = time.Time{}
+	b.completedAt = time.Time{}
+	b.events.reopen()
+	b.setStatusLocked(jobStatusPending, time.Now())
+}
+
+// requestCancel 会请求取消当前任务，并立刻把状态推进到 canceling。
+func (b *jobBase) requestCancel() {
+	var cancel context.CancelFunc
+
+	b.mu.Lock()
+	if b.isFinishedLocked() || b.status == jobStatusCanceling {
+		b.mu.Unlock()
+		return
+	}
+	b.cancelRequested = true
+	b.errMessage = "任务取消中。"
+	b.setStatusLocked(jobStatusCanceling, time.Now())
+	cancel = b.cancel
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// succeed 会在持有锁时执行 apply 写入最终结果，并把状态切换为 succeeded；若任务已被取消则改记为 canceled。
+func (b *jobBase) succeed(apply func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.cancelRequested || errors.Is(b.taskContext.Err(), context.Canceled) {
+		b.markCanceledLocked(now)
+		return
+	}
+
+	if apply != nil {
+		apply()
+	}
+	if b.template != "" {
+		description, err := b.renderDescriptionLocked(b.template)
+		if err != nil {
+			b.logger.Logf("[template] 渲染发布描述失败: %s", err.Error())
+		}
+		b.description = description
+	}
+	b.errMessage = ""
+	b.completedAt = now
+	b.setStatusLocked(jobStatusSucceeded, now)
+}
+
+// renderDescriptionLocked 会用模板 name 渲染当前产出对应的发布描述；调用方需持有锁。
+func (b *jobBase) renderDescriptionLocked(name string) (string, error) {
+	if b.releaseData == nil {
+		return "", errors.New("job has no release data")
+	}
+	return release.Render(config.TemplateDir, name, b.releaseData())
+}
+
+// fail 会记录后台任务失败原因，并把状态切换为 failed；取消导致的错误会改记为 canceled。
+func (b *jobBase) fail(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.cancelRequested || errors.Is(err, context.Canceled) || errors.Is(b.taskContext.Err(), context.Canceled) {
+		b.markCanceledLocked(now)
+		return
+	}
+
+	if err != nil {
+		b.errMessage = err.Error()
+	} else {
+		b.errMessage = "job failed"
+	}
+	b.completedAt = now
+	b.setStatusLocked(jobStatusFailed, now)
+}
+
+// finishCanceled 会把任务最终标记为 canceled，并记录完成时间。
+func (b *jobBase) finishCanceled() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.isFinishedLocked() {
+		return
+	}
+	b.markCanceledLocked(time.Now())
+}
+
+// markCanceledLocked 会把任务切换为 canceled；调用方需持有写锁。
+func (b *jobBase) markCanceledLocked(now time.Time) {
+	b.errMessage = "任务已取消。"
+	b.completedAt = now
+	b.setStatusLocked(jobStatusCanceled, now)
+}
+
+// progressHandler 会返回交给生产者的结构化进度回调：事件先补上 ETA，再在任务运行期间交给 apply 更新任务自身的进度状态，并推送到事件流。
+// apply 调用时已持有写锁。
+func (b *jobBase) progressHandler(apply func(event taskprogress.Event)) taskprogress.Handler {
+	return taskprogress.WithETA(func(event taskprogress.Event) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if b.status != jobStatusRunning {
+			return
+		}
+		apply(event)
+		b.updatedAt = time.Now()
+		b.events.publish(jobEventProgress, event)
+	})
+}
+
+// isCancellationRequested 会判断当前任务是否已经收到了取消请求。
+func (b *jobBase) isCancellationRequested() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.cancelRequested || errors.Is(b.taskContext.Err(), context.Canceled)
+}
+
+// release 会释放任务持有的上下文、输入清理函数和日志资源；可重复调用。
+func (b *jobBase) release() {
+	b.mu.Lock()
+	cancel := b.cancel
+	cleanup := b.cleanup
+	b.cleanup = nil
+	logger := b.logger
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if cleanup != nil {
+		cleanup()
+	}
+	if logger != nil {
+		logger.Close()
+	}
+}
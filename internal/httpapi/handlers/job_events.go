@@ -0,0 +1,130 @@
+// Package handlers 提供后台任务事件的缓存与订阅能力，供 SSE 事件流按序推送日志、进度和状态。
+
+package handlers
+
+import (
+	"sync"
+
+	"minfo/internal/httpapi/transport"
+)
+
+// 事件类型与 SSE 的 event 字段一一对应。
+const (
+	jobEventLog      = "log"
+	jobEventProgress = "progress"
+	jobEventItem     = "item"
+	jobEventStep     = "step"
+	jobEventStatus   = "status"
+	jobEventReset    = "reset"
+)
+
+// jobEventBufferSize 是单个任务事件流最多保留的事件数，超出后丢弃最早的事件。
+const jobEventBufferSize = 1000
+
+// jobEvent 表示任务事件流中的一条事件；id 在单个任务内单调递增，用于 Last-Event-ID 续传。
+type jobEvent struct {
+	id   int64
+	kind string
+	data any
+}
+
+// jobEventStream 会按发生顺序缓存最近的任务事件，并在新事件到达时唤醒所有订阅者；事件流在任务整个生命周期内只有一个，重新排队也不会重置事件 ID。
+type jobEventStream struct {
+	mu       sync.Mutex
+	events   []jobEvent
+	nextID   int64
+	finished bool
+	notify   chan struct{}
+}
+
+// newJobEventStream 会创建一个空的任务事件流。
+func newJobEventStream() *jobEventStream {
+	return &jobEventStream{
+		events: make([]jobEvent, 0, 32),
+		nextID: 1,
+		notify: make(chan struct{}),
+	}
+}
+
+// publish 会追加一条事件并唤醒等待中的订阅者；终态事件写入后事件流不再接受新事件。
+func (s *jobEventStream) publish(kind string, data any) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return
+	}
+	if len(s.events) >= jobEventBufferSize {
+		copy(s.events, s.events[1:])
+		s.events = s.events[:len(s.events)-1]
+	}
+	s.events = append(s.events, jobEvent{id: s.nextID, kind: kind, data: data})
+	s.nextID++
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// finish 会把事件流标记为已结束，并唤醒订阅者读取剩余事件。
+func (s *jobEventStream) finish() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished {
+		return
+	}
+	s.finished = true
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// lastID 返回最近一条事件的 ID；尚无事件时返回 0。
+func (s *jobEventStream) lastID() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.nextID - 1
+}
+
+// reopen 会让已结束的事件流重新接受事件，供任务重新排队时继续沿用原有事件 ID。
+func (s *jobEventStream) reopen() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = false
+}
+
+// since 返回 id 大于 lastID 的事件、用于等待下一批事件的通知通道，以及事件流是否已经结束。
+// lastID 之后的部分事件已被丢弃时，返回的事件以一条 reset 事件开头，提示客户端重新拉取任务快照。
+func (s *jobEventStream) since(lastID int64) ([]jobEvent, <-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := len(s.events)
+	for start > 0 && s.events[start-1].id > lastID {
+		start--
+	}
+	pending := make([]jobEvent, 0, len(s.events)-start+1)
+	if start == 0 && len(s.events) > 0 && s.events[0].id > lastID+1 {
+		first := s.events[0].id
+		pending = append(pending, jobEvent{id: first - 1, kind: jobEventReset, data: transport.JobResetEvent{FirstEventID: first}})
+	}
+	pending = append(pending, s.events[start:]...)
+	return pending, s.notify, s.finished
+}
+
+// publishLogEntry 会把一条日志写入事件流。
+func (s *jobEventStream) publishLogEntry(entry transport.LogEntry) {
+	s.publish(jobEventLog, entry)
+}
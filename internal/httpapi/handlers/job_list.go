@@ -0,0 +1,303 @@
+// Package handlers 提供后台任务列表的筛选、分页与摘要生成逻辑。
+
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/screenshot/delivery"
+)
+
+const (
+	defaultJobListLimit = 50
+	maxJobListLimit     = 200
+)
+
+// jobListFilter 描述任务列表接口支持的筛选与分页条件。
+type jobListFilter struct {
+	kinds    map[string]bool
+	statuses map[string]bool
+	path     string
+	since    time.Time
+	until    time.Time
+	offset   int
+	limit    int
+}
+
+// parseJobListFilter 会从查询参数中解析筛选条件；kind 和 status 支持逗号分隔的多个值。
+func parseJobListFilter(values map[string][]string) (jobListFilter, error) {
+	get := func(key string) string {
+		if items := values[key]; len(items) > 0 {
+			return strings.TrimSpace(items[0])
+		}
+		return ""
+	}
+
+	filter := jobListFilter{
+		kinds:    parseJobListSet(get("kind")),
+		statuses: parseJobListSet(get("status")),
+		path:     get("path"),
+		limit:    defaultJobListLimit,
+	}
+
+	var err error
+	if filter.since, err = parseJobListTime(get("since")); err != nil {
+		return jobListFilter{}, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.until, err = parseJobListTime(get("until")); err != nil {
+		return jobListFilter{}, fmt.Errorf("invalid until: %w", err)
+	}
+	if raw := get("offset"); raw != "" {
+		filter.offset, err = strconv.Atoi(raw)
+		if err != nil || filter.offset < 0 {
+			return jobListFilter{}, fmt.Errorf("invalid offset: %s", raw)
+		}
+	}
+	if raw := get("limit"); raw != "" {
+		filter.limit, err = strconv.Atoi(raw)
+		if err != nil || filter.limit <= 0 {
+			return jobListFilter{}, fmt.Errorf("invalid limit: %s", raw)
+		}
+		if filter.limit > maxJobListLimit {
+			filter.limit = maxJobListLimit
+		}
+	}
+	return filter, nil
+}
+
+// parseJobListSet 会把逗号分隔的取值整理成小写集合；空字符串表示不筛选。
+func parseJobListSet(raw string) map[string]bool {
+	if raw == "" {
+		return nil
+	}
+	set := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			set[item] = true
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return set
+}
+
+// parseJobListTime 会解析 RFC3339 时间或 Unix 秒时间戳。
+func parseJobListTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+// matches 会判断任务记录是否满足筛选条件；kind 可以是具体种类，也可以是 info、screenshot、torrent 等任务类型。
+func (f jobListFilter) matches(record jobstore.Record) bool {
+	if f.kinds != nil && !f.kinds[jobRecordKind(record)] && !f.kinds[record.Type] {
+		return false
+	}
+	if f.statuses != nil && !f.statuses[record.Status] {
+		return false
+	}
+	if f.path != "" && !matchesJobListPath(record.InputPath, f.path) {
+		return false
+	}
+	if !f.since.IsZero() && record.CreatedAt.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && record.CreatedAt.After(f.until) {
+		return false
+	}
+	return true
+}
+
+// matchesJobListPath 会用绝对路径匹配同一路径及其子路径，其它输入按不区分大小写的子串匹配。
+// 虚拟 ISO 路径按镜像文件路径接镜像内路径参与绝对路径匹配，例如 ISO:/movies/disc.iso!/BDMV 视为 /movies/disc.iso/BDMV。
+func matchesJobListPath(inputPath, query string) bool {
+	if strings.HasPrefix(query, "/") {
+		if rest, ok := strings.CutPrefix(inputPath, "ISO:"); ok {
+			inputPath = strings.TrimSuffix(strings.Replace(rest, "!", "", 1), "/")
+		}
+		cleaned := filepath.Clean(query)
+		return inputPath == cleaned || strings.HasPrefix(inputPath, strings.TrimSuffix(cleaned, "/")+"/")
+	}
+	return strings.Contains(strings.ToLower(inputPath), strings.ToLower(query))
+}
+
+// listJobSummaries 会按创建时间倒序筛选任务，并返回当前页摘要与筛选后的总数。
+func listJobSummaries(filter jobListFilter, now time.Time) ([]transport.JobSummary, int) {
+	records := make([]jobstore.Record, 0)
+	for _, job := range listJobs() {
+		if record := job.describe(); filter.matches(record) {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].CreatedAt.After(records[j].CreatedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+
+	total := len(records)
+	if filter.offset >= total {
+		return []transport.JobSummary{}, total
+	}
+	end := min(filter.offset+filter.limit, total)
+
+	summaries := make([]transport.JobSummary, 0, end-filter.offset)
+	for _, record := range records[filter.offset:end] {
+		summaries = append(summaries, buildJobSummary(record, now))
+	}
+	return summaries, total
+}
+
+// buildJobSummary 会把任务记录压缩为列表摘要，只保留参数、耗时、结果规模和查看地址。
+func buildJobSummary(record jobstore.Record, now time.Time) transport.JobSummary {
+	summary := transport.JobSummary{
+		JobID:       record.ID,
+		Type:        record.Type,
+		Kind:        jobRecordKind(record),
+		Mode:        record.Mode,
+		Status:      record.Status,
+		InputPath:   record.InputPath,
+		Options:     summarizeJobOptions(record.Options),
+		Error:       record.Error,
+		CreatedAt:   formatJobTime(record.CreatedAt),
+		StartedAt:   formatJobTime(record.StartedAt),
+		CompletedAt: formatJobTime(record.CompletedAt),
+		URL:         jobRecordURL(record),
+		DownloadURL: record.DownloadURL,
+	}
+	if !record.StartedAt.IsZero() {
+		end := record.CompletedAt
+		if end.IsZero() {
+			end = now
+		}
+		if duration := end.Sub(record.StartedAt); duration > 0 {
+			summary.DurationMS = duration.Milliseconds()
+		}
+	}
+	if record.Status == jobStatusPending {
+		summary.QueuePosition = jobQueuePosition(record.ID)
+	}
+	summary.ResultSize, summary.ResultCount = jobResultSize(record)
+	return summary
+}
+
+// jobRecordKind 返回任务记录对外展示的种类：信息类任务为 mediainfo 或 bdinfo，其它任务与类型同名。
+func jobRecordKind(record jobstore.Record) string {
+	if record.Kind != "" {
+		return record.Kind
+	}
+	return record.Type
+}
+
+// jobRecordURL 返回可以重新打开任务详情的接口地址。
+func jobRecordURL(record jobstore.Record) string {
+	switch record.Type {
+	case jobstore.TypeInfo:
+		return "/api/info-jobs/" + record.ID
+	case jobstore.TypeScreenshot:
+		return "/api/screenshot-jobs/" + record.ID
+	case jobstore.TypeTorrent:
+		return "/api/torrent-jobs/" + record.ID
+	case jobstore.TypeRelease:
+		return "/api/release-jobs/" + record.ID
+	default:
+		return ""
+	}
+}
+
+// summarizeJobOptions 会复制任务参数，并把可能带有私有 passkey 的 Tracker 和 Web Seed 地址替换为数量。
+func summarizeJobOptions(options map[string]string) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+	summary := make(map[string]string, len(options))
+	for key, value := range options {
+		switch key {
+		case "tracker_url", "web_seed_url":
+			summary[strings.TrimSuffix(key, "_url")+"_count"] = strconv.Itoa(len(strings.Split(value, "\n")))
+		default:
+			if value != "" {
+				summary[key] = value
+			}
+		}
+	}
+	return summary
+}
+
+// jobResultSize 会估算任务结果的字节数和条目数：信息类为输出文本长度，截图为图片或压缩包大小，制种为种子文件大小，发布包为产物文件总大小和文件数。
+func jobResultSize(record jobstore.Record) (int64, int) {
+	switch record.Type {
+	case jobstore.TypeInfo:
+		return int64(len(record.Output)), 0
+	case jobstore.TypeScreenshot:
+		if len(record.LinkItems) > 0 {
+			var size int64
+			for _, item := range record.LinkItems {
+				size += item.Size
+			}
+			return size, len(record.LinkItems)
+		}
+		return preparedDownloadSize(record.DownloadURL), 0
+	case jobstore.TypeTorrent:
+		return fileSize(record.OutputPath), 0
+	case jobstore.TypeRelease:
+		if record.Status != jobStatusSucceeded {
+			return 0, 0
+		}
+		files := listReleaseFiles(record.ID, record.OutputPath)
+		var size int64
+		for _, file := range files {
+			size += file.Size
+		}
+		return size, len(files)
+	default:
+		return 0, 0
+	}
+}
+
+// preparedDownloadSize 会根据截图下载地址中的令牌查找压缩包大小；令牌失效时返回 0。
+func preparedDownloadSize(downloadURL string) int64 {
+	_, token, ok := strings.Cut(downloadURL, "token=")
+	if !ok || token == "" {
+		return 0
+	}
+	path, err := delivery.GetPreparedDownload(token)
+	if err != nil {
+		return 0
+	}
+	return fileSize(path)
+}
+
+// fileSize 返回普通文件大小；文件不存在时返回 0。
+func fileSize(path string) int64 {
+	if path == "" {
+		return 0
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatJobTime 会把任务时间格式化为 RFC3339；零值返回空字符串。
+func formatJobTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339Nano)
+}
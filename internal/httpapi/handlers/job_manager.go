@@ -0,0 +1,175 @@
+// Package handlers 提供信息、截图和制种后台任务共用的任务表与排队调度。
+
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"minfo/internal/config"
+	"minfo/internal/jobqueue"
+	"minfo/internal/jobstore"
+)
+
+// 调度分类用于区分不同后台任务的并发上限。
+const (
+	jobClassMediaInfo  = infoKindMediaInfo
+	jobClassBDInfo     = infoKindBDInfo
+	jobClassScreenshot = "screenshot"
+	jobClassTorrent    = "torrent"
+	jobClassRelease    = "release"
+)
+
+// managedJob 描述可由任务管理器统一登记、调度、持久化和清理的后台任务。
+type managedJob interface {
+	base() *jobBase
+	execute(ctx context.Context)
+	describe() jobstore.Record
+	persist()
+	discard()
+}
+
+var jobRegistry = struct {
+	mu    sync.Mutex
+	items map[string]managedJob
+}{
+	items: make(map[string]managedJob),
+}
+
+var jobScheduler = jobqueue.New(config.MaxJobs, map[string]int{
+	jobClassMediaInfo:  config.MaxMediaInfoJobs,
+	jobClassBDInfo:     config.MaxBDInfoJobs,
+	jobClassScreenshot: config.MaxScreenshotJobs,
+	jobClassTorrent:    config.MaxTorrentJobs,
+	jobClassRelease:    config.MaxReleaseJobs,
+})
+
+// jobPriority 返回调度分类的优先级；耗时短的 MediaInfo 任务优先于截图等重任务启动。
+func jobPriority(class string) int {
+	switch class {
+	case jobClassMediaInfo:
+		return 20
+	case jobClassBDInfo:
+		return 10
+	default:
+		return 0
+	}
+}
+
+// submitJob 会登记新任务、写入仓库，并把任务放入调度队列等待执行。
+func submitJob(job managedJob) {
+	pruneJobs(time.Now())
+
+	b := job.base()
+	jobRegistry.mu.Lock()
+	jobRegistry.items[b.id] = job
+	jobRegistry.mu.Unlock()
+
+	job.persist()
+	jobScheduler.Submit(b.id, b.class, jobPriority(b.class), func() {
+		runManagedJob(job)
+	})
+}
+
+// storeRestoredJob 会把从仓库恢复的已结束任务放回任务表，但不会重新调度。
+func storeRestoredJob(job managedJob) {
+	jobRegistry.mu.Lock()
+	jobRegistry.items[job.base().id] = job
+	jobRegistry.mu.Unlock()
+}
+
+// lookupJob 返回指定任务；如果任务不存在或已过期，则返回 false。
+func lookupJob(jobID string) (managedJob, bool) {
+	pruneJobs(time.Now())
+
+	jobRegistry.mu.Lock()
+	defer jobRegistry.mu.Unlock()
+
+	job, ok := jobRegistry.items[jobID]
+	return job, ok
+}
+
+// listJobs 返回任务表中所有未过期任务的快照列表，顺序不固定。
+func listJobs() []managedJob {
+	pruneJobs(time.Now())
+
+	jobRegistry.mu.Lock()
+	defer jobRegistry.mu.Unlock()
+
+	jobs := make([]managedJob, 0, len(jobRegistry.items))
+	for _, job := range jobRegistry.items {
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// pruneJobs 会删除已完成且超过保留时间的后台任务记录及其产物。
+func pruneJobs(now time.Time) {
+	jobRegistry.mu.Lock()
+	expired := make([]managedJob, 0)
+	for jobID, job := range jobRegistry.items {
+		if !job.base().expired(now) {
+			continue
+		}
+		delete(jobRegistry.items, jobID)
+		expired = append(expired, job)
+	}
+	jobRegistry.mu.Unlock()
+
+	for _, job := range expired {
+		job.discard()
+		deleteJobRecord(job.base().id)
+	}
+}
+
+// runManagedJob 会在调度器分配到槽位后执行任务，并负责状态切换、定期落盘和资源释放。
+func runManagedJob(job managedJob) {
+	b := job.base()
+	defer func() {
+		b.release()
+		job.persist()
+	}()
+
+	if !b.beginRun() {
+		if b.isCancellationRequested() {
+			b.finishCanceled()
+		}
+		return
+	}
+	job.persist()
+	stopPersist := startJobPersistLoop(b.eventStream(), job.persist)
+	defer stopPersist()
+
+	ctx, cancel := context.WithTimeout(b.taskContext, config.RequestTimeout)
+	defer cancel()
+
+	job.execute(ctx)
+}
+
+// cancelJob 会请求取消任务；仍在排队的任务会直接出队并结束为 canceled。
+func cancelJob(job managedJob) {
+	b := job.base()
+	b.requestCancel()
+	if jobScheduler.Remove(b.id) {
+		b.finishCanceled()
+		b.release()
+	}
+	job.persist()
+}
+
+// jobQueuePosition 返回任务在等待队列中的位置；任务未在排队时返回 0。
+func jobQueuePosition(jobID string) int {
+	return jobScheduler.Position(jobID)
+}
+
+// buildJobID 生成适合 URL 使用的随机任务 ID。
+func buildJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"minfo/internal/jobqueue"
+	"minfo/internal/media"
+	"minfo/internal/torrent"
+)
+
+// TestCancelJobRemovesQueuedJobAndReleasesInput 验证排队中的任务被取消后会直接出队并释放输入资源。
+func TestCancelJobRemovesQueuedJobAndReleasesInput(t *testing.T) {
+	previous := jobScheduler
+	jobScheduler = jobqueue.New(0, map[string]int{jobClassTorrent: 1})
+	t.Cleanup(func() { jobScheduler = previous })
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	jobScheduler.Submit("blocker", jobClassTorrent, 0, func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	cleaned := false
+	job, err := createTorrentJob(torrentRequest{
+		Input:   formInput{source: "/media/demo", permission: media.PermissionTorrent},
+		Cleanup: func() { cleaned = true },
+		Options: torrent.Options{PieceLength: torrent.DefaultPieceLength},
+	})
+	if err != nil {
+		t.Fatalf("createTorrentJob() error = %v", err)
+	}
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	snapshot := job.snapshot()
+	if snapshot.Status != jobStatusPending || snapshot.QueuePosition != 1 {
+		t.Fatalf("snapshot status/position = %q/%d, want pending/1", snapshot.Status, snapshot.QueuePosition)
+	}
+	if snapshot.Progress == nil || snapshot.Progress.Stage != "排队中" {
+		t.Fatalf("Progress = %#v, want queued stage", snapshot.Progress)
+	}
+
+	cancelJob(job)
+	snapshot = job.snapshot()
+	if snapshot.Status != jobStatusCanceled {
+		t.Fatalf("Status = %q, want %q", snapshot.Status, jobStatusCanceled)
+	}
+	if snapshot.QueuePosition != 0 {
+		t.Fatalf("QueuePosition = %d, want 0 after cancel", snapshot.QueuePosition)
+	}
+	if !cleaned {
+		t.Fatal("input cleanup was not called for canceled queued job")
+	}
+}
+
+// TestJobResolvesInputOnlyWhileRunning 验证排队中的任务不会解析输入路径，ISO 要等调度器开始执行时才挂载或共享，并在任务结束后释放。
+func TestJobResolvesInputOnlyWhileRunning(t *testing.T) {
+	previous := jobScheduler
+	jobScheduler = jobqueue.New(0, map[string]int{jobClassTorrent: 1})
+	t.Cleanup(func() { jobScheduler = previous })
+
+	var resolved, released atomic.Int32
+	previousResolve := resolveInputPath
+	resolveInputPath = func(ctx context.Context, input string, permission media.Permission) (string, func(), error) {
+		if input != "ISO:/media/Disc.iso!/BDMV" || permission != media.PermissionTorrent {
+			t.Errorf("resolveInputPath(%q, %q), want the submitted ISO path with torrent permission", input, permission)
+		}
+		resolved.Add(1)
+		return filepath.Join(t.TempDir(), "missing"), func() { released.Add(1) }, nil
+	}
+	t.Cleanup(func() { resolveInputPath = previousResolve })
+
+	unblock := make(chan struct{})
+	started := make(chan struct{})
+	jobScheduler.Submit("blocker", jobClassTorrent, 0, func() {
+		close(started)
+		<-unblock
+	})
+	<-started
+
+	job, err := createTorrentJob(torrentRequest{
+		Input:   formInput{source: "ISO:/media/Disc.iso!/BDMV", permission: media.PermissionTorrent},
+		Options: torrent.Options{PieceLength: torrent.DefaultPieceLength},
+	})
+	if err != nil {
+		t.Fatalf("createTorrentJob() error = %v", err)
+	}
+	t.Cleanup(func() {
+		job.discard()
+		removeTestJob(job.id)
+	})
+	if resolved.Load() != 0 {
+		t.Fatal("queued job resolved its input before the scheduler started it")
+	}
+
+	close(unblock)
+	deadline := time.Now().Add(5 * time.Second)
+	for released.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// 同类槽位只有一个，排在后面的任务开始时，前一个任务已完成收尾和持久化。
+	finished := make(chan struct{})
+	jobScheduler.Submit("after", jobClassTorrent, 0, func() { close(finished) })
+	<-finished
+	if resolved.Load() != 1 || released.Load() != 1 {
+		t.Fatalf("resolved/released = %d/%d, want 1/1", resolved.Load(), released.Load())
+	}
+	if status := job.snapshot().Status; status != jobStatusFailed {
+		t.Fatalf("Status = %q, want failed for the missing input", status)
+	}
+	if record := job.describe(); record.InputPath != "ISO:/media/Disc.iso!/BDMV" {
+		t.Fatalf("InputPath = %q, want the submitted path", record.InputPath)
+	}
+}
@@ -0,0 +1,157 @@
+// Package handlers 提供后台任务持久化仓库的装配、恢复与定期落盘逻辑。
+
+package handlers
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"minfo/internal/config"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+)
+
+// jobPersistInterval 是运行中任务把日志和进度写回仓库的最短间隔。
+const jobPersistInterval = 2 * time.Second
+
+var (
+	jobRepository jobstore.Repository = jobstore.NewMemoryRepository()
+	jobDataDir    string
+)
+
+// ConfigureJobStorage 会替换后台任务仓库，并把仓库中已有的记录恢复为可查询的历史任务。
+// 重启前仍在运行的任务会被标记为 interrupted；dataDir 用于保存制种结果等任务产物。
+func ConfigureJobStorage(repo jobstore.Repository, dataDir string) error {
+	if repo == nil {
+		return errors.New("job repository is nil")
+	}
+	records, err := repo.Load()
+	if err != nil {
+		return err
+	}
+
+	jobRepository = repo
+	jobDataDir = dataDir
+
+	now := time.Now()
+	for _, record := range records {
+		changed := redactTorrentRecordOptions(record.Options)
+		if !record.Finished() {
+			record = jobstore.Interrupt(record, now)
+			changed = true
+		}
+		if changed {
+			saveJobRecord(record)
+		}
+		if now.Sub(record.CompletedAt) > config.JobRetention {
+			deleteJobRecord(record.ID)
+			switch record.Type {
+			case jobstore.TypeTorrent:
+				removeTorrentOutputDir(record.OutputPath)
+			case jobstore.TypeRelease:
+				removeReleaseOutputDir(record.OutputPath)
+			}
+			continue
+		}
+
+		switch record.Type {
+		case jobstore.TypeInfo:
+			restoreInfoJob(record)
+		case jobstore.TypeScreenshot:
+			restoreScreenshotJob(record)
+		case jobstore.TypeTorrent:
+			restoreTorrentJob(record)
+		case jobstore.TypeRelease:
+			restoreReleaseJob(record)
+		default:
+			log.Printf("skip unknown job record %s type=%q", record.ID, record.Type)
+		}
+	}
+	return nil
+}
+
+// saveJobRecord 会把任务快照写入当前仓库；写入失败只记录日志，不影响任务本身。
+func saveJobRecord(record jobstore.Record) {
+	if err := jobRepository.Save(record); err != nil {
+		log.Printf("persist job %s: %v", record.ID, err)
+	}
+}
+
+// deleteJobRecord 会从当前仓库删除指定任务记录。
+func deleteJobRecord(jobID string) {
+	if err := jobRepository.Delete(jobID); err != nil {
+		log.Printf("delete job %s: %v", jobID, err)
+	}
+}
+
+// startJobPersistLoop 会在任务运行期间按固定间隔检查事件流，只在上次落盘后产生了新事件时调用 persist，并返回停止函数。
+func startJobPersistLoop(stream *jobEventStream, persist func()) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jobPersistInterval)
+		defer ticker.Stop()
+
+		persisted := stream.lastID()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if current := stream.lastID(); current != persisted {
+					persist()
+					persisted = current
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+// createJobOutputDir 会为任务产物创建独立目录；配置了数据目录时放在数据目录内以便跨重启保留。
+func createJobOutputDir(scope, pattern string) (string, error) {
+	if jobDataDir == "" {
+		return os.MkdirTemp("", pattern)
+	}
+	parent := filepath.Join(jobDataDir, scope)
+	if err := os.MkdirAll(parent, 0o755); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(parent, pattern)
+}
+
+// removeTorrentOutputDir 会删除持久化制种任务遗留的产物目录。
+func removeTorrentOutputDir(outputPath string) {
+	if outputPath == "" {
+		return
+	}
+	_ = os.RemoveAll(filepath.Dir(outputPath))
+}
+
+// removeReleaseOutputDir 会删除持久化发布包任务遗留的产物目录。
+func removeReleaseOutputDir(outputDir string) {
+	if outputDir == "" {
+		return
+	}
+	_ = os.RemoveAll(outputDir)
+}
+
+// restoreInfoLogger 会根据持久化的结构化日志重建一个日志记录器。
+func restoreInfoLogger(entries []transport.LogEntry) *infoLogger {
+	logger := newInfoLogger()
+	for _, entry := range entries {
+		line := timedLogLine{message: entry.Message}
+		if entry.Timestamp != "" {
+			if parsed, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+				line.timestamp = parsed.Local()
+			}
+		}
+		logger.lines = append(logger.lines, line)
+	}
+	return logger
+}
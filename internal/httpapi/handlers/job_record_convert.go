@@ -0,0 +1,122 @@
+// Package handlers 负责在接口传输结构和任务仓库的持久化结构之间互相转换。
+
+package handlers
+
+import (
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+)
+
+// recordLogEntries 会把任务日志转换为持久化结构。
+func recordLogEntries(entries []transport.LogEntry) []jobstore.LogEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make([]jobstore.LogEntry, len(entries))
+	for index, entry := range entries {
+		result[index] = jobstore.LogEntry(entry)
+	}
+	return result
+}
+
+// transportLogEntries 会把持久化的任务日志还原为接口结构。
+func transportLogEntries(entries []jobstore.LogEntry) []transport.LogEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	result := make([]transport.LogEntry, len(entries))
+	for index, entry := range entries {
+		result[index] = transport.LogEntry(entry)
+	}
+	return result
+}
+
+// recordImageLinks 会把图床链接转换为持久化结构。
+func recordImageLinks(items []transport.ImageLinkItem) []jobstore.ImageLink {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]jobstore.ImageLink, len(items))
+	for index, item := range items {
+		result[index] = jobstore.ImageLink(item)
+	}
+	return result
+}
+
+// transportImageLinks 会把持久化的图床链接还原为接口结构。
+func transportImageLinks(items []jobstore.ImageLink) []transport.ImageLinkItem {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]transport.ImageLinkItem, len(items))
+	for index, item := range items {
+		result[index] = transport.ImageLinkItem(item)
+	}
+	return result
+}
+
+// recordFailedImages 会把上传失败的截图转换为持久化结构。
+func recordFailedImages(items []transport.FailedImageItem) []jobstore.FailedImage {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]jobstore.FailedImage, len(items))
+	for index, item := range items {
+		result[index] = jobstore.FailedImage(item)
+	}
+	return result
+}
+
+// transportFailedImages 会把持久化的上传失败截图还原为接口结构。
+func transportFailedImages(items []jobstore.FailedImage) []transport.FailedImageItem {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]transport.FailedImageItem, len(items))
+	for index, item := range items {
+		result[index] = transport.FailedImageItem(item)
+	}
+	return result
+}
+
+// recordProgress 会复制一份进度快照并转换为持久化结构。
+func recordProgress(progress *transport.TaskProgress) *jobstore.Progress {
+	if progress == nil {
+		return nil
+	}
+	result := jobstore.Progress(*progress)
+	return &result
+}
+
+// transportProgress 会把持久化的进度快照还原为接口结构。
+func transportProgress(progress *jobstore.Progress) *transport.TaskProgress {
+	if progress == nil {
+		return nil
+	}
+	result := transport.TaskProgress(*progress)
+	return &result
+}
+
+// recordReleaseSteps 会把发布包步骤转换为持久化结构。
+func recordReleaseSteps(steps []transport.ReleaseStep) []jobstore.ReleaseStep {
+	if len(steps) == 0 {
+		return nil
+	}
+	result := make([]jobstore.ReleaseStep, len(steps))
+	for index, step := range steps {
+		result[index] = jobstore.ReleaseStep{Name: step.Name, Status: step.Status, Progress: recordProgress(step.Progress), Error: step.Error}
+	}
+	return result
+}
+
+// transportReleaseSteps 会把持久化的发布包步骤还原为接口结构。
+func transportReleaseSteps(steps []jobstore.ReleaseStep) []transport.ReleaseStep {
+	if len(steps) == 0 {
+		return nil
+	}
+	result := make([]transport.ReleaseStep, len(steps))
+	for index, step := range steps {
+		result[index] = transport.ReleaseStep{Name: step.Name, Status: step.Status, Progress: transportProgress(step.Progress), Error: step.Error}
+	}
+	return result
+}
@@ -0,0 +1,158 @@
+// Package handlers 提供不区分任务类型的后台任务接口，包括任务列表和实时事件流。
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"minfo/internal/httpapi/transport"
+)
+
+// jobEventKeepAlive 是事件流在没有新事件时发送心跳注释的间隔，避免代理断开空闲连接。
+const jobEventKeepAlive = 15 * time.Second
+
+// JobsHandler 会返回所有后台任务的分页摘要，支持按种类、状态、输入路径和创建时间筛选。
+func JobsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJobListResponse(w, http.StatusMethodNotAllowed, transport.JobListResponse{Error: "method not allowed"})
+		return
+	}
+
+	filter, err := parseJobListFilter(r.URL.Query())
+	if err != nil {
+		writeJobListResponse(w, http.StatusBadRequest, transport.JobListResponse{Error: err.Error()})
+		return
+	}
+
+	jobs, total := listJobSummaries(filter, time.Now())
+	writeJobListResponse(w, http.StatusOK, transport.JobListResponse{
+		OK:     true,
+		Jobs:   jobs,
+		Total:  total,
+		Offset: filter.offset,
+		Limit:  filter.limit,
+	})
+}
+
+// writeJobListResponse 会把任务列表响应编码为 JSON，并显式关闭缓存。
+func writeJobListResponse(w http.ResponseWriter, status int, payload transport.JobListResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// JobHandler 会按子路径分发单个后台任务的通用接口，目前支持 GET /api/jobs/{id}/events 和 GET /api/jobs/{id}/description。
+func JobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, action := parseJobPath(r)
+	if jobID == "" || (action != "events" && action != "description") {
+		transport.WriteError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		transport.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	job, ok := lookupJob(jobID)
+	if !ok {
+		transport.WriteError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if action == "description" {
+		handleJobDescription(w, r, job)
+		return
+	}
+	streamJobEvents(w, r, job.base().eventStream())
+}
+
+// streamJobEvents 会以 SSE 格式推送 Last-Event-ID 之后的任务事件，直到任务结束或客户端断开。
+func streamJobEvents(w http.ResponseWriter, r *http.Request, stream *jobEventStream) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		transport.WriteError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	lastID := parseLastEventID(r)
+	events, notify, finished := stream.since(lastID)
+	if finished && len(events) == 0 {
+		// 204 会让浏览器的 EventSource 停止自动重连。
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(jobEventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		for _, event := range events {
+			if err := writeJobEvent(w, event); err != nil {
+				return
+			}
+			lastID = event.id
+		}
+		flusher.Flush()
+		if finished {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-notify:
+		}
+		events, notify, finished = stream.since(lastID)
+	}
+}
+
+// writeJobEvent 会把单条任务事件编码为 SSE 帧。
+func writeJobEvent(w http.ResponseWriter, event jobEvent) error {
+	data, err := json.Marshal(event.data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.id, event.kind, data)
+	return err
+}
+
+// parseLastEventID 会读取浏览器重连时携带的 Last-Event-ID；也接受 last_event_id 查询参数，便于手动续传。
+func parseLastEventID(r *http.Request) int64 {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
+// parseJobPath 会把 /api/jobs/{id}/{action} 拆成任务 ID 和子操作。
+func parseJobPath(r *http.Request) (string, string) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/jobs/"), "/")
+	jobID, action, _ := strings.Cut(rest, "/")
+	if strings.Contains(action, "/") {
+		return "", ""
+	}
+	return strings.TrimSpace(jobID), action
+}
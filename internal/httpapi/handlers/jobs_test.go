@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/taskprogress"
+)
+
+// TestJobEventsStreamsLiveEventsUntilFinalStatus 验证事件流会推送日志、进度和最终状态，并在终态后结束。
+func TestJobEventsStreamsLiveEventsUntilFinalStatus(t *testing.T) {
+	job := &infoJob{kind: infoKindBDInfo}
+	if err := initJobBase(&job.jobBase, jobClassBDInfo, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	storeRestoredJob(job)
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/events", nil)
+	done := make(chan struct{})
+	go func() {
+		JobHandler(recorder, request)
+		close(done)
+	}()
+
+	job.beginRun()
+	job.logger.LogLine("[bdinfo] start")
+	job.progressHandler(job.progressState.apply)(taskprogress.Step(bdinfo.StageScan, 3, bdinfo.StageCount, "正在扫描。"))
+	job.fail(errors.New("boom"))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream did not end after final status")
+	}
+
+	body := recorder.Body.String()
+	if got := recorder.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", got)
+	}
+	for _, want := range []string{
+		"id: 1\nevent: status\ndata: {\"status\":\"pending\"}",
+		"event: log\ndata: {\"timestamp\":",
+		"event: progress\ndata: {\"kind\":\"step\",\"stage\":\"扫描蓝光目录\",\"current\":3,\"total\":5",
+		"event: status\ndata: {\"status\":\"running\"}",
+		"event: status\ndata: {\"status\":\"failed\",\"error\":\"boom\"}",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body = %q, want it to contain %q", body, want)
+		}
+	}
+}
+
+// TestJobEventsResumesFromLastEventID 验证重连时只会补发 Last-Event-ID 之后的事件，已读完的终态任务返回 204。
+func TestJobEventsResumesFromLastEventID(t *testing.T) {
+	job := &infoJob{kind: infoKindMediaInfo}
+	if err := initJobBase(&job.jobBase, jobClassMediaInfo, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	storeRestoredJob(job)
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	job.logger.LogLine("first")
+	job.logger.LogLine("second")
+	job.succeedMediaInfo("ok", nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/events", nil)
+	request.Header.Set("Last-Event-ID", "2")
+	recorder := httptest.NewRecorder()
+	JobHandler(recorder, request)
+
+	body := recorder.Body.String()
+	if strings.Contains(body, "first") || !strings.Contains(body, "id: 3\nevent: log") || !strings.Contains(body, "second") {
+		t.Fatalf("body = %q, want events after id 2 only", body)
+	}
+	if !strings.Contains(body, "\"status\":\"succeeded\"") {
+		t.Fatalf("body = %q, want final status", body)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/events?last_event_id=4", nil)
+	recorder = httptest.NewRecorder()
+	JobHandler(recorder, request)
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d after final event", recorder.Code, http.StatusNoContent)
+	}
+}
+
+// TestJobEventsSendsResetWhenEventsWereDiscarded 验证事件缓冲区只保留最近的事件，续传位置早于缓冲区时先推送 reset 事件。
+func TestJobEventsSendsResetWhenEventsWereDiscarded(t *testing.T) {
+	job := &infoJob{kind: infoKindMediaInfo}
+	if err := initJobBase(&job.jobBase, jobClassMediaInfo, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	storeRestoredJob(job)
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	for index := 0; index < jobEventBufferSize+10; index++ {
+		job.logger.LogLine("line")
+	}
+	job.succeedMediaInfo("ok", nil)
+	if got := len(job.events.events); got != jobEventBufferSize {
+		t.Fatalf("buffered events = %d, want %d", got, jobEventBufferSize)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/events", nil)
+	request.Header.Set("Last-Event-ID", "5")
+	recorder := httptest.NewRecorder()
+	JobHandler(recorder, request)
+
+	first := job.events.events[0].id
+	want := fmt.Sprintf("id: %d\nevent: reset\ndata: {\"first_event_id\":%d}\n\nid: %d\nevent: log", first-1, first, first)
+	if body := recorder.Body.String(); !strings.HasPrefix(body, want) {
+		t.Fatalf("body starts with %q, want %q", body[:min(len(body), len(want))], want)
+	}
+}
+
+// TestJobEventsKeepIDsAcrossReopen 验证任务重新排队后沿用同一个事件流，事件 ID 继续递增而不是从 1 重新开始。
+func TestJobEventsKeepIDsAcrossReopen(t *testing.T) {
+	job := &infoJob{kind: infoKindMediaInfo}
+	if err := initJobBase(&job.jobBase, jobClassMediaInfo, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	storeRestoredJob(job)
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	stream := job.events
+	job.fail(errors.New("boom"))
+	job.mu.Lock()
+	job.reopenLocked()
+	job.mu.Unlock()
+	job.logger.LogLine("again")
+
+	if job.events != stream {
+		t.Fatal("reopenLocked() replaced the event stream")
+	}
+	request := httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/events?last_event_id=2", nil)
+	ctx, cancel := context.WithTimeout(request.Context(), 100*time.Millisecond)
+	defer cancel()
+	recorder := httptest.NewRecorder()
+	JobHandler(recorder, request.WithContext(ctx))
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, "id: 3\nevent: status\ndata: {\"status\":\"pending\"}") || !strings.Contains(body, "id: 4\nevent: log") {
+		t.Fatalf("body = %q, want pending status and log continuing after id 2", body)
+	}
+}
+
+// TestJobsHandlerFiltersAndPaginatesSummaries 验证任务列表支持按种类、状态、路径和时间筛选，并按创建时间倒序分页。
+func TestJobsHandlerFiltersAndPaginatesSummaries(t *testing.T) {
+	now := time.Now().UTC()
+	records := []jobstore.Record{
+		{ID: "list-info", Type: jobstore.TypeInfo, Kind: infoKindMediaInfo, InputPath: "/srv/list-test/movies/a.mkv", Status: jobStatusSucceeded, Output: "General", CreatedAt: now.Add(-3 * time.Minute), StartedAt: now.Add(-3 * time.Minute), CompletedAt: now.Add(-2 * time.Minute)},
+		{ID: "list-shots", Type: jobstore.TypeScreenshot, Mode: "links", InputPath: "/srv/list-test/movies/b.mkv", Status: jobStatusFailed, Error: "boom", CreatedAt: now.Add(-2 * time.Minute), CompletedAt: now.Add(-time.Minute),
+			LinkItems: []jobstore.ImageLink{{URL: "https://img/1.png", Size: 10}, {URL: "https://img/2.png", Size: 20}}},
+		{ID: "list-torrent", Type: jobstore.TypeTorrent, InputPath: "/srv/list-test/tv/show", Status: jobStatusSucceeded, Options: map[string]string{"tracker_url": "https://t/announce?passkey=secret\nhttps://u/announce"}, CreatedAt: now.Add(-time.Minute), CompletedAt: now},
+	}
+	restoreInfoJob(records[0])
+	restoreScreenshotJob(records[1])
+	restoreTorrentJob(records[2])
+	t.Cleanup(func() {
+		for _, record := range records {
+			removeTestJob(record.ID)
+		}
+	})
+
+	list := func(query string) transport.JobListResponse {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		JobsHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs?"+query, nil))
+		var response transport.JobListResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode %q: %v", query, err)
+		}
+		return response
+	}
+	ids := func(response transport.JobListResponse) string {
+		parts := make([]string, 0, len(response.Jobs))
+		for _, job := range response.Jobs {
+			if strings.HasPrefix(job.JobID, "list-") {
+				parts = append(parts, job.JobID)
+			}
+		}
+		return strings.Join(parts, ",")
+	}
+
+	if got := ids(list("path=/srv/list-test/movies")); got != "list-shots,list-info" {
+		t.Fatalf("path filter = %q, want list-shots,list-info", got)
+	}
+	if got := ids(list("kind=info,torrent&status=succeeded&path=/srv/list-test")); got != "list-torrent,list-info" {
+		t.Fatalf("kind/status filter = %q, want list-torrent,list-info", got)
+	}
+	if got := ids(list("path=list-test/movies&since=" + now.Add(-150*time.Second).Format(time.RFC3339))); got != "list-shots" {
+		t.Fatalf("since filter = %q, want list-shots", got)
+	}
+
+	page := list("path=/srv/list-test&limit=1&offset=1")
+	if page.Total != 3 || page.Limit != 1 || page.Offset != 1 || ids(page) != "list-shots" {
+		t.Fatalf("page = %#v, want second of three", page)
+	}
+	shots := page.Jobs[0]
+	if shots.ResultCount != 2 || shots.ResultSize != 30 || shots.URL != "/api/screenshot-jobs/list-shots" {
+		t.Fatalf("screenshot summary = %#v", shots)
+	}
+
+	torrentJob := list("kind=torrent&path=/srv/list-test/tv").Jobs[0]
+	if torrentJob.Options["tracker_count"] != "2" || strings.Contains(torrentJob.Options["tracker_url"], "secret") {
+		t.Fatalf("torrent options = %#v, want tracker urls redacted", torrentJob.Options)
+	}
+	infoJob := list("kind=mediainfo&path=list-test").Jobs[0]
+	if infoJob.DurationMS != time.Minute.Milliseconds() || infoJob.ResultSize != int64(len("General")) {
+		t.Fatalf("info summary = %#v", infoJob)
+	}
+
+	recorder := httptest.NewRecorder()
+	JobsHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs?since=yesterday", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("invalid since status = %d, want 400", recorder.Code)
+	}
+}
+
+// TestJobsHandlerFiltersISOJobByOriginalPath 验证 ISO 任务在列表中展示并按提交时的虚拟路径筛选，而不是挂载目录或本机共享地址。
+func TestJobsHandlerFiltersISOJobByOriginalPath(t *testing.T) {
+	const source = "ISO:/srv/iso-list-test/Disc.iso!/BDMV/STREAM/00800.m2ts"
+	job := &infoJob{kind: infoKindMediaInfo, input: formInput{source: source}, inputPath: "http://127.0.0.1:41234/iso/token/00800.m2ts"}
+	if err := initJobBase(&job.jobBase, job.kind, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	storeRestoredJob(job)
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	for _, query := range []string{url.QueryEscape(source), "/srv/iso-list-test", "/srv/iso-list-test/Disc.iso/BDMV", "disc.iso"} {
+		recorder := httptest.NewRecorder()
+		JobsHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs?path="+query, nil))
+		var response transport.JobListResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode %q: %v", query, err)
+		}
+		if len(response.Jobs) != 1 || response.Jobs[0].JobID != job.id || response.Jobs[0].InputPath != source {
+			t.Fatalf("path=%s jobs = %#v, want the ISO job listed with its original path", query, response.Jobs)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	JobsHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs?path=127.0.0.1", nil))
+	if strings.Contains(recorder.Body.String(), job.id) {
+		t.Fatalf("body = %s, want resolved share address not matched", recorder.Body.String())
+	}
+}
+
+// removeTestJob 会把测试登记的任务从任务表移除。
+func removeTestJob(jobID string) {
+	jobRegistry.mu.Lock()
+	delete(jobRegistry.items, jobID)
+	jobRegistry.mu.Unlock()
+}
+
+// TestJobDescriptionRendersSelectedTemplate 验证任务成功时会用所选模板生成描述，并可通过接口换用其他模板重新渲染。
+func TestJobDescriptionRendersSelectedTemplate(t *testing.T) {
+	job := &screenshotJob{mode: "links", input: formInput{source: "/srv/movies/Example.2024.mkv"}}
+	if err := initJobBase(&job.jobBase, jobClassScreenshot, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	job.template = "bbcode"
+	job.releaseData = job.releaseDataLocked
+	storeRestoredJob(job)
+	t.Cleanup(func() { removeTestJob(job.id) })
+
+	job.succeed("ok", "", []transport.ImageLinkItem{{URL: "https://img/1.png", ThumbnailURL: "https://img/1_t.png"}}, nil, nil)
+	if snapshot := job.snapshot(); !strings.Contains(snapshot.Description, "[url=https://img/1.png][img]https://img/1_t.png[/img][/url]") {
+		t.Fatalf("Description = %q, want bbcode image link", snapshot.Description)
+	}
+
+	recorder := httptest.NewRecorder()
+	JobHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/description?template=markdown", nil))
+	var payload transport.JobDescriptionResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if recorder.Code != http.StatusOK || payload.Template != "markdown" || !strings.Contains(payload.Description, "[![](https://img/1_t.png)](https://img/1.png)") {
+		t.Fatalf("response = %d %+v, want markdown description", recorder.Code, payload)
+	}
+
+	recorder = httptest.NewRecorder()
+	JobHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.id+"/description?template=missing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d for unknown template", recorder.Code, http.StatusNotFound)
+	}
+}
@@ -4,6 +4,7 @@ package handlers
 
 import (
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"minfo/internal/httpapi/transport"
@@ -17,11 +18,25 @@ func PathSuggestHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	roots, err := media.ResolveRoots(media.MediaRoots())
+	namedRoots, err := media.ResolveConfiguredRoots(media.ConfiguredRoots())
 	if err != nil {
 		transport.WritePathError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	roots := make([]string, 0, len(namedRoots))
+	labels := make(map[string]string, len(namedRoots))
+	responseRoots := make([]transport.MediaRoot, 0, len(namedRoots))
+	for _, root := range namedRoots {
+		roots = append(roots, root.Path)
+		labels[root.Path] = root.Label
+		responseRoots = append(responseRoots, transport.MediaRoot{
+			Label:        root.Label,
+			Path:         root.Path,
+			ReadOnly:     root.ReadOnly,
+			AllowTorrent: root.AllowTorrent,
+			AllowUpload:  root.AllowUpload,
+		})
+	}
 	prefix := strings.TrimSpace(r.URL.Query().Get("prefix"))
 	prefix = strings.Trim(prefix, "\"")
 
@@ -35,6 +50,7 @@ func PathSuggestHandler(w http.ResponseWriter, r *http.Request) {
 	for _, item := range items {
 		responseItems = append(responseItems, transport.PathItem{
 			Path:     item.Path,
+			Label:    labels[filepath.Clean(item.Path)],
 			IsDir:    item.IsDir,
 			Size:     item.Size,
 			Duration: item.Duration,
@@ -42,9 +58,10 @@ func PathSuggestHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	transport.WritePathJSON(w, http.StatusOK, transport.PathResponse{
-		OK:    true,
-		Root:  root,
-		Roots: roots,
-		Items: responseItems,
+		OK:         true,
+		Root:       root,
+		Roots:      roots,
+		NamedRoots: responseRoots,
+		Items:      responseItems,
 	})
 }
@@ -2,49 +2,70 @@
 
 package handlers
 
-import "minfo/internal/httpapi/transport"
+import (
+	"fmt"
 
-// buildInfoTaskProgress 会根据任务类型、状态和日志推导信息类任务当前进度。
-func buildInfoTaskProgress(kind, status string, entries []transport.LogEntry) *transport.TaskProgress {
+	"minfo/internal/httpapi/transport"
+)
+
+// buildInfoTaskProgress 会根据任务类型、状态和已收到的进度事件推导信息类任务当前进度。
+func buildInfoTaskProgress(kind, status string, state *bdinfoProgressState) *transport.TaskProgress {
 	if kind == infoKindMediaInfo {
 		return nil
 	}
-	running := estimateInfoTaskRunningProgress(kind, entries)
+	running := estimateInfoTaskRunningProgress(kind, state)
 	switch status {
-	case infoJobStatusSucceeded:
+	case jobStatusSucceeded:
 		return progressSnapshot(100, "已完成", "任务执行完成。", 0, 0, false)
-	case infoJobStatusFailed:
+	case jobStatusFailed:
 		return finalizeProgress(running, "已失败", "任务执行失败。", false)
-	case infoJobStatusCanceled:
+	case jobStatusCanceled:
 		return finalizeProgress(running, "已取消", "任务已取消。", false)
-	case infoJobStatusCanceling:
+	case jobStatusInterrupted:
+		return finalizeProgress(running, "已中断", "服务重启，任务已中断。", false)
+	case jobStatusCanceling:
 		return progressSnapshot(maxFloat(progressPercent(running), 10), "正在停止", "任务取消中...", progressCurrent(running), progressTotal(running), true)
-	case infoJobStatusRunning:
+	case jobStatusRunning:
 		return running
-	case infoJobStatusPending:
+	case jobStatusPending:
 		fallthrough
 	default:
 		return progressSnapshot(6, "等待开始", "任务已提交，等待执行。", 0, 0, true)
 	}
 }
 
-// buildScreenshotTaskProgress 会根据截图任务模式、状态和日志推导当前进度。
-func buildScreenshotTaskProgress(mode, status string, count int, entries []transport.LogEntry) *transport.TaskProgress {
-	running := estimateScreenshotTaskRunningProgress(mode, count, entries)
+// buildScreenshotTaskProgress 会根据截图任务模式、状态和已收到的进度事件推导当前进度。
+func buildScreenshotTaskProgress(mode, status string, count int, state *screenshotProgressState) *transport.TaskProgress {
+	running := estimateScreenshotTaskRunningProgress(mode, count, state)
 	switch status {
-	case screenshotJobStatusSucceeded:
+	case jobStatusSucceeded:
 		return progressSnapshot(100, "已完成", "任务执行完成。", 0, 0, false)
-	case screenshotJobStatusFailed:
+	case jobStatusFailed:
 		return finalizeProgress(running, "已失败", "任务执行失败。", false)
-	case screenshotJobStatusCanceled:
+	case jobStatusCanceled:
 		return finalizeProgress(running, "已取消", "任务已取消。", false)
-	case screenshotJobStatusCanceling:
+	case jobStatusInterrupted:
+		return finalizeProgress(running, "已中断", "服务重启，任务已中断。", false)
+	case jobStatusCanceling:
 		return progressSnapshot(maxFloat(progressPercent(running), 10), "正在停止", "任务取消中...", progressCurrent(running), progressTotal(running), true)
-	case screenshotJobStatusRunning:
+	case jobStatusRunning:
 		return running
-	case screenshotJobStatusPending:
+	case jobStatusPending:
 		fallthrough
 	default:
 		return progressSnapshot(0, "等待开始", "任务已提交，等待执行。", 0, 0, true)
 	}
 }
+
+// applyQueueProgress 会在任务排队时把进度说明替换为当前排队位置。
+func applyQueueProgress(progress *transport.TaskProgress, position int) {
+	if progress == nil || position <= 0 {
+		return
+	}
+	progress.Stage = "排队中"
+	if position == 1 {
+		progress.Detail = "任务已进入队列，等待空闲执行槽位。"
+		return
+	}
+	progress.Detail = fmt.Sprintf("任务排队中，前面还有 %d 个任务。", position-1)
+}
@@ -1,11 +1,6 @@
 package handlers
 
-import (
-	"strconv"
-	"strings"
-
-	"minfo/internal/httpapi/transport"
-)
+import "minfo/internal/httpapi/transport"
 
 // finalizeProgress 会基于当前快照生成任务结束态的进度结果。
 func finalizeProgress(base *transport.TaskProgress, stage, detail string, indeterminate bool) *transport.TaskProgress {
@@ -87,18 +82,6 @@ func clampPercent(value float64) float64 {
 	}
 }
 
-// parseInt 会从正则匹配结果中安全读取指定索引的整数值。
-func parseInt(values []string, index int) int {
-	if index < 0 || index >= len(values) {
-		return 0
-	}
-	value, err := strconv.Atoi(strings.TrimSpace(values[index]))
-	if err != nil {
-		return 0
-	}
-	return value
-}
-
 // progressPercent 会安全读取进度对象中的百分比值。
 func progressPercent(progress *transport.TaskProgress) float64 {
 	if progress == nil {
@@ -138,3 +121,14 @@ func maxFloat(left, right float64) float64 {
 	}
 	return right
 }
+
+// restoreTaskProgress 会复制持久化记录中的进度；任务因服务重启中断时，把最后的运行进度收尾为中断状态。
+func restoreTaskProgress(progress *transport.TaskProgress, status string) *transport.TaskProgress {
+	if progress == nil {
+		return nil
+	}
+	if status == jobStatusInterrupted {
+		return finalizeProgress(progress, "已中断", "服务重启，任务已中断。", false)
+	}
+	return cloneTaskProgress(progress)
+}
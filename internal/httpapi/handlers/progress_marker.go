@@ -1,11 +1,6 @@
 package handlers
 
-import (
-	"strings"
-
-	"minfo/internal/httpapi/transport"
-	"minfo/internal/taskprogress"
-)
+import "minfo/internal/taskprogress"
 
 type screenshotProgressMarker struct {
 	current      int
@@ -33,68 +28,58 @@ type screenshotProgressState struct {
 	uploadTotal         int
 	uploadProcessed     int
 	uploadFinished      bool
+	eta                 int64
+	received            int
 }
 
-// parseScreenshotProgressState 会把截图日志解析成便于估算进度的状态快照。
-func parseScreenshotProgressState(entries []transport.LogEntry) screenshotProgressState {
-	state := screenshotProgressState{}
-
-	for idx, entry := range entries {
-		line := strings.TrimSpace(entry.Message)
-		if event, ok := taskprogress.ParseLogLine(line); ok {
-			switch event.Kind {
-			case taskprogress.KindPercent:
-				switch event.Stage {
-				case taskprogress.StageBootstrap:
-					state.bootstrapMarker = updateScreenshotProgressMarkerPercent(state.bootstrapMarker, event.Percent, event.Detail, idx)
-				case taskprogress.StageRender:
-					state.renderMarker = updateScreenshotProgressMarkerPercent(state.renderMarker, event.Percent, event.Detail, idx)
-				case taskprogress.StagePrepare:
-					state.prepMarker = updateScreenshotProgressMarkerPercent(state.prepMarker, event.Percent, event.Detail, idx)
-				case taskprogress.StagePackage:
-					state.packageMarker = updateScreenshotProgressMarkerPercent(state.packageMarker, event.Percent, event.Detail, idx)
-				case taskprogress.StageSubtitle:
-					state.subtitleMarker = updateScreenshotProgressMarkerPercent(state.subtitleMarker, event.Percent, event.Detail, idx)
-				}
-			case taskprogress.KindStep:
-				switch event.Stage {
-				case taskprogress.StageBootstrap:
-					state.bootstrapMarker = updateScreenshotProgressMarkerStep(state.bootstrapMarker, event.Current, event.Total, event.Detail, idx)
-				case taskprogress.StageSubtitle:
-					state.subtitleMarker = updateScreenshotProgressMarkerStep(state.subtitleMarker, event.Current, event.Total, event.Detail, idx)
-				case taskprogress.StagePrepare:
-					state.prepMarker = updateScreenshotProgressMarkerStep(state.prepMarker, event.Current, event.Total, event.Detail, idx)
-				case taskprogress.StageCaptureStart:
-					state.captureStarted = event.Current
-					state.captureTotal = maxInt(state.captureTotal, event.Total)
-					state.captureStartDetail = event.Detail
-					state.captureStartOrder = idx
-					state.renderMarker = nil
-				case taskprogress.StageCaptureDone:
-					state.captureCompleted = event.Current
-					state.captureTotal = maxInt(state.captureTotal, event.Total)
-					state.captureFinishDetail = event.Detail
-					state.captureFinishOrder = idx
-					state.renderMarker = nil
-				case taskprogress.StagePackage:
-					state.packageMarker = updateScreenshotProgressMarkerStep(state.packageMarker, event.Current, event.Total, event.Detail, idx)
-				}
-			}
-			continue
+// apply 会按到达顺序把截图流程发出的一条结构化进度事件合并进状态快照。
+func (state *screenshotProgressState) apply(event taskprogress.Event) {
+	state.received++
+	order := state.received
+	state.eta = event.ETAMS
+	switch event.Kind {
+	case taskprogress.KindPercent:
+		switch event.Stage {
+		case taskprogress.StageBootstrap:
+			state.bootstrapMarker = updateScreenshotProgressMarkerPercent(state.bootstrapMarker, event.Percent, event.Detail, order)
+		case taskprogress.StageRender:
+			state.renderMarker = updateScreenshotProgressMarkerPercent(state.renderMarker, event.Percent, event.Detail, order)
+		case taskprogress.StagePrepare:
+			state.prepMarker = updateScreenshotProgressMarkerPercent(state.prepMarker, event.Percent, event.Detail, order)
+		case taskprogress.StagePackage:
+			state.packageMarker = updateScreenshotProgressMarkerPercent(state.packageMarker, event.Percent, event.Detail, order)
+		case taskprogress.StageSubtitle:
+			state.subtitleMarker = updateScreenshotProgressMarkerPercent(state.subtitleMarker, event.Percent, event.Detail, order)
+		case taskprogress.StageUpload:
+			state.uploadFinished = event.Percent >= 100
 		}
-
-		switch {
-		case screenshotUploadStartPattern.MatchString(line):
-			matches := screenshotUploadStartPattern.FindStringSubmatch(line)
-			state.uploadTotal = parseInt(matches, 1)
-		case strings.HasPrefix(line, "已上传并校准域名:") || strings.HasPrefix(line, "上传失败:"):
-			state.uploadProcessed++
-		case strings.HasPrefix(line, "处理完成! 成功:"):
-			state.uploadFinished = true
+	case taskprogress.KindStep:
+		switch event.Stage {
+		case taskprogress.StageBootstrap:
+			state.bootstrapMarker = updateScreenshotProgressMarkerStep(state.bootstrapMarker, event.Current, event.Total, event.Detail, order)
+		case taskprogress.StageSubtitle:
+			state.subtitleMarker = updateScreenshotProgressMarkerStep(state.subtitleMarker, event.Current, event.Total, event.Detail, order)
+		case taskprogress.StagePrepare:
+			state.prepMarker = updateScreenshotProgressMarkerStep(state.prepMarker, event.Current, event.Total, event.Detail, order)
+		case taskprogress.StageCaptureStart:
+			state.captureStarted = event.Current
+			state.captureTotal = maxInt(state.captureTotal, event.Total)
+			state.captureStartDetail = event.Detail
+			state.captureStartOrder = order
+			state.renderMarker = nil
+		case taskprogress.StageCaptureDone:
+			state.captureCompleted = event.Current
+			state.captureTotal = maxInt(state.captureTotal, event.Total)
+			state.captureFinishDetail = event.Detail
+			state.captureFinishOrder = order
+			state.renderMarker = nil
+		case taskprogress.StagePackage:
+			state.packageMarker = updateScreenshotProgressMarkerStep(state.packageMarker, event.Current, event.Total, event.Detail, order)
+		case taskprogress.StageUpload:
+			state.uploadTotal = event.Total
+			state.uploadProcessed = event.Current
 		}
 	}
-
-	return state
 }
 
 // updateScreenshotProgressMarkerStep 会用 step 型进度日志刷新阶段标记。
@@ -2,62 +2,32 @@ package handlers
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
-	"strings"
 
 	"minfo/internal/httpapi/transport"
 	"minfo/internal/screenshot"
 )
 
-var screenshotUploadStartPattern = regexp.MustCompile(`^开始处理 (\d+) 个文件\.\.\.$`)
-
-// estimateScreenshotTaskRunningProgress 会根据截图模式和日志推导截图任务的运行进度。
-func estimateScreenshotTaskRunningProgress(mode string, count int, entries []transport.LogEntry) *transport.TaskProgress {
+// estimateScreenshotTaskRunningProgress 会根据截图模式和已收到的进度事件推导截图任务的运行进度。
+func estimateScreenshotTaskRunningProgress(mode string, count int, state *screenshotProgressState) *transport.TaskProgress {
 	requestedCount := screenshot.NormalizeCount(strconv.Itoa(count))
 	if requestedCount <= 0 {
 		requestedCount = 1
 	}
 
-	state := parseScreenshotProgressState(entries)
-	if mode == screenshot.ModeLinks {
-		if progress := estimateUploadProgressFromMarkers(requestedCount, state); progress != nil {
-			return progress
+	var progress *transport.TaskProgress
+	if state != nil {
+		if mode == screenshot.ModeLinks {
+			progress = estimateUploadProgressFromMarkers(requestedCount, *state)
+		} else {
+			progress = estimateZipProgressFromMarkers(requestedCount, *state)
 		}
-	} else if progress := estimateZipProgressFromMarkers(requestedCount, state); progress != nil {
-		return progress
 	}
-
-	initFinished := false
-	captureAttempts := 0
-	captureFinished := false
-	uploadTotal := 0
-	uploadProcessed := 0
-	uploadFinished := false
-
-	for _, entry := range entries {
-		line := strings.TrimSpace(entry.Message)
-		switch {
-		case strings.HasPrefix(line, "[信息] 容器起始偏移："):
-			initFinished = true
-		case strings.HasPrefix(line, "[信息] 截图:"):
-			captureAttempts++
-		case line == "===== 任务完成 =====":
-			captureFinished = true
-		case screenshotUploadStartPattern.MatchString(line):
-			matches := screenshotUploadStartPattern.FindStringSubmatch(line)
-			uploadTotal = parseInt(matches, 1)
-		case strings.HasPrefix(line, "已上传并校准域名:") || strings.HasPrefix(line, "上传失败:"):
-			uploadProcessed++
-		case strings.HasPrefix(line, "处理完成! 成功:"):
-			uploadFinished = true
-		}
-	}
-
-	if mode == screenshot.ModeLinks {
-		return estimateUploadRunningProgress(requestedCount, initFinished, captureAttempts, captureFinished, uploadTotal, uploadProcessed, uploadFinished)
+	if progress == nil {
+		return progressSnapshot(0, "准备任务", "正在等待耗时步骤开始。", 0, 0, true)
 	}
-	return estimateZipRunningProgress(requestedCount, initFinished, captureAttempts, captureFinished)
+	progress.ETAMS = state.eta
+	return progress
 }
 
 // estimateZipProgressFromMarkers 会优先根据截图阶段标记估算压缩包模式进度。
@@ -175,49 +145,6 @@ func estimateUploadProgressFromMarkers(requestedCount int, state screenshotProgr
 	return nil
 }
 
-// estimateZipRunningProgress 会在缺少细粒度标记时估算压缩包模式的粗略进度。
-func estimateZipRunningProgress(requestedCount int, initFinished bool, captureAttempts int, captureFinished bool) *transport.TaskProgress {
-	if !initFinished {
-		return progressSnapshot(0, "准备任务", "正在等待耗时步骤开始。", 0, 0, true)
-	}
-	if captureFinished {
-		return progressSnapshot(90, "打包结果", "截图已生成，正在整理下载包。", requestedCount, requestedCount, true)
-	}
-
-	processed := clampInt(captureAttempts, 0, requestedCount)
-	percent := scaledProgress(processed, requestedCount, 100)
-	return progressSnapshot(percent, "生成截图", fmt.Sprintf("已处理 %d/%d 个截图点。", processed, requestedCount), processed, requestedCount, false)
-}
-
-// estimateUploadRunningProgress 会在缺少细粒度标记时估算上传模式的粗略进度。
-func estimateUploadRunningProgress(requestedCount int, initFinished bool, captureAttempts int, captureFinished bool, uploadTotal int, uploadProcessed int, uploadFinished bool) *transport.TaskProgress {
-	if !initFinished {
-		return progressSnapshot(0, "准备任务", "正在等待耗时步骤开始。", 0, 0, true)
-	}
-
-	if uploadFinished {
-		processed := uploadProcessed
-		if uploadTotal > 0 {
-			processed = clampInt(processed, 0, uploadTotal)
-		}
-		return progressSnapshot(97, "整理图床结果", "上传已完成，正在整理图床链接。", processed, uploadTotal, true)
-	}
-
-	if uploadTotal > 0 {
-		processed := clampInt(uploadProcessed, 0, uploadTotal)
-		percent := uploadStageBase() + scaledProgress(processed, uploadTotal, uploadStageWidth())
-		return progressSnapshot(percent, "上传图床", fmt.Sprintf("已处理 %d/%d 张截图上传。", processed, uploadTotal), processed, uploadTotal, false)
-	}
-
-	if captureFinished {
-		return progressSnapshot(uploadStageBase(), "准备上传", "截图已生成，正在准备上传图床。", requestedCount, requestedCount, true)
-	}
-
-	processed := clampInt(captureAttempts, 0, requestedCount)
-	percent := scaledProgress(processed, requestedCount, uploadRenderWidth(false))
-	return progressSnapshot(percent, "生成截图", fmt.Sprintf("已处理 %d/%d 个截图点。", processed, requestedCount), processed, requestedCount, false)
-}
-
 // subtitleStageWidth 会返回字幕准备阶段在总进度中的宽度占比。
 func subtitleStageWidth() int {
 	return 30
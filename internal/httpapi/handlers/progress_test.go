@@ -3,17 +3,31 @@ package handlers
 import (
 	"testing"
 
-	"minfo/internal/httpapi/transport"
+	"minfo/internal/bdinfo"
 	"minfo/internal/screenshot"
+	"minfo/internal/taskprogress"
 )
 
+// bdinfoStateFromEvents 会按顺序把进度事件合并成 BDInfo 进度状态。
+func bdinfoStateFromEvents(events ...taskprogress.Event) *bdinfoProgressState {
+	state := &bdinfoProgressState{}
+	for _, event := range events {
+		state.apply(event)
+	}
+	return state
+}
+
+// screenshotStateFromEvents 会按顺序把进度事件合并成截图进度状态。
+func screenshotStateFromEvents(events ...taskprogress.Event) *screenshotProgressState {
+	state := &screenshotProgressState{}
+	for _, event := range events {
+		state.apply(event)
+	}
+	return state
+}
+
 func TestBuildInfoTaskProgressForMediaInfoRunning(t *testing.T) {
-	progress := buildInfoTaskProgress(infoKindMediaInfo, infoJobStatusRunning, []transport.LogEntry{
-		{Message: "[mediainfo] 输入路径: /media/demo"},
-		{Message: "[mediainfo] 使用命令: /usr/bin/mediainfo"},
-		{Message: "[mediainfo] 候选源数量: 3"},
-		{Message: "[mediainfo] 尝试 2/3: /media/demo/STREAM/00002.m2ts"},
-	})
+	progress := buildInfoTaskProgress(infoKindMediaInfo, jobStatusRunning, &bdinfoProgressState{})
 
 	if progress != nil {
 		t.Fatalf("progress = %#v, want nil for mediainfo", progress)
@@ -21,35 +35,38 @@ func TestBuildInfoTaskProgressForMediaInfoRunning(t *testing.T) {
 }
 
 func TestBuildInfoTaskProgressForBDInfoRunning(t *testing.T) {
-	progress := buildInfoTaskProgress(infoKindBDInfo, infoJobStatusRunning, []transport.LogEntry{
-		{Message: "[bdinfo] 实际检测路径: /media/demo"},
-		{Message: "[bdinfo] 使用命令: /usr/local/bin/bdinfo"},
-		{Message: "[bdinfo] 已用 bind 挂载包装 BDMV 根: /media/demo/BDMV -> /tmp/minfo/source/BDMV"},
-		{Message: "[bdinfo] 执行命令: cwd=/tmp/minfo | /usr/local/bin/bdinfo -w /tmp/minfo/source /tmp/minfo"},
-	})
+	progress := buildInfoTaskProgress(infoKindBDInfo, jobStatusRunning, bdinfoStateFromEvents(
+		taskprogress.Step(bdinfo.StageResolve, 1, bdinfo.StageCount, "正在解析蓝光输入源。"),
+		taskprogress.Step(bdinfo.StagePrepare, 2, bdinfo.StageCount, "正在准备 BDInfo 扫描目录。"),
+		taskprogress.Step(bdinfo.StageScan, 3, bdinfo.StageCount, "BDInfo 已启动，等待扫描进度输出。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
 	}
-	if progress.Stage != "启动扫描" {
-		t.Fatalf("Stage = %q, want %q", progress.Stage, "启动扫描")
+	if progress.Stage != "扫描蓝光目录" {
+		t.Fatalf("Stage = %q, want %q", progress.Stage, "扫描蓝光目录")
 	}
 	if !progress.Indeterminate {
 		t.Fatalf("Indeterminate = false, want true")
 	}
-	if progress.Percent < 20 || progress.Percent > 24 {
-		t.Fatalf("Percent = %.2f, want around low-20s before real scan progress arrives", progress.Percent)
+	if progress.Percent != 28 {
+		t.Fatalf("Percent = %.2f, want 28 before real scan progress arrives", progress.Percent)
 	}
 }
 
 func TestBuildInfoTaskProgressForBDInfoCLIRealScanProgress(t *testing.T) {
-	progress := buildInfoTaskProgress(infoKindBDInfo, infoJobStatusRunning, []transport.LogEntry{
-		{Message: "[bdinfo] 实际检测路径: /media/demo"},
-		{Message: "[bdinfo] 使用命令: /usr/local/bin/bdinfo"},
-		{Message: "[bdinfo] 执行命令: cwd=/tmp/minfo | /usr/local/bin/bdinfo -w /tmp/minfo/source /tmp/minfo"},
-		{Message: "[bdinfo][stdout] Please wait while we scan the disc..."},
-		{Message: "[bdinfo][stdout] Scanning  42% - 00010.M2TS     00:00:12  |  00:00:15"},
-	})
+	progress := buildInfoTaskProgress(infoKindBDInfo, jobStatusRunning, bdinfoStateFromEvents(
+		taskprogress.Step(bdinfo.StageScan, 3, bdinfo.StageCount, "BDInfo 已启动，等待扫描进度输出。"),
+		taskprogress.Event{
+			Kind:    taskprogress.KindPercent,
+			Stage:   bdinfo.StageScan,
+			Current: 42,
+			Total:   100,
+			Percent: 42,
+			Detail:  "00010.M2TS     00:00:12  |  00:00:15",
+		},
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -72,10 +89,10 @@ func TestBuildInfoTaskProgressForBDInfoCLIRealScanProgress(t *testing.T) {
 }
 
 func TestBuildInfoTaskProgressForBDInfoReportGeneration(t *testing.T) {
-	progress := buildInfoTaskProgress(infoKindBDInfo, infoJobStatusRunning, []transport.LogEntry{
-		{Message: "[bdinfo][stdout] Scan completed successfully."},
-		{Message: "[bdinfo][stdout] Please wait while we generate the report..."},
-	})
+	progress := buildInfoTaskProgress(infoKindBDInfo, jobStatusRunning, bdinfoStateFromEvents(
+		taskprogress.Percent(bdinfo.StageScan, 100, "00010.M2TS"),
+		taskprogress.Step(bdinfo.StageGenerate, 4, bdinfo.StageCount, "BDInfo 已完成扫描，正在生成报告。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -92,11 +109,10 @@ func TestBuildInfoTaskProgressForBDInfoReportGeneration(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForZipRunning(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 4, []transport.LogEntry{
-		{Message: "[信息] 容器起始偏移：0.000s | 影片总时长：01:45:20"},
-		{Message: "[信息] 截图: 请求 00:10:00.000 → 对齐 00:10:01.000 → 输出 00:10:01.000 -> 00_10_01.png"},
-		{Message: "[信息] 截图: 请求 00:30:00.000 → 对齐 00:30:01.000 → 输出 00:30:01.000 -> 00_30_01.png"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 4, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageCaptureDone, 1, 4, "已完成第 1/4 张截图：00_10_01.png"),
+		taskprogress.Step(taskprogress.StageCaptureDone, 2, 4, "已完成第 2/4 张截图：00_30_01.png"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -113,9 +129,9 @@ func TestBuildScreenshotTaskProgressForZipRunning(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForBootstrapMarker(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 4, []transport.LogEntry{
-		{Message: "[进度] 启动 3/3: 正在估算影片时长并生成随机截图时间点。"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 4, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageBootstrap, 3, 3, "正在估算影片时长并生成随机截图时间点。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -132,13 +148,12 @@ func TestBuildScreenshotTaskProgressForBootstrapMarker(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForUploadRunning(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeLinks, screenshotJobStatusRunning, 4, []transport.LogEntry{
-		{Message: "[信息] 容器起始偏移：0.000s | 影片总时长：01:45:20"},
-		{Message: "===== 任务完成 ====="},
-		{Message: "开始处理 4 个文件..."},
-		{Message: "已上传并校准域名: 00_10_01.png"},
-		{Message: "上传失败: 00_30_01.png (timeout)"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeLinks, jobStatusRunning, 4, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageCaptureDone, 4, 4, "已完成第 4/4 张截图：01_20_01.png"),
+		taskprogress.Step(taskprogress.StageUpload, 0, 4, "开始处理 4 个文件..."),
+		taskprogress.Step(taskprogress.StageUpload, 1, 4, "已上传并校准域名: 00_10_01.png"),
+		taskprogress.Step(taskprogress.StageUpload, 2, 4, "上传失败: 00_30_01.png (timeout)"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -155,9 +170,9 @@ func TestBuildScreenshotTaskProgressForUploadRunning(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForSubtitleMarker(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 字幕 2/3: 正在用 ffprobe 补充蓝光字幕元数据：playlist 00800。"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageSubtitle, 2, 3, "正在用 ffprobe 补充蓝光字幕元数据：playlist 00800。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -174,10 +189,10 @@ func TestBuildScreenshotTaskProgressForSubtitleMarker(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForSubtitlePercentMarkerUsesStepProgress(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 字幕 3/3: 正在提取内封文字字幕。"},
-		{Message: "[进度] 字幕 50%: 正在提取内封文字字幕。 | frame=12 | speed=1.0x"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageSubtitle, 3, 3, "正在提取内封文字字幕。"),
+		taskprogress.Percent(taskprogress.StageSubtitle, 50, "正在提取内封文字字幕。 | frame=12 | speed=1.0x"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -200,10 +215,10 @@ func TestBuildScreenshotTaskProgressForSubtitlePercentMarkerUsesStepProgress(t *
 }
 
 func TestBuildScreenshotTaskProgressForSubtitlePercentMarkerUsesCurrentStepPosition(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 字幕 1/3: 正在探测内封字幕轨。"},
-		{Message: "[进度] 字幕 50%: 正在探测内封字幕轨。 | 已耗时 10s"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageSubtitle, 1, 3, "正在探测内封字幕轨。"),
+		taskprogress.Percent(taskprogress.StageSubtitle, 50, "正在探测内封字幕轨。 | 已耗时 10s"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -220,9 +235,9 @@ func TestBuildScreenshotTaskProgressForSubtitlePercentMarkerUsesCurrentStepPosit
 }
 
 func TestBuildScreenshotTaskProgressForDVDMediaInfoSubtitleMarker(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 字幕 1/3: 正在读取 DVD MediaInfo 字幕元数据。"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageSubtitle, 1, 3, "正在读取 DVD MediaInfo 字幕元数据。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -239,10 +254,10 @@ func TestBuildScreenshotTaskProgressForDVDMediaInfoSubtitleMarker(t *testing.T)
 }
 
 func TestBuildScreenshotTaskProgressForPrepMarkerAfterSubtitle(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 字幕 100%: 字幕准备完成。"},
-		{Message: "[进度] 准备 2/3: 正在检测 libplacebo / Vulkan 处理能力。"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Percent(taskprogress.StageSubtitle, 100, "字幕准备完成。"),
+		taskprogress.Step(taskprogress.StagePrepare, 2, 3, "正在检测 libplacebo / Vulkan 处理能力。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -262,11 +277,11 @@ func TestBuildScreenshotTaskProgressForPrepMarkerAfterSubtitle(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForRenderPercentMarker(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 启动 3/3: 正在估算影片时长并生成随机截图时间点。"},
-		{Message: "[进度] 截图开始 1/1: 正在渲染第 1/1 张截图：00_10_01.png"},
-		{Message: "[进度] 渲染 48%: 正在渲染第 1/1 张截图：00_10_01.png | frame=0 | speed=0.7x"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageBootstrap, 3, 3, "正在估算影片时长并生成随机截图时间点。"),
+		taskprogress.Step(taskprogress.StageCaptureStart, 1, 1, "正在渲染第 1/1 张截图：00_10_01.png"),
+		taskprogress.Percent(taskprogress.StageRender, 48, "正在渲染第 1/1 张截图：00_10_01.png | frame=0 | speed=0.7x"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -286,10 +301,10 @@ func TestBuildScreenshotTaskProgressForRenderPercentMarker(t *testing.T) {
 }
 
 func TestBuildScreenshotTaskProgressForAlignmentAndVisibilityMarker(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 4, []transport.LogEntry{
-		{Message: "[进度] 截图开始 1/4: 正在对齐第 1/4 张截图时间点..."},
-		{Message: "[进度] 截图开始 1/4: 正在校验 PGS 字幕是否可见..."},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 4, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageCaptureStart, 1, 4, "正在对齐第 1/4 张截图时间点..."),
+		taskprogress.Step(taskprogress.StageCaptureStart, 1, 4, "正在校验 PGS 字幕是否可见..."),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -309,11 +324,11 @@ func TestBuildScreenshotTaskProgressForAlignmentAndVisibilityMarker(t *testing.T
 }
 
 func TestBuildScreenshotTaskProgressForRenderPercentMarkerDoesNotRollbackOnReencode(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 截图开始 1/1: 正在渲染第 1/1 张截图：00_10_01.png"},
-		{Message: "[进度] 渲染 92%: 正在渲染第 1/1 张截图：00_10_01.png | frame=1 | speed=1.4x"},
-		{Message: "[进度] 渲染 8%: 正在重拍第 1/1 张截图：00_10_01.png | frame=0 | speed=0.6x"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageCaptureStart, 1, 1, "正在渲染第 1/1 张截图：00_10_01.png"),
+		taskprogress.Percent(taskprogress.StageRender, 92, "正在渲染第 1/1 张截图：00_10_01.png | frame=1 | speed=1.4x"),
+		taskprogress.Percent(taskprogress.StageRender, 8, "正在重拍第 1/1 张截图：00_10_01.png | frame=0 | speed=0.6x"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -330,10 +345,10 @@ func TestBuildScreenshotTaskProgressForRenderPercentMarkerDoesNotRollbackOnReenc
 }
 
 func TestBuildScreenshotTaskProgressForPackageMarker(t *testing.T) {
-	progress := buildScreenshotTaskProgress(screenshot.ModeZip, screenshotJobStatusRunning, 1, []transport.LogEntry{
-		{Message: "[进度] 截图完成 1/1: 已完成第 1/1 张截图：00_10_01.png"},
-		{Message: "[进度] 整理 2/4: 正在压缩截图文件。"},
-	})
+	progress := buildScreenshotTaskProgress(screenshot.ModeZip, jobStatusRunning, 1, screenshotStateFromEvents(
+		taskprogress.Step(taskprogress.StageCaptureDone, 1, 1, "已完成第 1/1 张截图：00_10_01.png"),
+		taskprogress.Step(taskprogress.StagePackage, 2, 4, "正在压缩截图文件。"),
+	))
 
 	if progress == nil {
 		t.Fatal("progress is nil")
@@ -0,0 +1,96 @@
+// Package handlers 提供发布描述模板的列表接口、模板参数解析和任务描述的按需渲染。
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"minfo/internal/config"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/release"
+)
+
+// parseReleaseTemplate 会读取表单中的 template 参数并确认模板存在且能解析；未填写时返回空字符串。
+func parseReleaseTemplate(r *http.Request) (string, error) {
+	name := release.NormalizeName(r.FormValue("template"))
+	if name == "" {
+		return "", nil
+	}
+	if err := release.Lookup(config.TemplateDir, name); err != nil {
+		if errors.Is(err, release.ErrTemplateNotFound) {
+			return "", errors.New("release template not found: " + name)
+		}
+		return "", err
+	}
+	return name, nil
+}
+
+// TemplatesHandler 会返回全部可用的发布描述模板，包括内置模板和用户模板目录中的模板。
+func TemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeTemplateListResponse(w, http.StatusMethodNotAllowed, transport.TemplateListResponse{Error: "method not allowed"})
+		return
+	}
+
+	templates, err := release.List(config.TemplateDir)
+	if err != nil {
+		writeTemplateListResponse(w, http.StatusInternalServerError, transport.TemplateListResponse{Error: err.Error()})
+		return
+	}
+	writeTemplateListResponse(w, http.StatusOK, transport.TemplateListResponse{OK: true, Templates: templates})
+}
+
+// handleJobDescription 会用 template 查询参数指定的模板重新渲染已成功任务的发布描述；未指定时沿用提交任务时选择的模板。
+func handleJobDescription(w http.ResponseWriter, r *http.Request, job managedJob) {
+	b := job.base()
+	name := release.NormalizeName(r.URL.Query().Get("template"))
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.status != jobStatusSucceeded {
+		writeJobDescriptionResponse(w, http.StatusConflict, transport.JobDescriptionResponse{JobID: b.id, Error: "job has not succeeded"})
+		return
+	}
+	if name == "" {
+		name = b.template
+	}
+	if name == "" {
+		writeJobDescriptionResponse(w, http.StatusBadRequest, transport.JobDescriptionResponse{JobID: b.id, Error: "template is required"})
+		return
+	}
+
+	description, err := b.renderDescriptionLocked(name)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, release.ErrTemplateNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJobDescriptionResponse(w, status, transport.JobDescriptionResponse{JobID: b.id, Template: name, Error: err.Error()})
+		return
+	}
+	writeJobDescriptionResponse(w, http.StatusOK, transport.JobDescriptionResponse{
+		OK:          true,
+		JobID:       b.id,
+		Template:    name,
+		Description: description,
+	})
+}
+
+// writeTemplateListResponse 会把模板列表响应编码为 JSON，并显式关闭缓存。
+func writeTemplateListResponse(w http.ResponseWriter, status int, payload transport.TemplateListResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// writeJobDescriptionResponse 会把任务描述响应编码为 JSON，并显式关闭缓存。
+func writeJobDescriptionResponse(w http.ResponseWriter, status int, payload transport.JobDescriptionResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,290 @@
+// Package handlers 提供发布包后台任务的分步执行与产物下载。
+
+package handlers
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/mediainfo"
+	"minfo/internal/screenshot"
+	screenshotdelivery "minfo/internal/screenshot/delivery"
+	"minfo/internal/system"
+	"minfo/internal/taskprogress"
+	"minfo/internal/torrent"
+)
+
+// 发布包目录中固定名称的产物文件；种子文件沿用 mkbrr 推导的文件名。
+const (
+	releaseDescriptionFile = "description.txt"
+	releaseScreenshotsFile = "screenshots.zip"
+	releaseBDInfoFile      = "bdinfo.txt"
+)
+
+// execute 会先解析输入路径，再按顺序执行各步骤；所有步骤共用同一次解析结果，ISO 挂载或共享在全部步骤结束后释放，任一步骤失败即结束任务。
+func (j *releaseJob) execute(ctx context.Context) {
+	inputPath, release, err := j.input.resolve(ctx)
+	if err != nil {
+		j.fail(err)
+		return
+	}
+	defer release()
+	j.inputPath = inputPath
+
+	outputDir, err := createJobOutputDir("releases", "minfo-release-job-*")
+	if err != nil {
+		j.fail(err)
+		return
+	}
+	j.mu.Lock()
+	j.outputDir = outputDir
+	j.mu.Unlock()
+	j.logger.Logf("[release] 输入路径: %s", j.inputPath)
+
+	runners := map[string]func(context.Context, string) error{
+		releaseStepInfo:        j.runInfoStep,
+		releaseStepScreenshots: j.runScreenshotStep,
+		releaseStepTorrent:     j.runTorrentStep,
+		releaseStepBundle:      j.runBundleStep,
+	}
+	for index, name := range releaseStepNames {
+		if err := ctx.Err(); err != nil {
+			j.fail(err)
+			return
+		}
+		j.setStepStatus(index, jobStatusRunning)
+		j.logger.Logf("[release] 开始步骤: %s", releaseStepLabels[name])
+		if err := runners[name](ctx, outputDir); err != nil {
+			j.logger.Logf("[release] 步骤失败: %s: %s", releaseStepLabels[name], err.Error())
+			j.fail(err)
+			return
+		}
+		j.setStepStatus(index, jobStatusSucceeded)
+	}
+
+	j.logger.Logf("[release] 发布包已生成: %s", outputDir)
+	j.succeed(nil)
+}
+
+// runInfoStep 会运行 MediaInfo 或 BDInfo，并把输出写入发布包。
+func (j *releaseJob) runInfoStep(ctx context.Context, outputDir string) error {
+	if j.infoKind == infoKindBDInfo {
+		output, report, err := runBDInfo(ctx, j.inputPath, j.bdinfoMode, j.logger, j.progressHandler(j.infoProgress.apply), j.cache)
+		if err != nil {
+			return err
+		}
+		j.mu.Lock()
+		j.info = output
+		j.bdinfoReport = report
+		j.mu.Unlock()
+		return writeReleaseFile(outputDir, releaseBDInfoFile, output)
+	}
+
+	bin, err := system.ResolveBin(system.MediaInfoBinaryPath)
+	if err != nil {
+		j.logger.Logf("[mediainfo] 未找到可执行文件: %s", err.Error())
+		return err
+	}
+	output, summary, err := runMediaInfo(ctx, j.inputPath, j.mediainfoMode, j.logger, bin, j.cache)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	j.info = output
+	j.mediainfoSummary = summary
+	j.mu.Unlock()
+	return writeReleaseFile(outputDir, mediainfoFileName(j.mediainfoMode), output)
+}
+
+// runScreenshotStep 会生成截图并打包为压缩包；开启上传时再把同一批截图上传到图床。
+func (j *releaseJob) runScreenshotStep(ctx context.Context, outputDir string) error {
+	tempDir, err := createScreenshotTempDir("minfo-release-screenshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	onProgress := j.progressHandler(j.screenshotProgress.apply)
+	uploadOptions := screenshot.UploadOptions{ProxyURL: j.proxyURL, Host: j.imageHost, Render: j.screenshot.Render}
+	var result screenshot.ScreenshotsResult
+	if j.upload {
+		// 需要上传时按所选图床的大小上限和预设压缩截图。
+		result, err = screenshot.RunScreenshotsForUploadWithLiveLogs(
+			ctx,
+			j.inputPath,
+			tempDir,
+			j.screenshot.Variant,
+			j.screenshot.SubtitleMode,
+			j.screenshot.HDRProcessor,
+			j.screenshot.Count,
+			uploadOptions,
+			j.logger.LogLine,
+			onProgress,
+		)
+	} else {
+		result, err = screenshot.RunScreenshotsWithRenderOptions(
+			ctx,
+			j.inputPath,
+			tempDir,
+			j.screenshot.Variant,
+			j.screenshot.SubtitleMode,
+			j.screenshot.HDRProcessor,
+			j.screenshot.Count,
+			j.screenshot.Render,
+			j.logger.LogLine,
+			onProgress,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	zipBytes, err := screenshotdelivery.ZipFiles(result.Files)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, releaseScreenshotsFile), zipBytes, 0o644); err != nil {
+		return err
+	}
+	if !j.upload {
+		return nil
+	}
+
+	onItem := func(item screenshot.UploadedImage) {
+		j.appendLinkItem(buildTransportImageLinkItem(item))
+	}
+	uploaded, err := screenshot.UploadScreenshots(ctx, result, uploadOptions, j.logger.LogLine, onProgress, onItem)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	j.linkItems = buildTransportImageLinkItems(uploaded.Items)
+	j.mu.Unlock()
+	return nil
+}
+
+// runTorrentStep 会制作种子并读取 info hash，种子文件以 mkbrr 推导的文件名保存在发布包中。
+func (j *releaseJob) runTorrentStep(ctx context.Context, outputDir string) error {
+	outputPath := filepath.Join(outputDir, "output.torrent")
+	onLine := func(stream, line string) {
+		logMkbrrLine(j.logger, stream, line)
+	}
+	onProgress := j.progressHandler(func(event taskprogress.Event) {
+		j.torrentProgress = torrentProgressSnapshot(event)
+	})
+	filename, err := torrent.Create(ctx, j.inputPath, outputPath, j.torrentOptions, onLine, onProgress)
+	if err != nil {
+		return err
+	}
+	meta, err := torrent.ReadMetaInfo(outputPath)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(filename)
+	if name == "." || name == string(filepath.Separator) || name == releaseDescriptionFile || name == releaseScreenshotsFile {
+		name = filepath.Base(outputPath)
+	}
+	if err := os.Rename(outputPath, filepath.Join(outputDir, name)); err != nil {
+		return err
+	}
+	j.logger.Logf("[torrent] 完成: %s (info hash %s)", name, meta.InfoHash)
+
+	j.mu.Lock()
+	j.metaInfo = meta
+	j.torrentFile = name
+	j.mu.Unlock()
+	return nil
+}
+
+// runBundleStep 会用所选模板渲染发布描述并写入发布包。
+func (j *releaseJob) runBundleStep(_ context.Context, outputDir string) error {
+	j.mu.RLock()
+	description, err := j.renderDescriptionLocked(j.template)
+	j.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return writeReleaseFile(outputDir, releaseDescriptionFile, description)
+}
+
+// appendLinkItem 会在上传过程中逐步追加已完成上传的图片链接。
+func (j *releaseJob) appendLinkItem(item transport.ImageLinkItem) {
+	if strings.TrimSpace(item.URL) == "" {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, existing := range j.linkItems {
+		if existing.URL == item.URL {
+			return
+		}
+	}
+	j.linkItems = append(j.linkItems, item)
+	j.updatedAt = time.Now()
+	j.events.publish(jobEventItem, item)
+}
+
+// writeReleaseFile 会把文本产物写入发布包目录。
+func writeReleaseFile(outputDir, name, content string) error {
+	return os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0o644)
+}
+
+// mediainfoFileName 返回 MediaInfo 输出在发布包中的文件名，扩展名与输出模式一致。
+func mediainfoFileName(mode string) string {
+	if mode == mediainfo.ModeText || mode == "" {
+		return "mediainfo.txt"
+	}
+	return "mediainfo." + mode
+}
+
+// handleReleaseJobFile 会以附件形式返回发布包中的单个产物文件。
+func handleReleaseJobFile(w http.ResponseWriter, r *http.Request, jobID, name string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeReleaseJobError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	job, ok := getReleaseJob(jobID)
+	if !ok {
+		writeReleaseJobError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	job.mu.RLock()
+	status := job.status
+	outputDir := job.outputDir
+	job.mu.RUnlock()
+
+	if status != jobStatusSucceeded || outputDir == "" {
+		writeReleaseJobError(w, http.StatusNotFound, "release bundle is not ready")
+		return
+	}
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		writeReleaseJobError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	path := filepath.Join(outputDir, name)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		writeReleaseJobError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	if filepath.Ext(name) == ".torrent" {
+		w.Header().Set("Content-Type", "application/x-bittorrent")
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	http.ServeFile(w, r, path)
+}
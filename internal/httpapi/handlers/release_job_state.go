@@ -0,0 +1,245 @@
+// Package handlers 提供发布包后台任务的快照、持久化记录和进度推导。
+
+package handlers
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/release"
+)
+
+// snapshot 会生成当前任务的安全快照，供 HTTP 接口直接返回。
+func (j *releaseJob) snapshot() transport.ReleaseJobResponse {
+	j.mu.RLock()
+	response := transport.ReleaseJobResponse{
+		OK:          true,
+		JobID:       j.id,
+		Status:      j.status,
+		Steps:       j.stepsLocked(),
+		InfoKind:    j.infoKind,
+		Info:        j.info,
+		MediaInfo:   j.mediainfoSummary,
+		BDInfo:      j.bdinfoReport,
+		LinkItems:   append([]transport.ImageLinkItem(nil), j.linkItems...),
+		InfoHash:    j.metaInfo.InfoHash,
+		InfoHashV2:  j.metaInfo.InfoHashV2,
+		Template:    j.template,
+		Description: j.description,
+		Error:       j.errMessage,
+		Progress:    j.progressLocked(),
+	}
+	if j.status == jobStatusSucceeded {
+		response.Files = listReleaseFiles(j.id, j.outputDir)
+	}
+	logger := j.logger
+	j.mu.RUnlock()
+
+	if logger != nil {
+		response.Logs = logger.String()
+		response.LogEntries = logger.Entries()
+	}
+	if response.Status == jobStatusPending {
+		response.QueuePosition = jobQueuePosition(response.JobID)
+	}
+	applyQueueProgress(response.Progress, response.QueuePosition)
+	return response
+}
+
+// record 会生成当前任务的持久化快照。
+func (j *releaseJob) record() jobstore.Record {
+	record := j.describe()
+	record.LogEntries = recordLogEntries(j.logger.Entries())
+	return record
+}
+
+// describe 会生成不含日志的任务记录，供任务列表和持久化共用；OutputPath 保存发布包目录。
+func (j *releaseJob) describe() jobstore.Record {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	record := j.recordLocked(jobstore.TypeRelease)
+	record.Kind = j.infoKind
+	record.InputPath = j.input.source
+	record.Options = releaseJobRecordOptions(j)
+	record.Output = j.info
+	record.BDInfo = j.bdinfoReport
+	record.MediaInfo = j.mediainfoSummary
+	record.LinkItems = recordImageLinks(j.linkItems)
+	record.OutputPath = j.outputDir
+	record.Filename = j.torrentFile
+	record.Steps = recordReleaseSteps(j.steps)
+	if j.status == jobStatusSucceeded {
+		record.DownloadURL = releaseFileURL(j.id, releaseDescriptionFile)
+	}
+	record.Progress = recordProgress(j.progressLocked())
+	return record
+}
+
+// persist 会把当前任务快照写入任务仓库。
+func (j *releaseJob) persist() {
+	saveJobRecord(j.record())
+}
+
+// stepsLocked 会复制步骤列表，并为运行中的步骤附上当前进度。调用方需持有锁。
+func (j *releaseJob) stepsLocked() []transport.ReleaseStep {
+	steps := append([]transport.ReleaseStep(nil), j.steps...)
+	for index := range steps {
+		if steps[index].Status == jobStatusRunning {
+			steps[index].Progress = j.stepProgressLocked(steps[index].Name)
+		}
+	}
+	return steps
+}
+
+// currentStepLocked 返回正在运行或最后一个已开始的步骤序号；尚未开始时返回 -1。调用方需持有锁。
+func (j *releaseJob) currentStepLocked() int {
+	current := -1
+	for index, step := range j.steps {
+		if step.Status != jobStatusPending && step.Status != releaseStepSkipped {
+			current = index
+		}
+	}
+	return current
+}
+
+// stepProgressLocked 会复用信息、截图和制种任务的进度估算，推导单个步骤内部的进度。调用方需持有锁。
+func (j *releaseJob) stepProgressLocked(name string) *transport.TaskProgress {
+	switch name {
+	case releaseStepInfo:
+		if j.infoKind == infoKindBDInfo {
+			return estimateBDInfoRunningProgress(&j.infoProgress)
+		}
+		return progressSnapshot(50, "读取媒体信息", "正在运行 MediaInfo。", 0, 0, true)
+	case releaseStepScreenshots:
+		return estimateScreenshotTaskRunningProgress(j.screenshotMode(), j.screenshot.Count, &j.screenshotProgress)
+	case releaseStepTorrent:
+		if j.torrentProgress != nil {
+			return cloneTaskProgress(j.torrentProgress)
+		}
+		return progressSnapshot(1, "准备", "正在准备制作种子。", 0, 0, true)
+	default:
+		return progressSnapshot(50, "打包", "正在写入发布描述和产物。", 0, 0, true)
+	}
+}
+
+// progressLocked 会把当前步骤序号和步骤内进度合成整体进度；重启恢复的任务直接沿用持久化进度。调用方需持有锁。
+func (j *releaseJob) progressLocked() *transport.TaskProgress {
+	if j.restoredProgress != nil {
+		return cloneTaskProgress(j.restoredProgress)
+	}
+
+	var running *transport.TaskProgress
+	if index := j.currentStepLocked(); index >= 0 {
+		name := j.steps[index].Name
+		step := j.stepProgressLocked(name)
+		percent := (float64(index)*100 + progressPercent(step)) / float64(len(j.steps))
+		running = progressSnapshot(percent, releaseStepLabels[name], step.Detail, index+1, len(j.steps), step.Indeterminate)
+		running.ETAMS = step.ETAMS
+	}
+
+	switch j.status {
+	case jobStatusSucceeded:
+		return progressSnapshot(100, "已完成", "发布包已生成。", len(j.steps), len(j.steps), false)
+	case jobStatusFailed:
+		return finalizeProgress(running, "已失败", "任务执行失败。", false)
+	case jobStatusCanceled:
+		return finalizeProgress(running, "已取消", "任务已取消。", false)
+	case jobStatusInterrupted:
+		return finalizeProgress(running, "已中断", "服务重启，任务已中断。", false)
+	case jobStatusCanceling:
+		return progressSnapshot(maxFloat(progressPercent(running), 10), "正在停止", "任务取消中...", progressCurrent(running), progressTotal(running), true)
+	case jobStatusRunning:
+		if running == nil {
+			return progressSnapshot(0, "启动中", "正在初始化发布包任务。", 0, len(j.steps), true)
+		}
+		return running
+	default:
+		return progressSnapshot(0, "等待开始", "任务已提交，等待执行。", 0, 0, true)
+	}
+}
+
+// setStepStatus 会更新第 index 个步骤的状态，并把新状态推送到事件流。
+func (j *releaseJob) setStepStatus(index int, status string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.isFinishedLocked() {
+		return
+	}
+	j.steps[index].Status = status
+	j.updatedAt = time.Now()
+	j.events.publish(jobEventStep, j.steps[index])
+}
+
+// releaseDataLocked 会把已完成步骤的产出整理成发布描述模板数据。调用方需持有锁。
+func (j *releaseJob) releaseDataLocked() release.Data {
+	data := release.Data{Name: release.NameFromPath(j.input.name())}
+	if j.metaInfo.Name != "" {
+		data.Name = j.metaInfo.Name
+	}
+
+	switch j.infoKind {
+	case infoKindMediaInfo:
+		data.MediaInfo = j.mediainfoSummary
+		data.MediaInfoText = j.info
+	case infoKindBDInfo:
+		data.BDInfo = bdinfo.ExtractCodeBlock(j.info)
+		data.BDInfoReport = j.bdinfoReport
+	}
+	for _, item := range j.linkItems {
+		data.Images = append(data.Images, release.Image{
+			URL:          item.URL,
+			ThumbnailURL: item.ThumbnailURL,
+			Filename:     item.Filename,
+			Width:        item.Width,
+			Height:       item.Height,
+		})
+	}
+	if j.metaInfo.InfoHash != "" {
+		data.Torrent = &release.Torrent{
+			InfoHash:    j.metaInfo.InfoHash,
+			InfoHashV2:  j.metaInfo.InfoHashV2,
+			Name:        j.metaInfo.Name,
+			Size:        j.metaInfo.TotalSize,
+			DownloadURL: releaseFileURL(j.id, j.torrentFile),
+		}
+	}
+	return data
+}
+
+// listReleaseFiles 会列出发布包目录中的全部产物文件，按文件名排序。
+func listReleaseFiles(jobID, outputDir string) []transport.ReleaseFile {
+	if outputDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil
+	}
+
+	files := make([]transport.ReleaseFile, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		files = append(files, transport.ReleaseFile{
+			Name: entry.Name(),
+			URL:  releaseFileURL(jobID, entry.Name()),
+			Size: fileSize(filepath.Join(outputDir, entry.Name())),
+		})
+	}
+	sort.Slice(files, func(a, b int) bool { return files[a].Name < files[b].Name })
+	return files
+}
+
+// releaseFileURL 返回发布包中单个文件的下载地址。
+func releaseFileURL(jobID, name string) string {
+	return "/api/release-jobs/" + jobID + "/files/" + url.PathEscape(name)
+}
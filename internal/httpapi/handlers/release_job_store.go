@@ -0,0 +1,219 @@
+// Package handlers 提供发布包后台任务的存储与创建逻辑。
+
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/mediainfo"
+	"minfo/internal/screenshot"
+	"minfo/internal/torrent"
+)
+
+// 发布包任务按固定顺序执行以下步骤。
+const (
+	releaseStepInfo        = "info"
+	releaseStepScreenshots = "screenshots"
+	releaseStepTorrent     = "torrent"
+	releaseStepBundle      = "bundle"
+)
+
+// releaseStepSkipped 表示步骤因前面的步骤失败或任务取消而没有执行。
+const releaseStepSkipped = "skipped"
+
+// releaseStepNames 是发布包任务的步骤执行顺序。
+var releaseStepNames = []string{releaseStepInfo, releaseStepScreenshots, releaseStepTorrent, releaseStepBundle}
+
+// releaseStepLabels 是各步骤在进度和日志中显示的名称。
+var releaseStepLabels = map[string]string{
+	releaseStepInfo:        "媒体信息",
+	releaseStepScreenshots: "截图",
+	releaseStepTorrent:     "制种",
+	releaseStepBundle:      "打包",
+}
+
+// releaseJob 会对同一个输入依次生成媒体信息、截图（可选上传图床）和种子，并把描述、截图压缩包和种子整理为发布包。
+type releaseJob struct {
+	jobBase
+	// input 是提交时的输入，原始路径作为记录的 InputPath 持久化；inputPath 是执行时才解析出、各步骤共用的实际路径。
+	input          formInput
+	inputPath      string
+	infoKind       string
+	bdinfoMode     string
+	mediainfoMode  string
+	cache          infoCacheRequest
+	screenshot     screenshotRunOptions
+	upload         bool
+	proxyURL       string
+	imageHost      string
+	torrentOptions torrent.Options
+
+	steps            []transport.ReleaseStep
+	info             string
+	bdinfoReport     *bdinfo.Report
+	mediainfoSummary *mediainfo.Summary
+	linkItems        []transport.ImageLinkItem
+	metaInfo         torrent.MetaInfo
+	// outputDir 保存发布包全部产物；torrentFile 是其中种子文件的文件名。
+	outputDir   string
+	torrentFile string
+
+	// 以下字段保存当前步骤收到的结构化进度；restoredProgress 保存重启前持久化的最终进度。
+	infoProgress       bdinfoProgressState
+	screenshotProgress screenshotProgressState
+	torrentProgress    *transport.TaskProgress
+	restoredProgress   *transport.TaskProgress
+}
+
+// createReleaseJob 会创建一个新的发布包后台任务，并交给任务管理器排队执行。
+func createReleaseJob(request releaseRequest) (*releaseJob, error) {
+	job := &releaseJob{
+		input:          request.Input,
+		infoKind:       request.InfoKind,
+		bdinfoMode:     request.BDInfoMode,
+		cache:          request.Cache,
+		screenshot:     request.Screenshot,
+		upload:         request.Upload,
+		proxyURL:       request.ProxyURL,
+		imageHost:      request.ImageHost,
+		torrentOptions: request.TorrentOptions,
+		steps:          newReleaseSteps(),
+	}
+	if job.infoKind == infoKindMediaInfo {
+		job.mediainfoMode = mediainfo.NormalizeMode(request.MediaInfoMode)
+	}
+	if err := initJobBase(&job.jobBase, jobClassRelease, request.Cleanup); err != nil {
+		return nil, err
+	}
+	job.onStatus = job.applyStatusLocked
+	job.template = request.Template
+	job.releaseData = job.releaseDataLocked
+
+	submitJob(job)
+	return job, nil
+}
+
+// restoreReleaseJob 会把持久化记录恢复为一个已结束的发布包任务，供重启后继续查询和下载产物。
+func restoreReleaseJob(record jobstore.Record) {
+	count, _ := strconv.Atoi(record.Options["count"])
+	upload, _ := strconv.ParseBool(record.Options["upload"])
+	job := &releaseJob{
+		input:         formInput{source: record.InputPath},
+		infoKind:      record.Kind,
+		bdinfoMode:    record.Options["bdinfo_mode"],
+		mediainfoMode: record.Options["mediainfo_mode"],
+		imageHost:     record.Options["host"],
+		screenshot: screenshotRunOptions{
+			Variant:      record.Options["variant"],
+			SubtitleMode: record.Options["subtitle_mode"],
+			HDRProcessor: record.Options["hdr_processor"],
+			Count:        count,
+			Preset:       record.Options["preset"],
+			Render:       renderOptionsFromRecord(record.Options),
+		},
+		upload:           upload,
+		torrentOptions:   torrentOptionsFromRecord(record.Options),
+		steps:            transportReleaseSteps(record.Steps),
+		info:             record.Output,
+		bdinfoReport:     record.BDInfo,
+		mediainfoSummary: record.MediaInfo,
+		linkItems:        transportImageLinks(record.LinkItems),
+		outputDir:        record.OutputPath,
+		torrentFile:      record.Filename,
+	}
+	if len(job.steps) != len(releaseStepNames) {
+		job.steps = newReleaseSteps()
+	}
+	restoreJobBase(&job.jobBase, jobClassRelease, record)
+	job.onStatus = job.applyStatusLocked
+	job.releaseData = job.releaseDataLocked
+	if job.status == jobStatusInterrupted {
+		job.applyStatusLocked(job.status)
+	}
+	if job.outputDir != "" && job.torrentFile != "" {
+		if meta, err := torrent.ReadMetaInfo(filepath.Join(job.outputDir, job.torrentFile)); err == nil {
+			job.metaInfo = meta
+		}
+	}
+	job.restoredProgress = restoreTaskProgress(transportProgress(record.Progress), job.status)
+	storeRestoredJob(job)
+}
+
+// getReleaseJob 返回指定任务；如果任务不存在、已过期或不是发布包任务，则返回 false。
+func getReleaseJob(jobID string) (*releaseJob, bool) {
+	job, ok := lookupJob(jobID)
+	if !ok {
+		return nil, false
+	}
+	releaseJob, ok := job.(*releaseJob)
+	return releaseJob, ok
+}
+
+// newReleaseSteps 会按执行顺序生成全部处于 pending 的步骤。
+func newReleaseSteps() []transport.ReleaseStep {
+	steps := make([]transport.ReleaseStep, 0, len(releaseStepNames))
+	for _, name := range releaseStepNames {
+		steps = append(steps, transport.ReleaseStep{Name: name, Status: jobStatusPending})
+	}
+	return steps
+}
+
+// applyStatusLocked 会在任务结束于失败、取消或中断时同步各步骤状态：运行中的步骤记为同一状态，未开始的步骤记为 skipped。
+// 已完成步骤的结果会保留下来，但不再提供发布包下载和描述。调用方需持有写锁。
+func (j *releaseJob) applyStatusLocked(status string) {
+	switch status {
+	case jobStatusFailed, jobStatusCanceled, jobStatusInterrupted:
+		for index := range j.steps {
+			switch j.steps[index].Status {
+			case jobStatusRunning:
+				j.steps[index].Status = status
+				j.steps[index].Error = j.errMessage
+			case jobStatusPending:
+				j.steps[index].Status = releaseStepSkipped
+			}
+		}
+		j.description = ""
+	}
+}
+
+// discard 会在任务过期时删除发布包产物目录。
+func (j *releaseJob) discard() {
+	j.mu.RLock()
+	outputDir := j.outputDir
+	j.mu.RUnlock()
+	if outputDir != "" {
+		_ = os.RemoveAll(outputDir)
+	}
+}
+
+// releaseJobRecordOptions 会把发布包任务参数整理成持久化记录使用的键值对，制种参数沿用制种任务的键名。
+func releaseJobRecordOptions(j *releaseJob) map[string]string {
+	options := torrentRecordOptions(j.torrentOptions)
+	for key, value := range screenshotJobRecordOptions(j.screenshot.Variant, j.screenshot.SubtitleMode, j.screenshot.HDRProcessor, j.screenshot.Preset, j.screenshot.Render, j.screenshot.Count, nil) {
+		options[key] = value
+	}
+	options["upload"] = strconv.FormatBool(j.upload)
+	if j.imageHost != "" {
+		options["host"] = j.imageHost
+	}
+	if j.bdinfoMode != "" {
+		options["bdinfo_mode"] = j.bdinfoMode
+	}
+	if j.mediainfoMode != "" {
+		options["mediainfo_mode"] = j.mediainfoMode
+	}
+	return options
+}
+
+// screenshotMode 返回截图步骤对应的截图模式，用于复用截图任务的进度估算。
+func (j *releaseJob) screenshotMode() string {
+	if j.upload {
+		return screenshot.ModeLinks
+	}
+	return screenshot.ModeZip
+}
@@ -0,0 +1,101 @@
+// Package handlers 提供发布包后台任务的创建、取消、状态查询与产物下载接口。
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"minfo/internal/httpapi/transport"
+)
+
+// ReleaseJobsHandler 负责创建新的发布包后台任务，并立即返回任务 ID。
+func ReleaseJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if !transport.EnsurePost(w, r) {
+		return
+	}
+	if err := transport.ParseForm(w, r); err != nil {
+		writeReleaseJobError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer transport.CleanupMultipart(r)
+
+	request, err := parseReleaseFormRequest(r)
+	if err != nil {
+		writeReleaseJobError(w, transport.InputPathStatus(err), err.Error())
+		return
+	}
+
+	job, err := createReleaseJob(request)
+	if err != nil {
+		if request.Cleanup != nil {
+			request.Cleanup()
+		}
+		writeReleaseJobError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeReleaseJobResponse(w, http.StatusAccepted, job.snapshot())
+}
+
+// ReleaseJobHandler 返回发布包任务当前状态、处理取消请求，或通过 /files/{name} 下载发布包中的产物。
+func ReleaseJobHandler(w http.ResponseWriter, r *http.Request) {
+	jobID, name, isFile := parseReleaseJobPath(r)
+	if jobID == "" {
+		writeReleaseJobError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if isFile {
+		handleReleaseJobFile(w, r, jobID, name)
+		return
+	}
+
+	job, ok := getReleaseJob(jobID)
+	if !ok {
+		writeReleaseJobError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeReleaseJobResponse(w, http.StatusOK, job.snapshot())
+	case http.MethodDelete:
+		cancelJob(job)
+		writeReleaseJobResponse(w, http.StatusOK, job.snapshot())
+	default:
+		writeReleaseJobError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// parseReleaseJobPath 会把 /api/release-jobs/{id} 或 /api/release-jobs/{id}/files/{name} 拆成任务 ID 和文件名。
+func parseReleaseJobPath(r *http.Request) (string, string, bool) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/release-jobs/"), "/")
+	jobID, tail, hasTail := strings.Cut(rest, "/")
+	jobID = strings.TrimSpace(jobID)
+	if !hasTail {
+		return jobID, "", false
+	}
+
+	name, ok := strings.CutPrefix(tail, "files/")
+	if !ok || strings.Contains(name, "/") {
+		return "", "", false
+	}
+	return jobID, name, true
+}
+
+// writeReleaseJobResponse 会把发布包任务响应编码为 JSON，并显式关闭缓存。
+func writeReleaseJobResponse(w http.ResponseWriter, status int, payload transport.ReleaseJobResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// writeReleaseJobError 会输出统一格式的发布包任务错误响应。
+func writeReleaseJobError(w http.ResponseWriter, status int, message string) {
+	writeReleaseJobResponse(w, status, transport.ReleaseJobResponse{
+		OK:    false,
+		Error: message,
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+)
+
+// TestReleaseJobFailureMarksRemainingStepsSkipped 验证步骤失败后，当前步骤记为失败、后续步骤记为跳过，整体进度停在失败步骤。
+func TestReleaseJobFailureMarksRemainingStepsSkipped(t *testing.T) {
+	job := &releaseJob{infoKind: infoKindMediaInfo, steps: newReleaseSteps()}
+	if err := initJobBase(&job.jobBase, jobClassRelease, nil); err != nil {
+		t.Fatalf("initJobBase() error = %v", err)
+	}
+	job.onStatus = job.applyStatusLocked
+
+	job.beginRun()
+	job.setStepStatus(0, jobStatusRunning)
+	job.setStepStatus(0, jobStatusSucceeded)
+	job.setStepStatus(1, jobStatusRunning)
+	job.fail(errors.New("ffmpeg failed"))
+
+	snapshot := job.snapshot()
+	want := []string{jobStatusSucceeded, jobStatusFailed, releaseStepSkipped, releaseStepSkipped}
+	for index, step := range snapshot.Steps {
+		if step.Status != want[index] {
+			t.Fatalf("steps[%d] = %+v, want status %s", index, step, want[index])
+		}
+	}
+	if snapshot.Steps[1].Error != "ffmpeg failed" {
+		t.Fatalf("failed step error = %q, want job error", snapshot.Steps[1].Error)
+	}
+	if snapshot.Progress == nil || snapshot.Progress.Current != 2 || snapshot.Progress.Percent < 25 || snapshot.Progress.Percent >= 50 {
+		t.Fatalf("progress = %+v, want second of four steps", snapshot.Progress)
+	}
+	if len(snapshot.Files) != 0 {
+		t.Fatalf("files = %+v, want none for failed job", snapshot.Files)
+	}
+}
+
+// TestReleaseJobServesBundleFiles 验证恢复后的发布包任务会列出产物文件并可逐个下载，目录外的文件名会被拒绝。
+func TestReleaseJobServesBundleFiles(t *testing.T) {
+	outputDir := t.TempDir()
+	for name, content := range map[string]string{
+		releaseDescriptionFile: "[b]Example[/b]\n",
+		releaseScreenshotsFile: "zip",
+		"Example.torrent":      "d4:infod4:name7:Exampleee",
+	} {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	record := jobstore.Record{
+		ID:          "release-files",
+		Type:        jobstore.TypeRelease,
+		Kind:        infoKindMediaInfo,
+		Status:      jobStatusSucceeded,
+		OutputPath:  outputDir,
+		Filename:    "Example.torrent",
+		Steps:       []jobstore.ReleaseStep{{Name: releaseStepInfo, Status: jobStatusSucceeded}},
+		CreatedAt:   now,
+		CompletedAt: now,
+	}
+	restoreReleaseJob(record)
+	t.Cleanup(func() { removeTestJob(record.ID) })
+
+	recorder := httptest.NewRecorder()
+	ReleaseJobHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/release-jobs/release-files", nil))
+	var payload transport.ReleaseJobResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(payload.Files) != 3 || payload.Files[0].Name != "Example.torrent" || payload.Files[0].URL != "/api/release-jobs/release-files/files/Example.torrent" {
+		t.Fatalf("files = %+v, want three sorted bundle files", payload.Files)
+	}
+	if len(payload.Steps) != len(releaseStepNames) {
+		t.Fatalf("steps = %+v, want incomplete persisted steps replaced", payload.Steps)
+	}
+
+	recorder = httptest.NewRecorder()
+	ReleaseJobHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/release-jobs/release-files/files/description.txt", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "[b]Example[/b]\n" {
+		t.Fatalf("download = %d %q, want description file", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	ReleaseJobHandler(recorder, httptest.NewRequest(http.MethodGet, "/api/release-jobs/release-files/files/..", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d for invalid file name", recorder.Code, http.StatusNotFound)
+	}
+}
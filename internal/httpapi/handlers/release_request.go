@@ -0,0 +1,102 @@
+// Package handlers 提供发布包任务的表单解析与参数规范化。
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
+	"minfo/internal/torrent"
+)
+
+// releaseDefaultTemplate 是发布包未指定模板时用于生成描述文件的内置模板。
+const releaseDefaultTemplate = "bbcode"
+
+// releaseRequest 表示一次发布包表单请求解析后的完整运行参数。
+type releaseRequest struct {
+	Input         formInput
+	Cleanup       func()
+	InfoKind      string
+	BDInfoMode    string
+	MediaInfoMode string
+	Cache         infoCacheRequest
+	Screenshot    screenshotRunOptions
+	// Upload 表示截图后是否上传图床；关闭时发布包只包含截图压缩包，描述中没有图片链接。
+	Upload         bool
+	ProxyURL       string
+	ImageHost      string
+	TorrentOptions torrent.Options
+	Template       string
+}
+
+// parseReleaseFormRequest 会把发布包表单解析成统一的运行参数；输入路径在提交时只做校验，执行时解析一次并由各步骤共用。
+//
+// 制种需要根目录允许 torrent；开启上传时还需要允许 upload。
+func parseReleaseFormRequest(r *http.Request) (releaseRequest, error) {
+	template, err := parseReleaseTemplate(r)
+	if err != nil {
+		return releaseRequest{}, err
+	}
+	if template == "" {
+		template = releaseDefaultTemplate
+	}
+
+	infoKind := infoKindMediaInfo
+	if raw := strings.TrimSpace(r.FormValue("info_kind")); raw != "" {
+		if infoKind = normalizeInfoJobKind(raw); infoKind == "" {
+			return releaseRequest{}, errors.New("invalid info kind")
+		}
+	}
+
+	upload := true
+	if raw := strings.TrimSpace(r.FormValue("upload")); raw != "" {
+		upload = parseTorrentBool(raw)
+	}
+	proxyURL, err := normalizeProxyURL(r.FormValue("proxy_url"))
+	if err != nil {
+		return releaseRequest{}, err
+	}
+	preset, err := parseScreenshotPreset(r)
+	if err != nil {
+		return releaseRequest{}, err
+	}
+	screenshotOptions := normalizeScreenshotFormOptions(r, preset)
+	imageHost := ""
+	if upload {
+		if imageHost, err = normalizeImageHost(r.FormValue("host"), screenshotOptions.Variant); err != nil {
+			return releaseRequest{}, err
+		}
+	}
+	torrentOptions, err := parseTorrentOptions(r)
+	if err != nil {
+		return releaseRequest{}, err
+	}
+
+	if source := transport.FormPath(r); upload && source != "" {
+		if err := media.CheckInputPermission(source, media.PermissionUpload); err != nil {
+			return releaseRequest{}, err
+		}
+	}
+	input, cleanup, err := parseFormInput(r, media.PermissionTorrent)
+	if err != nil {
+		return releaseRequest{}, err
+	}
+
+	return releaseRequest{
+		Input:          input,
+		Cleanup:        cleanup,
+		InfoKind:       infoKind,
+		BDInfoMode:     r.FormValue("bdinfo_mode"),
+		MediaInfoMode:  r.FormValue("mediainfo_mode"),
+		Cache:          newInfoCacheRequest(r),
+		Screenshot:     screenshotOptions,
+		Upload:         upload,
+		ProxyURL:       proxyURL,
+		ImageHost:      imageHost,
+		TorrentOptions: torrentOptions,
+		Template:       template,
+	}, nil
+}
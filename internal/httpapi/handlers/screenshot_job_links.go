@@ -25,6 +25,7 @@ func (j *screenshotJob) appendLinkItem(item transport.ImageLinkItem) {
 
 	j.linkItems = append(j.linkItems, item)
 	j.updatedAt = time.Now()
+	j.events.publish(jobEventItem, item)
 }
 
 // buildTransportImageLinkItems 会把截图上传结果批量转换为 HTTP 响应结构。
@@ -56,3 +57,16 @@ func buildTransportImageLinkItem(item screenshot.UploadedImage) transport.ImageL
 		Height:       item.Height,
 	}
 }
+
+// buildTransportFailedImageItems 会把上传失败的截图转换为 HTTP 响应结构。
+func buildTransportFailedImageItems(items []screenshot.FailedUpload) []transport.FailedImageItem {
+	if len(items) == 0 {
+		return nil
+	}
+
+	result := make([]transport.FailedImageItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, transport.FailedImageItem{Filename: item.Filename, Error: item.Error})
+	}
+	return result
+}
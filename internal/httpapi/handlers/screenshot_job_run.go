@@ -5,45 +5,50 @@ package handlers
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
-	"minfo/internal/config"
+	"minfo/internal/httpapi/transport"
 	"minfo/internal/screenshot"
 )
 
-// run 会在后台执行具体截图任务，并在结束后更新任务状态和结果。
-func (j *screenshotJob) run() {
-	defer func() {
-		if j.cancel != nil {
-			j.cancel()
-		}
-		if j.cleanup != nil {
-			j.cleanup()
-		}
-		if j.logger != nil {
-			j.logger.Close()
-		}
-	}()
+// uploadScreenshots 是 retry-upload 使用的上传入口；测试可替换它以检查传入的上传参数。
+var uploadScreenshots = screenshot.UploadScreenshots
 
-	if !j.beginRun() {
-		if j.isCancellationRequested() {
-			j.finishCanceled()
-		}
+// uploadOptions 会返回任务的图床上传参数；首次上传和 retry-upload 使用同一组代理、图床和渲染参数。
+func (j *screenshotJob) uploadOptions() screenshot.UploadOptions {
+	return screenshot.UploadOptions{ProxyURL: j.proxyURL, Host: j.imageHost, Render: j.render}
+}
+
+// execute 会在调度器分配到槽位后解析输入路径并执行具体截图任务，并更新任务状态和结果；retry-upload 只读取保留的截图，不再解析输入。
+func (j *screenshotJob) execute(ctx context.Context) {
+	j.mu.RLock()
+	retryUpload := j.retryUpload
+	j.mu.RUnlock()
+	if retryUpload {
+		j.executeRetryUpload(ctx)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(j.taskContext, config.RequestTimeout)
-	defer cancel()
-
-	tempDir, err := createScreenshotTempDir("minfo-screenshot-job-*")
+	inputPath, release, err := j.input.resolve(ctx)
 	if err != nil {
 		j.fail(err)
 		return
 	}
-	defer os.RemoveAll(tempDir)
+	defer release()
+	j.inputPath = inputPath
 
 	switch j.mode {
 	case screenshot.ModeLinks:
-		uploadOptions := screenshot.UploadOptions{ProxyURL: j.proxyURL}
+		// 截图放在任务产物目录中，上传失败的截图会留在原处供 retry-upload 重新上传。
+		outputDir, err := createJobOutputDir("screenshots", "minfo-screenshot-job-*")
+		if err != nil {
+			j.fail(err)
+			return
+		}
+		uploadOptions := j.uploadOptions()
+		onProgress := j.progressHandler(j.progressState.apply)
 		onItem := func(item screenshot.UploadedImage) {
 			j.appendLinkItem(buildTransportImageLinkItem(item))
 		}
@@ -52,36 +57,47 @@ func (j *screenshotJob) run() {
 			result, err = screenshot.RunUploadAtTimestampsWithLiveEventsWithOptions(
 				ctx,
 				j.inputPath,
-				tempDir,
+				outputDir,
 				j.variant,
 				j.subtitleMode,
 				j.hdrProcessor,
 				j.timestamps,
 				uploadOptions,
 				j.logger.LogLine,
+				onProgress,
 				onItem,
 			)
 		} else {
 			result, err = screenshot.RunUploadWithLiveEventsWithOptions(
 				ctx,
 				j.inputPath,
-				tempDir,
+				outputDir,
 				j.variant,
 				j.subtitleMode,
 				j.hdrProcessor,
 				j.count,
 				uploadOptions,
 				j.logger.LogLine,
+				onProgress,
 				onItem,
 			)
 		}
+		j.keepFailedUploads(ctx, outputDir, result.Failed)
 		if err != nil {
 			j.fail(err)
+			j.restoreLossyFilesForRetry(result.LossyPNGFiles)
 			return
 		}
 		j.succeed(result.Output, "", buildTransportImageLinkItems(result.Items), result.LossyPNGFiles, result.LossyPNGIndexes)
 	default:
-		downloadURL, _, err := prepareScreenshotZipDownload(ctx, j.inputPath, tempDir, j.variant, j.subtitleMode, j.hdrProcessor, j.count, j.logger.LogLine)
+		tempDir, err := createScreenshotTempDir("minfo-screenshot-job-*")
+		if err != nil {
+			j.fail(err)
+			return
+		}
+		defer os.RemoveAll(tempDir)
+
+		downloadURL, _, err := prepareScreenshotZipDownload(ctx, j.inputPath, tempDir, j.variant, j.subtitleMode, j.hdrProcessor, j.count, j.render, j.logger.LogLine, j.progressHandler(j.progressState.apply))
 		if err != nil {
 			j.fail(err)
 			return
@@ -89,3 +105,117 @@ func (j *screenshotJob) run() {
 		j.succeed("", downloadURL, nil, nil, nil)
 	}
 }
+
+// keepFailedUploads 会在上传结束后只保留失败的截图文件并记录失败明细；任务被取消或没有失败截图时删除整个截图目录。
+func (j *screenshotJob) keepFailedUploads(ctx context.Context, outputDir string, failed []screenshot.FailedUpload) {
+	if ctx.Err() != nil || j.isCancellationRequested() || len(failed) == 0 {
+		_ = os.RemoveAll(outputDir)
+		return
+	}
+
+	items := buildTransportFailedImageItems(failed)
+	keep := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		keep[item.Filename] = struct{}{}
+	}
+	entries, _ := os.ReadDir(outputDir)
+	for _, entry := range entries {
+		if _, ok := keep[entry.Name()]; !ok {
+			_ = os.RemoveAll(filepath.Join(outputDir, entry.Name()))
+		}
+	}
+
+	j.setFailedUploads(items, outputDir)
+	j.logger.Logf("[screenshot] %d 张截图上传失败，已保留在服务端，可稍后重新上传。", len(items))
+}
+
+// restoreLossyFilesForRetry 会在全部上传失败、结果已被清空后补回有损 PNG 列表，供重新上传时计算标记。
+func (j *screenshotJob) restoreLossyFilesForRetry(pngLossyFiles []string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if len(j.failedItems) > 0 {
+		j.pngLossyFiles = append([]string(nil), pngLossyFiles...)
+	}
+}
+
+// executeRetryUpload 会把 keptDir 中上传失败的截图重新上传到原图床，不重新生成截图；
+// 新上传成功的链接与已有链接按文件名合并，仍失败的截图继续保留。只要有任意一张图片上传成功，任务即视为成功。
+func (j *screenshotJob) executeRetryUpload(ctx context.Context) {
+	j.mu.RLock()
+	keptDir := j.keptDir
+	failedItems := append([]transport.FailedImageItem(nil), j.failedItems...)
+	pngLossyFiles := append([]string(nil), j.pngLossyFiles...)
+	j.mu.RUnlock()
+
+	files := make([]string, 0, len(failedItems))
+	for _, item := range failedItems {
+		files = append(files, filepath.Join(keptDir, item.Filename))
+	}
+	j.logger.Logf("[screenshot] 重新上传 %d 张失败的截图。", len(files))
+
+	result, err := uploadScreenshots(
+		ctx,
+		screenshot.ScreenshotsResult{Files: files, LossyPNGFiles: pngLossyFiles},
+		j.uploadOptions(),
+		j.logger.LogLine,
+		j.progressHandler(j.progressState.apply),
+		func(item screenshot.UploadedImage) {
+			j.appendLinkItem(buildTransportImageLinkItem(item))
+		},
+	)
+	if ctx.Err() != nil || j.isCancellationRequested() {
+		j.fail(ctx.Err())
+		return
+	}
+	for _, item := range result.Items {
+		_ = os.Remove(filepath.Join(keptDir, item.Filename))
+	}
+	if err != nil && len(result.Failed) == 0 && len(result.Items) == 0 {
+		// 图床不可用或截图文件已丢失时保留原有失败明细。
+		j.fail(err)
+		return
+	}
+
+	j.setFailedUploads(buildTransportFailedImageItems(result.Failed), keptDir)
+
+	j.mu.RLock()
+	linkItems := append([]transport.ImageLinkItem(nil), j.linkItems...)
+	j.mu.RUnlock()
+	for _, item := range buildTransportImageLinkItems(result.Items) {
+		if !containsImageLinkItem(linkItems, item.URL) {
+			linkItems = append(linkItems, item)
+		}
+	}
+	if len(linkItems) == 0 {
+		j.fail(err)
+		return
+	}
+
+	sort.SliceStable(linkItems, func(a, b int) bool {
+		return linkItems[a].Filename < linkItems[b].Filename
+	})
+	lossySet := make(map[string]struct{}, len(pngLossyFiles))
+	for _, name := range pngLossyFiles {
+		lossySet[name] = struct{}{}
+	}
+	links := make([]string, 0, len(linkItems))
+	lossyIndexes := make([]int, 0)
+	for index, item := range linkItems {
+		links = append(links, item.URL)
+		if _, ok := lossySet[item.Filename]; ok {
+			lossyIndexes = append(lossyIndexes, index)
+		}
+	}
+	j.succeed(strings.Join(links, "\n"), "", linkItems, pngLossyFiles, lossyIndexes)
+}
+
+// containsImageLinkItem 会判断图片链接列表中是否已经包含指定直链。
+func containsImageLinkItem(items []transport.ImageLinkItem, url string) bool {
+	for _, item := range items {
+		if item.URL == url {
+			return true
+		}
+	}
+	return false
+}
@@ -1,20 +1,17 @@
 package handlers
 
 import (
-	"context"
-	"errors"
+	"os"
 	"time"
 
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/release"
 )
 
 // snapshot 会生成当前任务的安全快照，供 HTTP 接口直接返回。
 func (j *screenshotJob) snapshot() transport.ScreenshotJobResponse {
 	j.mu.RLock()
-	count := j.count
-	if len(j.timestamps) > 0 {
-		count = len(j.timestamps)
-	}
 	response := transport.ScreenshotJobResponse{
 		OK:              true,
 		JobID:           j.id,
@@ -23,171 +20,114 @@ func (j *screenshotJob) snapshot() transport.ScreenshotJobResponse {
 		Output:          j.output,
 		DownloadURL:     j.downloadURL,
 		LinkItems:       append([]transport.ImageLinkItem(nil), j.linkItems...),
+		FailedItems:     append([]transport.FailedImageItem(nil), j.failedItems...),
 		Error:           j.errMessage,
 		PNGLossyFiles:   append([]string(nil), j.pngLossyFiles...),
 		PNGLossyIndexes: append([]int(nil), j.pngLossyIndexes...),
+		Progress:        j.progressLocked(),
+		Template:        j.template,
+		Description:     j.description,
 	}
 	logger := j.logger
 	j.mu.RUnlock()
 
-	var entries []transport.LogEntry
 	if logger != nil {
 		response.Logs = logger.String()
-		entries = logger.Entries()
-		response.LogEntries = entries
+		response.LogEntries = logger.Entries()
+	}
+	if response.Status == jobStatusPending {
+		response.QueuePosition = jobQueuePosition(response.JobID)
 	}
-	response.Progress = buildScreenshotTaskProgress(response.Mode, response.Status, count, response.LogEntries)
+	applyQueueProgress(response.Progress, response.QueuePosition)
 	return response
 }
 
-// expired 会判断后台任务是否已经完成且超过保留时间。
-func (j *screenshotJob) expired(now time.Time) bool {
+// record 会生成当前任务的持久化快照。
+func (j *screenshotJob) record() jobstore.Record {
+	record := j.describe()
+	record.LogEntries = recordLogEntries(j.logger.Entries())
+	return record
+}
+
+// describe 会生成不含日志的任务记录，供任务列表和持久化共用。
+func (j *screenshotJob) describe() jobstore.Record {
 	j.mu.RLock()
 	defer j.mu.RUnlock()
 
-	if j.completedAt.IsZero() {
-		return false
+	record := j.recordLocked(jobstore.TypeScreenshot)
+	record.Mode = j.mode
+	record.InputPath = j.input.source
+	record.Options = screenshotJobRecordOptions(j.variant, j.subtitleMode, j.hdrProcessor, j.preset, j.render, j.count, j.timestamps)
+	if j.imageHost != "" {
+		record.Options["host"] = j.imageHost
 	}
-	return now.Sub(j.completedAt) > screenshotJobTTL
+	record.Output = j.output
+	record.DownloadURL = j.downloadURL
+	record.LinkItems = recordImageLinks(j.linkItems)
+	record.FailedItems = recordFailedImages(j.failedItems)
+	record.OutputPath = j.keptDir
+	record.PNGLossyFiles = append([]string(nil), j.pngLossyFiles...)
+	record.PNGLossyIndexes = append([]int(nil), j.pngLossyIndexes...)
+	record.Progress = recordProgress(j.progressLocked())
+	return record
 }
 
-// beginRun 会把任务从 pending 切换到 running；如果任务已被取消，则返回 false。
-func (j *screenshotJob) beginRun() bool {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	if j.status != screenshotJobStatusPending {
-		return false
+// progressLocked 会根据已收到的进度事件推导当前进度；重启恢复的任务直接沿用持久化进度。调用方需持有锁。
+func (j *screenshotJob) progressLocked() *transport.TaskProgress {
+	if j.restoredProgress != nil {
+		return cloneTaskProgress(j.restoredProgress)
 	}
-	if j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled) {
-		return false
+	count := j.count
+	if len(j.timestamps) > 0 {
+		count = len(j.timestamps)
 	}
-
-	j.status = screenshotJobStatusRunning
-	j.updatedAt = time.Now()
-	return true
+	return buildScreenshotTaskProgress(j.mode, j.status, count, &j.progressState)
 }
 
-// requestCancel 会请求取消当前任务，并立刻把状态推进到 canceling。
-func (j *screenshotJob) requestCancel() {
-	var cancel context.CancelFunc
-
-	j.mu.Lock()
-	switch j.status {
-	case screenshotJobStatusSucceeded, screenshotJobStatusFailed, screenshotJobStatusCanceled:
-		j.mu.Unlock()
-		return
-	case screenshotJobStatusCanceling:
-		j.mu.Unlock()
-		return
-	default:
-		j.cancelRequested = true
-		j.status = screenshotJobStatusCanceling
-		j.errMessage = "任务取消中。"
-		j.updatedAt = time.Now()
-		cancel = j.cancel
-		j.mu.Unlock()
-	}
-
-	if cancel != nil {
-		cancel()
-	}
+// persist 会把当前任务快照写入任务仓库。
+func (j *screenshotJob) persist() {
+	saveJobRecord(j.record())
 }
 
 // succeed 会记录后台任务成功产出的最终结果。
 func (j *screenshotJob) succeed(output, downloadURL string, linkItems []transport.ImageLinkItem, pngLossyFiles []string, pngLossyIndexes []int) {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	now := time.Now()
-	if j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled) {
-		j.status = screenshotJobStatusCanceled
-		j.output = ""
-		j.downloadURL = ""
-		j.linkItems = nil
-		j.pngLossyFiles = nil
-		j.pngLossyIndexes = nil
-		j.errMessage = "任务已取消。"
-		j.updatedAt = now
-		j.completedAt = now
-		return
-	}
-
-	j.status = screenshotJobStatusSucceeded
-	j.output = output
-	j.downloadURL = downloadURL
-	j.linkItems = append([]transport.ImageLinkItem(nil), linkItems...)
-	j.pngLossyFiles = append([]string(nil), pngLossyFiles...)
-	j.pngLossyIndexes = append([]int(nil), pngLossyIndexes...)
-	j.errMessage = ""
-	j.updatedAt = now
-	j.completedAt = now
+	j.jobBase.succeed(func() {
+		j.output = output
+		j.downloadURL = downloadURL
+		j.linkItems = append([]transport.ImageLinkItem(nil), linkItems...)
+		j.pngLossyFiles = append([]string(nil), pngLossyFiles...)
+		j.pngLossyIndexes = append([]int(nil), pngLossyIndexes...)
+	})
 }
 
-// fail 会记录后台任务失败原因，并把状态切换为 failed。
-func (j *screenshotJob) fail(err error) {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	now := time.Now()
-	if j.cancelRequested || isScreenshotJobCanceledError(err) || errors.Is(j.taskContext.Err(), context.Canceled) {
-		j.status = screenshotJobStatusCanceled
-		j.output = ""
-		j.downloadURL = ""
-		j.linkItems = nil
-		j.pngLossyFiles = nil
-		j.pngLossyIndexes = nil
-		j.errMessage = "任务已取消。"
-		j.updatedAt = now
-		j.completedAt = now
-		return
-	}
-
-	j.status = screenshotJobStatusFailed
-	j.output = ""
-	j.downloadURL = ""
-	j.linkItems = nil
-	j.pngLossyFiles = nil
-	j.pngLossyIndexes = nil
-	if err != nil {
-		j.errMessage = err.Error()
-	} else {
-		j.errMessage = "job failed"
+// setFailedUploads 会记录仍上传失败的截图和保存它们的目录；没有失败截图时删除保留目录。
+func (j *screenshotJob) setFailedUploads(items []transport.FailedImageItem, keptDir string) {
+	if len(items) == 0 {
+		if keptDir != "" {
+			_ = os.RemoveAll(keptDir)
+		}
+		keptDir = ""
 	}
-	j.updatedAt = now
-	j.completedAt = now
-}
 
-// finishCanceled 会把任务最终标记为 canceled，并记录完成时间。
-func (j *screenshotJob) finishCanceled() {
 	j.mu.Lock()
 	defer j.mu.Unlock()
 
-	if j.status == screenshotJobStatusSucceeded || j.status == screenshotJobStatusFailed || j.status == screenshotJobStatusCanceled {
-		return
-	}
-
-	now := time.Now()
-	j.status = screenshotJobStatusCanceled
-	j.output = ""
-	j.downloadURL = ""
-	j.linkItems = nil
-	j.pngLossyFiles = nil
-	j.pngLossyIndexes = nil
-	j.errMessage = "任务已取消。"
-	j.updatedAt = now
-	j.completedAt = now
-}
-
-// isCancellationRequested 会判断当前任务是否已经收到了取消请求。
-func (j *screenshotJob) isCancellationRequested() bool {
-	j.mu.RLock()
-	defer j.mu.RUnlock()
-
-	return j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled)
+	j.failedItems = append([]transport.FailedImageItem(nil), items...)
+	j.keptDir = keptDir
+	j.updatedAt = time.Now()
 }
 
-// isScreenshotJobCanceledError 会判断错误是否来自主动取消而不是普通失败。
-func isScreenshotJobCanceledError(err error) bool {
-	return errors.Is(err, context.Canceled)
+// releaseDataLocked 会把已上传的截图整理成发布描述模板数据；只输出文件的截图任务没有图片链接。调用方需持有锁。
+func (j *screenshotJob) releaseDataLocked() release.Data {
+	data := release.Data{Name: release.NameFromPath(j.input.name())}
+	for _, item := range j.linkItems {
+		data.Images = append(data.Images, release.Image{
+			URL:          item.URL,
+			ThumbnailURL: item.ThumbnailURL,
+			Filename:     item.Filename,
+			Width:        item.Width,
+			Height:       item.Height,
+		})
+	}
+	return data
 }
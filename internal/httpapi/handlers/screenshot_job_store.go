@@ -3,131 +3,219 @@
 package handlers
 
 import (
-	"context"
-	"crypto/rand"
-	"encoding/hex"
-	"sync"
-	"time"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
 
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/screenshot"
 )
 
-const (
-	screenshotJobStatusPending   = "pending"
-	screenshotJobStatusRunning   = "running"
-	screenshotJobStatusCanceling = "canceling"
-	screenshotJobStatusSucceeded = "succeeded"
-	screenshotJobStatusFailed    = "failed"
-	screenshotJobStatusCanceled  = "canceled"
-	screenshotJobTTL             = 30 * time.Minute
-)
-
-var screenshotJobs = struct {
-	mu    sync.Mutex
-	items map[string]*screenshotJob
-}{
-	items: make(map[string]*screenshotJob),
-}
-
 type screenshotJob struct {
-	mu              sync.RWMutex
-	id              string
-	mode            string
-	inputPath       string
-	variant         string
-	subtitleMode    string
-	hdrProcessor    string
-	count           int
+	jobBase
+	// input 是提交时的输入，原始路径作为记录的 InputPath 持久化；inputPath 是执行截图时才解析出的实际路径，不对外展示。
+	input     formInput
+	inputPath string
+
+	mode         string
+	variant      string
+	subtitleMode string
+	hdrProcessor string
+	count        int
+	// preset 是提交时选择的截图预设名，render 是预设对应的渲染参数；两者都会持久化，retry-upload 沿用同一组渲染参数。
+	preset          string
+	render          screenshot.RenderOptions
 	proxyURL        string
+	imageHost       string
 	timestamps      []string
-	status          string
 	output          string
 	downloadURL     string
 	linkItems       []transport.ImageLinkItem
 	pngLossyFiles   []string
 	pngLossyIndexes []int
-	errMessage      string
-	createdAt       time.Time
-	updatedAt       time.Time
-	completedAt     time.Time
-	logger          *infoLogger
-	cleanup         func()
-	taskContext     context.Context
-	cancel          context.CancelFunc
-
-	cancelRequested bool
+	// failedItems 是重试后仍上传失败的截图，keptDir 保存这些截图文件，供 retry-upload 只重新上传失败的部分。
+	failedItems []transport.FailedImageItem
+	keptDir     string
+	// retryUpload 表示任务已通过 retry-upload 重新排队，后续执行只上传 keptDir 中的失败截图。
+	retryUpload bool
+
+	// progressState 保存运行期间收到的结构化进度；restoredProgress 保存重启前持久化的最终进度。
+	progressState    screenshotProgressState
+	restoredProgress *transport.TaskProgress
 }
 
-// createScreenshotJob 会创建一个新的截图后台任务，并立即启动后台执行流程。
+// createScreenshotJob 会创建一个新的截图后台任务，并交给任务管理器排队执行。
 func createScreenshotJob(request screenshotRequest) (*screenshotJob, error) {
-	pruneScreenshotJobs(time.Now())
-
-	jobID, err := buildScreenshotJobID()
-	if err != nil {
-		return nil, err
-	}
-
-	taskContext, cancel := context.WithCancel(context.Background())
-	now := time.Now()
 	job := &screenshotJob{
-		id:           jobID,
 		mode:         request.Mode,
-		inputPath:    request.InputPath,
+		input:        request.Input,
 		variant:      request.Variant,
 		subtitleMode: request.SubtitleMode,
 		hdrProcessor: request.HDRProcessor,
 		count:        request.Count,
+		preset:       request.Preset,
+		render:       request.Render,
 		proxyURL:     request.ProxyURL,
+		imageHost:    request.ImageHost,
 		timestamps:   append([]string(nil), request.Timestamps...),
-		status:       screenshotJobStatusPending,
-		createdAt:    now,
-		updatedAt:    now,
-		logger:       newInfoLogger(),
-		cleanup:      request.Cleanup,
-		taskContext:  taskContext,
-		cancel:       cancel,
 	}
+	if err := initJobBase(&job.jobBase, jobClassScreenshot, request.Cleanup); err != nil {
+		return nil, err
+	}
+	job.onStatus = job.applyStatusLocked
+	job.template = request.Template
+	job.releaseData = job.releaseDataLocked
 
-	screenshotJobs.mu.Lock()
-	screenshotJobs.items[jobID] = job
-	screenshotJobs.mu.Unlock()
-
-	go job.run()
+	submitJob(job)
 	return job, nil
 }
 
-// getScreenshotJob 返回指定任务；如果任务不存在或已过期，则返回 false。
-func getScreenshotJob(jobID string) (*screenshotJob, bool) {
-	pruneScreenshotJobs(time.Now())
-
-	screenshotJobs.mu.Lock()
-	defer screenshotJobs.mu.Unlock()
+// restoreScreenshotJob 会把持久化记录恢复为一个已结束的截图任务，供重启后继续查询。
+func restoreScreenshotJob(record jobstore.Record) {
+	count, _ := strconv.Atoi(record.Options["count"])
+	job := &screenshotJob{
+		mode:            record.Mode,
+		input:           formInput{source: record.InputPath},
+		variant:         record.Options["variant"],
+		subtitleMode:    record.Options["subtitle_mode"],
+		hdrProcessor:    record.Options["hdr_processor"],
+		imageHost:       record.Options["host"],
+		count:           count,
+		preset:          record.Options["preset"],
+		render:          renderOptionsFromRecord(record.Options),
+		timestamps:      splitScreenshotTimestampList(record.Options["timestamps"]),
+		output:          record.Output,
+		downloadURL:     record.DownloadURL,
+		linkItems:       transportImageLinks(record.LinkItems),
+		pngLossyFiles:   append([]string(nil), record.PNGLossyFiles...),
+		pngLossyIndexes: append([]int(nil), record.PNGLossyIndexes...),
+		failedItems:     transportFailedImages(record.FailedItems),
+		keptDir:         record.OutputPath,
+	}
+	restoreJobBase(&job.jobBase, jobClassScreenshot, record)
+	job.onStatus = job.applyStatusLocked
+	job.releaseData = job.releaseDataLocked
+	job.restoredProgress = restoreTaskProgress(transportProgress(record.Progress), job.status)
+	storeRestoredJob(job)
+}
 
-	job, ok := screenshotJobs.items[jobID]
+// getScreenshotJob 返回指定任务；如果任务不存在、已过期或不是截图任务，则返回 false。
+func getScreenshotJob(jobID string) (*screenshotJob, bool) {
+	job, ok := lookupJob(jobID)
 	if !ok {
 		return nil, false
 	}
-	return job, true
+	screenshotJob, ok := job.(*screenshotJob)
+	return screenshotJob, ok
 }
 
-// pruneScreenshotJobs 会删除已完成且超过保留时间的后台任务记录。
-func pruneScreenshotJobs(now time.Time) {
-	screenshotJobs.mu.Lock()
-	defer screenshotJobs.mu.Unlock()
+// beginRetryUpload 会把已结束且保留了失败截图的图床任务重新置为 pending，下一次执行只重新上传失败的截图。
+func (j *screenshotJob) beginRetryUpload() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch {
+	case !j.isFinishedLocked():
+		return errors.New("任务尚未结束，暂不能重新上传。")
+	case j.mode != screenshot.ModeLinks:
+		return errors.New("只有图床链接模式的截图任务可以重新上传。")
+	case len(j.failedItems) == 0 || j.keptDir == "":
+		return errors.New("任务没有需要重新上传的截图。")
+	}
+	if _, err := os.Stat(j.keptDir); err != nil {
+		return errors.New("失败的截图文件已不存在，请重新创建截图任务。")
+	}
 
-	for jobID, job := range screenshotJobs.items {
-		if !job.expired(now) {
-			continue
+	j.retryUpload = true
+	j.progressState = screenshotProgressState{}
+	j.restoredProgress = nil
+	j.reopenLocked()
+	return nil
+}
+
+// applyStatusLocked 会在任务失败或取消时清空已有结果；重新上传失败截图时保留上一轮已上传的结果。调用方需持有写锁。
+func (j *screenshotJob) applyStatusLocked(status string) {
+	if j.retryUpload {
+		return
+	}
+	switch status {
+	case jobStatusFailed, jobStatusCanceled:
+		j.output = ""
+		j.downloadURL = ""
+		j.linkItems = nil
+		j.pngLossyFiles = nil
+		j.pngLossyIndexes = nil
+		j.description = ""
+	}
+}
+
+// discard 会在任务过期时删除保留的失败截图；其余截图产物由下载缓存自行过期清理。
+func (j *screenshotJob) discard() {
+	j.mu.RLock()
+	keptDir := j.keptDir
+	j.mu.RUnlock()
+
+	if keptDir != "" {
+		_ = os.RemoveAll(keptDir)
+	}
+}
+
+// screenshotJobRecordOptions 会把截图任务参数整理成持久化记录使用的键值对。
+func screenshotJobRecordOptions(variant, subtitleMode, hdrProcessor, preset string, render screenshot.RenderOptions, count int, timestamps []string) map[string]string {
+	options := map[string]string{
+		"variant":       variant,
+		"subtitle_mode": subtitleMode,
+		"hdr_processor": hdrProcessor,
+		"count":         strconv.Itoa(count),
+	}
+	if preset != "" {
+		options["preset"] = preset
+	}
+	if len(timestamps) > 0 {
+		options["timestamps"] = strings.Join(timestamps, ",")
+	}
+	for key, value := range renderRecordOptions(render) {
+		options[key] = value
+	}
+	return options
+}
+
+// renderRecordOptions 会把渲染参数中的非零字段整理成持久化记录使用的键值对。
+func renderRecordOptions(render screenshot.RenderOptions) map[string]string {
+	options := make(map[string]string)
+	setInt := func(key string, value int64) {
+		if value > 0 {
+			options[key] = strconv.FormatInt(value, 10)
 		}
-		delete(screenshotJobs.items, jobID)
 	}
+	setInt("max_width", int64(render.MaxWidth))
+	setInt("max_height", int64(render.MaxHeight))
+	setInt("jpg_qscale", int64(render.JPGQScale))
+	setInt("webp_quality", int64(render.WebPQuality))
+	setInt("avif_crf", int64(render.AVIFCRF))
+	setInt("max_bytes", render.MaxBytes)
+	if render.Scaler != "" {
+		options["scaler"] = render.Scaler
+	}
+	return options
 }
 
-// buildScreenshotJobID 生成适合 URL 使用的随机任务 ID。
-func buildScreenshotJobID() (string, error) {
-	buf := make([]byte, 16)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
+// renderOptionsFromRecord 会从持久化记录的键值对中还原渲染参数；缺失或无效的字段保持零值。
+func renderOptionsFromRecord(options map[string]string) screenshot.RenderOptions {
+	atoi := func(key string) int {
+		value, _ := strconv.Atoi(options[key])
+		return value
+	}
+	maxBytes, _ := strconv.ParseInt(options["max_bytes"], 10, 64)
+	return screenshot.RenderOptions{
+		MaxWidth:    atoi("max_width"),
+		MaxHeight:   atoi("max_height"),
+		Scaler:      options["scaler"],
+		JPGQScale:   atoi("jpg_qscale"),
+		WebPQuality: atoi("webp_quality"),
+		AVIFCRF:     atoi("avif_crf"),
+		MaxBytes:    maxBytes,
 	}
-	return hex.EncodeToString(buf), nil
 }
@@ -23,7 +23,7 @@ func ScreenshotJobsHandler(w http.ResponseWriter, r *http.Request) {
 
 	request, err := parseScreenshotFormRequest(r)
 	if err != nil {
-		writeScreenshotJobError(w, http.StatusBadRequest, err.Error())
+		writeScreenshotJobError(w, transport.InputPathStatus(err), err.Error())
 		return
 	}
 
@@ -39,9 +39,11 @@ func ScreenshotJobsHandler(w http.ResponseWriter, r *http.Request) {
 	writeScreenshotJobResponse(w, http.StatusAccepted, job.snapshot())
 }
 
-// ScreenshotJobHandler 返回截图后台任务当前状态，或处理对已有任务的取消请求。
+// ScreenshotJobHandler 返回截图后台任务当前状态，或处理对已有任务的取消和重新上传请求。
 func ScreenshotJobHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case http.MethodPost:
+		handleScreenshotJobRetryUpload(w, r)
 	case http.MethodGet:
 		handleScreenshotJobGet(w, r)
 	case http.MethodDelete:
@@ -82,10 +84,33 @@ func handleScreenshotJobDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job.requestCancel()
+	cancelJob(job)
 	writeScreenshotJobResponse(w, http.StatusOK, job.snapshot())
 }
 
+// handleScreenshotJobRetryUpload 处理 POST /api/screenshot-jobs/{id}/retry-upload：
+// 把上一轮上传失败并保留在服务端的截图重新排队上传，不重新生成截图。
+func handleScreenshotJobRetryUpload(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := strings.CutSuffix(parseScreenshotJobID(r), "/retry-upload")
+	if !ok || jobID == "" || strings.Contains(jobID, "/") {
+		writeScreenshotJobError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	job, ok := getScreenshotJob(jobID)
+	if !ok {
+		writeScreenshotJobError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if err := job.beginRetryUpload(); err != nil {
+		writeScreenshotJobError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	submitJob(job)
+	writeScreenshotJobResponse(w, http.StatusAccepted, job.snapshot())
+}
+
 // parseScreenshotJobID 会从请求路径中提取截图后台任务 ID。
 func parseScreenshotJobID(r *http.Request) string {
 	jobID := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/api/screenshot-jobs/"))
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/screenshot"
+)
+
+// TestScreenshotJobRetryUploadReuploadsOnlyFailedImages 验证 retry-upload 只重新上传保留的失败截图，并与已有链接按文件名合并。
+func TestScreenshotJobRetryUploadReuploadsOnlyFailedImages(t *testing.T) {
+	var uploads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+		}
+		for _, header := range r.MultipartForm.File["source"] {
+			uploads = append(uploads, header.Filename)
+		}
+		_, _ = w.Write([]byte(`{"image":{"url":"https://img.example/02.png","thumb":{"url":"https://img.example/02.th.png"}}}`))
+	}))
+	defer server.Close()
+	t.Setenv("CHEVERETO_URL", server.URL)
+	t.Setenv("CHEVERETO_API_KEY", "key")
+
+	keptDir := t.TempDir()
+	file, err := os.Create(filepath.Join(keptDir, "shot-02.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	now := time.Now()
+	record := jobstore.Record{
+		ID:          "screenshot-retry-upload",
+		Type:        jobstore.TypeScreenshot,
+		Mode:        "links",
+		Options:     map[string]string{"host": "chevereto", "count": "2", "preset": "tracker", "max_width": "1280", "scaler": "lanczos", "max_bytes": "5000000"},
+		Status:      jobStatusSucceeded,
+		Output:      "https://img.example/01.png",
+		OutputPath:  keptDir,
+		LinkItems:   []jobstore.ImageLink{{URL: "https://img.example/01.png", Filename: "shot-01.png"}},
+		FailedItems: []jobstore.FailedImage{{Filename: "shot-02.png", Error: "chevereto returned HTTP 503"}},
+		CreatedAt:   now,
+		CompletedAt: now,
+	}
+	restoreScreenshotJob(record)
+	t.Cleanup(func() { removeTestJob(record.ID) })
+
+	var uploadOptions screenshot.UploadOptions
+	previous := uploadScreenshots
+	uploadScreenshots = func(ctx context.Context, result screenshot.ScreenshotsResult, options screenshot.UploadOptions, onLog screenshot.LogHandler, onProgress screenshot.ProgressHandler, onItem screenshot.UploadItemHandler) (screenshot.UploadResult, error) {
+		uploadOptions = options
+		return previous(ctx, result, options, onLog, onProgress, onItem)
+	}
+	t.Cleanup(func() { uploadScreenshots = previous })
+
+	recorder := httptest.NewRecorder()
+	ScreenshotJobHandler(recorder, httptest.NewRequest(http.MethodPost, "/api/screenshot-jobs/"+record.ID+"/retry-upload", nil))
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("status = %d body = %s, want 202", recorder.Code, recorder.Body.String())
+	}
+
+	job, _ := getScreenshotJob(record.ID)
+	deadline := time.Now().Add(5 * time.Second)
+	snapshot := job.snapshot()
+	for snapshot.Status != jobStatusSucceeded && snapshot.Status != jobStatusFailed && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		snapshot = job.snapshot()
+	}
+	if snapshot.Status != jobStatusSucceeded {
+		t.Fatalf("snapshot = %+v, want succeeded", snapshot)
+	}
+	wantRender := screenshot.RenderOptions{MaxWidth: 1280, Scaler: "lanczos", MaxBytes: 5000000}
+	if uploadOptions.Host != "chevereto" || uploadOptions.Render != wantRender {
+		t.Fatalf("upload options = %+v, want chevereto with the job's render options %+v", uploadOptions, wantRender)
+	}
+	if len(uploads) != 1 || uploads[0] != "shot-02.png" {
+		t.Fatalf("uploads = %v, want only the failed screenshot", uploads)
+	}
+	if len(snapshot.LinkItems) != 2 || snapshot.LinkItems[1].Filename != "shot-02.png" || len(snapshot.FailedItems) != 0 {
+		t.Fatalf("link items = %+v, failed = %+v, want merged links and no failures", snapshot.LinkItems, snapshot.FailedItems)
+	}
+	if snapshot.Output != "https://img.example/01.png\nhttps://img.example/02.png" {
+		t.Fatalf("output = %q, want both links", snapshot.Output)
+	}
+	if _, err := os.Stat(keptDir); !os.IsNotExist(err) {
+		t.Fatalf("kept dir stat error = %v, want removed", err)
+	}
+
+	recorder = httptest.NewRecorder()
+	ScreenshotJobHandler(recorder, httptest.NewRequest(http.MethodPost, "/api/screenshot-jobs/"+record.ID+"/retry-upload", nil))
+	var payload transport.ScreenshotJobResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if recorder.Code != http.StatusConflict || payload.OK {
+		t.Fatalf("second retry = %d %+v, want 409", recorder.Code, payload)
+	}
+}
@@ -0,0 +1,52 @@
+// Package handlers 提供截图预设的列表接口和表单预设参数解析。
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"minfo/internal/config"
+	"minfo/internal/httpapi/transport"
+	"minfo/internal/screenshot"
+)
+
+// parseScreenshotPreset 会读取表单中的 preset 参数并加载对应的截图预设；未填写时返回零值预设，其数量上限即默认上限。
+func parseScreenshotPreset(r *http.Request) (screenshot.Preset, error) {
+	name := screenshot.NormalizePresetName(r.FormValue("preset"))
+	if name == "" {
+		return screenshot.Preset{}, nil
+	}
+	preset, err := screenshot.LoadPreset(config.PresetDir, name)
+	if err != nil {
+		if errors.Is(err, screenshot.ErrPresetNotFound) {
+			return screenshot.Preset{}, errors.New("screenshot preset not found: " + name)
+		}
+		return screenshot.Preset{}, err
+	}
+	return preset, nil
+}
+
+// ScreenshotPresetsHandler 会返回预设目录中全部可用的截图预设。
+func ScreenshotPresetsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeScreenshotPresetListResponse(w, http.StatusMethodNotAllowed, transport.ScreenshotPresetListResponse{Error: "method not allowed"})
+		return
+	}
+
+	presets, err := screenshot.ListPresets(config.PresetDir)
+	if err != nil {
+		writeScreenshotPresetListResponse(w, http.StatusInternalServerError, transport.ScreenshotPresetListResponse{Error: err.Error()})
+		return
+	}
+	writeScreenshotPresetListResponse(w, http.StatusOK, transport.ScreenshotPresetListResponse{OK: true, Presets: presets})
+}
+
+// writeScreenshotPresetListResponse 会把截图预设列表响应编码为 JSON，并显式关闭缓存。
+func writeScreenshotPresetListResponse(w http.ResponseWriter, status int, payload transport.ScreenshotPresetListResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
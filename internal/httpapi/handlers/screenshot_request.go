@@ -9,7 +9,7 @@ import (
 	"os"
 	"strings"
 
-	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
 	"minfo/internal/screenshot"
 	screenshottimestamps "minfo/internal/screenshot/timestamps"
 )
@@ -17,14 +17,21 @@ import (
 // screenshotRequest 表示一次截图表单请求解析后的完整运行参数。
 type screenshotRequest struct {
 	Mode         string
-	InputPath    string
+	Input        formInput
 	Cleanup      func()
 	Variant      string
 	SubtitleMode string
 	HDRProcessor string
 	Count        int
 	ProxyURL     string
-	Timestamps   []string
+	// ImageHost 是链接模式上传使用的图床名称，已确认可用。
+	ImageHost  string
+	Timestamps []string
+	// Template 是后台任务成功后用于渲染发布描述的模板名，为空时不渲染。
+	Template string
+	// Preset 是选择的截图预设名，Render 是预设对应的渲染参数；未选择预设时均为零值。
+	Preset string
+	Render screenshot.RenderOptions
 }
 
 // screenshotRunOptions 表示截图流程真正执行时需要的规格化选项。
@@ -33,11 +40,23 @@ type screenshotRunOptions struct {
 	SubtitleMode string
 	HDRProcessor string
 	Count        int
+	// Preset 是选择的截图预设名，Render 是预设对应的渲染参数；未选择预设时均为零值。
+	Preset string
+	Render screenshot.RenderOptions
 }
 
 // parseScreenshotFormRequest 会把 multipart/form-data 请求解析成统一的截图运行参数。
 func parseScreenshotFormRequest(r *http.Request) (screenshotRequest, error) {
-	inputPath, cleanup, err := transport.InputPath(r)
+	mode := screenshot.NormalizeMode(r.FormValue("mode"))
+	template, err := parseReleaseTemplate(r)
+	if err != nil {
+		return screenshotRequest{}, err
+	}
+	permission := media.PermissionRead
+	if mode == screenshot.ModeLinks {
+		permission = media.PermissionUpload
+	}
+	input, cleanup, err := parseFormInput(r, permission)
 	if err != nil {
 		return screenshotRequest{}, err
 	}
@@ -48,8 +67,22 @@ func parseScreenshotFormRequest(r *http.Request) (screenshotRequest, error) {
 		return screenshotRequest{}, err
 	}
 
-	options := normalizeScreenshotFormOptions(r)
-	timestamps, err := normalizeScreenshotFormTimestamps(r)
+	preset, err := parseScreenshotPreset(r)
+	if err != nil {
+		cleanup()
+		return screenshotRequest{}, err
+	}
+	options := normalizeScreenshotFormOptions(r, preset)
+
+	imageHost := ""
+	if mode == screenshot.ModeLinks {
+		if imageHost, err = normalizeImageHost(r.FormValue("host"), options.Variant); err != nil {
+			cleanup()
+			return screenshotRequest{}, err
+		}
+	}
+
+	timestamps, err := normalizeScreenshotFormTimestamps(r, preset.CountLimit())
 	if err != nil {
 		cleanup()
 		return screenshotRequest{}, err
@@ -59,25 +92,31 @@ func parseScreenshotFormRequest(r *http.Request) (screenshotRequest, error) {
 	}
 
 	return screenshotRequest{
-		Mode:         screenshot.NormalizeMode(r.FormValue("mode")),
-		InputPath:    inputPath,
+		Mode:         mode,
+		Input:        input,
 		Cleanup:      cleanup,
 		Variant:      options.Variant,
 		SubtitleMode: options.SubtitleMode,
 		HDRProcessor: options.HDRProcessor,
 		Count:        options.Count,
 		ProxyURL:     proxyURL,
+		ImageHost:    imageHost,
 		Timestamps:   timestamps,
+		Template:     template,
+		Preset:       options.Preset,
+		Render:       options.Render,
 	}, nil
 }
 
-// normalizeScreenshotFormOptions 会从表单请求中提取并规范化截图运行选项。
-func normalizeScreenshotFormOptions(r *http.Request) screenshotRunOptions {
+// normalizeScreenshotFormOptions 会从表单请求中提取并规范化截图运行选项；预设设置的数量和格式优先于表单字段。
+func normalizeScreenshotFormOptions(r *http.Request, preset screenshot.Preset) screenshotRunOptions {
 	return screenshotRunOptions{
-		Variant:      screenshot.NormalizeVariant(r.FormValue("variant")),
+		Variant:      preset.ResolveVariant(r.FormValue("variant")),
 		SubtitleMode: screenshot.NormalizeSubtitleMode(r.FormValue("subtitle_mode")),
 		HDRProcessor: screenshot.NormalizeHDRProcessor(r.FormValue("hdr_processor")),
-		Count:        screenshot.NormalizeCount(r.FormValue("count")),
+		Count:        preset.ResolveCount(r.FormValue("count")),
+		Preset:       preset.Name,
+		Render:       preset.RenderOptions(),
 	}
 }
 
@@ -91,8 +130,8 @@ func normalizeScreenshotQueryOptions(r *http.Request) screenshotRunOptions {
 	}
 }
 
-// normalizeScreenshotFormTimestamps 会提取可选的指定截图时间点。
-func normalizeScreenshotFormTimestamps(r *http.Request) ([]string, error) {
+// normalizeScreenshotFormTimestamps 会提取可选的指定截图时间点，数量不能超过 limit。
+func normalizeScreenshotFormTimestamps(r *http.Request, limit int) ([]string, error) {
 	values := make([]string, 0)
 	if r != nil && r.Form != nil {
 		values = append(values, r.Form["timestamp"]...)
@@ -112,8 +151,8 @@ func normalizeScreenshotFormTimestamps(r *http.Request) ([]string, error) {
 	if len(result) == 0 {
 		return nil, nil
 	}
-	if len(result) > 10 {
-		return nil, fmt.Errorf("截图时间点数量不能超过 10 个")
+	if len(result) > limit {
+		return nil, fmt.Errorf("截图时间点数量不能超过 %d 个", limit)
 	}
 	if _, err := screenshottimestamps.ParseRequestedTimestamps(result); err != nil {
 		return nil, fmt.Errorf("截图时间点无效: %w", err)
@@ -132,6 +171,15 @@ func createScreenshotTempDir(pattern string) (string, error) {
 	return os.MkdirTemp("", pattern)
 }
 
+// normalizeImageHost 校验前端选择的图床；空值使用服务端默认图床，未知、缺少配置或不接受所选截图格式的图床返回错误。
+func normalizeImageHost(value, variant string) (string, error) {
+	name := screenshot.NormalizeImageHost(value)
+	if _, err := screenshot.LookupImageHostForVariant(name, variant); err != nil {
+		return "", fmt.Errorf("图床不可用: %w", err)
+	}
+	return name, nil
+}
+
 // normalizeProxyURL 校验前端传入的图床代理地址；空值表示使用服务端默认代理环境。
 func normalizeProxyURL(value string) (string, error) {
 	trimmed := strings.TrimSpace(value)
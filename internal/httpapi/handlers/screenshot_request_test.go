@@ -3,7 +3,12 @@ package handlers
 import (
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"minfo/internal/config"
+	"minfo/internal/screenshot"
 )
 
 func TestNormalizeProxyURLAllowsEmptyValue(t *testing.T) {
@@ -37,7 +42,7 @@ func TestNormalizeScreenshotFormTimestampsAllowsRepeatedTimestampFields(t *testi
 		"timestamp": {"00:01:02", "01:02:03"},
 	}}
 
-	timestamps, err := normalizeScreenshotFormTimestamps(request)
+	timestamps, err := normalizeScreenshotFormTimestamps(request, 10)
 	if err != nil {
 		t.Fatalf("normalizeScreenshotFormTimestamps returned error: %v", err)
 	}
@@ -51,7 +56,7 @@ func TestNormalizeScreenshotFormTimestampsAllowsDelimitedList(t *testing.T) {
 		"timestamps": {"00:01:02,01:02:03\n02:03:04"},
 	}}
 
-	timestamps, err := normalizeScreenshotFormTimestamps(request)
+	timestamps, err := normalizeScreenshotFormTimestamps(request, 10)
 	if err != nil {
 		t.Fatalf("normalizeScreenshotFormTimestamps returned error: %v", err)
 	}
@@ -65,7 +70,7 @@ func TestNormalizeScreenshotFormTimestampsRejectsInvalidValue(t *testing.T) {
 		"timestamp": {"00h01m02s"},
 	}}
 
-	if _, err := normalizeScreenshotFormTimestamps(request); err == nil {
+	if _, err := normalizeScreenshotFormTimestamps(request, 10); err == nil {
 		t.Fatal("expected invalid timestamp error")
 	}
 }
@@ -87,7 +92,44 @@ func TestNormalizeScreenshotFormTimestampsRejectsTooManyValues(t *testing.T) {
 		},
 	}}
 
-	if _, err := normalizeScreenshotFormTimestamps(request); err == nil {
+	if _, err := normalizeScreenshotFormTimestamps(request, 10); err == nil {
 		t.Fatal("expected too many timestamps error")
 	}
+	if _, err := normalizeScreenshotFormTimestamps(request, 40); err != nil {
+		t.Fatalf("normalizeScreenshotFormTimestamps with preset limit returned error: %v", err)
+	}
+}
+
+func TestParseScreenshotPresetAppliesCountVariantAndRenderOptions(t *testing.T) {
+	dir := t.TempDir()
+	previous := config.PresetDir
+	config.PresetDir = dir
+	t.Cleanup(func() { config.PresetDir = previous })
+
+	content := `{"count": 30, "variant": "jpg", "max_width": 1920, "max_height": 1080, "scaler": "spline", "jpg_qscale": 3, "max_bytes": 5000000}`
+	if err := os.WriteFile(filepath.Join(dir, "tracker.json"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	request := &http.Request{Form: url.Values{"preset": {" Tracker "}, "variant": {"png"}, "count": {"4"}}}
+	preset, err := parseScreenshotPreset(request)
+	if err != nil {
+		t.Fatalf("parseScreenshotPreset returned error: %v", err)
+	}
+	options := normalizeScreenshotFormOptions(request, preset)
+	if options.Preset != "tracker" || options.Count != 30 || options.Variant != screenshot.VariantJPG {
+		t.Fatalf("options = %+v, want preset count and variant", options)
+	}
+	want := screenshot.RenderOptions{MaxWidth: 1920, MaxHeight: 1080, Scaler: screenshot.ScalerSpline, JPGQScale: 3, MaxBytes: 5000000}
+	if options.Render != want {
+		t.Fatalf("render = %+v, want %+v", options.Render, want)
+	}
+	if preset.CountLimit() != 30 {
+		t.Fatalf("CountLimit() = %d, want 30", preset.CountLimit())
+	}
+
+	request = &http.Request{Form: url.Values{"preset": {"missing"}}}
+	if _, err := parseScreenshotPreset(request); err == nil {
+		t.Fatal("expected missing preset error")
+	}
 }
@@ -13,6 +13,7 @@ import (
 	"minfo/internal/screenshot"
 	screenshotdelivery "minfo/internal/screenshot/delivery"
 	screenshotprogress "minfo/internal/screenshot/progress"
+	"minfo/internal/taskprogress"
 )
 
 // handleScreenshotZipDownload 处理截图压缩包下载请求，也支持按令牌获取已准备好的 ZIP 文件。
@@ -25,7 +26,7 @@ func handleScreenshotZipDownload(w http.ResponseWriter, r *http.Request) {
 
 	path, cleanup, err := inputPathFromQuery(r)
 	if err != nil {
-		transport.WriteError(w, http.StatusBadRequest, err.Error())
+		transport.WriteError(w, transport.InputPathStatus(err), err.Error())
 		return
 	}
 	defer cleanup()
@@ -41,7 +42,7 @@ func handleScreenshotZipDownload(w http.ResponseWriter, r *http.Request) {
 	}
 	defer os.RemoveAll(tempDir)
 
-	if err := writeScreenshotZipResponse(ctx, w, path, tempDir, options.Variant, options.SubtitleMode, options.HDRProcessor, options.Count); err != nil {
+	if err := writeScreenshotZipResponse(ctx, w, path, tempDir, options.Variant, options.SubtitleMode, options.HDRProcessor, options.Count, options.Render); err != nil {
 		transport.WriteError(w, http.StatusInternalServerError, err.Error())
 	}
 }
@@ -52,13 +53,13 @@ func shouldPrepareDownload(r *http.Request) bool {
 }
 
 // prepareScreenshotZipDownload 生成截图压缩包并保存到临时下载缓存，返回可复用的下载地址。
-func prepareScreenshotZipDownload(ctx context.Context, path, tempDir, variant, subtitleMode, hdrProcessor string, count int, onLog screenshot.LogHandler) (string, string, error) {
-	zipBytes, logs, err := generateScreenshotZip(ctx, path, tempDir, variant, subtitleMode, hdrProcessor, count, onLog)
+func prepareScreenshotZipDownload(ctx context.Context, path, tempDir, variant, subtitleMode, hdrProcessor string, count int, render screenshot.RenderOptions, onLog screenshot.LogHandler, onProgress screenshot.ProgressHandler) (string, string, error) {
+	zipBytes, logs, err := generateScreenshotZip(ctx, path, tempDir, variant, subtitleMode, hdrProcessor, count, render, onLog, onProgress)
 	if err != nil {
 		return "", logs, err
 	}
 
-	screenshotprogress.EmitStepLog(onLog, "整理", 4, 4, "正在写入下载缓存。")
+	screenshotprogress.EmitStep(onLog, onProgress, taskprogress.StagePackage, 4, 4, "正在写入下载缓存。")
 	token, err := screenshotdelivery.SavePreparedDownload(zipBytes)
 	if err != nil {
 		return "", logs, err
@@ -67,8 +68,8 @@ func prepareScreenshotZipDownload(ctx context.Context, path, tempDir, variant, s
 }
 
 // writeScreenshotZipResponse 生成截图压缩包并直接以附件形式写回响应。
-func writeScreenshotZipResponse(ctx context.Context, w http.ResponseWriter, path, tempDir, variant, subtitleMode, hdrProcessor string, count int) error {
-	zipBytes, _, err := generateScreenshotZip(ctx, path, tempDir, variant, subtitleMode, hdrProcessor, count, nil)
+func writeScreenshotZipResponse(ctx context.Context, w http.ResponseWriter, path, tempDir, variant, subtitleMode, hdrProcessor string, count int, render screenshot.RenderOptions) error {
+	zipBytes, _, err := generateScreenshotZip(ctx, path, tempDir, variant, subtitleMode, hdrProcessor, count, render, nil, nil)
 	if err != nil {
 		return err
 	}
@@ -26,7 +26,7 @@ func handleScreenshotsPost(w http.ResponseWriter, r *http.Request) {
 
 	request, err := parseScreenshotFormRequest(r)
 	if err != nil {
-		transport.WriteJSON(w, http.StatusBadRequest, transport.InfoResponse{
+		transport.WriteJSON(w, transport.InputPathStatus(err), transport.InfoResponse{
 			OK:         false,
 			Error:      err.Error(),
 			Logs:       logger.String(),
@@ -39,6 +39,18 @@ func handleScreenshotsPost(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), config.RequestTimeout)
 	defer cancel()
 
+	inputPath, release, err := request.Input.resolve(ctx)
+	if err != nil {
+		transport.WriteJSON(w, transport.InputPathStatus(err), transport.InfoResponse{
+			OK:         false,
+			Error:      err.Error(),
+			Logs:       logger.String(),
+			LogEntries: pickRealtimeLogEntries(logger),
+		})
+		return
+	}
+	defer release()
+
 	tempDir, err := createScreenshotTempDir("minfo-shots-*")
 	if err != nil {
 		transport.WriteJSON(w, http.StatusInternalServerError, transport.InfoResponse{
@@ -54,21 +66,22 @@ func handleScreenshotsPost(w http.ResponseWriter, r *http.Request) {
 	if request.Mode == screenshot.ModeLinks {
 		result, err := screenshot.RunUploadWithLiveLogsWithOptions(
 			ctx,
-			request.InputPath,
+			inputPath,
 			tempDir,
 			request.Variant,
 			request.SubtitleMode,
 			request.HDRProcessor,
 			request.Count,
-			screenshot.UploadOptions{ProxyURL: request.ProxyURL},
+			screenshot.UploadOptions{ProxyURL: request.ProxyURL, Host: request.ImageHost, Render: request.Render},
 			logger.LogLine,
 		)
 		if err != nil {
 			transport.WriteJSON(w, http.StatusInternalServerError, transport.InfoResponse{
-				OK:         false,
-				Error:      err.Error(),
-				Logs:       pickRealtimeLogs(logger, result.Logs),
-				LogEntries: pickRealtimeLogEntries(logger),
+				OK:          false,
+				Error:       err.Error(),
+				Logs:        pickRealtimeLogs(logger, result.Logs),
+				LogEntries:  pickRealtimeLogEntries(logger),
+				FailedItems: buildTransportFailedImageItems(result.Failed),
 			})
 			return
 		}
@@ -78,6 +91,7 @@ func handleScreenshotsPost(w http.ResponseWriter, r *http.Request) {
 			Logs:            pickRealtimeLogs(logger, result.Logs),
 			LogEntries:      pickRealtimeLogEntries(logger),
 			LinkItems:       buildTransportImageLinkItems(result.Items),
+			FailedItems:     buildTransportFailedImageItems(result.Failed),
 			PNGLossyFiles:   result.LossyPNGFiles,
 			PNGLossyIndexes: result.LossyPNGIndexes,
 		})
@@ -85,7 +99,7 @@ func handleScreenshotsPost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if shouldPrepareDownload(r) {
-		downloadURL, logs, err := prepareScreenshotZipDownload(ctx, request.InputPath, tempDir, request.Variant, request.SubtitleMode, request.HDRProcessor, request.Count, logger.LogLine)
+		downloadURL, logs, err := prepareScreenshotZipDownload(ctx, inputPath, tempDir, request.Variant, request.SubtitleMode, request.HDRProcessor, request.Count, request.Render, logger.LogLine, nil)
 		if err != nil {
 			transport.WriteJSON(w, http.StatusInternalServerError, transport.InfoResponse{
 				OK:         false,
@@ -104,7 +118,7 @@ func handleScreenshotsPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := writeScreenshotZipResponse(ctx, w, request.InputPath, tempDir, request.Variant, request.SubtitleMode, request.HDRProcessor, request.Count); err != nil {
+	if err := writeScreenshotZipResponse(ctx, w, inputPath, tempDir, request.Variant, request.SubtitleMode, request.HDRProcessor, request.Count, request.Render); err != nil {
 		transport.WriteError(w, http.StatusInternalServerError, err.Error())
 	}
 }
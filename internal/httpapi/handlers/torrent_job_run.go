@@ -4,39 +4,24 @@ import (
 	"context"
 	"mime"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strings"
 
-	"minfo/internal/config"
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/taskprogress"
 	"minfo/internal/torrent"
 )
 
-func (j *torrentJob) run() {
-	defer func() {
-		if j.cancel != nil {
-			j.cancel()
-		}
-		if j.cleanup != nil {
-			j.cleanup()
-		}
-		if j.logger != nil {
-			j.logger.Close()
-		}
-	}()
-
-	if !j.beginRun() {
-		if j.isCancellationRequested() {
-			j.finishCanceled()
-		}
+func (j *torrentJob) execute(ctx context.Context) {
+	inputPath, release, err := j.input.resolve(ctx)
+	if err != nil {
+		j.fail(err)
 		return
 	}
+	defer release()
+	j.inputPath = inputPath
 
-	ctx, cancel := context.WithTimeout(j.taskContext, config.RequestTimeout)
-	defer cancel()
-
-	tempDir, err := os.MkdirTemp("", "minfo-torrent-job-*")
+	tempDir, err := createJobOutputDir("torrents", "minfo-torrent-job-*")
 	if err != nil {
 		j.fail(err)
 		return
@@ -51,38 +36,48 @@ func (j *torrentJob) run() {
 	onLine := func(stream, line string) {
 		j.handleTorrentCommandLine(stream, line)
 	}
-	filename, err := torrent.Create(ctx, j.inputPath, outputPath, j.options, onLine)
+	onProgress := j.progressHandler(func(event taskprogress.Event) {
+		j.progress = torrentProgressSnapshot(event)
+	})
+	filename, err := torrent.Create(ctx, j.inputPath, outputPath, j.options, onLine, onProgress)
+	if err != nil {
+		j.fail(err)
+		return
+	}
+
+	meta, err := torrent.ReadMetaInfo(outputPath)
 	if err != nil {
 		j.fail(err)
 		return
 	}
 
 	downloadURL := "/api/torrent-jobs/" + j.id + "/download"
-	j.logger.Logf("[torrent] 完成: %s", filename)
-	j.succeed("种子已生成。", downloadURL, outputPath, filename)
+	j.logger.Logf("[torrent] 完成: %s (info hash %s)", filename, meta.InfoHash)
+	j.succeed("种子已生成。", downloadURL, outputPath, filename, meta)
 }
 
 func (j *torrentJob) handleTorrentCommandLine(stream, line string) {
+	logMkbrrLine(j.logger, stream, line)
+}
+
+func logMkbrrLine(logger *infoLogger, stream, line string) {
 	cleaned := torrent.StripANSI(strings.TrimSpace(line))
 	cleaned = strings.Join(strings.Fields(cleaned), " ")
 	if cleaned == "" {
 		return
 	}
-
-	if parsed, ok := torrent.ParseProgressLine(line); ok {
-		j.updateProgress(torrentProgressSnapshot(parsed))
-		return
-	}
-	j.logger.Logf("[mkbrr][%s] %s", stream, cleaned)
+	logger.Logf("[mkbrr][%s] %s", stream, cleaned)
 }
 
-func torrentProgressSnapshot(progress torrent.Progress) *transport.TaskProgress {
-	percent := progress.Percent
-	indeterminate := percent <= 0 && !progress.Done
-	if progress.Stage == "正在哈希" && percent < 2 {
+func torrentProgressSnapshot(event taskprogress.Event) *transport.TaskProgress {
+	percent := event.Percent
+	indeterminate := percent <= 0
+	if event.Stage == torrent.StageHashing && percent < 2 {
 		percent = 2
 	}
-	return progressSnapshot(percent, progress.Stage, progress.Detail, 0, 0, indeterminate)
+	progress := progressSnapshot(percent, event.Stage, event.Detail, 0, 0, indeterminate)
+	progress.ETAMS = event.ETAMS
+	return progress
 }
 
 func handleTorrentJobDownload(w http.ResponseWriter, r *http.Request) {
@@ -109,7 +104,7 @@ func handleTorrentJobDownload(w http.ResponseWriter, r *http.Request) {
 	filename := job.filename
 	job.mu.RUnlock()
 
-	if status != torrentJobStatusSucceeded || outputPath == "" {
+	if status != jobStatusSucceeded || outputPath == "" {
 		writeTorrentJobError(w, http.StatusNotFound, "torrent file is not ready")
 		return
 	}
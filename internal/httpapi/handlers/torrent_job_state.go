@@ -1,11 +1,10 @@
 package handlers
 
 import (
-	"context"
-	"errors"
-	"time"
-
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
+	"minfo/internal/release"
+	"minfo/internal/torrent"
 )
 
 func (j *torrentJob) snapshot() transport.TorrentJobResponse {
@@ -18,6 +17,10 @@ func (j *torrentJob) snapshot() transport.TorrentJobResponse {
 		DownloadURL: j.downloadURL,
 		Error:       j.errMessage,
 		Progress:    cloneTaskProgress(j.progress),
+		InfoHash:    j.metaInfo.InfoHash,
+		InfoHashV2:  j.metaInfo.InfoHashV2,
+		Template:    j.template,
+		Description: j.description,
 	}
 	logger := j.logger
 	j.mu.RUnlock()
@@ -26,166 +29,92 @@ func (j *torrentJob) snapshot() transport.TorrentJobResponse {
 		response.Logs = logger.String()
 		response.LogEntries = logger.Entries()
 	}
+	if response.Status == jobStatusPending {
+		response.QueuePosition = jobQueuePosition(response.JobID)
+	}
 	if response.Progress == nil {
 		response.Progress = buildTorrentFallbackProgress(response.Status)
 	}
+	applyQueueProgress(response.Progress, response.QueuePosition)
 	return response
 }
 
-func (j *torrentJob) expired(now time.Time) bool {
+func (j *torrentJob) record() jobstore.Record {
+	record := j.describe()
+	record.LogEntries = recordLogEntries(j.logger.Entries())
+	return record
+}
+
+func (j *torrentJob) describe() jobstore.Record {
 	j.mu.RLock()
 	defer j.mu.RUnlock()
 
-	if j.completedAt.IsZero() {
-		return false
-	}
-	return now.Sub(j.completedAt) > torrentJobTTL
+	record := j.recordLocked(jobstore.TypeTorrent)
+	record.InputPath = j.input.source
+	record.Options = torrentRecordOptions(j.options)
+	record.Output = j.output
+	record.DownloadURL = j.downloadURL
+	record.OutputPath = j.outputPath
+	record.Filename = j.filename
+	record.Progress = recordProgress(j.progress)
+	return record
 }
 
-func (j *torrentJob) beginRun() bool {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	if j.status != torrentJobStatusPending {
-		return false
-	}
-	if j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled) {
-		return false
-	}
-
-	j.status = torrentJobStatusRunning
-	j.progress = progressSnapshot(1, "准备", "正在准备制作种子。", 0, 0, true)
-	j.updatedAt = time.Now()
-	return true
+func (j *torrentJob) persist() {
+	saveJobRecord(j.record())
 }
 
-func (j *torrentJob) requestCancel() {
-	var cancel context.CancelFunc
-
-	j.mu.Lock()
-	switch j.status {
-	case torrentJobStatusSucceeded, torrentJobStatusFailed, torrentJobStatusCanceled:
-		j.mu.Unlock()
-		return
-	case torrentJobStatusCanceling:
-		j.mu.Unlock()
-		return
-	default:
-		j.cancelRequested = true
-		j.status = torrentJobStatusCanceling
-		j.errMessage = "任务取消中。"
+func (j *torrentJob) applyStatusLocked(status string) {
+	switch status {
+	case jobStatusRunning:
+		j.progress = progressSnapshot(1, "准备", "正在准备制作种子。", 0, 0, true)
+	case jobStatusCanceling:
 		j.progress = progressSnapshot(progressPercent(j.progress), "取消中", "正在停止制种任务。", 0, 0, true)
-		j.updatedAt = time.Now()
-		cancel = j.cancel
-		j.mu.Unlock()
-	}
-
-	if cancel != nil {
-		cancel()
-	}
-}
-
-func (j *torrentJob) updateProgress(progress *transport.TaskProgress) {
-	if progress == nil {
-		return
-	}
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	if j.status != torrentJobStatusRunning {
-		return
-	}
-	j.progress = progress
-	j.updatedAt = time.Now()
-}
-
-func (j *torrentJob) succeed(output, downloadURL, outputPath, filename string) {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	now := time.Now()
-	if j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled) {
-		j.status = torrentJobStatusCanceled
-		j.output = ""
-		j.downloadURL = ""
-		j.outputPath = ""
-		j.filename = ""
-		j.errMessage = "任务已取消。"
+	case jobStatusSucceeded:
+		j.progress = progressSnapshot(100, "完成", "种子已生成，正在准备下载。", 0, 0, false)
+	case jobStatusCanceled:
+		j.clearResultLocked()
 		j.progress = progressSnapshot(progressPercent(j.progress), "已取消", "制种任务已取消。", 0, 0, true)
-		j.updatedAt = now
-		j.completedAt = now
-		return
+	case jobStatusFailed:
+		j.clearResultLocked()
+		j.progress = progressSnapshot(progressPercent(j.progress), "失败", "制作种子失败。", 0, 0, false)
 	}
-
-	j.status = torrentJobStatusSucceeded
-	j.output = output
-	j.downloadURL = downloadURL
-	j.outputPath = outputPath
-	j.filename = filename
-	j.errMessage = ""
-	j.progress = progressSnapshot(100, "完成", "种子已生成，正在准备下载。", 0, 0, false)
-	j.updatedAt = now
-	j.completedAt = now
 }
 
-func (j *torrentJob) fail(err error) {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	now := time.Now()
-	if j.cancelRequested || errors.Is(err, context.Canceled) || errors.Is(j.taskContext.Err(), context.Canceled) {
-		j.status = torrentJobStatusCanceled
-		j.output = ""
-		j.downloadURL = ""
-		j.outputPath = ""
-		j.filename = ""
-		j.errMessage = "任务已取消。"
-		j.progress = progressSnapshot(progressPercent(j.progress), "已取消", "制种任务已取消。", 0, 0, true)
-		j.updatedAt = now
-		j.completedAt = now
-		return
-	}
-
-	j.status = torrentJobStatusFailed
+func (j *torrentJob) clearResultLocked() {
 	j.output = ""
 	j.downloadURL = ""
 	j.outputPath = ""
 	j.filename = ""
-	if err != nil {
-		j.errMessage = err.Error()
-	} else {
-		j.errMessage = "job failed"
-	}
-	j.progress = progressSnapshot(progressPercent(j.progress), "失败", "制作种子失败。", 0, 0, false)
-	j.updatedAt = now
-	j.completedAt = now
+	j.metaInfo = torrent.MetaInfo{}
+	j.description = ""
 }
 
-func (j *torrentJob) finishCanceled() {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
-	if j.status == torrentJobStatusSucceeded || j.status == torrentJobStatusFailed || j.status == torrentJobStatusCanceled {
-		return
-	}
-
-	now := time.Now()
-	j.status = torrentJobStatusCanceled
-	j.output = ""
-	j.downloadURL = ""
-	j.outputPath = ""
-	j.filename = ""
-	j.errMessage = "任务已取消。"
-	j.progress = progressSnapshot(progressPercent(j.progress), "已取消", "制种任务已取消。", 0, 0, true)
-	j.updatedAt = now
-	j.completedAt = now
+func (j *torrentJob) succeed(output, downloadURL, outputPath, filename string, meta torrent.MetaInfo) {
+	j.jobBase.succeed(func() {
+		j.output = output
+		j.downloadURL = downloadURL
+		j.outputPath = outputPath
+		j.filename = filename
+		j.metaInfo = meta
+	})
 }
 
-func (j *torrentJob) isCancellationRequested() bool {
-	j.mu.RLock()
-	defer j.mu.RUnlock()
-
-	return j.cancelRequested || errors.Is(j.taskContext.Err(), context.Canceled)
+func (j *torrentJob) releaseDataLocked() release.Data {
+	data := release.Data{Name: j.metaInfo.Name}
+	if data.Name == "" {
+		data.Name = release.NameFromPath(j.input.name())
+	}
+	if j.outputPath != "" {
+		data.Torrent = &release.Torrent{
+			InfoHash:    j.metaInfo.InfoHash,
+			InfoHashV2:  j.metaInfo.InfoHashV2,
+			Name:        j.metaInfo.Name,
+			Size:        j.metaInfo.TotalSize,
+			DownloadURL: j.downloadURL,
+		}
+	}
+	return data
 }
 
 func cloneTaskProgress(progress *transport.TaskProgress) *transport.TaskProgress {
@@ -198,18 +127,20 @@ func cloneTaskProgress(progress *transport.TaskProgress) *transport.TaskProgress
 
 func buildTorrentFallbackProgress(status string) *transport.TaskProgress {
 	switch status {
-	case torrentJobStatusPending:
+	case jobStatusPending:
 		return progressSnapshot(1, "等待中", "制种任务等待开始。", 0, 0, true)
-	case torrentJobStatusRunning:
+	case jobStatusRunning:
 		return progressSnapshot(5, "制作中", "正在制作种子。", 0, 0, true)
-	case torrentJobStatusCanceling:
+	case jobStatusCanceling:
 		return progressSnapshot(5, "取消中", "正在停止制种任务。", 0, 0, true)
-	case torrentJobStatusSucceeded:
+	case jobStatusSucceeded:
 		return progressSnapshot(100, "完成", "种子已生成。", 0, 0, false)
-	case torrentJobStatusCanceled:
+	case jobStatusCanceled:
 		return progressSnapshot(0, "已取消", "制种任务已取消。", 0, 0, true)
-	case torrentJobStatusFailed:
+	case jobStatusFailed:
 		return progressSnapshot(0, "失败", "制作种子失败。", 0, 0, false)
+	case jobStatusInterrupted:
+		return progressSnapshot(0, "已中断", "服务重启，制种任务已中断。", 0, 0, false)
 	default:
 		return nil
 	}
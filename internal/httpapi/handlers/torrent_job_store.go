@@ -1,128 +1,153 @@
 package handlers
 
 import (
-	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"net/url"
 	"os"
-	"sync"
-	"time"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"minfo/internal/httpapi/transport"
+	"minfo/internal/jobstore"
 	"minfo/internal/torrent"
 )
 
-const (
-	torrentJobStatusPending   = "pending"
-	torrentJobStatusRunning   = "running"
-	torrentJobStatusCanceling = "canceling"
-	torrentJobStatusSucceeded = "succeeded"
-	torrentJobStatusFailed    = "failed"
-	torrentJobStatusCanceled  = "canceled"
-	torrentJobTTL             = 30 * time.Minute
-)
-
-var torrentJobs = struct {
-	mu    sync.Mutex
-	items map[string]*torrentJob
-}{
-	items: make(map[string]*torrentJob),
-}
-
 type torrentJob struct {
-	mu          sync.RWMutex
-	id          string
+	jobBase
+	// input 是提交时的输入，原始路径作为记录的 InputPath 持久化；inputPath 是执行时才解析出、交给 mkbrr 的实际路径。
+	input       formInput
 	inputPath   string
 	options     torrent.Options
-	status      string
 	output      string
 	downloadURL string
 	outputPath  string
 	filename    string
-	errMessage  string
+	metaInfo    torrent.MetaInfo
 	progress    *transport.TaskProgress
-	createdAt   time.Time
-	updatedAt   time.Time
-	completedAt time.Time
-	logger      *infoLogger
-	cleanup     func()
 	tempDir     string
-	taskContext context.Context
-	cancel      context.CancelFunc
-
-	cancelRequested bool
 }
 
 func createTorrentJob(request torrentRequest) (*torrentJob, error) {
-	pruneTorrentJobs(time.Now())
-
-	jobID, err := buildTorrentJobID()
-	if err != nil {
+	job := &torrentJob{
+		input:   request.Input,
+		options: request.Options,
+	}
+	if err := initJobBase(&job.jobBase, jobClassTorrent, request.Cleanup); err != nil {
 		return nil, err
 	}
+	job.onStatus = job.applyStatusLocked
+	job.template = request.Template
+	job.releaseData = job.releaseDataLocked
+
+	submitJob(job)
+	return job, nil
+}
 
-	taskContext, cancel := context.WithCancel(context.Background())
-	now := time.Now()
+func restoreTorrentJob(record jobstore.Record) {
 	job := &torrentJob{
-		id:          jobID,
-		inputPath:   request.InputPath,
-		options:     request.Options,
-		status:      torrentJobStatusPending,
-		createdAt:   now,
-		updatedAt:   now,
-		logger:      newInfoLogger(),
-		cleanup:     request.Cleanup,
-		taskContext: taskContext,
-		cancel:      cancel,
+		input:       formInput{source: record.InputPath},
+		options:     torrentOptionsFromRecord(record.Options),
+		output:      record.Output,
+		downloadURL: record.DownloadURL,
+		outputPath:  record.OutputPath,
+		filename:    record.Filename,
+		progress:    transportProgress(record.Progress),
 	}
-
-	torrentJobs.mu.Lock()
-	torrentJobs.items[jobID] = job
-	torrentJobs.mu.Unlock()
-
-	go job.run()
-	return job, nil
+	restoreJobBase(&job.jobBase, jobClassTorrent, record)
+	job.onStatus = job.applyStatusLocked
+	job.releaseData = job.releaseDataLocked
+	if record.OutputPath != "" {
+		job.tempDir = filepath.Dir(record.OutputPath)
+		if meta, err := torrent.ReadMetaInfo(record.OutputPath); err == nil {
+			job.metaInfo = meta
+		}
+	}
+	if job.status == jobStatusInterrupted {
+		job.progress = buildTorrentFallbackProgress(job.status)
+	}
+	storeRestoredJob(job)
 }
 
 func getTorrentJob(jobID string) (*torrentJob, bool) {
-	pruneTorrentJobs(time.Now())
-
-	torrentJobs.mu.Lock()
-	defer torrentJobs.mu.Unlock()
-
-	job, ok := torrentJobs.items[jobID]
+	job, ok := lookupJob(jobID)
 	if !ok {
 		return nil, false
 	}
-	return job, true
+	torrentJob, ok := job.(*torrentJob)
+	return torrentJob, ok
 }
 
-func pruneTorrentJobs(now time.Time) {
-	torrentJobs.mu.Lock()
-	defer torrentJobs.mu.Unlock()
+func (j *torrentJob) discard() {
+	j.mu.RLock()
+	tempDir := j.tempDir
+	j.mu.RUnlock()
+	if tempDir != "" {
+		_ = os.RemoveAll(tempDir)
+	}
+}
+
+func torrentRecordOptions(options torrent.Options) map[string]string {
+	values := map[string]string{
+		"format":       options.Format,
+		"piece_length": strconv.FormatInt(options.PieceLength, 10),
+		"private":      strconv.FormatBool(options.Private),
+		"comment":      options.Comment,
+		"source":       options.Source,
+	}
+	if len(options.Trackers) > 0 {
+		values["tracker_url"] = redactTorrentURLs(strings.Join(options.Trackers, "\n"))
+	}
+	if len(options.WebSeeds) > 0 {
+		values["web_seed_url"] = redactTorrentURLs(strings.Join(options.WebSeeds, "\n"))
+	}
+	return values
+}
 
-	for jobID, job := range torrentJobs.items {
-		if !job.expired(now) {
+// redactTorrentRecordOptions 会把记录参数中的 Tracker 和 Web Seed 地址原地脱敏，返回是否有改动；用于清理旧版本按原文落盘的记录。
+func redactTorrentRecordOptions(values map[string]string) bool {
+	changed := false
+	for _, key := range []string{"tracker_url", "web_seed_url"} {
+		raw, ok := values[key]
+		if !ok {
 			continue
 		}
-		job.removeTempDir()
-		delete(torrentJobs.items, jobID)
+		if redacted := redactTorrentURLs(raw); redacted != raw {
+			values[key] = redacted
+			changed = true
+		}
 	}
+	return changed
 }
 
-func buildTorrentJobID() (string, error) {
-	buf := make([]byte, 16)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
+// redactTorrentURLs 会把换行分隔的地址逐条裁剪为 scheme://host，去掉路径、查询参数和用户信息中可能携带的 passkey。
+func redactTorrentURLs(raw string) string {
+	lines := strings.Split(raw, "\n")
+	for index, line := range lines {
+		parsed, err := url.Parse(strings.TrimSpace(line))
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			lines[index] = "redacted"
+			continue
+		}
+		lines[index] = parsed.Scheme + "://" + parsed.Host
 	}
-	return hex.EncodeToString(buf), nil
+	return strings.Join(lines, "\n")
 }
 
-func (j *torrentJob) removeTempDir() {
-	j.mu.RLock()
-	tempDir := j.tempDir
-	j.mu.RUnlock()
-	if tempDir != "" {
-		_ = os.RemoveAll(tempDir)
+func torrentOptionsFromRecord(values map[string]string) torrent.Options {
+	pieceLength, _ := strconv.ParseInt(values["piece_length"], 10, 64)
+	private, _ := strconv.ParseBool(values["private"])
+	options := torrent.Options{
+		Format:      values["format"],
+		PieceLength: pieceLength,
+		Private:     private,
+		Comment:     values["comment"],
+		Source:      values["source"],
+	}
+	if raw := values["tracker_url"]; raw != "" {
+		options.Trackers = strings.Split(raw, "\n")
+	}
+	if raw := values["web_seed_url"]; raw != "" {
+		options.WebSeeds = strings.Split(raw, "\n")
 	}
+	return options
 }
@@ -21,7 +21,7 @@ func TorrentJobsHandler(w http.ResponseWriter, r *http.Request) {
 
 	request, err := parseTorrentFormRequest(r)
 	if err != nil {
-		writeTorrentJobError(w, http.StatusBadRequest, err.Error())
+		writeTorrentJobError(w, transport.InputPathStatus(err), err.Error())
 		return
 	}
 
@@ -83,7 +83,7 @@ func handleTorrentJobDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job.requestCancel()
+	cancelJob(job)
 	writeTorrentJobResponse(w, http.StatusOK, job.snapshot())
 }
 
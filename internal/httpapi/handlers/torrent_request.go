@@ -5,18 +5,24 @@ import (
 	"strconv"
 	"strings"
 
-	"minfo/internal/httpapi/transport"
+	"minfo/internal/media"
 	"minfo/internal/torrent"
 )
 
 type torrentRequest struct {
-	InputPath string
-	Cleanup   func()
-	Options   torrent.Options
+	Input    formInput
+	Cleanup  func()
+	Options  torrent.Options
+	Template string
 }
 
 func parseTorrentFormRequest(r *http.Request) (torrentRequest, error) {
-	inputPath, cleanup, err := transport.InputPath(r)
+	template, err := parseReleaseTemplate(r)
+	if err != nil {
+		return torrentRequest{}, err
+	}
+
+	input, cleanup, err := parseFormInput(r, media.PermissionTorrent)
 	if err != nil {
 		return torrentRequest{}, err
 	}
@@ -28,9 +34,10 @@ func parseTorrentFormRequest(r *http.Request) (torrentRequest, error) {
 	}
 
 	return torrentRequest{
-		InputPath: inputPath,
-		Cleanup:   cleanup,
-		Options:   options,
+		Input:    input,
+		Cleanup:  cleanup,
+		Options:  options,
+		Template: template,
 	}, nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"crypto/subtle"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"minfo/internal/config"
@@ -21,18 +22,26 @@ func Logging(next http.Handler) http.Handler {
 	})
 }
 
-// Authenticate 在配置了 Web 基础认证后校验请求凭据；未配置密码时直接放行。
+// Authenticate 在配置了 Web 基础认证或 API 令牌后校验请求凭据；两者都未配置时直接放行。
+// 配置了 API_TOKEN 时，携带 Authorization: Bearer <令牌> 的请求无需基础认证，供命令行远程客户端和脚本使用。
 func Authenticate(next http.Handler) http.Handler {
 	username := config.Getenv("WEB_USERNAME", "")
 	password := config.Getenv("WEB_PASSWORD", "")
-	if password == "" {
+	token := config.Getenv("API_TOKEN", "")
+	if password == "" && token == "" {
 		return next
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && matchesBearerToken(r, token) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		user, pass, ok := r.BasicAuth()
-		if !ok || !matchesCredential(password, pass) || (username != "" && !matchesCredential(username, user)) {
-			w.Header().Set("WWW-Authenticate", "Basic realm=\"minfo\"")
+		if password == "" || !ok || !matchesCredential(password, pass) || (username != "" && !matchesCredential(username, user)) {
+			if password != "" {
+				w.Header().Set("WWW-Authenticate", "Basic realm=\"minfo\"")
+			}
 			transport.WriteError(w, http.StatusUnauthorized, "unauthorized")
 			return
 		}
@@ -40,6 +49,15 @@ func Authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// matchesBearerToken 会判断请求的 Authorization 头是否携带了匹配的 Bearer 令牌。
+func matchesBearerToken(r *http.Request, token string) bool {
+	scheme, value, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return matchesCredential(token, strings.TrimSpace(value))
+}
+
 // matchesCredential 会判断认证信息是否匹配当前校验规则。
 func matchesCredential(expected, actual string) bool {
 	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// authenticateStatus 会用当前环境变量构造认证中间件，并返回请求的状态码和 WWW-Authenticate 头。
+func authenticateStatus(t *testing.T, configure func(r *http.Request)) (int, string) {
+	t.Helper()
+	handler := Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/api/paths", nil)
+	if configure != nil {
+		configure(request)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Header().Get("WWW-Authenticate")
+}
+
+// bearer 返回设置 Authorization 头的请求配置函数。
+func bearer(value string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", value)
+	}
+}
+
+// TestAuthenticateTokenOnly 验证只配置 API_TOKEN 时只接受匹配的 Bearer 令牌，错误方案和空令牌都返回 401 且不提示基础认证。
+func TestAuthenticateTokenOnly(t *testing.T) {
+	t.Setenv("WEB_USERNAME", "")
+	t.Setenv("WEB_PASSWORD", "")
+	t.Setenv("API_TOKEN", "secret-token")
+
+	if status, _ := authenticateStatus(t, bearer("Bearer secret-token")); status != http.StatusNoContent {
+		t.Fatalf("matching token status = %d, want 204", status)
+	}
+	if status, _ := authenticateStatus(t, bearer("bearer   secret-token ")); status != http.StatusNoContent {
+		t.Fatalf("lower-case scheme status = %d, want 204", status)
+	}
+
+	cases := map[string]func(r *http.Request){
+		"missing":      nil,
+		"wrong token":  bearer("Bearer other-token"),
+		"wrong scheme": bearer("Token secret-token"),
+		"empty bearer": bearer("Bearer "),
+		"bare scheme":  bearer("Bearer"),
+		"basic auth": func(r *http.Request) {
+			r.SetBasicAuth("", "secret-token")
+		},
+	}
+	for name, configure := range cases {
+		status, challenge := authenticateStatus(t, configure)
+		if status != http.StatusUnauthorized {
+			t.Fatalf("%s status = %d, want 401", name, status)
+		}
+		if challenge != "" {
+			t.Fatalf("%s WWW-Authenticate = %q, want none without WEB_PASSWORD", name, challenge)
+		}
+	}
+}
+
+// TestAuthenticateBasicAuthWithToken 验证同时配置 API_TOKEN 时基础认证仍然可用，失败时返回基础认证质询。
+func TestAuthenticateBasicAuthWithToken(t *testing.T) {
+	t.Setenv("WEB_USERNAME", "admin")
+	t.Setenv("WEB_PASSWORD", "web-pass")
+	t.Setenv("API_TOKEN", "secret-token")
+
+	basic := func(user, pass string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.SetBasicAuth(user, pass)
+		}
+	}
+	for name, configure := range map[string]func(r *http.Request){
+		"basic auth": basic("admin", "web-pass"),
+		"bearer":     bearer("Bearer secret-token"),
+	} {
+		if status, _ := authenticateStatus(t, configure); status != http.StatusNoContent {
+			t.Fatalf("%s status = %d, want 204", name, status)
+		}
+	}
+
+	for name, configure := range map[string]func(r *http.Request){
+		"wrong password": basic("admin", "other"),
+		"wrong username": basic("guest", "web-pass"),
+		"wrong token":    bearer("Bearer web-pass"),
+		"empty bearer":   bearer("Bearer "),
+	} {
+		status, challenge := authenticateStatus(t, configure)
+		if status != http.StatusUnauthorized || challenge != `Basic realm="minfo"` {
+			t.Fatalf("%s = %d %q, want 401 with basic challenge", name, status, challenge)
+		}
+	}
+}
+
+// TestAuthenticateDisabledWithoutCredentials 验证未配置密码和令牌时请求直接放行。
+func TestAuthenticateDisabledWithoutCredentials(t *testing.T) {
+	t.Setenv("WEB_USERNAME", "admin")
+	t.Setenv("WEB_PASSWORD", "")
+	t.Setenv("API_TOKEN", "")
+
+	if status, _ := authenticateStatus(t, nil); status != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", status)
+	}
+}
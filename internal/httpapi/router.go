@@ -8,9 +8,11 @@ import (
 
 	"minfo/internal/httpapi/handlers"
 	"minfo/internal/httpapi/middleware"
+	"minfo/internal/screenshot/imagehost"
 )
 
 // NewHandler 组装 API 路由、静态文件服务和鉴权中间件，返回应用的统一入口 Handler。
+// 本地图床图片需要被外部站点直接引用，因此 /i/ 路径不经过鉴权。
 func NewHandler(assets fs.FS) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.FS(assets)))
@@ -23,6 +25,18 @@ func NewHandler(assets fs.FS) http.Handler {
 	mux.HandleFunc("/api/screenshots", handlers.ScreenshotsHandler)
 	mux.HandleFunc("/api/torrent-jobs", handlers.TorrentJobsHandler)
 	mux.HandleFunc("/api/torrent-jobs/", handlers.TorrentJobHandler)
+	mux.HandleFunc("/api/release-jobs", handlers.ReleaseJobsHandler)
+	mux.HandleFunc("/api/release-jobs/", handlers.ReleaseJobHandler)
+	mux.HandleFunc("/api/jobs", handlers.JobsHandler)
+	mux.HandleFunc("/api/jobs/", handlers.JobHandler)
 	mux.HandleFunc("/api/path", handlers.PathSuggestHandler)
-	return middleware.Logging(middleware.Authenticate(mux))
+	mux.HandleFunc("/api/bluray/playlists", handlers.BlurayPlaylistsHandler)
+	mux.HandleFunc("/api/admin/cache", handlers.ResultCacheHandler)
+	mux.HandleFunc("/api/templates", handlers.TemplatesHandler)
+	mux.HandleFunc("/api/screenshot-presets", handlers.ScreenshotPresetsHandler)
+
+	root := http.NewServeMux()
+	root.HandleFunc(imagehost.LocalImagePathPrefix, handlers.LocalImageHandler)
+	root.Handle("/", middleware.Authenticate(mux))
+	return middleware.Logging(root)
 }
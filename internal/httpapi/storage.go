@@ -0,0 +1,36 @@
+// Package httpapi 负责装配后台任务依赖的持久化存储。
+
+package httpapi
+
+import (
+	"path/filepath"
+
+	"minfo/internal/config"
+	"minfo/internal/httpapi/handlers"
+	"minfo/internal/jobstore"
+	"minfo/internal/resultcache"
+	screenshotdelivery "minfo/internal/screenshot/delivery"
+	"minfo/internal/screenshot/imagehost"
+)
+
+// ConfigureStorage 会在 dataDir 下打开任务仓库、截图下载缓存、本地图床目录和信息结果缓存，并恢复重启前的任务历史。
+func ConfigureStorage(dataDir string) error {
+	repo, err := jobstore.OpenFileRepository(dataDir)
+	if err != nil {
+		return err
+	}
+	if err := screenshotdelivery.ConfigureStorageDir(filepath.Join(dataDir, "downloads")); err != nil {
+		return err
+	}
+	if err := imagehost.ConfigureLocalStore(filepath.Join(dataDir, "images")); err != nil {
+		return err
+	}
+	if config.ResultCacheEnabled {
+		cache, err := resultcache.Open(filepath.Join(dataDir, "cache"), config.ResultCacheTTL)
+		if err != nil {
+			return err
+		}
+		handlers.ConfigureResultCache(cache)
+	}
+	return handlers.ConfigureJobStorage(repo, dataDir)
+}
@@ -38,16 +38,45 @@ func CleanupMultipart(r *http.Request) {
 	}
 }
 
+// InputPathStatus 返回输入路径解析失败时应使用的 HTTP 状态码：越出媒体根目录的路径返回 403，其余错误返回 400。
+func InputPathStatus(err error) int {
+	if errors.Is(err, media.ErrPathNotAllowed) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
 // InputPath 从表单里的 path 或上传文件中解析输入路径，并返回对应的清理函数。
 func InputPath(r *http.Request) (string, func(), error) {
-	path := strings.TrimSpace(r.FormValue("path"))
-	path = strings.Trim(path, "\"")
+	return InputPathFor(r, media.PermissionRead)
+}
+
+// InputPathFor 与 InputPath 相同，但表单路径所在的媒体根目录还必须允许 permission 对应的操作；上传文件不受根目录权限约束。
+func InputPathFor(r *http.Request, permission media.Permission) (string, func(), error) {
+	path := FormPath(r)
 	if path != "" {
 		ctx, cancel := context.WithTimeout(r.Context(), config.RequestTimeout)
 		defer cancel()
-		return media.ResolveInputPath(ctx, path)
+		return media.ResolveInputPathFor(ctx, path, permission)
 	}
+	return saveUpload(r)
+}
 
+// CheckInputFor 供后台任务在提交时使用：表单里有 path 时只校验路径存在且所在媒体根目录允许 permission 对应的操作，不会挂载或共享 ISO；
+// 否则把上传文件保存到临时目录。返回用户提交的原始路径和上传文件的临时路径，二者只有一个非空；清理函数负责删除上传的临时文件。
+func CheckInputFor(r *http.Request, permission media.Permission) (string, string, func(), error) {
+	if path := FormPath(r); path != "" {
+		if err := media.CheckInputPathFor(path, permission); err != nil {
+			return "", "", func() {}, err
+		}
+		return path, "", func() {}, nil
+	}
+	upload, cleanup, err := saveUpload(r)
+	return "", upload, cleanup, err
+}
+
+// saveUpload 会把表单里的上传文件保存到独立临时目录，并返回文件路径和删除该目录的清理函数。
+func saveUpload(r *http.Request) (string, func(), error) {
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		return "", func() {}, errors.New("missing file or path")
@@ -81,6 +110,16 @@ func InputPath(r *http.Request) (string, func(), error) {
 	return tempFile.Name(), cleanup, nil
 }
 
+// FormPath 返回表单里用户提交的原始 path，已去除首尾空白和引号；使用上传文件时返回空字符串。
+func FormPath(r *http.Request) string {
+	return strings.Trim(strings.TrimSpace(r.FormValue("path")), "\"")
+}
+
+// WantsRefresh 会判断请求是否通过 refresh=1 要求跳过结果缓存重新探测。
+func WantsRefresh(r *http.Request) bool {
+	return strings.TrimSpace(r.FormValue("refresh")) == "1"
+}
+
 // uploadFileName 清理上传文件名，避免路径穿越并为无效名称提供稳定兜底值。
 func uploadFileName(name string) string {
 	cleaned := strings.TrimSpace(name)
@@ -2,6 +2,13 @@
 
 package transport
 
+import (
+	"minfo/internal/bdinfo"
+	"minfo/internal/mediainfo"
+	"minfo/internal/release"
+	"minfo/internal/screenshot"
+)
+
 // LogEntry 表示一条带绝对时间戳的结构化日志记录。
 type LogEntry struct {
 	Timestamp string `json:"timestamp,omitempty"`
@@ -18,6 +25,12 @@ type ImageLinkItem struct {
 	Height       int    `json:"height,omitempty"`
 }
 
+// FailedImageItem 表示一张重试后仍未能上传到图床的截图及失败原因。
+type FailedImageItem struct {
+	Filename string `json:"filename,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
 // TaskProgress 表示后台任务当前阶段对应的进度信息。
 type TaskProgress struct {
 	Percent       float64 `json:"percent,omitempty"`
@@ -26,66 +39,176 @@ type TaskProgress struct {
 	Current       int     `json:"current,omitempty"`
 	Total         int     `json:"total,omitempty"`
 	Indeterminate bool    `json:"indeterminate,omitempty"`
+	ETAMS         int64   `json:"eta_ms,omitempty"`
 }
 
 // InfoResponse 表示信息类接口共用的 JSON 响应。
 type InfoResponse struct {
-	OK              bool            `json:"ok"`
-	Output          string          `json:"output,omitempty"`
-	Error           string          `json:"error,omitempty"`
-	Logs            string          `json:"logs,omitempty"`
-	LogEntries      []LogEntry      `json:"log_entries,omitempty"`
-	LinkItems       []ImageLinkItem `json:"link_items,omitempty"`
-	PNGLossyFiles   []string        `json:"png_lossy_files,omitempty"`
-	PNGLossyIndexes []int           `json:"png_lossy_indexes,omitempty"`
+	OK     bool           `json:"ok"`
+	Output string         `json:"output,omitempty"`
+	BDInfo *bdinfo.Report `json:"bdinfo,omitempty"`
+	// MediaInfo 是 MediaInfo 结果的规范化摘要，OutputFormat 是 Output 使用的输出模式。
+	MediaInfo       *mediainfo.Summary `json:"mediainfo,omitempty"`
+	OutputFormat    string             `json:"output_format,omitempty"`
+	Error           string             `json:"error,omitempty"`
+	Logs            string             `json:"logs,omitempty"`
+	LogEntries      []LogEntry         `json:"log_entries,omitempty"`
+	LinkItems       []ImageLinkItem    `json:"link_items,omitempty"`
+	FailedItems     []FailedImageItem  `json:"failed_items,omitempty"`
+	PNGLossyFiles   []string           `json:"png_lossy_files,omitempty"`
+	PNGLossyIndexes []int              `json:"png_lossy_indexes,omitempty"`
 }
 
 // ScreenshotJobResponse 表示截图后台任务的创建结果、状态查询结果和最终产出。
 type ScreenshotJobResponse struct {
-	OK              bool            `json:"ok"`
-	JobID           string          `json:"job_id,omitempty"`
-	Status          string          `json:"status,omitempty"`
-	Mode            string          `json:"mode,omitempty"`
-	Output          string          `json:"output,omitempty"`
-	DownloadURL     string          `json:"download_url,omitempty"`
-	Error           string          `json:"error,omitempty"`
-	Logs            string          `json:"logs,omitempty"`
-	LogEntries      []LogEntry      `json:"log_entries,omitempty"`
-	Progress        *TaskProgress   `json:"progress,omitempty"`
-	LinkItems       []ImageLinkItem `json:"link_items,omitempty"`
-	PNGLossyFiles   []string        `json:"png_lossy_files,omitempty"`
-	PNGLossyIndexes []int           `json:"png_lossy_indexes,omitempty"`
+	OK            bool            `json:"ok"`
+	JobID         string          `json:"job_id,omitempty"`
+	Status        string          `json:"status,omitempty"`
+	Mode          string          `json:"mode,omitempty"`
+	Output        string          `json:"output,omitempty"`
+	DownloadURL   string          `json:"download_url,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	Logs          string          `json:"logs,omitempty"`
+	LogEntries    []LogEntry      `json:"log_entries,omitempty"`
+	Progress      *TaskProgress   `json:"progress,omitempty"`
+	QueuePosition int             `json:"queue_position,omitempty"`
+	LinkItems     []ImageLinkItem `json:"link_items,omitempty"`
+	// FailedItems 列出重试后仍上传失败的截图；这些截图会保留在服务端，可通过 retry-upload 接口重新上传。
+	FailedItems     []FailedImageItem `json:"failed_items,omitempty"`
+	PNGLossyFiles   []string          `json:"png_lossy_files,omitempty"`
+	PNGLossyIndexes []int             `json:"png_lossy_indexes,omitempty"`
+	// Template 是提交时选择的发布描述模板，Description 是任务成功后用它渲染出的描述。
+	Template    string `json:"template,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 // TorrentJobResponse 表示制种后台任务的创建结果、状态查询结果和最终下载地址。
 type TorrentJobResponse struct {
-	OK          bool          `json:"ok"`
-	JobID       string        `json:"job_id,omitempty"`
-	Status      string        `json:"status,omitempty"`
-	Output      string        `json:"output,omitempty"`
-	DownloadURL string        `json:"download_url,omitempty"`
-	Error       string        `json:"error,omitempty"`
-	Logs        string        `json:"logs,omitempty"`
-	LogEntries  []LogEntry    `json:"log_entries,omitempty"`
-	Progress    *TaskProgress `json:"progress,omitempty"`
+	OK            bool          `json:"ok"`
+	JobID         string        `json:"job_id,omitempty"`
+	Status        string        `json:"status,omitempty"`
+	Output        string        `json:"output,omitempty"`
+	DownloadURL   string        `json:"download_url,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Logs          string        `json:"logs,omitempty"`
+	LogEntries    []LogEntry    `json:"log_entries,omitempty"`
+	Progress      *TaskProgress `json:"progress,omitempty"`
+	QueuePosition int           `json:"queue_position,omitempty"`
+	// InfoHash 是生成种子的 v1 info hash；InfoHashV2 只在 v2 或混合种子中存在。
+	InfoHash   string `json:"info_hash,omitempty"`
+	InfoHashV2 string `json:"info_hash_v2,omitempty"`
+	// Template 是提交时选择的发布描述模板，Description 是任务成功后用它渲染出的描述。
+	Template    string `json:"template,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 // InfoJobResponse 表示信息类后台任务的创建结果、状态查询结果和最终输出。
 type InfoJobResponse struct {
-	OK         bool          `json:"ok"`
-	JobID      string        `json:"job_id,omitempty"`
-	Status     string        `json:"status,omitempty"`
-	Kind       string        `json:"kind,omitempty"`
-	Output     string        `json:"output,omitempty"`
-	Error      string        `json:"error,omitempty"`
-	Logs       string        `json:"logs,omitempty"`
-	LogEntries []LogEntry    `json:"log_entries,omitempty"`
-	Progress   *TaskProgress `json:"progress,omitempty"`
-}
-
-// PathItem 表示路径联想接口返回的一条候选路径。
+	OK     bool   `json:"ok"`
+	JobID  string `json:"job_id,omitempty"`
+	Status string `json:"status,omitempty"`
+	Kind   string `json:"kind,omitempty"`
+	Output string `json:"output,omitempty"`
+	// BDInfo 是 BDInfo 任务从完整报告解析出的结构化结果，与 Output 中的文本同时返回。
+	BDInfo *bdinfo.Report `json:"bdinfo,omitempty"`
+	// MediaInfo 是 MediaInfo 任务从 JSON 输出提取的规范化摘要；OutputFormat 是 Output 使用的输出模式。
+	MediaInfo     *mediainfo.Summary `json:"mediainfo,omitempty"`
+	OutputFormat  string             `json:"output_format,omitempty"`
+	Error         string             `json:"error,omitempty"`
+	Logs          string             `json:"logs,omitempty"`
+	LogEntries    []LogEntry         `json:"log_entries,omitempty"`
+	Progress      *TaskProgress      `json:"progress,omitempty"`
+	QueuePosition int                `json:"queue_position,omitempty"`
+	// Template 是提交时选择的发布描述模板，Description 是任务成功后用它渲染出的描述。
+	Template    string `json:"template,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ReleaseStep 表示发布包任务中一个步骤的状态；Progress 只在步骤运行时返回。
+type ReleaseStep struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Progress *TaskProgress `json:"progress,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// ReleaseFile 表示发布包中一个可下载的产物文件。
+type ReleaseFile struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// ReleaseJobResponse 表示发布包后台任务的创建结果、各步骤状态和最终产物。
+type ReleaseJobResponse struct {
+	OK     bool          `json:"ok"`
+	JobID  string        `json:"job_id,omitempty"`
+	Status string        `json:"status,omitempty"`
+	Steps  []ReleaseStep `json:"steps,omitempty"`
+	// InfoKind 是信息步骤使用的工具（mediainfo 或 bdinfo），Info 是其输出原文。
+	InfoKind      string             `json:"info_kind,omitempty"`
+	Info          string             `json:"info,omitempty"`
+	MediaInfo     *mediainfo.Summary `json:"mediainfo,omitempty"`
+	BDInfo        *bdinfo.Report     `json:"bdinfo,omitempty"`
+	LinkItems     []ImageLinkItem    `json:"link_items,omitempty"`
+	InfoHash      string             `json:"info_hash,omitempty"`
+	InfoHashV2    string             `json:"info_hash_v2,omitempty"`
+	Files         []ReleaseFile      `json:"files,omitempty"`
+	Template      string             `json:"template,omitempty"`
+	Description   string             `json:"description,omitempty"`
+	Error         string             `json:"error,omitempty"`
+	Logs          string             `json:"logs,omitempty"`
+	LogEntries    []LogEntry         `json:"log_entries,omitempty"`
+	Progress      *TaskProgress      `json:"progress,omitempty"`
+	QueuePosition int                `json:"queue_position,omitempty"`
+}
+
+// JobStatusEvent 表示任务事件流中的一次状态变化；终态事件之后服务端会结束事件流。
+type JobStatusEvent struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// JobResetEvent 表示续传位置之后的部分事件已被丢弃；客户端应重新拉取任务快照，再从 FirstEventID 起继续接收事件。
+type JobResetEvent struct {
+	FirstEventID int64 `json:"first_event_id"`
+}
+
+// JobSummary 表示任务列表中的一条精简任务记录，不包含日志和完整输出。
+type JobSummary struct {
+	JobID         string            `json:"job_id"`
+	Type          string            `json:"type"`
+	Kind          string            `json:"kind"`
+	Mode          string            `json:"mode,omitempty"`
+	Status        string            `json:"status"`
+	InputPath     string            `json:"input_path,omitempty"`
+	Options       map[string]string `json:"options,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	CreatedAt     string            `json:"created_at,omitempty"`
+	StartedAt     string            `json:"started_at,omitempty"`
+	CompletedAt   string            `json:"completed_at,omitempty"`
+	DurationMS    int64             `json:"duration_ms,omitempty"`
+	ResultSize    int64             `json:"result_size,omitempty"`
+	ResultCount   int               `json:"result_count,omitempty"`
+	URL           string            `json:"url,omitempty"`
+	DownloadURL   string            `json:"download_url,omitempty"`
+	QueuePosition int               `json:"queue_position,omitempty"`
+}
+
+// JobListResponse 表示任务列表接口的分页 JSON 响应。
+type JobListResponse struct {
+	OK     bool         `json:"ok"`
+	Jobs   []JobSummary `json:"jobs"`
+	Total  int          `json:"total"`
+	Offset int          `json:"offset"`
+	Limit  int          `json:"limit"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// PathItem 表示路径联想接口返回的一条候选路径；媒体根目录条目会带上配置的标签。
 type PathItem struct {
 	Path     string `json:"path"`
+	Label    string `json:"label,omitempty"`
 	IsDir    bool   `json:"isDir,omitempty"`
 	Size     int64  `json:"size,omitempty"`
 	Duration string `json:"duration,omitempty"`
@@ -93,9 +216,85 @@ type PathItem struct {
 
 // PathResponse 表示路径联想接口的 JSON 响应。
 type PathResponse struct {
-	OK    bool       `json:"ok"`
-	Root  string     `json:"root,omitempty"`
-	Roots []string   `json:"roots,omitempty"`
-	Items []PathItem `json:"items,omitempty"`
-	Error string     `json:"error,omitempty"`
+	OK         bool        `json:"ok"`
+	Root       string      `json:"root,omitempty"`
+	Roots      []string    `json:"roots,omitempty"`
+	NamedRoots []MediaRoot `json:"named_roots,omitempty"`
+	Items      []PathItem  `json:"items,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// MediaRoot 表示一个带标签和权限标记的媒体根目录。
+type MediaRoot struct {
+	Label        string `json:"label"`
+	Path         string `json:"path"`
+	ReadOnly     bool   `json:"read_only"`
+	AllowTorrent bool   `json:"allow_torrent"`
+	AllowUpload  bool   `json:"allow_upload"`
+}
+
+// BlurayPlaylistItem 表示蓝光播放列表清单中的一个播放列表；Path 可以直接作为 BDInfo 或截图接口的 path 参数。
+type BlurayPlaylistItem struct {
+	Playlist          string   `json:"playlist"`
+	Path              string   `json:"path"`
+	Duration          string   `json:"duration,omitempty"`
+	DurationSeconds   float64  `json:"duration_seconds"`
+	Clips             []string `json:"clips"`
+	Size              int64    `json:"size,omitempty"`
+	Chapters          int      `json:"chapters"`
+	AudioLanguages    []string `json:"audio_languages"`
+	SubtitleLanguages []string `json:"subtitle_languages"`
+	MainFeature       bool     `json:"main_feature"`
+	Suspicious        bool     `json:"suspicious,omitempty"`
+	Duplicates        int      `json:"duplicates,omitempty"`
+}
+
+// BlurayPlaylistResponse 表示蓝光播放列表清单接口的 JSON 响应，播放列表按正片可能性从高到低排列。
+type BlurayPlaylistResponse struct {
+	OK        bool                 `json:"ok"`
+	Root      string               `json:"root,omitempty"`
+	Playlists []BlurayPlaylistItem `json:"playlists,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// ResultCacheItem 表示结果缓存中的一条条目摘要，不含输出正文。
+type ResultCacheItem struct {
+	Key        string `json:"key"`
+	Kind       string `json:"kind"`
+	Mode       string `json:"mode,omitempty"`
+	Source     string `json:"source"`
+	OutputSize int    `json:"output_size"`
+	BDInfo     bool   `json:"bdinfo,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+}
+
+// ResultCacheResponse 表示结果缓存管理接口的 JSON 响应；Purged 只在清理请求中返回。
+type ResultCacheResponse struct {
+	OK      bool              `json:"ok"`
+	Entries []ResultCacheItem `json:"entries,omitempty"`
+	Purged  *int              `json:"purged,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// TemplateListResponse 表示发布描述模板列表接口的 JSON 响应。
+type TemplateListResponse struct {
+	OK        bool               `json:"ok"`
+	Templates []release.Template `json:"templates,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// ScreenshotPresetListResponse 表示截图预设列表接口的 JSON 响应。
+type ScreenshotPresetListResponse struct {
+	OK      bool                `json:"ok"`
+	Presets []screenshot.Preset `json:"presets,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// JobDescriptionResponse 表示按需渲染任务发布描述接口的 JSON 响应。
+type JobDescriptionResponse struct {
+	OK          bool   `json:"ok"`
+	JobID       string `json:"job_id,omitempty"`
+	Template    string `json:"template,omitempty"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
 }
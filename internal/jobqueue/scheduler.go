@@ -0,0 +1,144 @@
+// Package jobqueue 提供带分类并发上限和优先级的后台任务调度队列。
+
+package jobqueue
+
+import (
+	"sort"
+	"sync"
+)
+
+// Scheduler 会按优先级和提交顺序排队任务，并在分类并发上限和全局上限允许时启动任务。
+type Scheduler struct {
+	mu      sync.Mutex
+	total   int
+	limits  map[string]int
+	running map[string]int
+	active  int
+	waiting []*ticket
+	seq     uint64
+}
+
+type ticket struct {
+	id       string
+	class    string
+	priority int
+	seq      uint64
+	run      func()
+}
+
+// New 会创建调度器；total 和 limits 中小于等于 0 的值表示不限制。
+func New(total int, limits map[string]int) *Scheduler {
+	copied := make(map[string]int, len(limits))
+	for class, limit := range limits {
+		copied[class] = limit
+	}
+	return &Scheduler{
+		total:   total,
+		limits:  copied,
+		running: make(map[string]int),
+	}
+}
+
+// Submit 会把任务加入队列；priority 越大越先启动，同优先级按提交顺序先进先出。
+func (s *Scheduler) Submit(id, class string, priority int, run func()) {
+	s.mu.Lock()
+	s.seq++
+	item := &ticket{
+		id:       id,
+		class:    class,
+		priority: priority,
+		seq:      s.seq,
+		run:      run,
+	}
+	index := sort.Search(len(s.waiting), func(i int) bool {
+		return s.waiting[i].priority < priority
+	})
+	s.waiting = append(s.waiting, nil)
+	copy(s.waiting[index+1:], s.waiting[index:])
+	s.waiting[index] = item
+	started := s.dispatchLocked()
+	s.mu.Unlock()
+
+	s.start(started)
+}
+
+// Remove 会把仍在排队的任务移出队列；任务已启动或不存在时返回 false。
+func (s *Scheduler) Remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for index, item := range s.waiting {
+		if item.id != id {
+			continue
+		}
+		s.waiting = append(s.waiting[:index], s.waiting[index+1:]...)
+		return true
+	}
+	return false
+}
+
+// Position 返回任务在等待队列中的位置，从 1 开始；任务不在队列中时返回 0。
+func (s *Scheduler) Position(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for index, item := range s.waiting {
+		if item.id == id {
+			return index + 1
+		}
+	}
+	return 0
+}
+
+// dispatchLocked 会按队列顺序挑出可以立即启动的任务；调用方需持有锁。
+func (s *Scheduler) dispatchLocked() []*ticket {
+	var started []*ticket
+	remaining := s.waiting[:0]
+	for _, item := range s.waiting {
+		if s.canStartLocked(item.class) {
+			s.running[item.class]++
+			s.active++
+			started = append(started, item)
+			continue
+		}
+		remaining = append(remaining, item)
+	}
+	for index := len(remaining); index < len(s.waiting); index++ {
+		s.waiting[index] = nil
+	}
+	s.waiting = remaining
+	return started
+}
+
+// canStartLocked 会判断指定分类当前是否还有空闲并发槽位；调用方需持有锁。
+func (s *Scheduler) canStartLocked(class string) bool {
+	if s.total > 0 && s.active >= s.total {
+		return false
+	}
+	limit := s.limits[class]
+	return limit <= 0 || s.running[class] < limit
+}
+
+// start 会在独立 goroutine 中运行已出队的任务，并在结束后释放槽位、继续调度。
+func (s *Scheduler) start(items []*ticket) {
+	for _, item := range items {
+		go func(item *ticket) {
+			defer s.finish(item.class)
+			item.run()
+		}(item)
+	}
+}
+
+// finish 会释放任务占用的槽位，并尝试启动后续排队任务。
+func (s *Scheduler) finish(class string) {
+	s.mu.Lock()
+	s.running[class]--
+	if s.running[class] <= 0 {
+		delete(s.running, class)
+	}
+	s.active--
+	started := s.dispatchLocked()
+	s.mu.Unlock()
+
+	s.start(started)
+}
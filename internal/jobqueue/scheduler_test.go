@@ -0,0 +1,93 @@
+package jobqueue
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSchedulerHonoursClassLimitAndQueuePosition 验证分类并发上限生效，且排队任务能报告先进先出的位置。
+func TestSchedulerHonoursClassLimitAndQueuePosition(t *testing.T) {
+	scheduler := New(0, map[string]int{"screenshot": 1})
+	release := make(chan struct{})
+	started := make(chan string, 3)
+
+	for _, id := range []string{"a", "b", "c"} {
+		id := id
+		scheduler.Submit(id, "screenshot", 0, func() {
+			started <- id
+			<-release
+		})
+	}
+
+	if got := waitStarted(t, started); got != "a" {
+		t.Fatalf("first started = %q, want a", got)
+	}
+	if pos := scheduler.Position("b"); pos != 1 {
+		t.Fatalf("Position(b) = %d, want 1", pos)
+	}
+	if pos := scheduler.Position("c"); pos != 2 {
+		t.Fatalf("Position(c) = %d, want 2", pos)
+	}
+	if !scheduler.Remove("b") {
+		t.Fatal("Remove(b) = false, want true for queued job")
+	}
+	if pos := scheduler.Position("c"); pos != 1 {
+		t.Fatalf("Position(c) after remove = %d, want 1", pos)
+	}
+
+	release <- struct{}{}
+	if got := waitStarted(t, started); got != "c" {
+		t.Fatalf("second started = %q, want c", got)
+	}
+	close(release)
+}
+
+// TestSchedulerStartsHigherPriorityFirstUnderGlobalLimit 验证全局上限占满时高优先级任务会先于更早提交的低优先级任务启动。
+func TestSchedulerStartsHigherPriorityFirstUnderGlobalLimit(t *testing.T) {
+	scheduler := New(1, nil)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	order := make([]string, 0, 3)
+	started := make(chan string, 3)
+
+	submit := func(id, class string, priority int) {
+		scheduler.Submit(id, class, priority, func() {
+			mu.Lock()
+			order = append(order, id)
+			mu.Unlock()
+			started <- id
+			<-release
+		})
+	}
+
+	submit("shot-1", "screenshot", 0)
+	waitStarted(t, started)
+	submit("shot-2", "screenshot", 0)
+	submit("info", "mediainfo", 10)
+
+	if pos := scheduler.Position("info"); pos != 1 {
+		t.Fatalf("Position(info) = %d, want 1", pos)
+	}
+
+	release <- struct{}{}
+	if got := waitStarted(t, started); got != "info" {
+		t.Fatalf("next started = %q, want info", got)
+	}
+	release <- struct{}{}
+	if got := waitStarted(t, started); got != "shot-2" {
+		t.Fatalf("last started = %q, want shot-2", got)
+	}
+	close(release)
+}
+
+func waitStarted(t *testing.T, started <-chan string) string {
+	t.Helper()
+	select {
+	case id := <-started:
+		return id
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for job start")
+		return ""
+	}
+}
@@ -0,0 +1,116 @@
+// Package jobstore 提供后台任务元数据、状态、输出和日志的持久化存储。
+
+package jobstore
+
+import (
+	"time"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/mediainfo"
+)
+
+// Type 常量描述持久化记录对应的后台任务类别。
+const (
+	TypeInfo       = "info"
+	TypeScreenshot = "screenshot"
+	TypeTorrent    = "torrent"
+	TypeRelease    = "release"
+)
+
+// StatusInterrupted 表示任务在服务退出时仍未结束，重启后已无法继续执行。
+const StatusInterrupted = "interrupted"
+
+const interruptedMessage = "服务重启，任务已中断。"
+
+// Record 表示一条可被持久化和恢复的后台任务快照。
+type Record struct {
+	ID              string             `json:"id"`
+	Type            string             `json:"type"`
+	Kind            string             `json:"kind,omitempty"`
+	Mode            string             `json:"mode,omitempty"`
+	InputPath       string             `json:"input_path,omitempty"`
+	Options         map[string]string  `json:"options,omitempty"`
+	Status          string             `json:"status"`
+	Output          string             `json:"output,omitempty"`
+	BDInfo          *bdinfo.Report     `json:"bdinfo,omitempty"`
+	MediaInfo       *mediainfo.Summary `json:"mediainfo,omitempty"`
+	DownloadURL     string             `json:"download_url,omitempty"`
+	OutputPath      string             `json:"output_path,omitempty"`
+	Filename        string             `json:"filename,omitempty"`
+	Template        string             `json:"template,omitempty"`
+	Description     string             `json:"description,omitempty"`
+	Steps           []ReleaseStep      `json:"steps,omitempty"`
+	Error           string             `json:"error,omitempty"`
+	LinkItems       []ImageLink        `json:"link_items,omitempty"`
+	FailedItems     []FailedImage      `json:"failed_items,omitempty"`
+	PNGLossyFiles   []string           `json:"png_lossy_files,omitempty"`
+	PNGLossyIndexes []int              `json:"png_lossy_indexes,omitempty"`
+	Progress        *Progress          `json:"progress,omitempty"`
+	LogEntries      []LogEntry         `json:"log_entries,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+	StartedAt       time.Time          `json:"started_at,omitempty"`
+	CompletedAt     time.Time          `json:"completed_at,omitempty"`
+}
+
+// LogEntry 表示记录中保存的一条带时间戳的任务日志。
+type LogEntry struct {
+	Timestamp string `json:"timestamp,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// ImageLink 表示记录中保存的一张已上传图片及其元信息。
+type ImageLink struct {
+	URL          string `json:"url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+}
+
+// FailedImage 表示记录中保存的一张上传失败的截图及失败原因。
+type FailedImage struct {
+	Filename string `json:"filename,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Progress 表示记录中保存的任务进度快照。
+type Progress struct {
+	Percent       float64 `json:"percent,omitempty"`
+	Stage         string  `json:"stage,omitempty"`
+	Detail        string  `json:"detail,omitempty"`
+	Current       int     `json:"current,omitempty"`
+	Total         int     `json:"total,omitempty"`
+	Indeterminate bool    `json:"indeterminate,omitempty"`
+	ETAMS         int64   `json:"eta_ms,omitempty"`
+}
+
+// ReleaseStep 表示记录中保存的发布包任务单个步骤的状态。
+type ReleaseStep struct {
+	Name     string    `json:"name"`
+	Status   string    `json:"status"`
+	Progress *Progress `json:"progress,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Finished 会判断记录是否已经处于不会再变化的结束态。
+func (r Record) Finished() bool {
+	return !r.CompletedAt.IsZero()
+}
+
+// Interrupt 会把未结束的记录标记为 interrupted，供服务重启后恢复历史时使用。
+func Interrupt(record Record, now time.Time) Record {
+	if record.Finished() {
+		return record
+	}
+	record.Status = StatusInterrupted
+	record.Error = interruptedMessage
+	record.LogEntries = append(record.LogEntries, LogEntry{
+		Timestamp: now.UTC().Format(time.RFC3339Nano),
+		Message:   "[任务] " + interruptedMessage,
+	})
+	record.UpdatedAt = now
+	record.CompletedAt = now
+	return record
+}
@@ -0,0 +1,181 @@
+// Package jobstore 实现任务记录的内存仓库和按任务拆分文件的持久化仓库。
+
+package jobstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"minfo/internal/system"
+)
+
+const (
+	// recordDirName 是文件型任务仓库在数据目录中保存记录的子目录，每条记录对应其中一个 <id>.json 文件。
+	recordDirName = "jobs"
+	recordExt     = ".json"
+)
+
+var recordIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Repository 描述后台任务记录的存储后端，可替换为内存或文件实现。
+type Repository interface {
+	Load() ([]Record, error)
+	Save(record Record) error
+	Delete(id string) error
+}
+
+// MemoryRepository 是仅保存在进程内存中的任务仓库，服务重启后记录会丢失。
+type MemoryRepository struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryRepository 会创建一个空的内存任务仓库。
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{records: make(map[string]Record)}
+}
+
+// Load 返回当前仓库中的全部记录，并按创建时间排序。
+func (m *MemoryRepository) Load() ([]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sortedRecords(m.records), nil
+}
+
+// Save 会新增或覆盖一条任务记录。
+func (m *MemoryRepository) Save(record Record) error {
+	if record.ID == "" {
+		return errors.New("job record id is empty")
+	}
+	m.mu.Lock()
+	m.records[record.ID] = record
+	m.mu.Unlock()
+	return nil
+}
+
+// Delete 会删除指定任务记录；记录不存在时直接返回。
+func (m *MemoryRepository) Delete(id string) error {
+	m.mu.Lock()
+	delete(m.records, id)
+	m.mu.Unlock()
+	return nil
+}
+
+// FileRepository 把每条任务记录保存为数据目录下 jobs 子目录中的独立 JSON 文件，保存一条记录只会重写它自己的文件。
+type FileRepository struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// OpenFileRepository 会在 dir 下打开或创建文件型任务仓库。
+func OpenFileRepository(dir string) (*FileRepository, error) {
+	repo := &FileRepository{dir: filepath.Join(dir, recordDirName)}
+	if err := os.MkdirAll(repo.dir, 0o755); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// Path 返回仓库保存记录文件的目录。
+func (f *FileRepository) Path() string {
+	return f.dir
+}
+
+// Load 会读取记录目录中的全部记录，并按创建时间排序。
+func (f *FileRepository) Load() ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]Record, len(entries))
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), recordExt)
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), recordExt) || !recordIDPattern.MatchString(id) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var record Record
+		if err := json.Unmarshal(content, &record); err != nil {
+			return nil, fmt.Errorf("parse job record %s: %w", id, err)
+		}
+		if record.ID != id {
+			continue
+		}
+		records[id] = record
+	}
+	return sortedRecords(records), nil
+}
+
+// Save 会新增或覆盖一条任务记录，只重写这条记录对应的文件。
+func (f *FileRepository) Save(record Record) error {
+	if err := validateRecordID(record.ID); err != nil {
+		return err
+	}
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return system.WriteFileAtomicBytes(f.recordPath(record.ID), 0o600, content)
+}
+
+// Delete 会删除指定任务记录的文件；记录不存在时直接返回。
+func (f *FileRepository) Delete(id string) error {
+	if err := validateRecordID(id); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(f.recordPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// recordPath 返回任务记录对应的文件路径。
+func (f *FileRepository) recordPath(id string) string {
+	return filepath.Join(f.dir, id+recordExt)
+}
+
+// validateRecordID 会确认任务 ID 可以安全地用作文件名。
+func validateRecordID(id string) error {
+	if id == "" {
+		return errors.New("job record id is empty")
+	}
+	if !recordIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid job record id %q", id)
+	}
+	return nil
+}
+
+// sortedRecords 会把记录表转换成按创建时间和 ID 排序的稳定列表。
+func sortedRecords(records map[string]Record) []Record {
+	result := make([]Record, 0, len(records))
+	for _, record := range records {
+		result = append(result, record)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
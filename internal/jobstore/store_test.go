@@ -0,0 +1,95 @@
+package jobstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileRepositoryPersistsRecordsAcrossReopen 验证文件仓库写入的记录在重新打开后仍可读取。
+func TestFileRepositoryPersistsRecordsAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenFileRepository(dir)
+	if err != nil {
+		t.Fatalf("OpenFileRepository() error = %v", err)
+	}
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.Save(Record{ID: "b", Type: TypeInfo, Status: "running", CreatedAt: createdAt.Add(time.Minute)}); err != nil {
+		t.Fatalf("Save(b) error = %v", err)
+	}
+	if err := repo.Save(Record{ID: "a", Type: TypeTorrent, Status: "succeeded", CreatedAt: createdAt, CompletedAt: createdAt}); err != nil {
+		t.Fatalf("Save(a) error = %v", err)
+	}
+
+	reopened, err := OpenFileRepository(dir)
+	if err != nil {
+		t.Fatalf("OpenFileRepository() reopen error = %v", err)
+	}
+	records, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(records) != 2 || records[0].ID != "a" || records[1].ID != "b" {
+		t.Fatalf("records = %#v, want a,b ordered by creation time", records)
+	}
+
+	if err := reopened.Delete("a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	again, err := OpenFileRepository(dir)
+	if err != nil {
+		t.Fatalf("OpenFileRepository() third open error = %v", err)
+	}
+	records, _ = again.Load()
+	if len(records) != 1 || records[0].ID != "b" {
+		t.Fatalf("records after delete = %#v, want only b", records)
+	}
+}
+
+// TestFileRepositorySavesOnlyChangedRecordFile 验证保存一条记录只会重写它自己的文件，不会改动其他记录。
+func TestFileRepositorySavesOnlyChangedRecordFile(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := OpenFileRepository(dir)
+	if err != nil {
+		t.Fatalf("OpenFileRepository() error = %v", err)
+	}
+	if err := repo.Save(Record{ID: "a", Status: "succeeded"}); err != nil {
+		t.Fatalf("Save(a) error = %v", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	pathA := filepath.Join(dir, recordDirName, "a.json")
+	if err := os.Chtimes(pathA, past, past); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	if err := repo.Save(Record{ID: "b", Status: "running"}); err != nil {
+		t.Fatalf("Save(b) error = %v", err)
+	}
+	info, err := os.Stat(pathA)
+	if err != nil || !info.ModTime().Equal(past) {
+		t.Fatalf("a.json modified by Save(b): %v, %v", info, err)
+	}
+	if err := repo.Save(Record{ID: "../escape"}); err == nil {
+		t.Fatal("Save() with path-like id succeeded, want error")
+	}
+}
+
+// TestInterruptMarksOnlyUnfinishedRecords 验证只有未结束的记录会在恢复时被标记为 interrupted。
+func TestInterruptMarksOnlyUnfinishedRecords(t *testing.T) {
+	now := time.Now()
+
+	running := Interrupt(Record{ID: "run", Status: "running"}, now)
+	if running.Status != StatusInterrupted || running.CompletedAt.IsZero() || running.Error == "" {
+		t.Fatalf("running record = %#v, want interrupted with completion time", running)
+	}
+	if len(running.LogEntries) != 1 {
+		t.Fatalf("LogEntries = %#v, want one interruption entry", running.LogEntries)
+	}
+
+	done := Record{ID: "done", Status: "succeeded", CompletedAt: now.Add(-time.Hour)}
+	if got := Interrupt(done, now); got.Status != "succeeded" || !got.CompletedAt.Equal(done.CompletedAt) {
+		t.Fatalf("finished record = %#v, want unchanged", got)
+	}
+}
@@ -0,0 +1,87 @@
+// Package media 提供输入路径的媒体根目录白名单与权限校验。
+
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPathNotAllowed 表示输入路径在解析符号链接后不在任何媒体根目录之内，或所在根目录不允许请求的操作。
+var ErrPathNotAllowed = errors.New("path is not allowed")
+
+// CheckAllowedPath 会在解析 path 和各根目录的符号链接后，确认 path 位于某个根目录之内；否则返回包装了 ErrPathNotAllowed 的错误。
+func CheckAllowedPath(path string, roots []string) error {
+	_, err := findAllowedRoot(path, rootsFromPaths(roots))
+	return err
+}
+
+// CheckInputPermission 会确认用户输入路径所在的媒体根目录允许 permission 对应的操作；ISO 虚拟路径按 ISO 文件本身校验，不会挂载镜像。
+func CheckInputPermission(input string, permission Permission) error {
+	cleaned := strings.TrimSpace(strings.Trim(input, "\""))
+	if isoPath, _, ok := parseVirtualISOPath(cleaned); ok {
+		cleaned = isoPath
+	}
+	return checkRootPermission(filepath.Clean(cleaned), ConfiguredRoots(), permission)
+}
+
+// checkRootPermission 会确认 path 位于某个根目录之内，且该根目录允许执行 permission 对应的操作。
+func checkRootPermission(path string, roots []Root, permission Permission) error {
+	root, err := findAllowedRoot(path, roots)
+	if err != nil {
+		return err
+	}
+	if !root.Allows(permission) {
+		return fmt.Errorf("%w: media root %q does not allow %s", ErrPathNotAllowed, root.Label, permission)
+	}
+	return nil
+}
+
+// findAllowedRoot 返回解析符号链接后包含 path 的最深一级根目录。
+// path 不存在时按其最深的已存在上级目录解析，因此根目录外的路径无论是否存在都返回同一个 ErrPathNotAllowed，不会泄露存在性。
+func findAllowedRoot(path string, roots []Root) (Root, error) {
+	resolved, err := resolveExistingPrefix(filepath.Clean(path))
+	if err != nil {
+		return Root{}, fmt.Errorf("%w: %s is outside MEDIA_ROOTS", ErrPathNotAllowed, path)
+	}
+
+	var matched Root
+	matchedLength := -1
+	for _, root := range roots {
+		resolvedRoot, err := filepath.EvalSymlinks(root.Path)
+		if err != nil {
+			continue
+		}
+		if isSubpath(resolvedRoot, resolved) && len(resolvedRoot) > matchedLength {
+			matched = root
+			matchedLength = len(resolvedRoot)
+		}
+	}
+	if matchedLength < 0 {
+		return Root{}, fmt.Errorf("%w: %s is outside MEDIA_ROOTS", ErrPathNotAllowed, path)
+	}
+	return matched, nil
+}
+
+// resolveExistingPrefix 会解析 path 中最深的已存在部分的符号链接，再拼回其余尚不存在的路径段。
+func resolveExistingPrefix(path string) (string, error) {
+	existing, rest := path, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", err
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
@@ -0,0 +1,83 @@
+// Package media 验证输入路径的媒体根目录白名单校验。
+
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResolveInputPathWithinRejectsPathsOutsideRoots 验证根目录内的路径可以通过，根目录外的路径和指向外部的符号链接无论是否存在都会被拒绝。
+func TestResolveInputPathWithinRejectsPathsOutsideRoots(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "media")
+	outside := filepath.Join(base, "secret")
+	for _, dir := range []string{root, outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error: %v", err)
+		}
+	}
+	movie := filepath.Join(root, "movie.mkv")
+	secret := filepath.Join(outside, "shadow")
+	iso := filepath.Join(outside, "disc.iso")
+	for _, path := range []string{movie, secret, iso} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+	escape := filepath.Join(root, "escape")
+	if err := os.Symlink(outside, escape); err != nil {
+		t.Fatalf("Symlink() error: %v", err)
+	}
+
+	roots := rootsFromPaths([]string{root})
+	if got, _, err := resolveInputPathWithin(context.Background(), movie, roots, PermissionRead); err != nil || got != movie {
+		t.Fatalf("resolveInputPathWithin(movie) = %q, %v; want %q, nil", got, err, movie)
+	}
+
+	for _, input := range []string{
+		secret,
+		filepath.Join(escape, "shadow"),
+		filepath.Join(root, "..", "secret", "shadow"),
+		"ISO:" + iso + "!/BDMV",
+		filepath.Join(outside, "missing.mkv"),
+		filepath.Join(escape, "missing", "movie.mkv"),
+		"ISO:" + filepath.Join(outside, "missing.iso") + "!/BDMV",
+	} {
+		if _, _, err := resolveInputPathWithin(context.Background(), input, roots, PermissionRead); !errors.Is(err, ErrPathNotAllowed) {
+			t.Fatalf("resolveInputPathWithin(%q) error = %v, want ErrPathNotAllowed", input, err)
+		}
+	}
+
+	missing := filepath.Join(root, "missing.mkv")
+	if _, _, err := resolveInputPathWithin(context.Background(), missing, roots, PermissionRead); err == nil || errors.Is(err, ErrPathNotAllowed) {
+		t.Fatalf("resolveInputPathWithin(%q) error = %v, want path not found", missing, err)
+	}
+}
+
+// TestCheckAllowedPathFollowsSymlinkedRoot 验证根目录本身是符号链接时，会按解析后的真实目录判断包含关系。
+func TestCheckAllowedPathFollowsSymlinkedRoot(t *testing.T) {
+	base := t.TempDir()
+	real := filepath.Join(base, "real")
+	if err := os.MkdirAll(real, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	link := filepath.Join(base, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatalf("Symlink() error: %v", err)
+	}
+	file := filepath.Join(real, "movie.mkv")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	if err := CheckAllowedPath(file, []string{link}); err != nil {
+		t.Fatalf("CheckAllowedPath() error = %v, want nil", err)
+	}
+	if err := CheckAllowedPath(filepath.Join(link, "movie.mkv"), []string{real}); err != nil {
+		t.Fatalf("CheckAllowedPath(via link) error = %v, want nil", err)
+	}
+}
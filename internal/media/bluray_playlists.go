@@ -0,0 +1,157 @@
+// Package media 提供不依赖 BDInfo 的蓝光播放列表清单，覆盖 BDMV 目录、蓝光 ISO 和 ISO 虚拟路径。
+
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"minfo/internal/media/isofs"
+)
+
+// BlurayPlaylist 表示清单中的一个播放列表。
+type BlurayPlaylist struct {
+	BlurayTitle
+	// Path 是该 MPLS 文件可直接传回 BDInfo 或截图接口的输入路径；ISO 内的播放列表使用 ISO 虚拟路径。
+	Path string
+	// DurationText 是与路径联想接口相同格式（H:MM:SS）的时长。
+	DurationText string
+	// MainFeature 表示该列表被识别为最可能的正片。
+	MainFeature bool
+}
+
+// BlurayPlaylistListing 表示一次播放列表清单的结果。
+type BlurayPlaylistListing struct {
+	// Root 是蓝光光盘根目录对应的输入路径。
+	Root      string
+	Playlists []BlurayPlaylist
+}
+
+// ListBlurayPlaylists 列出输入路径对应蓝光光盘中的全部播放列表，并按正片可能性排序。
+//
+// 输入可以是光盘根目录、BDMV/PLAYLIST/STREAM 目录、单个 MPLS 文件、蓝光 ISO 或 ISO 虚拟路径；
+// ISO 优先直接解析镜像，无法解析时才回退为挂载。
+func ListBlurayPlaylists(ctx context.Context, input string) (BlurayPlaylistListing, error) {
+	return listBlurayPlaylistsWithin(ctx, input, ConfiguredRoots())
+}
+
+// listBlurayPlaylistsWithin 按给定的媒体根目录白名单列出蓝光播放列表。
+func listBlurayPlaylistsWithin(ctx context.Context, input string, roots []Root) (BlurayPlaylistListing, error) {
+	cleaned := strings.TrimSpace(strings.Trim(input, "\""))
+	if cleaned == "" {
+		return BlurayPlaylistListing{}, fmt.Errorf("missing path")
+	}
+
+	isoPath, inner := "", "/"
+	if isVirtualISOPath(cleaned) {
+		isoPath, inner, _ = parseVirtualISOPath(cleaned)
+	} else {
+		cleaned = filepath.Clean(cleaned)
+	}
+
+	target := cleaned
+	if isoPath != "" {
+		target = isoPath
+	}
+	if err := checkRootPermission(target, roots, PermissionRead); err != nil {
+		return BlurayPlaylistListing{}, err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return BlurayPlaylistListing{}, fmt.Errorf("path not found: %v", err)
+	}
+
+	if isoPath == "" {
+		switch {
+		case !info.IsDir() && isISOFile(cleaned):
+			isoPath = cleaned
+		case !info.IsDir() && isMPLSFile(cleaned):
+			return collectBlurayPlaylists(osTree{}, filepath.Dir(cleaned), diskBlurayPath)
+		case !info.IsDir():
+			return BlurayPlaylistListing{}, errors.New("path must be a Blu-ray folder, a MPLS file or a BD ISO")
+		default:
+			if _, ok := resolveBDInfoRoot(osTree{}, cleaned); ok {
+				return collectBlurayPlaylists(osTree{}, cleaned, diskBlurayPath)
+			}
+			found, err := findISOInDir(cleaned)
+			if err != nil {
+				if errors.Is(err, errNoISO) {
+					return BlurayPlaylistListing{}, errors.New("path does not contain BDMV or BDISO content")
+				}
+				return BlurayPlaylistListing{}, err
+			}
+			isoPath = found
+		}
+	}
+
+	if isMPLSFile(inner) {
+		inner = path.Dir(inner)
+	}
+	return listISOBlurayPlaylists(ctx, isoPath, inner)
+}
+
+// listISOBlurayPlaylists 列出 ISO 内 inner 目录对应蓝光光盘的播放列表。
+func listISOBlurayPlaylists(ctx context.Context, isoPath, inner string) (BlurayPlaylistListing, error) {
+	virtualPath := func(treePath string) string {
+		return buildVirtualISOPath(isoPath, filepath.ToSlash(treePath), false)
+	}
+	if image, err := isofs.Open(isoPath); err == nil {
+		defer image.Close()
+		return collectBlurayPlaylists(isoTree{image: image}, inner, virtualPath)
+	}
+
+	mountDir, cleanup, err := mountISO(ctx, isoPath)
+	if err != nil {
+		return BlurayPlaylistListing{}, err
+	}
+	defer cleanup()
+
+	start := filepath.Clean(filepath.Join(mountDir, filepath.FromSlash(strings.TrimPrefix(inner, "/"))))
+	if !isSubpath(mountDir, start) {
+		return BlurayPlaylistListing{}, errors.New("path is outside mounted ISO")
+	}
+	return collectBlurayPlaylists(osTree{}, start, func(treePath string) string {
+		relative, err := filepath.Rel(mountDir, treePath)
+		if err != nil {
+			return ""
+		}
+		return virtualPath("/" + relative)
+	})
+}
+
+// collectBlurayPlaylists 在 tree 中定位 start 所属的蓝光光盘，并把排序后的播放列表映射成对外输入路径。
+func collectBlurayPlaylists(tree sourceTree, start string, inputPath func(treePath string) string) (BlurayPlaylistListing, error) {
+	discRoot, ok := resolveBDInfoRoot(tree, start)
+	if !ok {
+		return BlurayPlaylistListing{}, errors.New("path does not contain BDMV content")
+	}
+
+	titles := rankBlurayTitles(tree, filepath.Join(discRoot, "BDMV"))
+	if len(titles) == 0 {
+		return BlurayPlaylistListing{}, errors.New("no readable playlists found under BDMV/PLAYLIST")
+	}
+
+	listing := BlurayPlaylistListing{
+		Root:      inputPath(discRoot),
+		Playlists: make([]BlurayPlaylist, 0, len(titles)),
+	}
+	for index, title := range titles {
+		listing.Playlists = append(listing.Playlists, BlurayPlaylist{
+			BlurayTitle:  title,
+			Path:         inputPath(title.path),
+			DurationText: formatMPLSDuration(title.Duration),
+			MainFeature:  index == 0 && !title.Suspicious,
+		})
+	}
+	return listing, nil
+}
+
+// diskBlurayPath 原样返回本地目录树中的路径。
+func diskBlurayPath(treePath string) string {
+	return treePath
+}
@@ -0,0 +1,86 @@
+// Package media 验证蓝光播放列表清单。
+
+package media
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// TestListBlurayPlaylistsFromFolder 验证本地蓝光目录会列出全部可用播放列表，并只把排名第一的列表标记为正片。
+func TestListBlurayPlaylistsFromFolder(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "disc")
+	bdmv := writeTestBlurayDisc(t, root)
+	roots := []Root{newRoot("All", base)}
+
+	listing, err := listBlurayPlaylistsWithin(context.Background(), filepath.Join(bdmv, "PLAYLIST"), roots)
+	if err != nil {
+		t.Fatalf("listBlurayPlaylistsWithin() error: %v", err)
+	}
+	if listing.Root != root || len(listing.Playlists) != 5 {
+		t.Fatalf("listing = %q with %d playlists, want %q with 5", listing.Root, len(listing.Playlists), root)
+	}
+
+	main := listing.Playlists[0]
+	if !main.MainFeature || main.Playlist != "00800.MPLS" || main.DurationText != "1:40:00" {
+		t.Fatalf("Playlists[0] = %+v, want main feature 00800.MPLS lasting 1:40:00", main)
+	}
+	if want := filepath.Join(bdmv, "PLAYLIST", "00800.mpls"); main.Path != want {
+		t.Fatalf("Playlists[0].Path = %q, want %q", main.Path, want)
+	}
+	for _, playlist := range listing.Playlists[1:] {
+		if playlist.MainFeature {
+			t.Fatalf("playlist %s should not be marked as main feature", playlist.Playlist)
+		}
+	}
+
+	source, cleanup, err := ResolveScreenshotSource(context.Background(), listing.Playlists[1].Path)
+	if err != nil {
+		t.Fatalf("ResolveScreenshotSource(mpls) error: %v", err)
+	}
+	defer cleanup()
+	if want := filepath.Join(bdmv, "STREAM", "00012.m2ts"); source != want {
+		t.Fatalf("ResolveScreenshotSource(mpls) = %q, want %q", source, want)
+	}
+
+	if _, err := listBlurayPlaylistsWithin(context.Background(), root, []Root{newRoot("Other", t.TempDir())}); !errors.Is(err, ErrPathNotAllowed) {
+		t.Fatalf("listBlurayPlaylistsWithin(outside root) error = %v, want ErrPathNotAllowed", err)
+	}
+}
+
+// TestListBlurayPlaylistsFromISOPath 验证 ISO 虚拟路径无需挂载即可列出播放列表，并汇总音轨和字幕语言。
+func TestListBlurayPlaylistsFromISOPath(t *testing.T) {
+	base := t.TempDir()
+	isoPath := filepath.Join(base, "disc.iso")
+	writeTestISOImage(t, isoPath, map[string][]byte{
+		"BDMV/PLAYLIST/00001.MPLS": buildTestFullMPLSFile(),
+		"BDMV/STREAM/00055.M2TS":   make([]byte, 100),
+		"BDMV/STREAM/00057.M2TS":   make([]byte, 50),
+	})
+
+	listing, err := listBlurayPlaylistsWithin(context.Background(), "ISO:"+isoPath+"!/BDMV/PLAYLIST/00001.MPLS", []Root{newRoot("All", base)})
+	if err != nil {
+		t.Fatalf("listBlurayPlaylistsWithin() error: %v", err)
+	}
+	if listing.Root != "ISO:"+isoPath+"!" || len(listing.Playlists) != 1 {
+		t.Fatalf("listing = %+v, want one playlist under the ISO root", listing)
+	}
+
+	playlist := listing.Playlists[0]
+	if want := "ISO:" + isoPath + "!/BDMV/PLAYLIST/00001.MPLS"; playlist.Path != want {
+		t.Fatalf("Path = %q, want %q", playlist.Path, want)
+	}
+	if !playlist.MainFeature || playlist.Chapters != 4 || playlist.Size != 150 {
+		t.Fatalf("playlist = %+v, want main feature with 4 chapters and 150 bytes", playlist)
+	}
+	audio, subtitles := playlist.AudioLanguages(), playlist.SubtitleLanguages()
+	if len(audio) != 2 || audio[0] != "eng" || audio[1] != "jpn" {
+		t.Fatalf("AudioLanguages() = %v, want [eng jpn]", audio)
+	}
+	if len(subtitles) != 2 || subtitles[0] != "chi" || subtitles[1] != "eng" {
+		t.Fatalf("SubtitleLanguages() = %v, want [chi eng]", subtitles)
+	}
+}
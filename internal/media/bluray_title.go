@@ -0,0 +1,338 @@
+// Package media 提供基于 MPLS / CLPI 的蓝光正片播放列表识别。
+
+package media
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 正片识别的阈值。
+const (
+	// blurayLoopRatio 表示播放项数量超过不同片段数量的该倍数时，视为循环播放的菜单或背景列表。
+	blurayLoopRatio = 1.5
+	// blurayShortRatio 表示时长不足最长有效播放列表该比例时，视为花絮或片头。
+	blurayShortRatio = 1.0 / 3
+	// blurayObfuscationDuplicates 表示同一片段集合被至少这么多个其它等长播放列表重复引用时，视为混淆保护生成的列表组。
+	blurayObfuscationDuplicates = 3
+)
+
+// BlurayTitle 表示一个蓝光播放列表及其正片识别指标。
+type BlurayTitle struct {
+	// Playlist 是播放列表文件名，例如 00800.MPLS。
+	Playlist string
+	Duration time.Duration
+	// Clips 按播放顺序列出引用的片段编号，同一片段只出现一次。
+	Clips []string
+	// Size 是所有不同片段的总字节数。
+	Size     int64
+	Chapters int
+	// Duplicates 是与该列表时长相同、片段集合相同的其它播放列表数量；数量较多通常意味着播放列表混淆保护。
+	Duplicates int
+	// Inversions 是片段编号倒序出现的次数；混淆生成的假列表通常会打乱片段顺序。
+	Inversions int
+	// Suspicious 表示该列表因循环播放或时长过短被排在候选正片之后。
+	Suspicious bool
+	Info       *MPLSPlaylist
+
+	// path 是播放列表文件在所属目录树中的实际路径，保留原始大小写。
+	path      string
+	clipFiles map[string]blurayClipFile
+}
+
+// BlurayClipSegment 表示播放列表主路径中按播放顺序排列的一个播放项。
+type BlurayClipSegment struct {
+	// Clip 是片段编号，例如 00001。
+	Clip string
+	// Path 是片段 M2TS 在所属目录树中的路径；只能从 CLPI 推算大小的片段为空。
+	Path string
+	// Start 是播放项在整个播放列表时间线上的起点，Duration 是播放项时长。
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// blurayClipFile 记录片段对应的 M2TS 路径和大小；path 为空表示只能从 CLPI 推算大小。
+type blurayClipFile struct {
+	path string
+	size int64
+}
+
+// rankBlurayTitles 解析 BDMV/PLAYLIST 下的所有播放列表，并按正片可能性从高到低排序。
+//
+// 引用了不存在片段的播放列表会被丢弃；循环播放或明显过短的列表排在后面；
+// 其余按时长比较，时长相同时属于混淆列表组的排在独立列表之后，再依次比较片段总大小、片段顺序是否被打乱、章节数和文件名。
+func rankBlurayTitles(tree sourceTree, bdmvDir string) []BlurayTitle {
+	entries, err := tree.ReadDir(filepath.Join(bdmvDir, "PLAYLIST"))
+	if err != nil {
+		return nil
+	}
+
+	clipCache := make(map[string]*blurayClipFile)
+	titles := make([]BlurayTitle, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !isMPLSFile(entry.Name()) {
+			continue
+		}
+		if title, ok := readBlurayTitle(tree, bdmvDir, entry.Name(), clipCache); ok {
+			titles = append(titles, title)
+		}
+	}
+
+	markSuspiciousBlurayTitles(titles)
+	countDuplicateBlurayTitles(titles)
+	sort.SliceStable(titles, func(i, j int) bool {
+		return lessBlurayTitle(titles[i], titles[j])
+	})
+	return titles
+}
+
+// findMainBlurayTitle 返回最可能是正片的播放列表。
+func findMainBlurayTitle(tree sourceTree, bdmvDir string) (BlurayTitle, bool) {
+	titles := rankBlurayTitles(tree, bdmvDir)
+	if len(titles) == 0 {
+		return BlurayTitle{}, false
+	}
+	return titles[0], true
+}
+
+// readBlurayTitle 读取并解析 BDMV/PLAYLIST 下名为 name 的播放列表；无法解析或没有播放项时返回 false。
+func readBlurayTitle(tree sourceTree, bdmvDir, name string, cache map[string]*blurayClipFile) (BlurayTitle, bool) {
+	playlistPath := filepath.Join(bdmvDir, "PLAYLIST", name)
+	data, err := tree.ReadFile(playlistPath)
+	if err != nil {
+		return BlurayTitle{}, false
+	}
+	playlist, err := ParseMPLS(data)
+	if err != nil || len(playlist.Items) == 0 {
+		return BlurayTitle{}, false
+	}
+	title, ok := newBlurayTitle(tree, bdmvDir, strings.ToUpper(name), playlist, cache)
+	title.path = playlistPath
+	return title, ok
+}
+
+// newBlurayTitle 计算单个播放列表的识别指标；任一片段既没有 M2TS 也没有 CLPI 时返回 false。
+func newBlurayTitle(tree sourceTree, bdmvDir, name string, playlist *MPLSPlaylist, cache map[string]*blurayClipFile) (BlurayTitle, bool) {
+	title := BlurayTitle{
+		Playlist:  name,
+		Duration:  playlist.Duration(),
+		Clips:     playlist.ClipIDs(),
+		Chapters:  len(playlist.Chapters()),
+		Info:      playlist,
+		clipFiles: make(map[string]blurayClipFile),
+	}
+
+	for _, clip := range title.Clips {
+		file, ok := cache[clip]
+		if !ok {
+			if found, exists := lookupBlurayClipFile(tree, bdmvDir, clip); exists {
+				file = &found
+			}
+			cache[clip] = file
+		}
+		if file == nil {
+			return BlurayTitle{}, false
+		}
+		title.clipFiles[clip] = *file
+		title.Size += file.size
+	}
+
+	for index := 1; index < len(playlist.Items); index++ {
+		if clipNumber(playlist.Items[index].ClipID) < clipNumber(playlist.Items[index-1].ClipID) {
+			title.Inversions++
+		}
+	}
+	return title, true
+}
+
+// lookupBlurayClipFile 查找片段的 M2TS 文件；找不到时回退为读取 CLPI 推算大小。
+func lookupBlurayClipFile(tree sourceTree, bdmvDir, clip string) (blurayClipFile, bool) {
+	for _, name := range []string{clip + ".m2ts", clip + ".M2TS"} {
+		path := filepath.Join(bdmvDir, "STREAM", name)
+		if info, err := tree.Stat(path); err == nil && !info.IsDir() {
+			return blurayClipFile{path: path, size: info.Size()}, true
+		}
+	}
+	for _, name := range []string{clip + ".clpi", clip + ".CLPI"} {
+		data, err := tree.ReadFile(filepath.Join(bdmvDir, "CLIPINF", name))
+		if err != nil {
+			continue
+		}
+		if info, err := ParseCLPI(data); err == nil {
+			return blurayClipFile{size: info.Size()}, true
+		}
+	}
+	return blurayClipFile{}, false
+}
+
+// markSuspiciousBlurayTitles 标记循环播放和明显过短的播放列表。
+func markSuspiciousBlurayTitles(titles []BlurayTitle) {
+	var longest time.Duration
+	for index := range titles {
+		title := &titles[index]
+		if float64(len(title.Info.Items)) > float64(len(title.Clips))*blurayLoopRatio {
+			title.Suspicious = true
+			continue
+		}
+		if title.Duration > longest {
+			longest = title.Duration
+		}
+	}
+	for index := range titles {
+		if float64(titles[index].Duration) < float64(longest)*blurayShortRatio {
+			titles[index].Suspicious = true
+		}
+	}
+}
+
+// countDuplicateBlurayTitles 统计时长（按秒）和片段集合都相同的播放列表。
+func countDuplicateBlurayTitles(titles []BlurayTitle) {
+	groups := make(map[string]int, len(titles))
+	keys := make([]string, len(titles))
+	for index, title := range titles {
+		clips := append([]string(nil), title.Clips...)
+		sort.Strings(clips)
+		keys[index] = strconv.FormatInt(int64(title.Duration/time.Second), 10) + "|" + strings.Join(clips, ",")
+		groups[keys[index]]++
+	}
+	for index := range titles {
+		titles[index].Duplicates = groups[keys[index]] - 1
+	}
+}
+
+// lessBlurayTitle 定义正片候选的排序规则。
+func lessBlurayTitle(left, right BlurayTitle) bool {
+	if left.Suspicious != right.Suspicious {
+		return !left.Suspicious
+	}
+	if leftSeconds, rightSeconds := left.Duration/time.Second, right.Duration/time.Second; leftSeconds != rightSeconds {
+		return leftSeconds > rightSeconds
+	}
+	if leftObfuscated, rightObfuscated := left.Duplicates >= blurayObfuscationDuplicates, right.Duplicates >= blurayObfuscationDuplicates; leftObfuscated != rightObfuscated {
+		return !leftObfuscated
+	}
+	if left.Size != right.Size {
+		return left.Size > right.Size
+	}
+	if left.Inversions != right.Inversions {
+		return left.Inversions < right.Inversions
+	}
+	if left.Chapters != right.Chapters {
+		return left.Chapters > right.Chapters
+	}
+	return left.Playlist < right.Playlist
+}
+
+// largestClipPath 返回播放列表中体积最大且存在 M2TS 文件的片段路径。
+func (t BlurayTitle) largestClipPath() (string, bool) {
+	var bestPath string
+	var bestSize int64 = -1
+	for _, clip := range t.Clips {
+		file := t.clipFiles[clip]
+		if file.path != "" && file.size > bestSize {
+			bestPath, bestSize = file.path, file.size
+		}
+	}
+	return bestPath, bestPath != ""
+}
+
+// ClipSegments 按播放顺序返回主路径的播放项及其在播放列表时间线上的位置；同一片段被多次播放时会出现多次，时长为零的播放项会被跳过。
+func (t BlurayTitle) ClipSegments() []BlurayClipSegment {
+	if t.Info == nil {
+		return nil
+	}
+	segments := make([]BlurayClipSegment, 0, len(t.Info.Items))
+	var start time.Duration
+	for _, item := range t.Info.Items {
+		duration := item.Duration()
+		if duration <= 0 {
+			continue
+		}
+		segments = append(segments, BlurayClipSegment{
+			Clip:     item.ClipID,
+			Path:     t.clipFiles[item.ClipID].path,
+			Start:    start,
+			Duration: duration,
+		})
+		start += duration
+	}
+	return segments
+}
+
+// AudioLanguages 按首次出现的顺序返回所有播放项 STN 表中的音轨语言，去重且忽略未标注语言的音轨。
+func (t BlurayTitle) AudioLanguages() []string {
+	return t.streamLanguages(func(streams MPLSStreamTable) []MPLSStream { return streams.Audio })
+}
+
+// SubtitleLanguages 按首次出现的顺序返回所有播放项 STN 表中的 PG 字幕语言，去重且忽略未标注语言的字幕。
+func (t BlurayTitle) SubtitleLanguages() []string {
+	return t.streamLanguages(func(streams MPLSStreamTable) []MPLSStream { return streams.PG })
+}
+
+// streamLanguages 汇总 pick 选出的流的语言代码。
+func (t BlurayTitle) streamLanguages(pick func(MPLSStreamTable) []MPLSStream) []string {
+	if t.Info == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var languages []string
+	for _, item := range t.Info.Items {
+		for _, stream := range pick(item.Streams) {
+			language := strings.ToLower(strings.TrimSpace(stream.Language))
+			if language == "" || seen[language] {
+				continue
+			}
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// clipNumber 把五位片段编号转换成整数；非数字编号返回 -1。
+func clipNumber(clip string) int {
+	value, err := strconv.Atoi(clip)
+	if err != nil {
+		return -1
+	}
+	return value
+}
+
+// blurayBDMVDir 把 resolveBDMVRoot 返回的 BDMV 或 BDMV/STREAM 目录统一成 BDMV 目录。
+func blurayBDMVDir(path string) string {
+	if strings.EqualFold(filepath.Base(path), "STREAM") {
+		return filepath.Dir(path)
+	}
+	return path
+}
+
+// findBlurayScreenshotClip 选择正片播放列表中体积最大的片段作为单一截图源；多片段正片会优先通过 ResolveScreenshotTimeline 在整条时间线上截图，这里只用于无法拆分片段时的回退。识别不到正片时回退为整个 STREAM 目录中最大的 M2TS。
+func findBlurayScreenshotClip(tree sourceTree, bdmvRoot string) (string, error) {
+	if title, ok := findMainBlurayTitle(tree, blurayBDMVDir(bdmvRoot)); ok {
+		if path, ok := title.largestClipPath(); ok {
+			return path, nil
+		}
+	}
+	return findLargestM2TS(tree, bdmvRoot)
+}
+
+// findBlurayPlaylistClip 返回指定 MPLS 文件所引用片段中体积最大的 M2TS，用于按用户选定的播放列表截图。
+func findBlurayPlaylistClip(tree sourceTree, mplsPath string) (string, bool) {
+	bdmvDir := filepath.Dir(filepath.Dir(mplsPath))
+	title, ok := readBlurayTitle(tree, bdmvDir, filepath.Base(mplsPath), make(map[string]*blurayClipFile))
+	if !ok {
+		return "", false
+	}
+	return title.largestClipPath()
+}
+
+// mainBlurayPlaylist 返回蓝光根目录下正片播放列表的文件名；识别不到时返回空字符串。
+func mainBlurayPlaylist(tree sourceTree, discRoot string) string {
+	if title, ok := findMainBlurayTitle(tree, filepath.Join(discRoot, "BDMV")); ok {
+		return title.Playlist
+	}
+	return ""
+}
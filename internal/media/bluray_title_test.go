@@ -0,0 +1,232 @@
+// Package media 验证 CLPI 解析和蓝光正片播放列表识别。
+
+package media
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestParseCLPIReadsSequencesAndStreams 验证 CLPI 的 source packet 数量、STC 时间范围和节目流都能被解析。
+func TestParseCLPIReadsSequencesAndStreams(t *testing.T) {
+	clip, err := ParseCLPI(buildTestCLPIFile(1000, 90*mplsClockRate, 690*mplsClockRate))
+	if err != nil {
+		t.Fatalf("ParseCLPI() error: %v", err)
+	}
+
+	if clip.Size() != 1000*bdmvSourcePacketSize {
+		t.Fatalf("Size() = %d, want %d", clip.Size(), 1000*bdmvSourcePacketSize)
+	}
+	if clip.Duration() != 600*time.Second {
+		t.Fatalf("Duration() = %v, want 10m0s", clip.Duration())
+	}
+	if len(clip.Streams) != 2 || clip.Streams[0].Codec != "HEVC" || clip.Streams[1].Codec != "DTS-HD Master Audio" || clip.Streams[1].Language != "fra" {
+		t.Fatalf("Streams = %+v, want HEVC video and fra DTS-HD MA audio", clip.Streams)
+	}
+}
+
+// TestFindMainBlurayTitleSkipsBonusLoopsAndObfuscatedPlaylists 验证正片识别会跳过花絮、循环列表、缺片段列表和打乱顺序的假列表。
+func TestFindMainBlurayTitleSkipsBonusLoopsAndObfuscatedPlaylists(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "disc")
+	bdmv := writeTestBlurayDisc(t, root)
+
+	titles := rankBlurayTitles(osTree{}, bdmv)
+	if len(titles) != 5 {
+		t.Fatalf("rankBlurayTitles() returned %d titles, want 5 (missing-clip playlist dropped)", len(titles))
+	}
+	main := titles[0]
+	if main.Playlist != "00800.MPLS" {
+		t.Fatalf("main playlist = %q, want %q", main.Playlist, "00800.MPLS")
+	}
+	if main.Duration != 100*time.Minute || main.Duplicates != 1 || main.Inversions != 0 {
+		t.Fatalf("main title = %+v, want 100m duration, 1 duplicate, no inversions", main)
+	}
+	if titles[1].Playlist != "00801.MPLS" || titles[1].Inversions == 0 {
+		t.Fatalf("titles[1] = %+v, want shuffled duplicate 00801.MPLS", titles[1])
+	}
+	for _, title := range titles[2:] {
+		if !title.Suspicious {
+			t.Fatalf("title %s should be marked suspicious", title.Playlist)
+		}
+	}
+
+	source, cleanup, err := ResolveScreenshotSource(context.Background(), root)
+	if err != nil {
+		t.Fatalf("ResolveScreenshotSource() error: %v", err)
+	}
+	defer cleanup()
+	if want := filepath.Join(bdmv, "STREAM", "00012.m2ts"); source != want {
+		t.Fatalf("ResolveScreenshotSource() = %q, want %q", source, want)
+	}
+
+	bdinfoSource, bdinfoCleanup, err := ResolveBDInfoSource(context.Background(), root)
+	if err != nil {
+		t.Fatalf("ResolveBDInfoSource() error: %v", err)
+	}
+	defer bdinfoCleanup()
+	if bdinfoSource.Path != root || bdinfoSource.Playlist != "00800.MPLS" {
+		t.Fatalf("ResolveBDInfoSource() = %+v, want root with 00800.MPLS", bdinfoSource)
+	}
+}
+
+// TestRankBlurayTitlesPrefersUniquePlaylistOverObfuscatedGroup 验证等长时独立的播放列表排在大量重复同一片段集合的混淆列表组之前，即使混淆组的片段更大。
+func TestRankBlurayTitlesPrefersUniquePlaylistOverObfuscatedGroup(t *testing.T) {
+	bdmv := writeTestBlurayDisc(t, filepath.Join(t.TempDir(), "disc"))
+	for clip, size := range map[string]int{"00030": 300, "00031": 300} {
+		if err := os.WriteFile(filepath.Join(bdmv, "STREAM", clip+".m2ts"), make([]byte, size), 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+	playlists := map[string][]byte{
+		"00100.mpls": buildTestClipMPLSFile([]string{"00030", "00031"}, 50*60),
+		"00802.mpls": buildTestClipMPLSFile([]string{"00013", "00012", "00011", "00010"}, 25*60),
+		"00803.mpls": buildTestClipMPLSFile([]string{"00011", "00013", "00010", "00012"}, 25*60),
+	}
+	for name, data := range playlists {
+		if err := os.WriteFile(filepath.Join(bdmv, "PLAYLIST", name), data, 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+
+	titles := rankBlurayTitles(osTree{}, bdmv)
+	if len(titles) < 2 || titles[0].Playlist != "00100.MPLS" || titles[0].Duplicates != 0 {
+		t.Fatalf("main title = %+v, want unique 00100.MPLS", titles[0])
+	}
+	if titles[1].Playlist != "00800.MPLS" || titles[1].Duplicates != 3 || titles[1].Size <= titles[0].Size {
+		t.Fatalf("titles[1] = %+v, want larger in-order 00800.MPLS from the obfuscated group", titles[1])
+	}
+}
+
+// TestResolveScreenshotTimelineCoversMainPlaylistClips 验证截图时间线按播放顺序覆盖正片播放列表的全部片段，单片段列表回退为单一截图源。
+func TestResolveScreenshotTimelineCoversMainPlaylistClips(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "disc")
+	bdmv := writeTestBlurayDisc(t, root)
+
+	timeline, err := ResolveScreenshotTimeline(context.Background(), root)
+	if err != nil {
+		t.Fatalf("ResolveScreenshotTimeline() error: %v", err)
+	}
+	if timeline.Playlist != "00800.MPLS" || len(timeline.Segments) != 4 {
+		t.Fatalf("timeline = %+v, want 4 segments from 00800.MPLS", timeline)
+	}
+	for index, clip := range []string{"00010", "00011", "00012", "00013"} {
+		segment := timeline.Segments[index]
+		want := BlurayClipSegment{
+			Clip:     clip,
+			Path:     filepath.Join(bdmv, "STREAM", clip+".m2ts"),
+			Start:    time.Duration(index) * 25 * time.Minute,
+			Duration: 25 * time.Minute,
+		}
+		if segment != want {
+			t.Fatalf("Segments[%d] = %+v, want %+v", index, segment, want)
+		}
+	}
+	if timeline.Duration() != 100*time.Minute {
+		t.Fatalf("Duration() = %v, want 100m0s", timeline.Duration())
+	}
+	if index, local := timeline.Locate(60 * time.Minute); index != 2 || local != 10*time.Minute {
+		t.Fatalf("Locate(60m) = %d, %v, want 2, 10m0s", index, local)
+	}
+	if index, local := timeline.Locate(2 * time.Hour); index != 3 || local != 25*time.Minute {
+		t.Fatalf("Locate(2h) = %d, %v, want 3, 25m0s", index, local)
+	}
+
+	single, err := ResolveScreenshotTimeline(context.Background(), filepath.Join(bdmv, "PLAYLIST", "00000.mpls"))
+	if err != nil {
+		t.Fatalf("ResolveScreenshotTimeline(00000.mpls) error: %v", err)
+	}
+	if len(single.Segments) != 0 {
+		t.Fatalf("single-clip timeline = %+v, want empty", single)
+	}
+}
+
+// writeTestBlurayDisc 生成一张带多种干扰播放列表的蓝光目录，返回 BDMV 路径。
+//
+// 单个最大的 M2TS 是花絮 00001；正片由 00010-00013 组成，其中 00012 最大；
+// 00801 是打乱片段顺序的混淆副本，00900 循环播放同一片段，00999 引用不存在的片段，
+// 00020 只有 CLPI 没有 M2TS。
+func writeTestBlurayDisc(t *testing.T, root string) string {
+	t.Helper()
+
+	bdmv := filepath.Join(root, "BDMV")
+	for _, dir := range []string{"PLAYLIST", "STREAM", "CLIPINF"} {
+		if err := os.MkdirAll(filepath.Join(bdmv, dir), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error: %v", err)
+		}
+	}
+
+	write := func(path string, data []byte) {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+	for clip, size := range map[string]int{"00001": 900, "00010": 400, "00011": 400, "00012": 450, "00013": 400} {
+		write(filepath.Join(bdmv, "STREAM", clip+".m2ts"), make([]byte, size))
+	}
+	write(filepath.Join(bdmv, "CLIPINF", "00020.clpi"), buildTestCLPIFile(10, 0, 60*mplsClockRate))
+
+	loop := make([]string, 30)
+	for index := range loop {
+		loop[index] = "00010"
+	}
+	playlists := map[string][]byte{
+		"00000.mpls": buildTestClipMPLSFile([]string{"00001"}, 20*60),
+		"00800.mpls": buildTestClipMPLSFile([]string{"00010", "00011", "00012", "00013"}, 25*60),
+		"00801.mpls": buildTestClipMPLSFile([]string{"00012", "00010", "00013", "00011"}, 25*60),
+		"00900.mpls": buildTestClipMPLSFile(loop, 5*60),
+		"00999.mpls": buildTestClipMPLSFile([]string{"00010", "00050"}, 120*60),
+		"00020.mpls": buildTestClipMPLSFile([]string{"00020"}, 60),
+	}
+	for name, data := range playlists {
+		write(filepath.Join(bdmv, "PLAYLIST", name), data)
+	}
+	return bdmv
+}
+
+// buildTestClipMPLSFile 构造按顺序引用 clips 的 MPLS 文件，每个播放项时长均为 seconds 秒。
+func buildTestClipMPLSFile(clips []string, seconds uint32) []byte {
+	items := make([][2]uint32, len(clips))
+	for index := range items {
+		items[index] = [2]uint32{0, seconds * mplsClockRate}
+	}
+	data := buildTestMPLSFile(items)
+	const firstItem = 20 + 10
+	for index, clip := range clips {
+		copy(data[firstItem+index*22+2:], clip)
+	}
+	return data
+}
+
+// buildTestCLPIFile 构造包含一个 STC 序列和两条节目流（HEVC 视频、法语 DTS-HD MA 音频）的 CLPI 文件。
+func buildTestCLPIFile(packets, start, end uint32) []byte {
+	const sequenceOffset = 60
+	data := make([]byte, sequenceOffset)
+	copy(data[:8], "HDMV0300")
+	binary.BigEndian.PutUint32(data[56:60], packets)
+
+	binary.BigEndian.PutUint32(data[8:12], sequenceOffset)
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = append(data, 0, 1)
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = append(data, 1, 0)
+	data = binary.BigEndian.AppendUint16(data, 0x1001)
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = binary.BigEndian.AppendUint32(data, start)
+	data = binary.BigEndian.AppendUint32(data, end)
+
+	binary.BigEndian.PutUint32(data[12:16], uint32(len(data)))
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = append(data, 0, 1)
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = binary.BigEndian.AppendUint16(data, 0x0100)
+	data = append(data, 2, 0)
+	data = binary.BigEndian.AppendUint16(data, 0x1011)
+	data = append(data, 2, 0x24, 0x86)
+	data = binary.BigEndian.AppendUint16(data, 0x1100)
+	data = append(data, 5, 0x86, 0x61, 'f', 'r', 'a')
+	return data
+}
@@ -0,0 +1,155 @@
+// Package media 提供 CLPI 片段信息文件解析：片段大小、STC 时间范围和节目流列表。
+
+package media
+
+import (
+	"fmt"
+	"time"
+)
+
+// bdmvSourcePacketSize 是 M2TS 中每个 source packet 的字节数（4 字节 TP_extra_header + 188 字节 TS 包）。
+const bdmvSourcePacketSize = 192
+
+// CLPIClipInfo 表示一个解析后的 CLPI 片段信息文件。
+type CLPIClipInfo struct {
+	Version         string
+	ClipStreamType  uint8
+	ApplicationType uint8
+	TSRecordingRate uint32
+	SourcePackets   uint32
+	Sequences       []CLPISTCSequence
+	// Streams 复用 MPLSStream 描述节目流，SubPathID 固定为 -1。
+	Streams []MPLSStream
+}
+
+// CLPISTCSequence 表示片段中的一段连续 STC 时间轴；时间以 45kHz 时钟计数。
+type CLPISTCSequence struct {
+	PCRPID            uint16
+	SPNStart          uint32
+	PresentationStart uint32
+	PresentationEnd   uint32
+}
+
+// ParseCLPI 解析 CLPI 数据中的 ClipInfo、SequenceInfo 和 ProgramInfo。
+func ParseCLPI(data []byte) (*CLPIClipInfo, error) {
+	if len(data) < 60 {
+		return nil, fmt.Errorf("clpi: file too short")
+	}
+
+	fileType := string(data[:8])
+	switch fileType {
+	case "HDMV0100", "HDMV0200", "HDMV0300":
+	default:
+		return nil, fmt.Errorf("clpi: unsupported file type %q", fileType)
+	}
+
+	clip := &CLPIClipInfo{
+		Version:         fileType[4:],
+		ClipStreamType:  data[46],
+		ApplicationType: data[47],
+		TSRecordingRate: readUint32BE(data[52:56]),
+		SourcePackets:   readUint32BE(data[56:60]),
+	}
+
+	sequenceOffset := int(readUint32BE(data[8:12]))
+	programOffset := int(readUint32BE(data[12:16]))
+	if sequenceOffset > 0 {
+		sequences, err := parseCLPISequences(data, sequenceOffset)
+		if err != nil {
+			return nil, err
+		}
+		clip.Sequences = sequences
+	}
+	if programOffset > 0 {
+		streams, err := parseCLPIPrograms(data, programOffset)
+		if err != nil {
+			return nil, err
+		}
+		clip.Streams = streams
+	}
+	return clip, nil
+}
+
+// parseCLPISequences 解析 SequenceInfo 中所有 ATC 序列下的 STC 序列。
+func parseCLPISequences(data []byte, offset int) ([]CLPISTCSequence, error) {
+	reader := &bdmvReader{data: data, pos: offset}
+	if !reader.has(6) {
+		return nil, fmt.Errorf("clpi: invalid sequence info offset")
+	}
+	reader.skip(4) // length
+	reader.skip(1) // reserved
+	atcCount := int(reader.u8())
+
+	var sequences []CLPISTCSequence
+	for atc := 0; atc < atcCount; atc++ {
+		if !reader.has(6) {
+			return nil, fmt.Errorf("clpi: truncated ATC sequence")
+		}
+		reader.skip(4) // SPN_ATC_start
+		stcCount := int(reader.u8())
+		reader.skip(1) // offset_STC_id
+		if !reader.has(stcCount * 14) {
+			return nil, fmt.Errorf("clpi: truncated STC sequence")
+		}
+		for stc := 0; stc < stcCount; stc++ {
+			sequences = append(sequences, CLPISTCSequence{
+				PCRPID:            reader.u16(),
+				SPNStart:          reader.u32(),
+				PresentationStart: reader.u32(),
+				PresentationEnd:   reader.u32(),
+			})
+		}
+	}
+	return sequences, nil
+}
+
+// parseCLPIPrograms 解析 ProgramInfo 中所有节目的流列表。
+func parseCLPIPrograms(data []byte, offset int) ([]MPLSStream, error) {
+	reader := &bdmvReader{data: data, pos: offset}
+	if !reader.has(6) {
+		return nil, fmt.Errorf("clpi: invalid program info offset")
+	}
+	reader.skip(4) // length
+	reader.skip(1) // reserved
+	programCount := int(reader.u8())
+
+	var streams []MPLSStream
+	for program := 0; program < programCount; program++ {
+		if !reader.has(8) {
+			return nil, fmt.Errorf("clpi: truncated program sequence")
+		}
+		reader.skip(4) // SPN_program_sequence_start
+		reader.skip(2) // program_map_PID
+		streamCount := int(reader.u8())
+		reader.skip(1) // num_groups
+		for index := 0; index < streamCount; index++ {
+			if !reader.has(2) {
+				return nil, fmt.Errorf("clpi: truncated program stream")
+			}
+			stream := MPLSStream{PID: reader.u16(), SubPathID: -1}
+			attributes, ok := reader.block()
+			if !ok {
+				return nil, fmt.Errorf("clpi: truncated stream coding info")
+			}
+			parseStreamAttributes(&stream, attributes)
+			streams = append(streams, stream)
+		}
+	}
+	return streams, nil
+}
+
+// Duration 返回所有 STC 序列展示时间之和。
+func (c *CLPIClipInfo) Duration() time.Duration {
+	var ticks uint64
+	for _, sequence := range c.Sequences {
+		if sequence.PresentationEnd > sequence.PresentationStart {
+			ticks += uint64(sequence.PresentationEnd - sequence.PresentationStart)
+		}
+	}
+	return mplsTicksToDuration(ticks)
+}
+
+// Size 根据 source packet 数量估算对应 M2TS 文件的字节数。
+func (c *CLPIClipInfo) Size() int64 {
+	return int64(c.SourcePackets) * bdmvSourcePacketSize
+}
@@ -0,0 +1,145 @@
+// Package media 提供基于 IFO 的 DVD 正片标题识别。
+
+package media
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"minfo/internal/media/dvdifo"
+)
+
+// DVDTitle 表示 DVD 光盘上的一个标题及其正片识别指标。
+type DVDTitle struct {
+	// Number 是光盘级标题编号，从 1 开始。
+	Number        int
+	TitleSet      int
+	TitleSetTitle int
+	Duration      time.Duration
+	Chapters      int
+	Angles        int
+	// AspectRatio 是所在标题集的视频显示宽高比，例如 16:9。
+	AspectRatio       string
+	AudioLanguages    []string
+	SubtitleLanguages []string
+}
+
+// rankDVDTitles 读取 VIDEO_TS.IFO 和各标题集的 VTS_xx_0.IFO，按正片可能性从高到低返回全部标题。
+//
+// IFO 损坏时会尝试同名 BUP 备份；排序依次比较播放时长、章节数和标题编号。
+func rankDVDTitles(tree sourceTree, videoTSDir string) []DVDTitle {
+	entries, err := tree.ReadDir(videoTSDir)
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names[strings.ToUpper(entry.Name())] = entry.Name()
+		}
+	}
+
+	data, ok := readDVDControlFile(tree, videoTSDir, names, "VIDEO_TS")
+	if !ok {
+		return nil
+	}
+	vmg, err := dvdifo.ParseVMG(data)
+	if err != nil {
+		return nil
+	}
+
+	titleSets := make(map[int]*dvdifo.VTS)
+	titles := make([]DVDTitle, 0, len(vmg.Titles))
+	for _, title := range vmg.Titles {
+		vts, cached := titleSets[title.TitleSet]
+		if !cached {
+			if data, ok := readDVDControlFile(tree, videoTSDir, names, fmt.Sprintf("VTS_%02d_0", title.TitleSet)); ok {
+				vts, _ = dvdifo.ParseVTS(data)
+			}
+			titleSets[title.TitleSet] = vts
+		}
+		if vts == nil {
+			continue
+		}
+		titles = append(titles, DVDTitle{
+			Number:            title.Number,
+			TitleSet:          title.TitleSet,
+			TitleSetTitle:     title.TitleSetTitle,
+			Duration:          vts.TitleDuration(title.TitleSetTitle),
+			Chapters:          title.Chapters,
+			Angles:            title.Angles,
+			AspectRatio:       vts.Video.AspectRatio,
+			AudioLanguages:    dvdAudioLanguages(vts),
+			SubtitleLanguages: dvdSubtitleLanguages(vts),
+		})
+	}
+
+	sort.SliceStable(titles, func(i, j int) bool {
+		if titles[i].Duration != titles[j].Duration {
+			return titles[i].Duration > titles[j].Duration
+		}
+		if titles[i].Chapters != titles[j].Chapters {
+			return titles[i].Chapters > titles[j].Chapters
+		}
+		return titles[i].Number < titles[j].Number
+	})
+	return titles
+}
+
+// findMainDVDTitle 返回播放时长最长的 DVD 标题；IFO 不可读或没有有效时长时返回 false。
+func findMainDVDTitle(tree sourceTree, videoTSDir string) (DVDTitle, bool) {
+	titles := rankDVDTitles(tree, videoTSDir)
+	if len(titles) == 0 || titles[0].Duration <= 0 {
+		return DVDTitle{}, false
+	}
+	return titles[0], true
+}
+
+// readDVDControlFile 读取名为 base 的 IFO 文件，读取失败或文件头无效时回退到同名 BUP。
+func readDVDControlFile(tree sourceTree, videoTSDir string, names map[string]string, base string) ([]byte, bool) {
+	for _, ext := range []string{".IFO", ".BUP"} {
+		name, ok := names[base+ext]
+		if !ok {
+			continue
+		}
+		data, err := tree.ReadFile(filepath.Join(videoTSDir, name))
+		if err == nil && len(data) >= 12 && strings.HasPrefix(string(data[:12]), "DVDVIDEO-") {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// dvdAudioLanguages 按逻辑音轨顺序返回标题集中已标注的音轨语言，去重。
+func dvdAudioLanguages(vts *dvdifo.VTS) []string {
+	languages := make([]string, 0, len(vts.Audio))
+	for _, stream := range vts.Audio {
+		languages = appendUniqueLanguage(languages, stream.Language)
+	}
+	return languages
+}
+
+// dvdSubtitleLanguages 按逻辑字幕顺序返回标题集中已标注的字幕语言，去重。
+func dvdSubtitleLanguages(vts *dvdifo.VTS) []string {
+	languages := make([]string, 0, len(vts.Subpictures))
+	for _, stream := range vts.Subpictures {
+		languages = appendUniqueLanguage(languages, stream.Language)
+	}
+	return languages
+}
+
+// appendUniqueLanguage 在 language 非空且尚未出现时把它追加到 languages。
+func appendUniqueLanguage(languages []string, language string) []string {
+	if language == "" {
+		return languages
+	}
+	for _, existing := range languages {
+		if existing == language {
+			return languages
+		}
+	}
+	return append(languages, language)
+}
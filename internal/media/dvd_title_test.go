@@ -0,0 +1,93 @@
+// Package media 验证基于 IFO 的 DVD 正片标题识别。
+
+package media
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFindMainDVDTitleSetPrefersLongestIFOTitle 验证 IFO 可读时按标题时长挑选主标题集，IFO 损坏时读取 BUP，全部不可读时退回按体积挑选。
+func TestFindMainDVDTitleSetPrefersLongestIFOTitle(t *testing.T) {
+	videoTS := filepath.Join(t.TempDir(), "VIDEO_TS")
+	if err := os.MkdirAll(videoTS, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(videoTS, name), data, 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+	write("VTS_01_1.VOB", make([]byte, 2000))
+	write("VTS_02_1.VOB", make([]byte, 1000))
+	write("VTS_02_2.VOB", make([]byte, 500))
+
+	if got, err := findMainDVDTitleSetFirstVOB(osTree{}, videoTS); err != nil || filepath.Base(got) != "VTS_01_1.VOB" {
+		t.Fatalf("findMainDVDTitleSetFirstVOB(without IFO) = %q, %v; want VTS_01_1.VOB", got, err)
+	}
+
+	write("VIDEO_TS.IFO", buildTestDVDVMG(2))
+	write("VTS_01_0.IFO", buildTestDVDVTS([4]byte{0x00, 0x10, 0x00, 0xC0}))
+	write("VTS_02_0.IFO", []byte("corrupt"))
+	write("VTS_02_0.BUP", buildTestDVDVTS([4]byte{0x01, 0x30, 0x00, 0xC0}))
+
+	title, ok := findMainDVDTitle(osTree{}, videoTS)
+	if !ok || title.TitleSet != 2 || title.Duration != 90*time.Minute || title.AspectRatio != "16:9" {
+		t.Fatalf("findMainDVDTitle() = %+v, %v; want title set 2 lasting 1h30m", title, ok)
+	}
+	if len(title.SubtitleLanguages) != 1 || title.SubtitleLanguages[0] != "zh" {
+		t.Fatalf("SubtitleLanguages = %v, want [zh]", title.SubtitleLanguages)
+	}
+	if got, err := findMainDVDTitleSetFirstVOB(osTree{}, videoTS); err != nil || filepath.Base(got) != "VTS_02_1.VOB" {
+		t.Fatalf("findMainDVDTitleSetFirstVOB() = %q, %v; want VTS_02_1.VOB", got, err)
+	}
+}
+
+// buildTestDVDVMG 构造 VIDEO_TS.IFO，其中第 n 个标题位于第 n 个标题集的第一个标题。
+func buildTestDVDVMG(titleSets int) []byte {
+	data := make([]byte, 2048)
+	copy(data, "DVDVIDEO-VMG")
+	binary.BigEndian.PutUint16(data[0x3E:], uint16(titleSets))
+	binary.BigEndian.PutUint32(data[0xC4:], 1)
+
+	table := make([]byte, 8+titleSets*12)
+	binary.BigEndian.PutUint16(table[0:], uint16(titleSets))
+	binary.BigEndian.PutUint32(table[4:], uint32(len(table)-1))
+	for index := 0; index < titleSets; index++ {
+		entry := table[8+index*12:]
+		entry[1] = 1
+		binary.BigEndian.PutUint16(entry[2:], 1)
+		entry[6] = byte(index + 1)
+		entry[7] = 1
+	}
+	return append(data, table...)
+}
+
+// buildTestDVDVTS 构造只有一个标题、一个 PGC 的 16:9 VTS_xx_0.IFO，PGC 时长为 BCD 编码的 duration，带一条中文字幕。
+func buildTestDVDVTS(duration [4]byte) []byte {
+	data := make([]byte, 3*2048)
+	copy(data, "DVDVIDEO-VTS")
+	binary.BigEndian.PutUint32(data[0xC8:], 1)
+	binary.BigEndian.PutUint32(data[0xCC:], 2)
+	data[0x200] = 0x4C
+	binary.BigEndian.PutUint16(data[0x254:], 1)
+	copy(data[0x256:], []byte{0x01, 0, 'z', 'h', 0, 1})
+
+	ptt := data[2048:]
+	binary.BigEndian.PutUint16(ptt[0:], 1)
+	binary.BigEndian.PutUint32(ptt[4:], 15)
+	binary.BigEndian.PutUint32(ptt[8:], 12)
+	binary.BigEndian.PutUint16(ptt[12:], 1)
+	binary.BigEndian.PutUint16(ptt[14:], 1)
+
+	pgcit := make([]byte, 16+0xEC)
+	binary.BigEndian.PutUint16(pgcit[0:], 1)
+	binary.BigEndian.PutUint32(pgcit[4:], uint32(len(pgcit)-1))
+	pgcit[8] = 0x81
+	binary.BigEndian.PutUint32(pgcit[12:], 16)
+	copy(pgcit[16+4:], duration[:])
+	return append(data[:2*2048], pgcit...)
+}
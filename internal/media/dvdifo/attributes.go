@@ -0,0 +1,138 @@
+// Package dvdifo 提供 VTS 视频、音轨和字幕属性的解析。
+
+package dvdifo
+
+import "encoding/binary"
+
+// 视频显示宽高比。
+const (
+	AspectRatio4x3  = "4:3"
+	AspectRatio16x9 = "16:9"
+)
+
+// VideoAttributes 表示标题集的视频属性。
+type VideoAttributes struct {
+	MPEGVersion int
+	// Standard 是电视制式，NTSC 或 PAL。
+	Standard    string
+	AspectRatio string
+	Width       int
+	Height      int
+	Letterboxed bool
+	FilmMode    bool
+}
+
+// AudioStream 表示一条逻辑音轨的属性。
+type AudioStream struct {
+	// Format 是编码格式，例如 AC-3、DTS、LPCM、MPEG-1、MPEG-2。
+	Format string
+	// Language 是 ISO 639-1 两字母语言代码；光盘未标注语言时为空。
+	Language   string
+	Channels   int
+	SampleRate int
+	// CodeExtension 是语言扩展说明：1 普通、2 视障、3 导演评论、4 其它评论。
+	CodeExtension int
+}
+
+// SubpictureStream 表示一条逻辑字幕的属性。
+type SubpictureStream struct {
+	// Language 是 ISO 639-1 两字母语言代码；光盘未标注语言时为空。
+	Language string
+	// CodeExtension 是语言扩展说明：1 普通、2 大字号、3 儿童、5/6/7 对应字幕的隐藏式字幕版本、9 强制、13/14/15 导演评论。
+	CodeExtension int
+}
+
+// Description 返回字幕语言扩展对应的简短英文说明；普通字幕或未知扩展返回空字符串。
+func (s SubpictureStream) Description() string {
+	switch s.CodeExtension {
+	case 2:
+		return "Large"
+	case 3:
+		return "Children"
+	case 5, 6, 7:
+		return "Closed Caption"
+	case 9:
+		return "Forced"
+	case 13, 14, 15:
+		return "Director's Comments"
+	}
+	return ""
+}
+
+// parseVideoAttributes 解析两字节的视频属性。
+func parseVideoAttributes(data []byte) VideoAttributes {
+	attributes := VideoAttributes{
+		MPEGVersion: int(data[0]>>6) + 1,
+		Standard:    "NTSC",
+		AspectRatio: AspectRatio4x3,
+		Letterboxed: data[1]&0x02 != 0,
+		FilmMode:    data[1]&0x01 != 0,
+	}
+	height := 480
+	if (data[0]>>4)&0x03 == 1 {
+		attributes.Standard = "PAL"
+		height = 576
+	}
+	if (data[0]>>2)&0x03 == 3 {
+		attributes.AspectRatio = AspectRatio16x9
+	}
+
+	switch (data[1] >> 2) & 0x03 {
+	case 0:
+		attributes.Width, attributes.Height = 720, height
+	case 1:
+		attributes.Width, attributes.Height = 704, height
+	case 2:
+		attributes.Width, attributes.Height = 352, height
+	case 3:
+		attributes.Width, attributes.Height = 352, height/2
+	}
+	return attributes
+}
+
+// parseAudioStream 解析八字节的音轨属性。
+func parseAudioStream(data []byte) AudioStream {
+	stream := AudioStream{
+		Channels:      int(data[1]&0x07) + 1,
+		SampleRate:    48000,
+		CodeExtension: int(data[5]),
+	}
+	switch data[0] >> 5 {
+	case 0:
+		stream.Format = "AC-3"
+	case 2:
+		stream.Format = "MPEG-1"
+	case 3:
+		stream.Format = "MPEG-2"
+	case 4:
+		stream.Format = "LPCM"
+	case 6:
+		stream.Format = "DTS"
+	}
+	if (data[1]>>4)&0x03 == 1 {
+		stream.SampleRate = 96000
+	}
+	if (data[0]>>2)&0x03 == 1 {
+		stream.Language = decodeLanguage(data[2:4])
+	}
+	return stream
+}
+
+// parseSubpictureStream 解析六字节的字幕属性。
+func parseSubpictureStream(data []byte) SubpictureStream {
+	stream := SubpictureStream{CodeExtension: int(data[5])}
+	if data[0]&0x03 == 1 {
+		stream.Language = decodeLanguage(data[2:4])
+	}
+	return stream
+}
+
+// decodeLanguage 把两字节语言代码转换成小写字母代码；非字母内容视为未标注。
+func decodeLanguage(data []byte) string {
+	code := binary.BigEndian.Uint16(data)
+	first, second := byte(code>>8)|0x20, byte(code)|0x20
+	if first < 'a' || first > 'z' || second < 'a' || second > 'z' {
+		return ""
+	}
+	return string([]byte{first, second})
+}
@@ -0,0 +1,171 @@
+// Package dvdifo 提供不依赖 MediaInfo 的 DVD-Video IFO 解析能力。
+//
+// VIDEO_TS.IFO（VMG）描述整张光盘的标题及其所在标题集；VTS_xx_0.IFO（VTS）描述单个标题集的
+// 视频、音轨和字幕属性，以及每个标题的 PGC（节目链）、节目和单元播放时间。
+package dvdifo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const sectorSize = 2048
+
+// ErrNotIFO 表示数据不是可识别的 VMG 或 VTS IFO 文件。
+var ErrNotIFO = errors.New("dvdifo: not a DVD-Video IFO file")
+
+// VMG 表示 VIDEO_TS.IFO 中的光盘级信息。
+type VMG struct {
+	Version       uint16
+	TitleSetCount int
+	// Titles 按光盘标题编号顺序列出全部标题。
+	Titles []Title
+}
+
+// Title 表示 VMG 标题搜索表（TT_SRPT）中的一个标题。
+type Title struct {
+	// Number 是光盘级标题编号，从 1 开始。
+	Number int
+	// TitleSet 是标题所在的 VTS 编号，对应 VTS_xx_0.IFO 中的 xx。
+	TitleSet int
+	// TitleSetTitle 是该标题在所属 VTS 内部的标题编号（VTS_TTN）。
+	TitleSetTitle int
+	Angles        int
+	Chapters      int
+}
+
+// VTS 表示 VTS_xx_0.IFO 中的标题集信息。
+type VTS struct {
+	Version     uint16
+	Video       VideoAttributes
+	Audio       []AudioStream
+	Subpictures []SubpictureStream
+	// PGCs 按 PGC 编号顺序列出标题集内的所有节目链，PGC 编号从 1 开始。
+	PGCs []PGC
+	// Titles 按 VTS_TTN 顺序列出每个标题的章节入口。
+	Titles [][]PartOfTitle
+}
+
+// PartOfTitle 表示一个章节入口：章节从 PGC 的第 Program 个节目开始，两者都从 1 开始编号。
+type PartOfTitle struct {
+	PGC     int
+	Program int
+}
+
+// ParseVMG 解析 VIDEO_TS.IFO 数据。
+func ParseVMG(data []byte) (*VMG, error) {
+	if len(data) < 0x100 || string(data[:12]) != "DVDVIDEO-VMG" {
+		return nil, ErrNotIFO
+	}
+
+	vmg := &VMG{
+		Version:       binary.BigEndian.Uint16(data[0x20:0x22]),
+		TitleSetCount: int(binary.BigEndian.Uint16(data[0x3E:0x40])),
+	}
+
+	table, err := sectorTable(data, binary.BigEndian.Uint32(data[0xC4:0xC8]), "TT_SRPT")
+	if err != nil {
+		return nil, err
+	}
+	if len(table) < 8 {
+		return nil, fmt.Errorf("dvdifo: truncated TT_SRPT")
+	}
+	count := int(binary.BigEndian.Uint16(table[0:2]))
+	if len(table) < 8+count*12 {
+		return nil, fmt.Errorf("dvdifo: truncated TT_SRPT entries")
+	}
+	for index := 0; index < count; index++ {
+		entry := table[8+index*12:]
+		vmg.Titles = append(vmg.Titles, Title{
+			Number:        index + 1,
+			Angles:        int(entry[1]),
+			Chapters:      int(binary.BigEndian.Uint16(entry[2:4])),
+			TitleSet:      int(entry[6]),
+			TitleSetTitle: int(entry[7]),
+		})
+	}
+	return vmg, nil
+}
+
+// ParseVTS 解析 VTS_xx_0.IFO 数据。
+func ParseVTS(data []byte) (*VTS, error) {
+	if len(data) < 0x400 || string(data[:12]) != "DVDVIDEO-VTS" {
+		return nil, ErrNotIFO
+	}
+
+	vts := &VTS{
+		Version: binary.BigEndian.Uint16(data[0x20:0x22]),
+		Video:   parseVideoAttributes(data[0x200:0x202]),
+	}
+
+	audioCount := min(int(binary.BigEndian.Uint16(data[0x202:0x204])), 8)
+	for index := 0; index < audioCount; index++ {
+		vts.Audio = append(vts.Audio, parseAudioStream(data[0x204+index*8:0x204+index*8+8]))
+	}
+	subpictureCount := min(int(binary.BigEndian.Uint16(data[0x254:0x256])), 32)
+	for index := 0; index < subpictureCount; index++ {
+		vts.Subpictures = append(vts.Subpictures, parseSubpictureStream(data[0x256+index*6:0x256+index*6+6]))
+	}
+
+	pgcit, err := sectorTable(data, binary.BigEndian.Uint32(data[0xCC:0xD0]), "VTS_PGCIT")
+	if err != nil {
+		return nil, err
+	}
+	if vts.PGCs, err = parsePGCIT(pgcit); err != nil {
+		return nil, err
+	}
+
+	if sector := binary.BigEndian.Uint32(data[0xC8:0xCC]); sector != 0 {
+		ptt, err := sectorTable(data, sector, "VTS_PTT_SRPT")
+		if err != nil {
+			return nil, err
+		}
+		if vts.Titles, err = parsePTTSRPT(ptt); err != nil {
+			return nil, err
+		}
+	}
+	return vts, nil
+}
+
+// parsePTTSRPT 解析 VTS_PTT_SRPT，每个标题的章节数由相邻标题偏移之差推出。
+func parsePTTSRPT(table []byte) ([][]PartOfTitle, error) {
+	if len(table) < 8 {
+		return nil, fmt.Errorf("dvdifo: truncated VTS_PTT_SRPT")
+	}
+	count := int(binary.BigEndian.Uint16(table[0:2]))
+	end := int(binary.BigEndian.Uint32(table[4:8])) + 1
+	if end > len(table) || 8+count*4 > end {
+		return nil, fmt.Errorf("dvdifo: truncated VTS_PTT_SRPT")
+	}
+
+	offsets := make([]int, count+1)
+	for index := 0; index < count; index++ {
+		offsets[index] = int(binary.BigEndian.Uint32(table[8+index*4:]))
+	}
+	offsets[count] = end
+
+	titles := make([][]PartOfTitle, count)
+	for index := 0; index < count; index++ {
+		start, stop := offsets[index], offsets[index+1]
+		if start < 8+count*4 || stop > end || start > stop {
+			return nil, fmt.Errorf("dvdifo: invalid VTS_PTT_SRPT offset for title %d", index+1)
+		}
+		for offset := start; offset+4 <= stop; offset += 4 {
+			titles[index] = append(titles[index], PartOfTitle{
+				PGC:     int(binary.BigEndian.Uint16(table[offset:])),
+				Program: int(binary.BigEndian.Uint16(table[offset+2:])),
+			})
+		}
+	}
+	return titles, nil
+}
+
+// sectorTable 返回从 IFO 内第 sector 扇区开始的数据。
+func sectorTable(data []byte, sector uint32, name string) ([]byte, error) {
+	offset := int64(sector) * sectorSize
+	if sector == 0 || offset >= int64(len(data)) {
+		return nil, fmt.Errorf("dvdifo: %s sector %d is outside the IFO", name, sector)
+	}
+	return data[offset:], nil
+}
@@ -0,0 +1,177 @@
+// Package dvdifo 验证 VMG / VTS IFO 解析。
+
+package dvdifo
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// TestParseVMGReadsTitles 验证 VMG 标题搜索表中的标题集、章节数和角度数都能被解析。
+func TestParseVMGReadsTitles(t *testing.T) {
+	vmg, err := ParseVMG(buildTestVMG([]Title{
+		{TitleSet: 1, TitleSetTitle: 1, Angles: 1, Chapters: 2},
+		{TitleSet: 2, TitleSetTitle: 1, Angles: 2, Chapters: 28},
+	}))
+	if err != nil {
+		t.Fatalf("ParseVMG() error: %v", err)
+	}
+	if vmg.TitleSetCount != 2 || len(vmg.Titles) != 2 {
+		t.Fatalf("vmg = %+v, want 2 title sets and 2 titles", vmg)
+	}
+	if want := (Title{Number: 2, TitleSet: 2, TitleSetTitle: 1, Angles: 2, Chapters: 28}); vmg.Titles[1] != want {
+		t.Fatalf("Titles[1] = %+v, want %+v", vmg.Titles[1], want)
+	}
+
+	if _, err := ParseVMG(buildTestVTS(true, nil)); err != ErrNotIFO {
+		t.Fatalf("ParseVMG(VTS) error = %v, want ErrNotIFO", err)
+	}
+}
+
+// TestParseVTSReadsAttributesAndPGCs 验证 VTS 的视频、音轨、字幕属性以及 PGC 节目、单元时长和字幕流映射。
+func TestParseVTSReadsAttributesAndPGCs(t *testing.T) {
+	vts, err := ParseVTS(buildTestVTS(true, [][][4]byte{
+		{{0x00, 0x45, 0x30, 0xC0 | 0x15}, {0x01, 0x00, 0x00, 0xC0}},
+		{{0x00, 0x02, 0x00, 0x40 | 0x12}},
+	}))
+	if err != nil {
+		t.Fatalf("ParseVTS() error: %v", err)
+	}
+
+	if want := (VideoAttributes{MPEGVersion: 2, Standard: "NTSC", AspectRatio: AspectRatio16x9, Width: 720, Height: 480}); vts.Video != want {
+		t.Fatalf("Video = %+v, want %+v", vts.Video, want)
+	}
+	if len(vts.Audio) != 2 || vts.Audio[0] != (AudioStream{Format: "AC-3", Language: "en", Channels: 6, SampleRate: 48000, CodeExtension: 1}) || vts.Audio[1].Format != "DTS" || vts.Audio[1].Language != "ja" {
+		t.Fatalf("Audio = %+v, want AC-3 en 5.1 and DTS ja", vts.Audio)
+	}
+	if len(vts.Subpictures) != 3 || vts.Subpictures[0].Language != "en" || vts.Subpictures[1].Description() != "Forced" || vts.Subpictures[2].Language != "" {
+		t.Fatalf("Subpictures = %+v, want en, forced fr and an unlabelled stream", vts.Subpictures)
+	}
+
+	if len(vts.PGCs) != 2 || len(vts.Titles) != 2 {
+		t.Fatalf("got %d PGCs and %d titles, want 2 and 2", len(vts.PGCs), len(vts.Titles))
+	}
+	first := vts.PGCs[0]
+	if !first.Entry || len(first.Programs) != 2 || first.Programs[1] != 2 || len(first.Cells) != 2 || first.Cells[1].FirstSector != 100 {
+		t.Fatalf("PGCs[0] = %+v, want entry PGC with 2 programs and 2 cells", first)
+	}
+	wantFirst := 45*time.Minute + 30*time.Second + 15*time.Second*1001/30000 + time.Hour
+	if got := vts.TitleDuration(1); got != wantFirst {
+		t.Fatalf("TitleDuration(1) = %v, want %v", got, wantFirst)
+	}
+	if got := vts.TitleDuration(2); got != 2*time.Minute+12*time.Second/25 {
+		t.Fatalf("TitleDuration(2) = %v, want 2m0.48s", got)
+	}
+	if vts.LongestTitle() != 1 {
+		t.Fatalf("LongestTitle() = %d, want 1", vts.LongestTitle())
+	}
+	if len(vts.Titles[0]) != 2 || vts.Titles[0][1] != (PartOfTitle{PGC: 1, Program: 2}) {
+		t.Fatalf("Titles[0] = %+v, want two chapters in PGC 1", vts.Titles[0])
+	}
+
+	control := first.Subpictures[1]
+	if !control.Available || SubpictureStreamID(control.StreamFor(vts.Video.AspectRatio)) != 0x23 || SubpictureStreamID(control.StreamFor(AspectRatio4x3)) != 0x22 {
+		t.Fatalf("Subpictures[1] control = %+v, want wide stream 3 and standard stream 2", control)
+	}
+	if first.Subpictures[3].Available || first.AudioStreams[0] != 0 || first.AudioStreams[1] != 1 || first.AudioStreams[2] != -1 {
+		t.Fatalf("stream controls = %+v / %+v, want only two audio streams and three subpictures", first.AudioStreams, first.Subpictures[:4])
+	}
+}
+
+// TestParseVTSRejectsTruncatedPGC 验证 PGC 单元表越界时返回错误。
+func TestParseVTSRejectsTruncatedPGC(t *testing.T) {
+	data := buildTestVTS(false, [][][4]byte{{{0, 1, 0, 0x40}}})
+	if _, err := ParseVTS(data[:len(data)-10]); err == nil {
+		t.Fatal("ParseVTS(truncated) error = nil, want error")
+	}
+}
+
+// buildTestVMG 构造 VIDEO_TS.IFO：第 1 扇区是 TT_SRPT。
+func buildTestVMG(titles []Title) []byte {
+	data := make([]byte, sectorSize)
+	copy(data, "DVDVIDEO-VMG")
+	binary.BigEndian.PutUint16(data[0x3E:], 2)
+	binary.BigEndian.PutUint32(data[0xC4:], 1)
+
+	table := make([]byte, 8, 8+len(titles)*12)
+	binary.BigEndian.PutUint16(table[0:], uint16(len(titles)))
+	for _, title := range titles {
+		entry := make([]byte, 12)
+		entry[1] = byte(title.Angles)
+		binary.BigEndian.PutUint16(entry[2:], uint16(title.Chapters))
+		entry[6] = byte(title.TitleSet)
+		entry[7] = byte(title.TitleSetTitle)
+		table = append(table, entry...)
+	}
+	binary.BigEndian.PutUint32(table[4:], uint32(len(table)-1))
+	return append(data, table...)
+}
+
+// buildTestVTS 构造 VTS_xx_0.IFO：第 1 扇区是 VTS_PTT_SRPT，第 2 扇区是 VTS_PGCIT。
+//
+// 每个 PGC 对应一个标题，每个单元就是一个节目和一个章节；单元 BCD 时长由 pgcs 给出，PGC 头部时长留空。
+// 音轨为英语 AC-3 5.1 和日语 DTS；字幕为英语、强制法语和未标注语言三条，宽屏流编号依次为 1、3、5。
+func buildTestVTS(wide bool, pgcs [][][4]byte) []byte {
+	data := make([]byte, sectorSize)
+	copy(data, "DVDVIDEO-VTS")
+	binary.BigEndian.PutUint32(data[0xC8:], 1)
+	binary.BigEndian.PutUint32(data[0xCC:], 2)
+	data[0x200] = 0x40
+	if wide {
+		data[0x200] |= 0x0C
+	}
+
+	binary.BigEndian.PutUint16(data[0x202:], 2)
+	copy(data[0x204:], []byte{0x04, 0x05, 'e', 'n', 0, 1, 0, 0})
+	copy(data[0x20C:], []byte{0xC4, 0x05, 'j', 'a', 0, 1, 0, 0})
+	binary.BigEndian.PutUint16(data[0x254:], 3)
+	copy(data[0x256:], []byte{0x01, 0, 'e', 'n', 0, 1})
+	copy(data[0x25C:], []byte{0x01, 0, 'f', 'r', 0, 9})
+	copy(data[0x262:], []byte{0x00, 0, 0, 0, 0, 0})
+
+	ptt := make([]byte, 8+len(pgcs)*4)
+	binary.BigEndian.PutUint16(ptt[0:], uint16(len(pgcs)))
+	for index, cells := range pgcs {
+		binary.BigEndian.PutUint32(ptt[8+index*4:], uint32(len(ptt)))
+		for program := range cells {
+			ptt = binary.BigEndian.AppendUint16(ptt, uint16(index+1))
+			ptt = binary.BigEndian.AppendUint16(ptt, uint16(program+1))
+		}
+	}
+	binary.BigEndian.PutUint32(ptt[4:], uint32(len(ptt)-1))
+	data = append(data, ptt...)
+	data = append(data, make([]byte, 2*sectorSize-len(data))...)
+
+	pgcit := make([]byte, 8+len(pgcs)*8)
+	binary.BigEndian.PutUint16(pgcit[0:], uint16(len(pgcs)))
+	for index, cells := range pgcs {
+		pgcit[8+index*8] = 0x80 | byte(index+1)
+		binary.BigEndian.PutUint32(pgcit[8+index*8+4:], uint32(len(pgcit)))
+
+		pgc := make([]byte, 0xEC)
+		pgc[2], pgc[3] = byte(len(cells)), byte(len(cells))
+		binary.BigEndian.PutUint16(pgc[0x0C:], 0x8000)
+		binary.BigEndian.PutUint16(pgc[0x0E:], 0x8100)
+		binary.BigEndian.PutUint32(pgc[0x1C:], 0x80010000)
+		binary.BigEndian.PutUint32(pgc[0x20:], 0x82030000)
+		binary.BigEndian.PutUint32(pgc[0x24:], 0x84050000)
+		binary.BigEndian.PutUint16(pgc[0xE6:], 0xEC)
+		programMap := make([]byte, (len(cells)+1)&^1)
+		for cell := range cells {
+			programMap[cell] = byte(cell + 1)
+		}
+		binary.BigEndian.PutUint16(pgc[0xE8:], uint16(0xEC+len(programMap)))
+		pgc = append(pgc, programMap...)
+		for cell, duration := range cells {
+			entry := make([]byte, 24)
+			copy(entry[4:8], duration[:])
+			binary.BigEndian.PutUint32(entry[8:], uint32(cell*100))
+			binary.BigEndian.PutUint32(entry[20:], uint32(cell*100+99))
+			pgc = append(pgc, entry...)
+		}
+		pgcit = append(pgcit, pgc...)
+	}
+	binary.BigEndian.PutUint32(pgcit[4:], uint32(len(pgcit)-1))
+	return append(data, pgcit...)
+}
@@ -0,0 +1,226 @@
+// Package dvdifo 提供 PGC（节目链）、节目和单元播放时间的解析。
+
+package dvdifo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// PGC 表示一个节目链。
+type PGC struct {
+	// Entry 表示该 PGC 是某个标题的入口 PGC。
+	Entry    bool
+	Duration time.Duration
+	// Programs 列出每个节目的入口单元编号，单元编号从 1 开始。
+	Programs []int
+	Cells    []Cell
+	// AudioStreams 按逻辑音轨顺序给出对应的物理流编号；该逻辑音轨在本 PGC 中不可用时为 -1。
+	AudioStreams []int
+	// Subpictures 按逻辑字幕顺序给出不同显示模式下对应的物理字幕流。
+	Subpictures []SubpictureControl
+	NextPGC     int
+}
+
+// Cell 表示 PGC 中的一个播放单元。
+type Cell struct {
+	Duration time.Duration
+	// FirstSector 和 LastSector 是单元在标题 VOB 集合中的起止扇区。
+	FirstSector uint32
+	LastSector  uint32
+	// Angle 表示该单元属于多角度块；同一块中只有一个角度会被实际播放。
+	Angle bool
+	// AngleBlockStart 表示该单元是多角度块中的第一个角度。
+	AngleBlockStart bool
+}
+
+// SubpictureControl 表示某个逻辑字幕在各显示模式下使用的物理字幕流编号。
+type SubpictureControl struct {
+	Available bool
+	Standard  int
+	Wide      int
+	Letterbox int
+	PanScan   int
+}
+
+// StreamFor 按视频显示宽高比返回实际播放时使用的物理字幕流编号。
+func (c SubpictureControl) StreamFor(aspectRatio string) int {
+	if aspectRatio == AspectRatio16x9 {
+		return c.Wide
+	}
+	return c.Standard
+}
+
+// SubpictureStreamID 返回物理字幕流在 MPEG-PS 私有流 1 中的子流 ID，与 ffprobe / MediaInfo 报告的 0x20 起始编号一致。
+func SubpictureStreamID(physical int) int {
+	return 0x20 + physical
+}
+
+// PlaybackDuration 返回 PGC 实际播放时长；多角度块只计算第一个角度，避免把各角度时长重复累加。
+//
+// PGC 头部自带的总时长在部分光盘上会被填成 0，这时用各单元时长之和代替。
+func (p PGC) PlaybackDuration() time.Duration {
+	if p.Duration > 0 {
+		return p.Duration
+	}
+	var total time.Duration
+	for _, cell := range p.Cells {
+		if cell.Angle && !cell.AngleBlockStart {
+			continue
+		}
+		total += cell.Duration
+	}
+	return total
+}
+
+// TitlePGCs 返回 VTS 内第 ttn 个标题按章节顺序用到的 PGC 编号，相同编号只出现一次。
+func (v *VTS) TitlePGCs(ttn int) []int {
+	if ttn < 1 || ttn > len(v.Titles) {
+		return nil
+	}
+	seen := make(map[int]bool)
+	var pgcs []int
+	for _, part := range v.Titles[ttn-1] {
+		if part.PGC < 1 || part.PGC > len(v.PGCs) || seen[part.PGC] {
+			continue
+		}
+		seen[part.PGC] = true
+		pgcs = append(pgcs, part.PGC)
+	}
+	return pgcs
+}
+
+// TitleDuration 返回 VTS 内第 ttn 个标题的总播放时长。
+func (v *VTS) TitleDuration(ttn int) time.Duration {
+	var total time.Duration
+	for _, pgc := range v.TitlePGCs(ttn) {
+		total += v.PGCs[pgc-1].PlaybackDuration()
+	}
+	return total
+}
+
+// LongestTitle 返回 VTS 内播放时长最长的标题编号；没有任何标题时返回 0。
+func (v *VTS) LongestTitle() int {
+	best := 0
+	var bestDuration time.Duration
+	for ttn := 1; ttn <= len(v.Titles); ttn++ {
+		if duration := v.TitleDuration(ttn); duration > bestDuration {
+			best, bestDuration = ttn, duration
+		}
+	}
+	return best
+}
+
+// parsePGCIT 解析 VTS_PGCIT 中的全部 PGC。
+func parsePGCIT(table []byte) ([]PGC, error) {
+	if len(table) < 8 {
+		return nil, fmt.Errorf("dvdifo: truncated VTS_PGCIT")
+	}
+	count := int(binary.BigEndian.Uint16(table[0:2]))
+	if len(table) < 8+count*8 {
+		return nil, fmt.Errorf("dvdifo: truncated VTS_PGCIT entries")
+	}
+
+	pgcs := make([]PGC, 0, count)
+	for index := 0; index < count; index++ {
+		entry := table[8+index*8:]
+		offset := int(binary.BigEndian.Uint32(entry[4:8]))
+		if offset >= len(table) {
+			return nil, fmt.Errorf("dvdifo: PGC %d is outside VTS_PGCIT", index+1)
+		}
+		pgc, err := parsePGC(table[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("dvdifo: PGC %d: %w", index+1, err)
+		}
+		pgc.Entry = entry[0]&0x80 != 0
+		pgcs = append(pgcs, pgc)
+	}
+	return pgcs, nil
+}
+
+// parsePGC 解析单个 PGC 的头部、节目表和单元播放信息表。
+func parsePGC(data []byte) (PGC, error) {
+	const headerSize = 0xEC
+	if len(data) < headerSize {
+		return PGC{}, fmt.Errorf("truncated header")
+	}
+
+	programCount := int(data[2])
+	cellCount := int(data[3])
+	pgc := PGC{
+		Duration: decodeBCDTime(data[4:8]),
+		NextPGC:  int(binary.BigEndian.Uint16(data[0x9C:0x9E])),
+	}
+
+	for index := 0; index < 8; index++ {
+		control := binary.BigEndian.Uint16(data[0x0C+index*2:])
+		stream := -1
+		if control&0x8000 != 0 {
+			stream = int(control>>8) & 0x07
+		}
+		pgc.AudioStreams = append(pgc.AudioStreams, stream)
+	}
+	for index := 0; index < 32; index++ {
+		control := binary.BigEndian.Uint32(data[0x1C+index*4:])
+		pgc.Subpictures = append(pgc.Subpictures, SubpictureControl{
+			Available: control&0x80000000 != 0,
+			Standard:  int(control>>24) & 0x1F,
+			Wide:      int(control>>16) & 0x1F,
+			Letterbox: int(control>>8) & 0x1F,
+			PanScan:   int(control) & 0x1F,
+		})
+	}
+
+	if programCount > 0 {
+		offset := int(binary.BigEndian.Uint16(data[0xE6:0xE8]))
+		if offset < headerSize || offset+programCount > len(data) {
+			return PGC{}, fmt.Errorf("truncated program map")
+		}
+		for index := 0; index < programCount; index++ {
+			pgc.Programs = append(pgc.Programs, int(data[offset+index]))
+		}
+	}
+
+	if cellCount > 0 {
+		offset := int(binary.BigEndian.Uint16(data[0xE8:0xEA]))
+		if offset < headerSize || offset+cellCount*24 > len(data) {
+			return PGC{}, fmt.Errorf("truncated cell playback table")
+		}
+		for index := 0; index < cellCount; index++ {
+			cell := data[offset+index*24:]
+			blockMode := cell[0] >> 6
+			blockType := (cell[0] >> 4) & 0x03
+			pgc.Cells = append(pgc.Cells, Cell{
+				Duration:        decodeBCDTime(cell[4:8]),
+				FirstSector:     binary.BigEndian.Uint32(cell[8:12]),
+				LastSector:      binary.BigEndian.Uint32(cell[20:24]),
+				Angle:           blockType == 1,
+				AngleBlockStart: blockType == 1 && blockMode == 1,
+			})
+		}
+	}
+	return pgc, nil
+}
+
+// decodeBCDTime 解析 DVD 的 BCD 播放时间：时、分、秒和帧，帧字节最高两位表示帧率（01 为 25fps，11 为 29.97fps）。
+func decodeBCDTime(data []byte) time.Duration {
+	hours := decodeBCD(data[0])
+	minutes := decodeBCD(data[1])
+	seconds := decodeBCD(data[2])
+	frames := decodeBCD(data[3] & 0x3F)
+
+	duration := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	switch data[3] >> 6 {
+	case 1:
+		duration += time.Duration(frames) * time.Second / 25
+	case 3:
+		duration += time.Duration(frames) * time.Second * 1001 / 30000
+	}
+	return duration
+}
+
+// decodeBCD 把一个字节的两位 BCD 数字转换成整数。
+func decodeBCD(value byte) int {
+	return int(value>>4)*10 + int(value&0x0F)
+}
@@ -0,0 +1,211 @@
+// Package media 提供用于结果缓存的廉价源文件指纹计算。
+
+package media
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fingerprintEdgeBytes 是计算视频文件指纹时从文件首尾各读取的字节数。
+const fingerprintEdgeBytes = 1 << 20
+
+// Fingerprint 为用户输入的媒体路径计算廉价指纹，源内容未变化时结果稳定，可作为 MediaInfo、BDInfo 结果缓存的键。
+//
+// ISO 镜像（包括虚拟 ISO 路径）只取镜像大小和修改时间；蓝光目录取 index.bdmv、全部 MPLS 的大小和修改时间，
+// 以及最大 M2TS 首尾各 1 MiB 的内容；DVD 目录取全部 IFO/BUP 和最大 VOB；普通文件取大小、修改时间和首尾内容。
+func Fingerprint(input string) (string, error) {
+	cleaned := strings.TrimSpace(strings.Trim(input, "\""))
+	if cleaned == "" {
+		return "", errors.New("missing path")
+	}
+
+	h := sha256.New()
+	if isoPath, inner, ok := parseVirtualISOPath(cleaned); ok {
+		if err := fingerprintISO(h, isoPath); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "inner|%s\n", inner)
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	cleaned = filepath.Clean(cleaned)
+	info, err := os.Stat(cleaned)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "input|%s\n", cleaned)
+
+	switch {
+	case !info.IsDir() && isISOFile(cleaned):
+		err = fingerprintISO(h, cleaned)
+	case !info.IsDir():
+		if root, playlist, ok := resolveBDInfoPlaylistSelection(cleaned); ok {
+			fmt.Fprintf(h, "playlist|%s\n", playlist)
+			err = fingerprintBluray(h, root)
+		} else {
+			err = fingerprintFile(h, cleaned, info, true)
+		}
+	default:
+		err = fingerprintDir(h, cleaned)
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fingerprintDir 按目录内容类型选择蓝光、DVD、ISO 或逐文件的指纹规则。
+func fingerprintDir(h hash.Hash, dir string) error {
+	if root, ok := resolveBDInfoRoot(osTree{}, dir); ok {
+		return fingerprintBluray(h, root)
+	}
+	if videoTS, ok := resolveDVDVideoRoot(osTree{}, dir); ok {
+		return fingerprintDVD(h, videoTS)
+	}
+
+	isoPath, err := findISOInDir(dir)
+	if err == nil {
+		return fingerprintISO(h, isoPath)
+	}
+	if !errors.Is(err, errNoISO) {
+		return err
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fingerprintFile(h, path, info, false)
+	})
+}
+
+// fingerprintBluray 记录蓝光根目录下 index.bdmv、全部 MPLS 以及最大 M2TS 的指纹。
+func fingerprintBluray(h hash.Hash, root string) error {
+	bdmv := filepath.Join(root, "BDMV")
+	entries, err := os.ReadDir(bdmv)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), "index.bdmv") {
+			if err := fingerprintEntry(h, filepath.Join(bdmv, entry.Name()), entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	playlistDir := filepath.Join(bdmv, "PLAYLIST")
+	if playlists, err := os.ReadDir(playlistDir); err == nil {
+		for _, entry := range playlists {
+			if entry.IsDir() || !isMPLSFile(entry.Name()) {
+				continue
+			}
+			if err := fingerprintEntry(h, filepath.Join(playlistDir, entry.Name()), entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	mainStream, err := findLargestM2TS(osTree{}, bdmv)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(mainStream)
+	if err != nil {
+		return err
+	}
+	return fingerprintFile(h, mainStream, info, true)
+}
+
+// fingerprintDVD 记录 VIDEO_TS 下全部 IFO/BUP 以及最大 VOB 的指纹。
+func fingerprintDVD(h hash.Hash, videoTS string) error {
+	entries, err := os.ReadDir(videoTS)
+	if err != nil {
+		return err
+	}
+
+	var largestPath string
+	var largest fs.FileInfo
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(videoTS, entry.Name())
+		switch strings.ToUpper(filepath.Ext(entry.Name())) {
+		case ".IFO", ".BUP":
+			if err := fingerprintEntry(h, path, entry); err != nil {
+				return err
+			}
+		case ".VOB":
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if largest == nil || info.Size() > largest.Size() {
+				largestPath, largest = path, info
+			}
+		}
+	}
+	if largest == nil {
+		return nil
+	}
+	return fingerprintFile(h, largestPath, largest, true)
+}
+
+// fingerprintISO 只记录 ISO 镜像的路径、大小和修改时间，避免读取整张镜像。
+func fingerprintISO(h hash.Hash, isoPath string) error {
+	info, err := os.Stat(isoPath)
+	if err != nil {
+		return err
+	}
+	return fingerprintFile(h, filepath.Clean(isoPath), info, false)
+}
+
+// fingerprintEntry 记录目录项对应文件的路径、大小和修改时间。
+func fingerprintEntry(h hash.Hash, path string, entry fs.DirEntry) error {
+	info, err := entry.Info()
+	if err != nil {
+		return err
+	}
+	return fingerprintFile(h, path, info, false)
+}
+
+// fingerprintFile 记录文件的路径、大小和修改时间；edges 为 true 时再追加文件首尾各 1 MiB 的内容，小文件直接读取全文。
+func fingerprintFile(h hash.Hash, path string, info fs.FileInfo, edges bool) error {
+	fmt.Fprintf(h, "file|%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+	if !edges {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if info.Size() <= 2*fingerprintEdgeBytes {
+		_, err := io.Copy(h, file)
+		return err
+	}
+	if _, err := io.CopyN(h, file, fingerprintEdgeBytes); err != nil {
+		return err
+	}
+	if _, err := file.Seek(-fingerprintEdgeBytes, io.SeekEnd); err != nil {
+		return err
+	}
+	_, err = io.CopyN(h, file, fingerprintEdgeBytes)
+	return err
+}
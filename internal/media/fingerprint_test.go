@@ -0,0 +1,68 @@
+// Package media 验证结果缓存使用的源文件指纹。
+
+package media
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFingerprintTracksBlurayKeyFiles 验证蓝光目录的指纹在无关文件变化时保持稳定，在 MPLS 或主视频首尾内容变化时改变。
+func TestFingerprintTracksBlurayKeyFiles(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "Disc")
+	for _, dir := range []string{"BDMV/PLAYLIST", "BDMV/STREAM", "CERTIFICATE"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("MkdirAll() error: %v", err)
+		}
+	}
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(root, name), data, 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+	mainStream := make([]byte, 3*fingerprintEdgeBytes)
+	write("BDMV/index.bdmv", []byte("INDX0200"))
+	write("BDMV/PLAYLIST/00800.mpls", []byte("MPLS0200"))
+	write("BDMV/STREAM/00055.m2ts", mainStream)
+	write("BDMV/STREAM/00001.m2ts", make([]byte, 1024))
+
+	fingerprint := func(input string) string {
+		t.Helper()
+		value, err := Fingerprint(input)
+		if err != nil {
+			t.Fatalf("Fingerprint(%q) error: %v", input, err)
+		}
+		return value
+	}
+
+	base := fingerprint(root)
+	write("CERTIFICATE/id.bdmv", []byte("ignored"))
+	if got := fingerprint(root); got != base {
+		t.Fatal("fingerprint changed after touching a file outside the key set")
+	}
+	if fingerprint(filepath.Join(root, "BDMV", "PLAYLIST", "00800.mpls")) == base {
+		t.Fatal("MPLS selection should produce a different fingerprint from the disc root")
+	}
+
+	mainStream[len(mainStream)-1] = 1
+	write("BDMV/STREAM/00055.m2ts", mainStream)
+	stamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(root, "BDMV/STREAM/00055.m2ts"), stamp, stamp); err != nil {
+		t.Fatalf("Chtimes() error: %v", err)
+	}
+	changed := fingerprint(root)
+	if changed == base {
+		t.Fatal("fingerprint did not change after the main stream tail changed")
+	}
+
+	write("BDMV/PLAYLIST/00801.mpls", []byte("MPLS0200"))
+	if fingerprint(root) == changed {
+		t.Fatal("fingerprint did not change after a playlist was added")
+	}
+
+	if _, err := Fingerprint(filepath.Join(root, "missing")); err == nil {
+		t.Fatal("Fingerprint(missing) error = nil, want error")
+	}
+}
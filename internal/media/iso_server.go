@@ -0,0 +1,173 @@
+// Package media 提供把 ISO 内部文件通过本机 HTTP Range 服务暴露给外部工具的能力。
+
+package media
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"minfo/internal/media/isofs"
+)
+
+const isoServerHost = "127.0.0.1"
+
+// isoServer 是只监听回环地址的 HTTP 服务，按随机 token 区分已共享的 ISO 镜像。
+//
+// ffprobe、ffmpeg 和 mediainfo 可以直接读取 http://127.0.0.1:port/<token>/BDMV/STREAM/00800.m2ts，
+// 服务端通过 http.ServeContent 支持 Range 请求，因此不需要挂载 ISO。
+type isoServer struct {
+	mu      sync.Mutex
+	baseURL string
+	images  map[string]*sharedISOImage
+}
+
+// sharedISOImage 记录一个已共享镜像及正在读取它的请求数；撤销后要等最后一个请求结束才关闭镜像。
+type sharedISOImage struct {
+	image    *isofs.Image
+	active   int
+	released bool
+}
+
+var sharedISOServer = &isoServer{images: make(map[string]*sharedISOImage)}
+
+// IsISOServerURL 判断路径是否是本机 ISO HTTP 服务生成的地址。
+func IsISOServerURL(path string) bool {
+	return strings.HasPrefix(path, "http://"+isoServerHost+":")
+}
+
+// serveISOFile 打开 ISO 并返回镜像内文件的本机 HTTP 地址；返回的清理函数会撤销 token，并在进行中的请求结束后关闭镜像。
+func serveISOFile(isoPath, inner string) (string, func(), error) {
+	image, err := isofs.Open(isoPath)
+	if err != nil {
+		return "", func() {}, err
+	}
+	info, err := image.Stat(isoRelativePath(inner))
+	if err != nil {
+		image.Close()
+		return "", func() {}, fmt.Errorf("path not found: %v", err)
+	}
+	if info.IsDir() {
+		image.Close()
+		return "", func() {}, errors.New("ISO directories can only be accessed by mounting the image")
+	}
+
+	baseURL, release, err := sharedISOServer.share(image)
+	if err != nil {
+		image.Close()
+		return "", func() {}, err
+	}
+	return baseURL + escapeISOInnerPath(inner), release, nil
+}
+
+// share 在首次调用时启动服务，并为镜像分配一个新的 token。
+func (s *isoServer) share(image *isofs.Image) (string, func(), error) {
+	token, err := newISOServerToken()
+	if err != nil {
+		return "", func() {}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.baseURL == "" {
+		listener, err := net.Listen("tcp", net.JoinHostPort(isoServerHost, "0"))
+		if err != nil {
+			return "", func() {}, fmt.Errorf("start ISO range server: %w", err)
+		}
+		s.baseURL = "http://" + listener.Addr().String()
+		go http.Serve(listener, s)
+	}
+	shared := &sharedISOImage{image: image}
+	s.images[token] = shared
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.images, token)
+			shared.released = true
+			idle := shared.active == 0
+			s.mu.Unlock()
+			if idle {
+				image.Close()
+			}
+		})
+	}
+	return s.baseURL + "/" + token, release, nil
+}
+
+// lookup 返回 token 对应的镜像并登记一个进行中的请求；调用方必须在读取结束后调用返回的 done。
+func (s *isoServer) lookup(token string) (*isofs.Image, func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	shared, ok := s.images[token]
+	if !ok {
+		return nil, func() {}, false
+	}
+	shared.active++
+	done := func() {
+		s.mu.Lock()
+		shared.active--
+		closeNow := shared.released && shared.active == 0
+		s.mu.Unlock()
+		if closeNow {
+			shared.image.Close()
+		}
+	}
+	return shared.image, done, true
+}
+
+// ServeHTTP 以只读方式输出镜像内文件，支持 HEAD 和 Range 请求。
+func (s *isoServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, inner, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	image, done, ok := s.lookup(token)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	defer done()
+
+	file, err := image.Open(isoRelativePath(inner))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file.(*isofs.File))
+}
+
+// newISOServerToken 生成不可猜测的 URL token。
+func newISOServerToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// escapeISOInnerPath 对镜像内路径逐段转义，保留 / 分隔符。
+func escapeISOInnerPath(inner string) string {
+	parts := strings.Split(isoRelativePath(inner), "/")
+	for index, part := range parts {
+		parts[index] = url.PathEscape(part)
+	}
+	return "/" + strings.Join(parts, "/")
+}
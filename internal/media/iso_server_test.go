@@ -0,0 +1,100 @@
+// Package media 验证 ISO 内部文件的本机 HTTP Range 服务。
+
+package media
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestServeISOFileSupportsRangeRequests 验证镜像内文件可以通过回环地址按字节范围读取，撤销后地址失效。
+func TestServeISOFileSupportsRangeRequests(t *testing.T) {
+	isoPath := filepath.Join(t.TempDir(), "disc.iso")
+	content := []byte("0123456789abcdef")
+	writeTestISOImage(t, isoPath, map[string][]byte{
+		"BDMV/STREAM/00800.M2TS": content,
+	})
+
+	url, release, err := serveISOFile(isoPath, "/BDMV/STREAM/00800.m2ts")
+	if err != nil {
+		t.Fatalf("serveISOFile() error: %v", err)
+	}
+	if !IsISOServerURL(url) || !strings.HasSuffix(url, "/BDMV/STREAM/00800.m2ts") {
+		t.Fatalf("serveISOFile() url = %q, want loopback url ending with inner path", url)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error: %v", err)
+	}
+	request.Header.Set("Range", "bytes=4-9")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET range error: %v", err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if response.StatusCode != http.StatusPartialContent || string(body) != "456789" {
+		t.Fatalf("GET range = %d %q, want 206 %q", response.StatusCode, body, "456789")
+	}
+
+	release()
+	response, err = http.Get(url)
+	if err != nil {
+		t.Fatalf("GET after release error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET after release status = %d, want 404", response.StatusCode)
+	}
+
+	if _, _, err := serveISOFile(isoPath, "/BDMV"); err == nil {
+		t.Fatal("serveISOFile(directory) error = nil, want error")
+	}
+}
+
+// TestServeISOFileReleaseWaitsForActiveReads 验证撤销 token 时进行中的读取仍能完整读完，镜像在请求结束后才关闭。
+func TestServeISOFileReleaseWaitsForActiveReads(t *testing.T) {
+	isoPath := filepath.Join(t.TempDir(), "disc.iso")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1<<19)
+	writeTestISOImage(t, isoPath, map[string][]byte{
+		"BDMV/STREAM/00800.M2TS": content,
+	})
+
+	url, release, err := serveISOFile(isoPath, "/BDMV/STREAM/00800.m2ts")
+	if err != nil {
+		t.Fatalf("serveISOFile() error: %v", err)
+	}
+
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET error: %v", err)
+	}
+	defer response.Body.Close()
+	head := make([]byte, 16)
+	if _, err := io.ReadFull(response.Body, head); err != nil {
+		t.Fatalf("read first bytes error: %v", err)
+	}
+
+	release()
+	rest, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("read after release error: %v", err)
+	}
+	if body := append(head, rest...); !bytes.Equal(body, content) {
+		t.Fatalf("body after release = %d bytes, want %d bytes of original content", len(body), len(content))
+	}
+
+	response, err = http.Get(url)
+	if err != nil {
+		t.Fatalf("GET after release error: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("GET after release status = %d, want 404", response.StatusCode)
+	}
+}
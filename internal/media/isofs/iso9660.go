@@ -0,0 +1,188 @@
+// Package isofs 实现 ISO9660（含 Joliet 扩展）目录结构的解析。
+
+package isofs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	isoFirstDescriptorSector = 16
+	isoMaxDescriptors        = 64
+
+	isoFlagDirectory   = 0x02
+	isoFlagMultiExtent = 0x80
+)
+
+// isoVolume 保存 ISO9660 卷的根目录和名称编码方式。
+type isoVolume struct {
+	r         io.ReaderAt
+	size      int64
+	blockSize int64
+	joliet    bool
+	root      *node
+}
+
+// openISO9660 读取卷描述符集合；存在 Joliet 补充卷描述符时优先使用其目录树。size 是镜像总字节数，未知时为 -1。
+func openISO9660(r io.ReaderAt, size int64) (*isoVolume, error) {
+	var primary, joliet []byte
+descriptors:
+	for index := 0; index < isoMaxDescriptors; index++ {
+		data := make([]byte, sectorSize)
+		if _, err := r.ReadAt(data, int64(isoFirstDescriptorSector+index)*sectorSize); err != nil {
+			return nil, err
+		}
+		if string(data[1:6]) != "CD001" {
+			return nil, errors.New("isofs: missing ISO9660 volume descriptor")
+		}
+
+		switch data[0] {
+		case 1:
+			if primary == nil {
+				primary = data
+			}
+		case 2:
+			if joliet == nil && isJolietEscape(data[88:91]) {
+				joliet = data
+			}
+		case 255:
+			break descriptors
+		}
+	}
+
+	descriptor := primary
+	volume := &isoVolume{r: r, size: size}
+	if joliet != nil {
+		descriptor = joliet
+		volume.joliet = true
+	}
+	if descriptor == nil {
+		return nil, errors.New("isofs: missing ISO9660 primary volume descriptor")
+	}
+
+	volume.blockSize = int64(binary.LittleEndian.Uint16(descriptor[128:130]))
+	if volume.blockSize == 0 {
+		volume.blockSize = sectorSize
+	}
+	if !validLogicalBlockSize(volume.blockSize) {
+		return nil, fmt.Errorf("isofs: invalid ISO9660 logical block size %d", volume.blockSize)
+	}
+	root, _, err := volume.parseRecord(descriptor[156:190])
+	if err != nil {
+		return nil, err
+	}
+	root.name = ""
+	volume.root = root
+	return volume, nil
+}
+
+// isJolietEscape 判断补充卷描述符的转义序列是否声明了 Joliet UCS-2 编码。
+func isJolietEscape(escape []byte) bool {
+	switch string(escape) {
+	case "%/@", "%/C", "%/E":
+		return true
+	default:
+		return false
+	}
+}
+
+// readDir 解析目录记录；目录记录不会跨越扇区，遇到长度为 0 的记录时跳到下一个扇区。
+// 多区段文件的各条记录会合并成一个节点。
+func (v *isoVolume) readDir(dir *node) ([]*node, error) {
+	data, err := readAllExtents(v.r, v.size, dir.extents, dir.size, maxDirectoryBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*node
+	var pending *node
+	for pos := 0; pos < len(data); {
+		length := int(data[pos])
+		if length == 0 {
+			pos = (pos/int(v.blockSize) + 1) * int(v.blockSize)
+			continue
+		}
+		if pos+length > len(data) || length < 34 {
+			return nil, fmt.Errorf("isofs: invalid directory record at %d", pos)
+		}
+
+		record := data[pos : pos+length]
+		pos += length
+		nameLength := int(record[32])
+		if nameLength == 1 && (record[33] == 0 || record[33] == 1) {
+			continue
+		}
+
+		child, multiExtent, err := v.parseRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		if pending != nil && pending.name == child.name {
+			pending.extents = append(pending.extents, child.extents...)
+			pending.size += child.size
+		} else {
+			pending = child
+			children = append(children, child)
+		}
+		if !multiExtent {
+			pending = nil
+		}
+	}
+	return children, nil
+}
+
+// parseRecord 把单条目录记录转换成节点，并返回是否还有后续区段。
+func (v *isoVolume) parseRecord(record []byte) (*node, bool, error) {
+	if len(record) < 34 {
+		return nil, false, errors.New("isofs: directory record too short")
+	}
+	nameLength := int(record[32])
+	if 33+nameLength > len(record) {
+		return nil, false, errors.New("isofs: directory record name out of range")
+	}
+
+	location := int64(binary.LittleEndian.Uint32(record[2:6]))
+	size := int64(binary.LittleEndian.Uint32(record[10:14]))
+	flags := record[25]
+	n := &node{
+		name:    v.decodeName(record[33 : 33+nameLength]),
+		dir:     flags&isoFlagDirectory != 0,
+		size:    size,
+		modTime: parseISORecordTime(record[18:25]),
+		extents: []extent{{offset: (location + int64(record[1])) * v.blockSize, length: size}},
+	}
+	return n, flags&isoFlagMultiExtent != 0, nil
+}
+
+// decodeName 解码文件标识符，并去掉 ;1 版本号和无扩展名时残留的句点。
+func (v *isoVolume) decodeName(raw []byte) string {
+	var name string
+	if v.joliet {
+		units := make([]uint16, 0, len(raw)/2)
+		for index := 0; index+1 < len(raw); index += 2 {
+			units = append(units, binary.BigEndian.Uint16(raw[index:]))
+		}
+		name = string(utf16.Decode(units))
+	} else {
+		name = string(raw)
+	}
+	if index := strings.LastIndex(name, ";"); index >= 0 {
+		name = name[:index]
+	}
+	return strings.TrimSuffix(name, ".")
+}
+
+// parseISORecordTime 解析目录记录中 7 字节的录制时间，时区以 15 分钟为单位。
+func parseISORecordTime(raw []byte) time.Time {
+	if raw[0] == 0 && raw[1] == 0 {
+		return time.Time{}
+	}
+	zone := time.FixedZone("", int(int8(raw[6]))*15*60)
+	return time.Date(1900+int(raw[0]), time.Month(raw[1]), int(raw[2]), int(raw[3]), int(raw[4]), int(raw[5]), 0, zone)
+}
@@ -0,0 +1,495 @@
+// Package isofs 提供不依赖内核挂载的只读 ISO9660 / UDF 镜像读取能力。
+//
+// 镜像以 fs.FS 的形式暴露：可以列目录、读取文件元数据，并按任意偏移读取文件内容。
+// UDF（含 2.50/2.60 元数据分区）优先于 ISO9660；ISO9660 会优先使用 Joliet 扩展的长文件名。
+package isofs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format 常量描述镜像实际使用的文件系统。
+const (
+	FormatUDF     = "udf"
+	FormatISO9660 = "iso9660"
+)
+
+const sectorSize = 2048
+
+const (
+	// maxDirectoryBytes 限制单个目录或描述符块一次读入内存的大小；真实光盘的目录远小于这个值。
+	maxDirectoryBytes = 4 << 20
+	// MaxReadFileBytes 限制 ReadFile 一次读入内存的文件大小，更大的文件需要通过 Open 流式读取。
+	MaxReadFileBytes = 64 << 20
+
+	minLogicalBlockSize = 512
+	maxLogicalBlockSize = 4096
+
+	// maxDirectoryDepth 限制目录嵌套层数；真实光盘的目录层级很浅，过深通常说明镜像被构造成了环或长链。
+	maxDirectoryDepth = 64
+)
+
+// ErrUnsupported 表示镜像中既没有可识别的 UDF 卷，也没有 ISO9660 主卷描述符。
+var ErrUnsupported = errors.New("isofs: no UDF or ISO9660 file system found")
+
+// ErrTooLarge 表示要读入内存的目录或文件超过了大小上限。
+var ErrTooLarge = errors.New("isofs: content too large to read into memory")
+
+// errDirectoryCycle 表示目录项指向了自身或某个上级目录的内容，继续遍历会陷入死循环。
+var errDirectoryCycle = errors.New("isofs: directory refers back to an ancestor")
+
+// errDirectoryTooDeep 表示目录嵌套超过了 maxDirectoryDepth。
+var errDirectoryTooDeep = errors.New("isofs: directory nesting too deep")
+
+// errExtentOutOfRange 表示区段指向镜像末尾之外，通常说明镜像已损坏或被篡改。
+var errExtentOutOfRange = errors.New("isofs: extent beyond end of image")
+
+// extent 表示文件内容在镜像中的一段连续字节；sparse 区段不占用镜像空间，读取时返回 0。
+type extent struct {
+	offset int64
+	length int64
+	sparse bool
+}
+
+// node 表示镜像中的一个文件或目录；目录的子项在首次访问时才解析。
+// parent 和 depth 只对目录有意义，在父目录解析子项时设置，用于发现目录环。
+type node struct {
+	name    string
+	dir     bool
+	size    int64
+	modTime time.Time
+	extents []extent
+	parent  *node
+	depth   int
+
+	once     sync.Once
+	children []*node
+	err      error
+}
+
+// Image 表示一个已打开的 ISO 镜像，实现 fs.FS、fs.ReadDirFS、fs.StatFS 和 fs.ReadFileFS。
+type Image struct {
+	r       io.ReaderAt
+	size    int64
+	closer  io.Closer
+	format  string
+	root    *node
+	readDir func(dir *node) ([]*node, error)
+}
+
+// Open 打开磁盘上的 ISO 文件并解析其中的文件系统。
+func Open(name string) (*Image, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	image, err := NewImage(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	image.closer = file
+	return image, nil
+}
+
+// NewImage 从任意 io.ReaderAt 解析镜像；调用方负责关闭底层数据源。
+func NewImage(r io.ReaderAt) (*Image, error) {
+	image := &Image{r: r, size: readerSize(r)}
+	if volume, err := openUDF(r, image.size); err == nil {
+		image.format = FormatUDF
+		image.root = volume.root
+		image.readDir = volume.readDir
+		return image, nil
+	}
+	if volume, err := openISO9660(r, image.size); err == nil {
+		image.format = FormatISO9660
+		image.root = volume.root
+		image.readDir = volume.readDir
+		return image, nil
+	}
+	return nil, ErrUnsupported
+}
+
+// Format 返回镜像使用的文件系统类型。
+func (img *Image) Format() string {
+	return img.format
+}
+
+// Close 关闭 Open 打开的底层文件；NewImage 创建的镜像关闭时不做任何事。
+func (img *Image) Close() error {
+	if img.closer == nil {
+		return nil
+	}
+	return img.closer.Close()
+}
+
+// Open 实现 fs.FS；name 使用 fs.ValidPath 规定的无前导斜杠路径，文件名匹配不区分大小写。
+func (img *Image) Open(name string) (fs.File, error) {
+	n, err := img.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &File{image: img, node: n}, nil
+}
+
+// Stat 实现 fs.StatFS。
+func (img *Image) Stat(name string) (fs.FileInfo, error) {
+	n, err := img.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return fileInfo{n}, nil
+}
+
+// ReadDir 实现 fs.ReadDirFS，返回按名称排序的目录项。
+func (img *Image) ReadDir(name string) ([]fs.DirEntry, error) {
+	n, err := img.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	children, err := img.children(n)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return dirEntries(children), nil
+}
+
+// ReadFile 实现 fs.ReadFileFS；超过 MaxReadFileBytes 的文件返回 ErrTooLarge，需要改用 Open 流式读取。
+func (img *Image) ReadFile(name string) ([]byte, error) {
+	n, err := img.lookup("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if n.dir {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.New("is a directory")}
+	}
+	data, err := readAllExtents(img.r, img.size, n.extents, n.size, MaxReadFileBytes)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// lookup 按路径逐级查找节点；优先精确匹配，找不到时再做大小写不敏感匹配。
+func (img *Image) lookup(op, name string) (*node, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	current := img.root
+	if name == "." {
+		return current, nil
+	}
+	for _, part := range strings.Split(name, "/") {
+		if !current.dir {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		children, err := img.children(current)
+		if err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		next := findChild(children, part)
+		if next == nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// children 返回目录的子节点，并缓存首次解析结果；子目录指向上级目录或嵌套过深时返回错误。
+func (img *Image) children(dir *node) ([]*node, error) {
+	dir.once.Do(func() {
+		children, err := img.readDir(dir)
+		if err == nil {
+			err = linkChildDirectories(dir, children)
+		}
+		if err != nil {
+			dir.err = err
+			return
+		}
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].name < children[j].name
+		})
+		dir.children = children
+	})
+	return dir.children, dir.err
+}
+
+// linkChildDirectories 为子目录记录父目录和层级，并检查子目录内容是否与自身或任一上级目录相同。
+func linkChildDirectories(dir *node, children []*node) error {
+	for _, child := range children {
+		if !child.dir {
+			continue
+		}
+		child.parent = dir
+		child.depth = dir.depth + 1
+		if child.depth > maxDirectoryDepth {
+			return errDirectoryTooDeep
+		}
+		for ancestor := dir; ancestor != nil; ancestor = ancestor.parent {
+			if sameDirectoryContent(child, ancestor) {
+				return errDirectoryCycle
+			}
+		}
+	}
+	return nil
+}
+
+// sameDirectoryContent 判断两个目录节点的内容是否从镜像中的同一位置开始。
+func sameDirectoryContent(left, right *node) bool {
+	if len(left.extents) == 0 || len(right.extents) == 0 {
+		return false
+	}
+	a, b := left.extents[0], right.extents[0]
+	return !a.sparse && !b.sparse && a.length > 0 && a.offset == b.offset
+}
+
+// readAt 按文件内偏移读取内容，并把读取请求拆分到各个区段。
+func (img *Image) readAt(n *node, p []byte, off int64) (int, error) {
+	if off >= n.size {
+		return 0, io.EOF
+	}
+	if remaining := n.size - off; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	read, err := readExtents(img.r, n.extents, p, off)
+	if err != nil {
+		return read, err
+	}
+	if off+int64(read) >= n.size {
+		return read, io.EOF
+	}
+	return read, nil
+}
+
+// findChild 在子节点中查找名称匹配的一项。
+func findChild(children []*node, name string) *node {
+	for _, child := range children {
+		if child.name == name {
+			return child
+		}
+	}
+	for _, child := range children {
+		if strings.EqualFold(child.name, name) {
+			return child
+		}
+	}
+	return nil
+}
+
+// readExtents 从区段列表中读取 p，off 是相对第一个区段起点的偏移。
+func readExtents(r io.ReaderAt, extents []extent, p []byte, off int64) (int, error) {
+	read := 0
+	for _, ext := range extents {
+		if len(p) == 0 {
+			break
+		}
+		if off >= ext.length {
+			off -= ext.length
+			continue
+		}
+
+		chunk := p
+		if remaining := ext.length - off; int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+		if ext.sparse {
+			clear(chunk)
+		} else if n, err := r.ReadAt(chunk, ext.offset+off); n < len(chunk) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return read + n, err
+		}
+		read += len(chunk)
+		p = p[len(chunk):]
+		off = 0
+	}
+	if len(p) > 0 {
+		return read, io.ErrUnexpectedEOF
+	}
+	return read, nil
+}
+
+// readAllExtents 读取区段列表中的前 size 个字节；分配内存前会确认 size 不超过 limit，且区段都落在 imageSize 以内。
+func readAllExtents(r io.ReaderAt, imageSize int64, extents []extent, size, limit int64) ([]byte, error) {
+	if size < 0 || size > limit {
+		return nil, ErrTooLarge
+	}
+	if err := checkExtents(extents, size, imageSize); err != nil {
+		return nil, err
+	}
+	data := make([]byte, size)
+	if _, err := readExtents(r, extents, data, 0); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// checkExtents 确认读取前 size 个字节时用到的非稀疏区段都位于镜像范围内；imageSize 小于 0 表示镜像大小未知，只检查偏移非负。
+func checkExtents(extents []extent, size, imageSize int64) error {
+	for _, ext := range extents {
+		if size <= 0 {
+			break
+		}
+		take := min(ext.length, size)
+		size -= take
+		if ext.sparse {
+			continue
+		}
+		if ext.offset < 0 || take < 0 || (imageSize >= 0 && (ext.offset > imageSize || take > imageSize-ext.offset)) {
+			return errExtentOutOfRange
+		}
+	}
+	return nil
+}
+
+// readerSize 返回数据源的总字节数；无法得知时返回 -1。
+func readerSize(r io.ReaderAt) int64 {
+	switch source := r.(type) {
+	case interface{ Size() int64 }:
+		return source.Size()
+	case interface{ Stat() (os.FileInfo, error) }:
+		if info, err := source.Stat(); err == nil {
+			return info.Size()
+		}
+	}
+	return -1
+}
+
+// validLogicalBlockSize 判断逻辑块大小是否是 512 到 4096 之间的 2 的幂。
+func validLogicalBlockSize(size int64) bool {
+	return size >= minLogicalBlockSize && size <= maxLogicalBlockSize && size&(size-1) == 0
+}
+
+// File 表示镜像中已打开的文件或目录，支持随机读取和按批读取目录项。
+type File struct {
+	image  *Image
+	node   *node
+	offset int64
+	dirPos int
+}
+
+// Stat 返回文件元数据。
+func (f *File) Stat() (fs.FileInfo, error) {
+	return fileInfo{f.node}, nil
+}
+
+// Read 从当前偏移顺序读取文件内容。
+func (f *File) Read(p []byte) (int, error) {
+	if f.node.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.node.name, Err: errors.New("is a directory")}
+	}
+	n, err := f.image.readAt(f.node, p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt 从指定偏移读取文件内容，不改变当前偏移。
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if f.node.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.node.name, Err: errors.New("is a directory")}
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.node.name, Err: fs.ErrInvalid}
+	}
+	n, err := f.image.readAt(f.node, p, off)
+	if err == io.EOF && n == len(p) {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek 调整下一次 Read 的偏移。
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.node.size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.node.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.node.name, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// ReadDir 实现 fs.ReadDirFile。
+func (f *File) ReadDir(count int) ([]fs.DirEntry, error) {
+	if !f.node.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.node.name, Err: errors.New("not a directory")}
+	}
+	children, err := f.image.children(f.node)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := children[f.dirPos:]
+	if count > 0 {
+		if len(remaining) == 0 {
+			return nil, io.EOF
+		}
+		if len(remaining) > count {
+			remaining = remaining[:count]
+		}
+	}
+	f.dirPos += len(remaining)
+	return dirEntries(remaining), nil
+}
+
+// Close 实现 fs.File；镜像内文件没有需要释放的资源。
+func (f *File) Close() error {
+	return nil
+}
+
+// fileInfo 把节点包装成 fs.FileInfo。
+type fileInfo struct {
+	node *node
+}
+
+func (i fileInfo) Name() string {
+	if i.node.name == "" {
+		return "."
+	}
+	return path.Base(i.node.name)
+}
+
+func (i fileInfo) Size() int64        { return i.node.size }
+func (i fileInfo) ModTime() time.Time { return i.node.modTime }
+func (i fileInfo) IsDir() bool        { return i.node.dir }
+func (i fileInfo) Sys() any           { return nil }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.node.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// dirEntries 把子节点列表转换成 fs.DirEntry。
+func dirEntries(children []*node) []fs.DirEntry {
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		entries = append(entries, fs.FileInfoToDirEntry(fileInfo{child}))
+	}
+	return entries
+}
@@ -0,0 +1,485 @@
+// Package isofs 验证 ISO9660 与 UDF 镜像的目录解析和文件读取。
+
+package isofs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"unicode/utf16"
+)
+
+// TestNewImageReadsISO9660 验证 ISO9660 镜像可以列目录、按大小写不敏感路径读取文件，并合并多区段文件。
+func TestNewImageReadsISO9660(t *testing.T) {
+	image, err := NewImage(bytes.NewReader(buildTestISO9660Image()))
+	if err != nil {
+		t.Fatalf("NewImage() error: %v", err)
+	}
+	if image.Format() != FormatISO9660 {
+		t.Fatalf("Format() = %q, want %q", image.Format(), FormatISO9660)
+	}
+
+	assertDirNames(t, image, ".", []string{"BDMV", "README.TXT"})
+	assertDirNames(t, image, "BDMV", []string{"BIG.M2TS", "INDEX.BDMV"})
+
+	data, err := image.ReadFile("readme.txt")
+	if err != nil || string(data) != "hello iso" {
+		t.Fatalf("ReadFile(readme.txt) = %q, %v; want %q", data, err, "hello iso")
+	}
+
+	big, err := image.ReadFile("BDMV/BIG.M2TS")
+	if err != nil {
+		t.Fatalf("ReadFile(BIG.M2TS) error: %v", err)
+	}
+	if want := testISOMultiExtentContent(); !bytes.Equal(big, want) {
+		t.Fatalf("ReadFile(BIG.M2TS) returned %d bytes, want %d matching bytes", len(big), len(want))
+	}
+
+	if err := fstest.TestFS(image, "README.TXT", "BDMV/INDEX.BDMV", "BDMV/BIG.M2TS"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNewImageReadsUDFMetadataPartition 验证 UDF 2.50 元数据分区、长短分配描述符、稀疏区段和内嵌数据都能正确读取。
+func TestNewImageReadsUDFMetadataPartition(t *testing.T) {
+	image, err := NewImage(bytes.NewReader(buildTestUDFImage()))
+	if err != nil {
+		t.Fatalf("NewImage() error: %v", err)
+	}
+	if image.Format() != FormatUDF {
+		t.Fatalf("Format() = %q, want %q", image.Format(), FormatUDF)
+	}
+
+	assertDirNames(t, image, ".", []string{"BDMV", "影片.txt"})
+	assertDirNames(t, image, "BDMV", []string{"00000.m2ts"})
+
+	data, err := image.ReadFile("影片.txt")
+	if err != nil || string(data) != "hello udf" {
+		t.Fatalf("ReadFile(影片.txt) = %q, %v; want %q", data, err, "hello udf")
+	}
+
+	file, err := image.Open("BDMV/00000.m2ts")
+	if err != nil {
+		t.Fatalf("Open(00000.m2ts) error: %v", err)
+	}
+	defer file.Close()
+
+	stream, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("ReadAll(00000.m2ts) error: %v", err)
+	}
+	if want := testUDFStreamContent(); !bytes.Equal(stream, want) {
+		t.Fatalf("ReadAll(00000.m2ts) returned %d bytes, want %d matching bytes", len(stream), len(want))
+	}
+
+	tail := make([]byte, 4)
+	if _, err := file.(*File).ReadAt(tail, int64(len(stream)-4)); err != nil {
+		t.Fatalf("ReadAt(tail) error: %v", err)
+	}
+	if !bytes.Equal(tail, stream[len(stream)-4:]) {
+		t.Fatalf("ReadAt(tail) = %v, want %v", tail, stream[len(stream)-4:])
+	}
+
+	if err := fstest.TestFS(image, "影片.txt", "BDMV/00000.m2ts"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestNewImageRejectsUnknownData 验证既不是 UDF 也不是 ISO9660 的数据会返回 ErrUnsupported。
+func TestNewImageRejectsUnknownData(t *testing.T) {
+	if _, err := NewImage(bytes.NewReader(make([]byte, 300*sectorSize))); err != ErrUnsupported {
+		t.Fatalf("NewImage() error = %v, want ErrUnsupported", err)
+	}
+}
+
+// TestImageRejectsOversizedDirectory 验证目录记录声明的超大尺寸会在分配内存前被拒绝。
+func TestImageRejectsOversizedDirectory(t *testing.T) {
+	data := buildTestISO9660Image()
+	binary.LittleEndian.PutUint32(data[16*sectorSize+156+10:], 0xfffff800)
+
+	image, err := NewImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewImage() error: %v", err)
+	}
+	if _, err := image.ReadDir("."); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("ReadDir(.) error = %v, want ErrTooLarge", err)
+	}
+}
+
+// TestImageRejectsExtentBeyondImage 验证指向镜像末尾之外的区段会返回错误，而不是按声明大小分配内存。
+func TestImageRejectsExtentBeyondImage(t *testing.T) {
+	data := buildTestISO9660Image()
+	writeISODir(data[20*sectorSize:21*sectorSize],
+		isoRecord("\x00", 20, sectorSize, isoFlagDirectory),
+		isoRecord("\x01", 20, sectorSize, isoFlagDirectory),
+		isoRecord("BDMV", 21, sectorSize, isoFlagDirectory),
+		isoRecord("README.TXT;1", 1000, 1<<20, 0),
+	)
+
+	image, err := NewImage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewImage() error: %v", err)
+	}
+	if _, err := image.ReadFile("README.TXT"); !errors.Is(err, errExtentOutOfRange) {
+		t.Fatalf("ReadFile(README.TXT) error = %v, want errExtentOutOfRange", err)
+	}
+}
+
+// TestImageRejectsDirectoryCycles 验证指向上级目录的目录项会让遍历返回错误，而不是无限循环。
+func TestImageRejectsDirectoryCycles(t *testing.T) {
+	iso := buildTestISO9660Image()
+	writeISODir(iso[21*sectorSize:22*sectorSize],
+		isoRecord("\x00", 21, sectorSize, isoFlagDirectory),
+		isoRecord("\x01", 20, sectorSize, isoFlagDirectory),
+		isoRecord("LOOP", 20, sectorSize, isoFlagDirectory),
+	)
+
+	udf := buildTestUDFImage()
+	bdmvDir := joinBytes(
+		udfFID("", udfCharDirectory|udfCharParent, 1, 1),
+		udfFID("00000.m2ts", 0, 6, 1),
+		udfFID("LOOPBACK", udfCharDirectory, 1, 1),
+	)
+	copy(udf[(testUDFPartitionStart+21)*sectorSize:], bdmvDir)
+
+	for name, data := range map[string][]byte{"iso9660": iso, "udf": udf} {
+		image, err := NewImage(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: NewImage() error: %v", name, err)
+		}
+		visited := 0
+		err = fs.WalkDir(image, ".", func(_ string, _ fs.DirEntry, err error) error {
+			visited++
+			if visited > 100 {
+				return errors.New("walk did not terminate")
+			}
+			return err
+		})
+		if !errors.Is(err, errDirectoryCycle) {
+			t.Fatalf("%s: WalkDir() error = %v, want errDirectoryCycle", name, err)
+		}
+	}
+}
+
+// TestNewImageRejectsInvalidUDFBlockSize 验证不是 512 到 4096 之间 2 的幂的 UDF 逻辑块大小会被拒绝。
+func TestNewImageRejectsInvalidUDFBlockSize(t *testing.T) {
+	for _, blockSize := range []uint32{1, 1000, 8192, 1 << 31} {
+		data := buildTestUDFImage()
+		logicalVolume := data[258*sectorSize : 259*sectorSize]
+		binary.LittleEndian.PutUint32(logicalVolume[212:216], blockSize)
+		setUDFTag(logicalVolume, udfTagLogicalVolume)
+
+		if _, err := NewImage(bytes.NewReader(data)); err == nil {
+			t.Fatalf("NewImage() with block size %d succeeded, want error", blockSize)
+		}
+	}
+}
+
+// TestNewImageRejectsTruncatedUDFPartitionMaps 验证长度不足的分区映射项会返回错误而不是越界。
+func TestNewImageRejectsTruncatedUDFPartitionMaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset int
+		length byte
+	}{
+		{name: "type 1", offset: 440, length: 2},
+		{name: "type 2", offset: 446, length: 30},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := buildTestUDFImage()
+			logicalVolume := data[258*sectorSize : 259*sectorSize]
+			logicalVolume[test.offset+1] = test.length
+			setUDFTag(logicalVolume, udfTagLogicalVolume)
+
+			if _, err := NewImage(bytes.NewReader(data)); err == nil {
+				t.Fatal("NewImage() succeeded, want error")
+			}
+		})
+	}
+}
+
+func assertDirNames(t *testing.T, image *Image, dir string, want []string) {
+	t.Helper()
+
+	entries, err := image.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir(%q) error: %v", dir, err)
+	}
+	got := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ReadDir(%q) = %v, want %v", dir, got, want)
+	}
+	for index := range want {
+		if got[index] != want[index] {
+			t.Fatalf("ReadDir(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func testISOMultiExtentContent() []byte {
+	data := make([]byte, sectorSize+300)
+	for index := range data {
+		data[index] = byte(index % 251)
+	}
+	return data
+}
+
+// buildTestISO9660Image 构造一个只有主卷描述符的最小 ISO9660 镜像：
+// 根目录位于扇区 20，BDMV 目录位于扇区 21，BIG.M2TS 由扇区 24 和 26 两个区段组成。
+func buildTestISO9660Image() []byte {
+	data := make([]byte, 30*sectorSize)
+	sector := func(index int) []byte {
+		return data[index*sectorSize : (index+1)*sectorSize]
+	}
+
+	primary := sector(16)
+	primary[0] = 1
+	copy(primary[1:6], "CD001")
+	primary[6] = 1
+	binary.LittleEndian.PutUint16(primary[128:130], sectorSize)
+	binary.BigEndian.PutUint16(primary[130:132], sectorSize)
+	copy(primary[156:190], isoRecord("\x00", 20, sectorSize, isoFlagDirectory))
+
+	terminator := sector(17)
+	terminator[0] = 255
+	copy(terminator[1:6], "CD001")
+	terminator[6] = 1
+
+	readme := []byte("hello iso")
+	big := testISOMultiExtentContent()
+
+	writeISODir(sector(20),
+		isoRecord("\x00", 20, sectorSize, isoFlagDirectory),
+		isoRecord("\x01", 20, sectorSize, isoFlagDirectory),
+		isoRecord("BDMV", 21, sectorSize, isoFlagDirectory),
+		isoRecord("README.TXT;1", 22, uint32(len(readme)), 0),
+	)
+	writeISODir(sector(21),
+		isoRecord("\x00", 21, sectorSize, isoFlagDirectory),
+		isoRecord("\x01", 20, sectorSize, isoFlagDirectory),
+		isoRecord("BIG.M2TS;1", 24, sectorSize, isoFlagMultiExtent),
+		isoRecord("BIG.M2TS;1", 26, uint32(len(big)-sectorSize), 0),
+		isoRecord("INDEX.BDMV;1", 23, 4, 0),
+	)
+
+	copy(sector(22), readme)
+	copy(sector(23), "INDX")
+	copy(sector(24), big[:sectorSize])
+	copy(sector(26), big[sectorSize:])
+	return data
+}
+
+func isoRecord(name string, location, size uint32, flags byte) []byte {
+	length := 33 + len(name)
+	if length%2 == 1 {
+		length++
+	}
+	record := make([]byte, length)
+	record[0] = byte(length)
+	binary.LittleEndian.PutUint32(record[2:6], location)
+	binary.BigEndian.PutUint32(record[6:10], location)
+	binary.LittleEndian.PutUint32(record[10:14], size)
+	binary.BigEndian.PutUint32(record[14:18], size)
+	copy(record[18:25], []byte{124, 1, 2, 3, 4, 5, 32})
+	record[25] = flags
+	record[32] = byte(len(name))
+	copy(record[33:], name)
+	return record
+}
+
+func writeISODir(sector []byte, records ...[]byte) {
+	pos := 0
+	for _, record := range records {
+		pos += copy(sector[pos:], record)
+	}
+}
+
+func testUDFStreamContent() []byte {
+	data := make([]byte, 2*sectorSize+100)
+	for index := 0; index < sectorSize; index++ {
+		data[index] = byte(index % 241)
+	}
+	for index := 2 * sectorSize; index < len(data); index++ {
+		data[index] = byte(index % 239)
+	}
+	return data
+}
+
+// 测试 UDF 镜像的布局：物理分区从扇区 300 开始，元数据文件位于分区块 0，
+// 其内容由分区块 10-12 和 20-24 两个区段组成，对应元数据块 0-7。
+const (
+	testUDFPartitionStart = 300
+	testUDFBlocks         = 60
+)
+
+// buildTestUDFImage 构造一个使用元数据分区的最小 UDF 2.50 镜像。
+func buildTestUDFImage() []byte {
+	data := make([]byte, (testUDFPartitionStart+testUDFBlocks)*sectorSize)
+	sector := func(index int) []byte {
+		return data[index*sectorSize : (index+1)*sectorSize]
+	}
+	block := func(lbn int) []byte {
+		return sector(testUDFPartitionStart + lbn)
+	}
+	metadataBlocks := []int{10, 11, 12, 20, 21, 22, 23, 24}
+	metadata := func(index int) []byte {
+		return block(metadataBlocks[index])
+	}
+
+	anchor := sector(udfAnchorSector)
+	binary.LittleEndian.PutUint32(anchor[16:20], 4*sectorSize)
+	binary.LittleEndian.PutUint32(anchor[20:24], 257)
+	setUDFTag(anchor, udfTagAnchor)
+
+	partition := sector(257)
+	binary.LittleEndian.PutUint16(partition[22:24], 0)
+	binary.LittleEndian.PutUint32(partition[188:192], testUDFPartitionStart)
+	setUDFTag(partition, udfTagPartition)
+
+	logicalVolume := sector(258)
+	binary.LittleEndian.PutUint32(logicalVolume[212:216], sectorSize)
+	binary.LittleEndian.PutUint32(logicalVolume[248:252], sectorSize)
+	binary.LittleEndian.PutUint32(logicalVolume[252:256], 0)
+	binary.LittleEndian.PutUint16(logicalVolume[256:258], 1)
+	binary.LittleEndian.PutUint32(logicalVolume[264:268], 6+64)
+	binary.LittleEndian.PutUint32(logicalVolume[268:272], 2)
+	physicalMap := logicalVolume[440:446]
+	physicalMap[0], physicalMap[1] = 1, 6
+	binary.LittleEndian.PutUint16(physicalMap[4:6], 0)
+	metadataMap := logicalVolume[446:510]
+	metadataMap[0], metadataMap[1] = 2, 64
+	copy(metadataMap[5:28], "*UDF Metadata Partition")
+	binary.LittleEndian.PutUint16(metadataMap[38:40], 0)
+	binary.LittleEndian.PutUint32(metadataMap[40:44], 0)
+	setUDFTag(logicalVolume, udfTagLogicalVolume)
+
+	setUDFTag(sector(259), udfTagTerminating)
+
+	writeUDFFileEntry(block(0), udfTagFileEntry, 0, 0, 8*sectorSize,
+		shortAD(3*sectorSize, 10), shortAD(5*sectorSize, 20))
+
+	fileSet := metadata(0)
+	binary.LittleEndian.PutUint32(fileSet[400:404], sectorSize)
+	binary.LittleEndian.PutUint32(fileSet[404:408], 1)
+	binary.LittleEndian.PutUint16(fileSet[408:410], 1)
+	setUDFTag(fileSet, udfTagFileSet)
+
+	rootDir := joinBytes(
+		udfFID("", udfCharDirectory|udfCharParent, 1, 1),
+		udfFID("BDMV", udfCharDirectory, 3, 1),
+		udfFID("影片.txt", 0, 5, 1),
+	)
+	writeUDFFileEntry(metadata(1), udfTagFileEntry, udfFileTypeDirectory, 0, len(rootDir), shortAD(len(rootDir), 2))
+	copy(metadata(2), rootDir)
+
+	bdmvDir := joinBytes(
+		udfFID("", udfCharDirectory|udfCharParent, 1, 1),
+		udfFID("00000.m2ts", 0, 6, 1),
+		udfFID("old.m2ts", udfCharDeleted, 7, 1),
+	)
+	writeUDFFileEntry(metadata(3), udfTagExtFileEntry, udfFileTypeDirectory, 1, len(bdmvDir), longAD(len(bdmvDir), 4, 1))
+	copy(metadata(4), bdmvDir)
+
+	writeUDFFileEntry(metadata(5), udfTagFileEntry, 5, 3, 9, []byte("hello udf"))
+
+	stream := testUDFStreamContent()
+	writeUDFFileEntry(metadata(6), udfTagExtFileEntry, 5, 1, len(stream),
+		longAD(sectorSize, 40, 0),
+		longAD(1<<30|sectorSize, 0, 0),
+		longAD(100, 50, 0),
+	)
+	copy(block(40), stream[:sectorSize])
+	copy(block(50), stream[2*sectorSize:])
+	return data
+}
+
+func setUDFTag(data []byte, id uint16) {
+	binary.LittleEndian.PutUint16(data[0:2], id)
+	binary.LittleEndian.PutUint16(data[2:4], 2)
+	var sum byte
+	for index := 0; index < 16; index++ {
+		if index != 4 {
+			sum += data[index]
+		}
+	}
+	data[4] = sum
+}
+
+// writeUDFFileEntry 写入 File Entry 或 Extended File Entry，descriptors 为分配描述符或内嵌数据。
+func writeUDFFileEntry(data []byte, tag uint16, fileType byte, adType uint16, size int, descriptors ...[]byte) {
+	data[27] = fileType
+	binary.LittleEndian.PutUint16(data[34:36], adType)
+	binary.LittleEndian.PutUint64(data[56:64], uint64(size))
+
+	payload := joinBytes(descriptors...)
+	adStart := 176
+	lengthOffset := 172
+	if tag == udfTagExtFileEntry {
+		adStart = 216
+		lengthOffset = 212
+	}
+	binary.LittleEndian.PutUint32(data[lengthOffset:lengthOffset+4], uint32(len(payload)))
+	copy(data[adStart:], payload)
+	setUDFTag(data, tag)
+}
+
+func shortAD(length int, block uint32) []byte {
+	descriptor := make([]byte, 8)
+	binary.LittleEndian.PutUint32(descriptor[0:4], uint32(length))
+	binary.LittleEndian.PutUint32(descriptor[4:8], block)
+	return descriptor
+}
+
+func longAD(length int, block uint32, partitionRef uint16) []byte {
+	descriptor := make([]byte, 16)
+	binary.LittleEndian.PutUint32(descriptor[0:4], uint32(length))
+	binary.LittleEndian.PutUint32(descriptor[4:8], block)
+	binary.LittleEndian.PutUint16(descriptor[8:10], partitionRef)
+	return descriptor
+}
+
+// udfFID 构造文件标识描述符；非 ASCII 名称使用 16 位 CS0 压缩。
+func udfFID(name string, characteristics byte, block uint32, partitionRef uint16) []byte {
+	var encoded []byte
+	if name != "" {
+		ascii := true
+		for _, r := range name {
+			if r > 0x7F {
+				ascii = false
+			}
+		}
+		if ascii {
+			encoded = append([]byte{8}, name...)
+		} else {
+			encoded = []byte{16}
+			for _, unit := range utf16.Encode([]rune(name)) {
+				encoded = binary.BigEndian.AppendUint16(encoded, unit)
+			}
+		}
+	}
+
+	length := 38 + len(encoded)
+	record := make([]byte, (length+3)&^3)
+	record[18] = characteristics
+	record[19] = byte(len(encoded))
+	binary.LittleEndian.PutUint32(record[20:24], sectorSize)
+	binary.LittleEndian.PutUint32(record[24:28], block)
+	binary.LittleEndian.PutUint16(record[28:30], partitionRef)
+	copy(record[38:], encoded)
+	setUDFTag(record, udfTagFileIdentifier)
+	return record
+}
+
+func joinBytes(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+var _ fs.ReadDirFS = (*Image)(nil)
@@ -0,0 +1,543 @@
+// Package isofs 实现 UDF（ECMA-167 / OSTA UDF 1.02-2.60）目录结构的解析。
+
+package isofs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// UDF 描述符标签 ID。
+const (
+	udfTagAnchor         = 2
+	udfTagPartition      = 5
+	udfTagLogicalVolume  = 6
+	udfTagTerminating    = 8
+	udfTagFileSet        = 256
+	udfTagFileIdentifier = 257
+	udfTagAllocationExt  = 258
+	udfTagFileEntry      = 261
+	udfTagExtFileEntry   = 266
+)
+
+const (
+	udfAnchorSector = 256
+
+	udfFileTypeDirectory = 4
+
+	udfCharDirectory = 0x02
+	udfCharDeleted   = 0x04
+	udfCharParent    = 0x08
+
+	udfExtentRecorded     = 0
+	udfExtentContinuation = 3
+
+	udfMaxContinuations = 64
+
+	// udfType1MapLength 和 udfType2MapLength 是 ECMA-167 规定的两类分区映射项长度。
+	udfType1MapLength = 6
+	udfType2MapLength = 64
+)
+
+// udfSpace 把某个分区内的逻辑块地址换算成镜像内的绝对区段。
+type udfSpace func(block uint32, length int64) ([]extent, error)
+
+// udfPartitionMap 表示逻辑卷分区映射表中的一项。
+type udfPartitionMap struct {
+	partition uint16
+	metadata  bool
+	// metadataFile 是元数据分区对应的元数据文件在镜像中的区段，仅元数据分区使用。
+	metadataFile     []extent
+	metadataLocation uint32
+}
+
+// udfVolume 保存解析后的逻辑卷信息。
+type udfVolume struct {
+	r          io.ReaderAt
+	size       int64
+	blockSize  int64
+	partitions map[uint16]uint32
+	maps       []udfPartitionMap
+	root       *node
+}
+
+// openUDF 依次读取锚点、主卷描述符序列、文件集描述符和根目录。size 是镜像总字节数，未知时为 -1。
+func openUDF(r io.ReaderAt, size int64) (*udfVolume, error) {
+	anchor := make([]byte, sectorSize)
+	if _, err := r.ReadAt(anchor, udfAnchorSector*sectorSize); err != nil {
+		return nil, err
+	}
+	if id, ok := udfTagID(anchor); !ok || id != udfTagAnchor {
+		return nil, errors.New("isofs: missing UDF anchor volume descriptor")
+	}
+
+	volume := &udfVolume{
+		r:          r,
+		size:       size,
+		blockSize:  sectorSize,
+		partitions: make(map[uint16]uint32),
+	}
+	sequenceLength := int64(binary.LittleEndian.Uint32(anchor[16:20]))
+	sequenceStart := int64(binary.LittleEndian.Uint32(anchor[20:24]))
+
+	var logicalVolume []byte
+	for index := int64(0); index < sequenceLength/sectorSize; index++ {
+		data := make([]byte, sectorSize)
+		if _, err := r.ReadAt(data, (sequenceStart+index)*sectorSize); err != nil {
+			return nil, err
+		}
+		id, ok := udfTagID(data)
+		if !ok {
+			continue
+		}
+		switch id {
+		case udfTagPartition:
+			number := binary.LittleEndian.Uint16(data[22:24])
+			volume.partitions[number] = binary.LittleEndian.Uint32(data[188:192])
+		case udfTagLogicalVolume:
+			logicalVolume = data
+		}
+		if id == udfTagTerminating {
+			break
+		}
+	}
+	if logicalVolume == nil || len(volume.partitions) == 0 {
+		return nil, errors.New("isofs: incomplete UDF volume descriptor sequence")
+	}
+
+	if err := volume.parseLogicalVolume(logicalVolume); err != nil {
+		return nil, err
+	}
+	if err := volume.loadMetadataFiles(); err != nil {
+		return nil, err
+	}
+
+	fileSetBlock := binary.LittleEndian.Uint32(logicalVolume[252:256])
+	fileSetRef := binary.LittleEndian.Uint16(logicalVolume[256:258])
+	fileSet, err := volume.readBlock(fileSetRef, fileSetBlock)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := udfTagID(fileSet); !ok || id != udfTagFileSet {
+		return nil, errors.New("isofs: missing UDF file set descriptor")
+	}
+
+	rootBlock := binary.LittleEndian.Uint32(fileSet[404:408])
+	rootRef := binary.LittleEndian.Uint16(fileSet[408:410])
+	root, err := volume.readICB(rootRef, rootBlock)
+	if err != nil {
+		return nil, err
+	}
+	if !root.dir {
+		return nil, errors.New("isofs: UDF root is not a directory")
+	}
+	volume.root = root
+	return volume, nil
+}
+
+// parseLogicalVolume 读取逻辑块大小和分区映射表；逻辑块大小必须是 512 到 4096 之间的 2 的幂。
+func (v *udfVolume) parseLogicalVolume(data []byte) error {
+	blockSize := int64(binary.LittleEndian.Uint32(data[212:216]))
+	if !validLogicalBlockSize(blockSize) {
+		return fmt.Errorf("isofs: invalid UDF logical block size %d", blockSize)
+	}
+	v.blockSize = blockSize
+
+	tableLength := int(binary.LittleEndian.Uint32(data[264:268]))
+	count := int(binary.LittleEndian.Uint32(data[268:272]))
+	table := data[440:]
+	if tableLength > len(table) {
+		return errors.New("isofs: UDF partition map table out of range")
+	}
+	table = table[:tableLength]
+
+	for pos := 0; len(v.maps) < count; {
+		if pos+2 > len(table) {
+			return errors.New("isofs: truncated UDF partition map")
+		}
+		mapType, mapLength := table[pos], int(table[pos+1])
+		if mapLength == 0 || pos+mapLength > len(table) {
+			return errors.New("isofs: invalid UDF partition map length")
+		}
+		entry := table[pos : pos+mapLength]
+		pos += mapLength
+
+		switch mapType {
+		case 1:
+			if mapLength < udfType1MapLength {
+				return errors.New("isofs: truncated UDF type 1 partition map")
+			}
+			v.maps = append(v.maps, udfPartitionMap{partition: binary.LittleEndian.Uint16(entry[4:6])})
+		case 2:
+			if mapLength < udfType2MapLength {
+				return errors.New("isofs: truncated UDF type 2 partition map")
+			}
+			identifier := strings.TrimRight(string(entry[5:28]), "\x00")
+			partitionMap := udfPartitionMap{partition: binary.LittleEndian.Uint16(entry[38:40])}
+			switch {
+			case strings.HasPrefix(identifier, "*UDF Metadata Partition"):
+				partitionMap.metadata = true
+				partitionMap.metadataLocation = binary.LittleEndian.Uint32(entry[40:44])
+			case strings.HasPrefix(identifier, "*UDF Sparable Partition"):
+			default:
+				return fmt.Errorf("isofs: unsupported UDF partition map %q", identifier)
+			}
+			v.maps = append(v.maps, partitionMap)
+		default:
+			return fmt.Errorf("isofs: unknown UDF partition map type %d", mapType)
+		}
+	}
+	return nil
+}
+
+// loadMetadataFiles 读取每个元数据分区对应的元数据文件，元数据分区内的地址都相对该文件内容。
+func (v *udfVolume) loadMetadataFiles() error {
+	for index := range v.maps {
+		partitionMap := &v.maps[index]
+		if !partitionMap.metadata {
+			continue
+		}
+		metadataFile, err := v.readICBIn(v.physicalSpace(partitionMap.partition), partitionMap.metadataLocation)
+		if err != nil {
+			return fmt.Errorf("isofs: read UDF metadata file: %w", err)
+		}
+		partitionMap.metadataFile = metadataFile.extents
+	}
+	return nil
+}
+
+// physicalSpace 返回物理分区的地址换算函数。
+func (v *udfVolume) physicalSpace(partition uint16) udfSpace {
+	return func(block uint32, length int64) ([]extent, error) {
+		start, ok := v.partitions[partition]
+		if !ok {
+			return nil, fmt.Errorf("isofs: UDF partition %d not found", partition)
+		}
+		return []extent{{offset: (int64(start) + int64(block)) * v.blockSize, length: length}}, nil
+	}
+}
+
+// space 返回分区映射表第 ref 项的地址换算函数。
+func (v *udfVolume) space(ref uint16) (udfSpace, error) {
+	if int(ref) >= len(v.maps) {
+		return nil, fmt.Errorf("isofs: UDF partition reference %d out of range", ref)
+	}
+	partitionMap := v.maps[ref]
+	if !partitionMap.metadata {
+		return v.physicalSpace(partitionMap.partition), nil
+	}
+	return func(block uint32, length int64) ([]extent, error) {
+		return sliceExtents(partitionMap.metadataFile, int64(block)*v.blockSize, length)
+	}, nil
+}
+
+// readBlock 读取分区映射表第 ref 项中的一个逻辑块。
+func (v *udfVolume) readBlock(ref uint16, block uint32) ([]byte, error) {
+	space, err := v.space(ref)
+	if err != nil {
+		return nil, err
+	}
+	return v.readBlockIn(space, block)
+}
+
+// readBlockIn 读取指定地址空间中的一个逻辑块。
+func (v *udfVolume) readBlockIn(space udfSpace, block uint32) ([]byte, error) {
+	extents, err := space(block, v.blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return readAllExtents(v.r, v.size, extents, v.blockSize, maxDirectoryBytes)
+}
+
+// readICB 读取分区映射表第 ref 项中的 (Extended) File Entry。
+func (v *udfVolume) readICB(ref uint16, block uint32) (*node, error) {
+	space, err := v.space(ref)
+	if err != nil {
+		return nil, err
+	}
+	return v.readICBIn(space, block)
+}
+
+// readICBIn 读取 File Entry 或 Extended File Entry，并把分配描述符换算成镜像内的绝对区段。
+func (v *udfVolume) readICBIn(space udfSpace, block uint32) (*node, error) {
+	data, err := v.readBlockIn(space, block)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := udfTagID(data)
+	if !ok {
+		return nil, errors.New("isofs: invalid UDF file entry tag")
+	}
+
+	var eaLength, adLength, adStart, modTimeOffset int
+	switch id {
+	case udfTagFileEntry:
+		eaLength = int(binary.LittleEndian.Uint32(data[168:172]))
+		adLength = int(binary.LittleEndian.Uint32(data[172:176]))
+		adStart = 176 + eaLength
+		modTimeOffset = 84
+	case udfTagExtFileEntry:
+		eaLength = int(binary.LittleEndian.Uint32(data[208:212]))
+		adLength = int(binary.LittleEndian.Uint32(data[212:216]))
+		adStart = 216 + eaLength
+		modTimeOffset = 92
+	default:
+		return nil, fmt.Errorf("isofs: unexpected UDF descriptor %d where file entry expected", id)
+	}
+	if adStart+adLength > len(data) {
+		return nil, errors.New("isofs: UDF allocation descriptors out of range")
+	}
+
+	n := &node{
+		dir:     data[27] == udfFileTypeDirectory,
+		size:    int64(binary.LittleEndian.Uint64(data[56:64])),
+		modTime: parseUDFTimestamp(data[modTimeOffset : modTimeOffset+12]),
+	}
+
+	descriptors := data[adStart : adStart+adLength]
+	switch adType := binary.LittleEndian.Uint16(data[34:36]) & 0x07; adType {
+	case 3:
+		location, err := space(block, v.blockSize)
+		if err != nil {
+			return nil, err
+		}
+		n.extents = []extent{{offset: location[0].offset + int64(adStart), length: int64(adLength)}}
+	default:
+		n.extents, err = v.allocationExtents(space, int(adType), descriptors)
+		if err != nil {
+			return nil, err
+		}
+	}
+	n.extents = truncateExtents(n.extents, n.size)
+	return n, nil
+}
+
+// allocationExtents 解析 short_ad / long_ad / ext_ad 分配描述符，并跟随延续区段。
+func (v *udfVolume) allocationExtents(space udfSpace, adType int, descriptors []byte) ([]extent, error) {
+	var size int
+	switch adType {
+	case 0:
+		size = 8
+	case 1:
+		size = 16
+	case 2:
+		size = 20
+	default:
+		return nil, fmt.Errorf("isofs: unsupported UDF allocation descriptor type %d", adType)
+	}
+
+	var extents []extent
+	for continuations := 0; ; {
+		var next []byte
+		for pos := 0; pos+size <= len(descriptors); pos += size {
+			descriptor := descriptors[pos : pos+size]
+			raw := binary.LittleEndian.Uint32(descriptor[0:4])
+			length := int64(raw & 0x3FFFFFFF)
+			kind := raw >> 30
+			if length == 0 {
+				break
+			}
+
+			target := space
+			var block uint32
+			switch adType {
+			case 0:
+				block = binary.LittleEndian.Uint32(descriptor[4:8])
+			case 1:
+				block = binary.LittleEndian.Uint32(descriptor[4:8])
+				mapped, err := v.space(binary.LittleEndian.Uint16(descriptor[8:10]))
+				if err != nil {
+					return nil, err
+				}
+				target = mapped
+			case 2:
+				block = binary.LittleEndian.Uint32(descriptor[12:16])
+				mapped, err := v.space(binary.LittleEndian.Uint16(descriptor[16:18]))
+				if err != nil {
+					return nil, err
+				}
+				target = mapped
+			}
+
+			switch kind {
+			case udfExtentContinuation:
+				data, err := v.readBlockIn(target, block)
+				if err != nil {
+					return nil, err
+				}
+				if id, ok := udfTagID(data); !ok || id != udfTagAllocationExt {
+					return nil, errors.New("isofs: invalid UDF allocation extent descriptor")
+				}
+				adLength := int(binary.LittleEndian.Uint32(data[20:24]))
+				if 24+adLength > len(data) {
+					return nil, errors.New("isofs: UDF allocation extent out of range")
+				}
+				next = data[24 : 24+adLength]
+				space = target
+			case udfExtentRecorded:
+				mapped, err := target(block, length)
+				if err != nil {
+					return nil, err
+				}
+				extents = append(extents, mapped...)
+			default:
+				extents = append(extents, extent{length: length, sparse: true})
+			}
+			if next != nil {
+				break
+			}
+		}
+		if next == nil {
+			return extents, nil
+		}
+		continuations++
+		if continuations > udfMaxContinuations {
+			return nil, errors.New("isofs: too many UDF allocation extent descriptors")
+		}
+		descriptors = next
+	}
+}
+
+// readDir 解析目录内容中的文件标识描述符，并读取每个子项的 File Entry。
+func (v *udfVolume) readDir(dir *node) ([]*node, error) {
+	data, err := readAllExtents(v.r, v.size, dir.extents, dir.size, maxDirectoryBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*node
+	for pos := 0; pos+38 <= len(data); {
+		record := data[pos:]
+		if id, ok := udfTagID(record); !ok || id != udfTagFileIdentifier {
+			return nil, fmt.Errorf("isofs: invalid UDF file identifier at %d", pos)
+		}
+		characteristics := record[18]
+		nameLength := int(record[19])
+		implementationLength := int(binary.LittleEndian.Uint16(record[36:38]))
+		length := 38 + implementationLength + nameLength
+		if length > len(record) {
+			return nil, errors.New("isofs: UDF file identifier out of range")
+		}
+		pos += (length + 3) &^ 3
+
+		if characteristics&(udfCharDeleted|udfCharParent) != 0 {
+			continue
+		}
+		block := binary.LittleEndian.Uint32(record[24:28])
+		ref := binary.LittleEndian.Uint16(record[28:30])
+		child, err := v.readICB(ref, block)
+		if err != nil {
+			return nil, err
+		}
+		child.name = decodeUDFName(record[38+implementationLength : length])
+		child.dir = child.dir || characteristics&udfCharDirectory != 0
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// udfTagID 校验描述符标签的校验和，并返回标签 ID。
+func udfTagID(data []byte) (uint16, bool) {
+	if len(data) < 16 {
+		return 0, false
+	}
+	var sum byte
+	for index := 0; index < 16; index++ {
+		if index != 4 {
+			sum += data[index]
+		}
+	}
+	if sum != data[4] {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(data[0:2]), true
+}
+
+// decodeUDFName 解码 OSTA CS0 压缩的文件名：8/254 表示单字节字符，16/255 表示 UTF-16BE。
+func decodeUDFName(raw []byte) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	switch raw[0] {
+	case 16, 255:
+		units := make([]uint16, 0, len(raw)/2)
+		for index := 1; index+1 < len(raw); index += 2 {
+			units = append(units, binary.BigEndian.Uint16(raw[index:]))
+		}
+		return string(utf16.Decode(units))
+	default:
+		runes := make([]rune, 0, len(raw)-1)
+		for _, b := range raw[1:] {
+			runes = append(runes, rune(b))
+		}
+		return string(runes)
+	}
+}
+
+// parseUDFTimestamp 解析 12 字节的 UDF 时间戳；时区为 -2047 表示未指定，按 UTC 处理。
+func parseUDFTimestamp(raw []byte) time.Time {
+	year := int(binary.LittleEndian.Uint16(raw[2:4]))
+	if year == 0 {
+		return time.Time{}
+	}
+	location := time.UTC
+	typeAndZone := binary.LittleEndian.Uint16(raw[0:2])
+	if typeAndZone>>12 == 1 {
+		offset := int(int16(typeAndZone<<4) >> 4)
+		if offset >= -1440 && offset <= 1440 {
+			location = time.FixedZone("", offset*60)
+		}
+	}
+	nanos := (int(raw[9])*10000 + int(raw[10])*100 + int(raw[11])) * 1000
+	return time.Date(year, time.Month(raw[4]), int(raw[5]), int(raw[6]), int(raw[7]), int(raw[8]), nanos, location)
+}
+
+// sliceExtents 返回区段列表中 [offset, offset+length) 这一段对应的区段。
+func sliceExtents(extents []extent, offset, length int64) ([]extent, error) {
+	var result []extent
+	for _, ext := range extents {
+		if length <= 0 {
+			break
+		}
+		if offset >= ext.length {
+			offset -= ext.length
+			continue
+		}
+		take := ext.length - offset
+		if take > length {
+			take = length
+		}
+		part := extent{length: take, sparse: ext.sparse}
+		if !ext.sparse {
+			part.offset = ext.offset + offset
+		}
+		result = append(result, part)
+		length -= take
+		offset = 0
+	}
+	if length > 0 {
+		return nil, errors.New("isofs: address beyond UDF metadata file")
+	}
+	return result, nil
+}
+
+// truncateExtents 把区段总长度截断到文件实际大小。
+func truncateExtents(extents []extent, size int64) []extent {
+	result := make([]extent, 0, len(extents))
+	for _, ext := range extents {
+		if size <= 0 {
+			break
+		}
+		if ext.length > size {
+			ext.length = size
+		}
+		result = append(result, ext)
+		size -= ext.length
+	}
+	return result
+}
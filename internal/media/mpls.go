@@ -42,74 +42,22 @@ func resolveBDInfoPlaylistSelection(path string) (string, string, bool) {
 	return root, playlist, true
 }
 
-// readMPLSDuration 解析 MPLS 文件里主播放项的总时长。
+// readMPLSDuration 读取 MPLS 文件并解析其中主播放项的总时长。
 func readMPLSDuration(path string) (time.Duration, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return 0, err
 	}
-	if len(data) < 18 {
-		return 0, fmt.Errorf("mpls: file too short")
-	}
-
-	fileType := string(data[:8])
-	switch fileType {
-	case "MPLS0100", "MPLS0200", "MPLS0300":
-	default:
-		return 0, fmt.Errorf("mpls: unsupported file type %q", fileType)
-	}
-
-	playlistOffset := int(readUint32BE(data[8:12]))
-	if playlistOffset < 0 || playlistOffset+10 > len(data) {
-		return 0, fmt.Errorf("mpls: invalid playlist offset")
-	}
-
-	pos := playlistOffset
-	pos += 4 // playlist_length
-	pos += 2 // reserved
-	itemCount := int(readUint16BE(data[pos : pos+2]))
-	pos += 2
-	pos += 2 // subitem_count
-
-	var totalTicks uint64
-	for itemIndex := 0; itemIndex < itemCount; itemIndex++ {
-		itemStart := pos
-		if itemStart+2 > len(data) {
-			return 0, fmt.Errorf("mpls: truncated playlist item header")
-		}
-
-		itemLength := int(readUint16BE(data[pos : pos+2]))
-		itemEnd := itemStart + itemLength + 2
-		if itemEnd > len(data) {
-			return 0, fmt.Errorf("mpls: truncated playlist item body")
-		}
-
-		pos += 2 // item_length
-		pos += 5 // clip_information_file_name
-		pos += 4 // clip_codec_identifier
-		pos += 1 // reserved
-		pos += 2 // flags
-		if pos+8 > itemEnd {
-			return 0, fmt.Errorf("mpls: truncated in/out time fields")
-		}
-
-		inTime := readUint32BE(data[pos : pos+4])
-		pos += 4
-		outTime := readUint32BE(data[pos : pos+4])
-		pos += 4
-
-		if outTime > inTime {
-			totalTicks += uint64(outTime - inTime)
-		}
-
-		pos = itemEnd
-	}
+	return parseMPLSDuration(data)
+}
 
-	if totalTicks == 0 {
-		return 0, nil
+// parseMPLSDuration 解析 MPLS 数据里主播放项的总时长；数据可以来自本地文件，也可以来自 ISO 镜像内部。
+func parseMPLSDuration(data []byte) (time.Duration, error) {
+	playlist, err := ParseMPLS(data)
+	if err != nil {
+		return 0, err
 	}
-
-	return time.Duration(totalTicks * uint64(time.Second) / mplsClockRate), nil
+	return playlist.Duration(), nil
 }
 
 // formatMPLSDuration 把 MPLS 时长格式化为 H:MM:SS。
@@ -0,0 +1,551 @@
+// Package media 提供完整的 MPLS 播放列表解析：播放项、多角度、STN 流表、子路径和章节标记。
+
+package media
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// MPLS 播放列表标记类型。
+const (
+	MPLSMarkEntry     = 1
+	MPLSMarkLinkPoint = 2
+)
+
+// MPLSPlaylist 表示一个解析后的 MPLS 播放列表。
+type MPLSPlaylist struct {
+	Version  string
+	Items    []MPLSPlayItem
+	SubPaths []MPLSSubPath
+	Marks    []MPLSMark
+}
+
+// MPLSPlayItem 表示主路径中的一个播放项；时间以 45kHz 时钟计数。
+type MPLSPlayItem struct {
+	ClipID              string
+	CodecID             string
+	ConnectionCondition uint8
+	STCID               uint8
+	InTime              uint32
+	OutTime             uint32
+	// Angles 保存多角度播放项除第一个角度以外的其它角度片段。
+	Angles  []MPLSAngle
+	Streams MPLSStreamTable
+}
+
+// MPLSAngle 表示多角度播放项中的一个附加角度。
+type MPLSAngle struct {
+	ClipID  string
+	CodecID string
+	STCID   uint8
+}
+
+// MPLSStreamTable 表示播放项的 STN 流表。
+type MPLSStreamTable struct {
+	Video []MPLSStream
+	Audio []MPLSStream
+	PG    []MPLSStream
+	IG    []MPLSStream
+}
+
+// MPLSStream 表示 STN 流表中的一条基本流。
+type MPLSStream struct {
+	PID        uint16
+	CodingType uint8
+	Codec      string
+	Language   string
+	// Format 和 Rate 是视频格式/帧率或音频声道/采样率的原始编码值。
+	Format uint8
+	Rate   uint8
+	// SubPathID 在流来自子路径时为子路径序号，否则为 -1。
+	SubPathID int
+}
+
+// MPLSSubPath 表示一个子路径，例如画中画、次要音频或文本字幕。
+type MPLSSubPath struct {
+	Type   uint8
+	Repeat bool
+	Items  []MPLSSubPlayItem
+}
+
+// MPLSSubPlayItem 表示子路径中的一个播放项。
+type MPLSSubPlayItem struct {
+	ClipID       string
+	CodecID      string
+	InTime       uint32
+	OutTime      uint32
+	SyncPlayItem uint16
+	SyncStartPTS uint32
+}
+
+// MPLSMark 表示 PlayListMark 中的一个标记；类型为 MPLSMarkEntry 的标记即章节点。
+type MPLSMark struct {
+	Type      uint8
+	PlayItem  uint16
+	Timestamp uint32
+	PID       uint16
+	Duration  uint32
+}
+
+// ReadMPLS 读取并解析本地 MPLS 文件。
+func ReadMPLS(path string) (*MPLSPlaylist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMPLS(data)
+}
+
+// ParseMPLS 解析 MPLS 数据。
+//
+// 播放项中 in/out time 之后的字段是可选的：数据被截断时只保留已经读到的部分，
+// 这样只包含时序信息的精简文件也能得到时长。
+func ParseMPLS(data []byte) (*MPLSPlaylist, error) {
+	if len(data) < 20 {
+		return nil, fmt.Errorf("mpls: file too short")
+	}
+
+	fileType := string(data[:8])
+	switch fileType {
+	case "MPLS0100", "MPLS0200", "MPLS0300":
+	default:
+		return nil, fmt.Errorf("mpls: unsupported file type %q", fileType)
+	}
+
+	playlist := &MPLSPlaylist{Version: fileType[4:]}
+	playlistOffset := int(readUint32BE(data[8:12]))
+	markOffset := int(readUint32BE(data[12:16]))
+	if playlistOffset+10 > len(data) {
+		return nil, fmt.Errorf("mpls: invalid playlist offset")
+	}
+
+	reader := &bdmvReader{data: data, pos: playlistOffset}
+	reader.skip(4) // playlist_length
+	reader.skip(2) // reserved
+	itemCount := int(reader.u16())
+	subPathCount := int(reader.u16())
+
+	for index := 0; index < itemCount; index++ {
+		item, err := parseMPLSPlayItem(reader)
+		if err != nil {
+			return nil, err
+		}
+		playlist.Items = append(playlist.Items, item)
+	}
+	for index := 0; index < subPathCount; index++ {
+		subPath, err := parseMPLSSubPath(reader)
+		if err != nil {
+			return nil, err
+		}
+		playlist.SubPaths = append(playlist.SubPaths, subPath)
+	}
+
+	if markOffset > 0 {
+		marks, err := parseMPLSMarks(data, markOffset)
+		if err != nil {
+			return nil, err
+		}
+		playlist.Marks = marks
+	}
+	return playlist, nil
+}
+
+// parseMPLSPlayItem 解析一个 PlayItem，结束后把读取位置移动到下一个播放项。
+func parseMPLSPlayItem(reader *bdmvReader) (MPLSPlayItem, error) {
+	if !reader.has(2) {
+		return MPLSPlayItem{}, fmt.Errorf("mpls: truncated playlist item header")
+	}
+	itemStart := reader.pos
+	itemEnd := itemStart + 2 + int(reader.u16())
+	if itemEnd > len(reader.data) {
+		return MPLSPlayItem{}, fmt.Errorf("mpls: truncated playlist item body")
+	}
+	if itemStart+22 > itemEnd {
+		return MPLSPlayItem{}, fmt.Errorf("mpls: truncated in/out time fields")
+	}
+
+	item := MPLSPlayItem{
+		ClipID:  reader.str(5),
+		CodecID: reader.str(4),
+	}
+	flags := reader.u16()
+	multiAngle := flags&0x10 != 0
+	item.ConnectionCondition = uint8(flags & 0x0F)
+	item.STCID = reader.u8()
+	item.InTime = reader.u32()
+	item.OutTime = reader.u32()
+
+	item.body(reader, itemEnd, multiAngle)
+	reader.pos = itemEnd
+	return item, nil
+}
+
+// body 解析 in/out time 之后的可选字段：UO 掩码、静帧信息、多角度和 STN 流表。
+func (item *MPLSPlayItem) body(reader *bdmvReader, itemEnd int, multiAngle bool) {
+	reader.limit = itemEnd
+	defer func() { reader.limit = 0 }()
+
+	if !reader.has(8 + 1 + 1 + 2) {
+		return
+	}
+	reader.skip(8) // UO_mask_table
+	reader.skip(1) // random_access_flag
+	reader.skip(1) // still_mode
+	reader.skip(2) // still_time
+
+	if multiAngle {
+		if !reader.has(2) {
+			return
+		}
+		angleCount := int(reader.u8())
+		reader.skip(1) // is_different_audios / is_seamless_angle_change
+		for angle := 1; angle < angleCount && reader.has(10); angle++ {
+			item.Angles = append(item.Angles, MPLSAngle{
+				ClipID:  reader.str(5),
+				CodecID: reader.str(4),
+				STCID:   reader.u8(),
+			})
+		}
+	}
+
+	item.Streams = parseMPLSStreamTable(reader)
+}
+
+// parseMPLSStreamTable 解析 STN 表中的主视频、主音频、PG（含画中画 PG）和 IG 流。
+func parseMPLSStreamTable(reader *bdmvReader) MPLSStreamTable {
+	var table MPLSStreamTable
+	if !reader.has(16) {
+		return table
+	}
+	tableStart := reader.pos
+	tableEnd := tableStart + 2 + int(reader.u16())
+	reader.skip(2) // reserved
+	videoCount := int(reader.u8())
+	audioCount := int(reader.u8())
+	pgCount := int(reader.u8())
+	igCount := int(reader.u8())
+	reader.skip(1) // number_of_secondary_audio_stream_entries
+	reader.skip(1) // number_of_secondary_video_stream_entries
+	pipPGCount := int(reader.u8())
+	reader.skip(5) // reserved
+	if tableEnd < reader.limit {
+		reader.limit = tableEnd
+	}
+
+	readStreams := func(count int) []MPLSStream {
+		var streams []MPLSStream
+		for index := 0; index < count; index++ {
+			stream, ok := parseMPLSStream(reader)
+			if !ok {
+				break
+			}
+			streams = append(streams, stream)
+		}
+		return streams
+	}
+	table.Video = readStreams(videoCount)
+	table.Audio = readStreams(audioCount)
+	table.PG = readStreams(pgCount + pipPGCount)
+	table.IG = readStreams(igCount)
+	return table
+}
+
+// parseMPLSStream 解析一条 stream_entry 和紧随其后的 stream_attributes。
+func parseMPLSStream(reader *bdmvReader) (MPLSStream, bool) {
+	entry, ok := reader.block()
+	if !ok {
+		return MPLSStream{}, false
+	}
+	attributes, ok := reader.block()
+	if !ok {
+		return MPLSStream{}, false
+	}
+
+	stream := MPLSStream{SubPathID: -1}
+	if len(entry) > 0 {
+		switch entry[0] {
+		case 1:
+			if len(entry) >= 3 {
+				stream.PID = readUint16BE(entry[1:3])
+			}
+		case 2:
+			if len(entry) >= 5 {
+				stream.SubPathID = int(entry[1])
+				stream.PID = readUint16BE(entry[3:5])
+			}
+		case 3, 4:
+			if len(entry) >= 4 {
+				stream.SubPathID = int(entry[1])
+				stream.PID = readUint16BE(entry[2:4])
+			}
+		}
+	}
+
+	parseStreamAttributes(&stream, attributes)
+	return stream, true
+}
+
+// parseStreamAttributes 解析 MPLS stream_attributes 或 CLPI stream_coding_info 中的编码类型、格式和语言。
+func parseStreamAttributes(stream *MPLSStream, attributes []byte) {
+	if len(attributes) == 0 {
+		return
+	}
+	stream.CodingType = attributes[0]
+	stream.Codec = MPLSCodecName(stream.CodingType)
+	switch stream.CodingType {
+	case 0x01, 0x02, 0x1B, 0x20, 0x24, 0xEA:
+		if len(attributes) >= 2 {
+			stream.Format, stream.Rate = attributes[1]>>4, attributes[1]&0x0F
+		}
+	case 0x03, 0x04, 0x80, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0xA1, 0xA2:
+		if len(attributes) >= 5 {
+			stream.Format, stream.Rate = attributes[1]>>4, attributes[1]&0x0F
+			stream.Language = string(attributes[2:5])
+		}
+	case 0x90, 0x91:
+		if len(attributes) >= 4 {
+			stream.Language = string(attributes[1:4])
+		}
+	case 0x92:
+		if len(attributes) >= 5 {
+			stream.Language = string(attributes[2:5])
+		}
+	}
+}
+
+// parseMPLSSubPath 解析一个 SubPath，结束后把读取位置移动到下一个子路径。
+func parseMPLSSubPath(reader *bdmvReader) (MPLSSubPath, error) {
+	if !reader.has(4) {
+		return MPLSSubPath{}, fmt.Errorf("mpls: truncated sub path header")
+	}
+	subPathStart := reader.pos
+	subPathEnd := subPathStart + 4 + int(reader.u32())
+	if subPathEnd > len(reader.data) || subPathStart+10 > subPathEnd {
+		return MPLSSubPath{}, fmt.Errorf("mpls: truncated sub path body")
+	}
+
+	reader.skip(1) // reserved
+	subPath := MPLSSubPath{Type: reader.u8()}
+	subPath.Repeat = reader.u16()&0x01 != 0
+	reader.skip(1) // reserved
+	itemCount := int(reader.u8())
+
+	for index := 0; index < itemCount; index++ {
+		if reader.pos+2 > subPathEnd {
+			return MPLSSubPath{}, fmt.Errorf("mpls: truncated sub play item header")
+		}
+		itemStart := reader.pos
+		itemEnd := itemStart + 2 + int(reader.u16())
+		if itemEnd > subPathEnd || itemStart+30 > itemEnd {
+			return MPLSSubPath{}, fmt.Errorf("mpls: truncated sub play item body")
+		}
+		item := MPLSSubPlayItem{
+			ClipID:  reader.str(5),
+			CodecID: reader.str(4),
+		}
+		reader.skip(4) // connection_condition / is_multi_Clip_entries
+		reader.skip(1) // ref_to_STC_id
+		item.InTime = reader.u32()
+		item.OutTime = reader.u32()
+		item.SyncPlayItem = reader.u16()
+		item.SyncStartPTS = reader.u32()
+		subPath.Items = append(subPath.Items, item)
+		reader.pos = itemEnd
+	}
+
+	reader.pos = subPathEnd
+	return subPath, nil
+}
+
+// parseMPLSMarks 解析 PlayListMark 区段。
+func parseMPLSMarks(data []byte, offset int) ([]MPLSMark, error) {
+	if offset+6 > len(data) {
+		return nil, fmt.Errorf("mpls: invalid playlist mark offset")
+	}
+	reader := &bdmvReader{data: data, pos: offset}
+	reader.skip(4) // length
+	count := int(reader.u16())
+	if !reader.has(count * 14) {
+		return nil, fmt.Errorf("mpls: truncated playlist marks")
+	}
+
+	marks := make([]MPLSMark, 0, count)
+	for index := 0; index < count; index++ {
+		reader.skip(1) // reserved
+		marks = append(marks, MPLSMark{
+			Type:      reader.u8(),
+			PlayItem:  reader.u16(),
+			Timestamp: reader.u32(),
+			PID:       reader.u16(),
+			Duration:  reader.u32(),
+		})
+	}
+	return marks, nil
+}
+
+// Duration 返回播放项 in/out time 之间的时长。
+func (item MPLSPlayItem) Duration() time.Duration {
+	if item.OutTime <= item.InTime {
+		return 0
+	}
+	return mplsTicksToDuration(uint64(item.OutTime - item.InTime))
+}
+
+// Duration 返回播放列表所有主路径播放项的总时长。
+func (p *MPLSPlaylist) Duration() time.Duration {
+	var total time.Duration
+	for _, item := range p.Items {
+		total += item.Duration()
+	}
+	return total
+}
+
+// ClipIDs 按播放顺序返回主路径引用的片段编号，同一片段只返回一次。
+func (p *MPLSPlaylist) ClipIDs() []string {
+	seen := make(map[string]struct{}, len(p.Items))
+	clips := make([]string, 0, len(p.Items))
+	for _, item := range p.Items {
+		if _, ok := seen[item.ClipID]; ok {
+			continue
+		}
+		seen[item.ClipID] = struct{}{}
+		clips = append(clips, item.ClipID)
+	}
+	return clips
+}
+
+// Chapters 返回各章节点相对播放列表开头的时间偏移；引用不存在播放项的标记会被忽略。
+func (p *MPLSPlaylist) Chapters() []time.Duration {
+	starts := make([]time.Duration, len(p.Items))
+	var elapsed time.Duration
+	for index, item := range p.Items {
+		starts[index] = elapsed
+		elapsed += item.Duration()
+	}
+
+	chapters := make([]time.Duration, 0, len(p.Marks))
+	for _, mark := range p.Marks {
+		if mark.Type != MPLSMarkEntry || int(mark.PlayItem) >= len(p.Items) {
+			continue
+		}
+		item := p.Items[mark.PlayItem]
+		offset := starts[mark.PlayItem]
+		if mark.Timestamp > item.InTime {
+			offset += mplsTicksToDuration(uint64(mark.Timestamp - item.InTime))
+		}
+		chapters = append(chapters, offset)
+	}
+	return chapters
+}
+
+// MPLSCodecName 返回 STN 流编码类型对应的常用名称。
+func MPLSCodecName(codingType uint8) string {
+	switch codingType {
+	case 0x01:
+		return "MPEG-1 Video"
+	case 0x02:
+		return "MPEG-2 Video"
+	case 0x1B:
+		return "AVC"
+	case 0x20:
+		return "MVC"
+	case 0x24:
+		return "HEVC"
+	case 0xEA:
+		return "VC-1"
+	case 0x03:
+		return "MPEG-1 Audio"
+	case 0x04:
+		return "MPEG-2 Audio"
+	case 0x80:
+		return "LPCM"
+	case 0x81:
+		return "Dolby Digital"
+	case 0x82:
+		return "DTS"
+	case 0x83:
+		return "Dolby TrueHD"
+	case 0x84, 0xA1:
+		return "Dolby Digital Plus"
+	case 0x85:
+		return "DTS-HD High Resolution"
+	case 0x86:
+		return "DTS-HD Master Audio"
+	case 0xA2:
+		return "DTS Express"
+	case 0x90:
+		return "Presentation Graphics"
+	case 0x91:
+		return "Interactive Graphics"
+	case 0x92:
+		return "Text Subtitle"
+	default:
+		return fmt.Sprintf("0x%02X", codingType)
+	}
+}
+
+// mplsTicksToDuration 把 45kHz 时钟计数转换成时长。
+func mplsTicksToDuration(ticks uint64) time.Duration {
+	return time.Duration(ticks * uint64(time.Second) / mplsClockRate)
+}
+
+// bdmvReader 按大端序顺序读取 MPLS / CLPI 数据；limit 非 0 时表示当前结构的结束位置。
+type bdmvReader struct {
+	data  []byte
+	pos   int
+	limit int
+}
+
+// has 判断从当前位置起是否还能读取 n 个字节。
+func (r *bdmvReader) has(n int) bool {
+	end := len(r.data)
+	if r.limit > 0 && r.limit < end {
+		end = r.limit
+	}
+	return n >= 0 && r.pos+n <= end
+}
+
+func (r *bdmvReader) skip(n int) {
+	r.pos += n
+}
+
+func (r *bdmvReader) u8() uint8 {
+	value := r.data[r.pos]
+	r.pos++
+	return value
+}
+
+func (r *bdmvReader) u16() uint16 {
+	value := readUint16BE(r.data[r.pos : r.pos+2])
+	r.pos += 2
+	return value
+}
+
+func (r *bdmvReader) u32() uint32 {
+	value := readUint32BE(r.data[r.pos : r.pos+4])
+	r.pos += 4
+	return value
+}
+
+// block 读取一个以单字节长度开头的数据块，并返回长度字段之后的内容。
+func (r *bdmvReader) block() ([]byte, bool) {
+	if !r.has(1) {
+		return nil, false
+	}
+	length := int(r.data[r.pos])
+	if !r.has(1 + length) {
+		return nil, false
+	}
+	value := r.data[r.pos+1 : r.pos+1+length]
+	r.pos += 1 + length
+	return value, true
+}
+
+func (r *bdmvReader) str(n int) string {
+	value := strings.TrimRight(string(r.data[r.pos:r.pos+n]), "\x00 ")
+	r.pos += n
+	return value
+}
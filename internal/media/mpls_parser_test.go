@@ -0,0 +1,198 @@
+// Package media 验证完整 MPLS 播放列表解析。
+
+package media
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// TestParseMPLSReadsItemsStreamsSubPathsAndMarks 验证播放项、多角度、STN 流表、子路径和章节标记都能被解析。
+func TestParseMPLSReadsItemsStreamsSubPathsAndMarks(t *testing.T) {
+	playlist, err := ParseMPLS(buildTestFullMPLSFile())
+	if err != nil {
+		t.Fatalf("ParseMPLS() error: %v", err)
+	}
+
+	if playlist.Version != "0200" {
+		t.Fatalf("Version = %q, want %q", playlist.Version, "0200")
+	}
+	if len(playlist.Items) != 2 {
+		t.Fatalf("len(Items) = %d, want 2", len(playlist.Items))
+	}
+
+	first := playlist.Items[0]
+	if first.ClipID != "00055" || first.CodecID != "M2TS" || first.ConnectionCondition != 1 {
+		t.Fatalf("Items[0] = %+v, want clip 00055/M2TS with connection condition 1", first)
+	}
+	if first.Duration() != 600*time.Second {
+		t.Fatalf("Items[0].Duration() = %v, want 10m0s", first.Duration())
+	}
+	if len(first.Angles) != 1 || first.Angles[0].ClipID != "00056" {
+		t.Fatalf("Items[0].Angles = %+v, want one extra angle 00056", first.Angles)
+	}
+
+	streams := first.Streams
+	if len(streams.Video) != 1 || streams.Video[0].Codec != "AVC" || streams.Video[0].PID != 0x1011 {
+		t.Fatalf("Video = %+v, want AVC on PID 0x1011", streams.Video)
+	}
+	if streams.Video[0].Format != 6 || streams.Video[0].Rate != 1 {
+		t.Fatalf("Video format/rate = %d/%d, want 6/1", streams.Video[0].Format, streams.Video[0].Rate)
+	}
+	if len(streams.Audio) != 2 ||
+		streams.Audio[0].Codec != "Dolby TrueHD" || streams.Audio[0].Language != "eng" ||
+		streams.Audio[1].Codec != "Dolby Digital" || streams.Audio[1].Language != "jpn" {
+		t.Fatalf("Audio = %+v, want TrueHD eng and Dolby Digital jpn", streams.Audio)
+	}
+	if len(streams.PG) != 2 || streams.PG[0].Language != "chi" || streams.PG[1].Language != "eng" || streams.PG[1].SubPathID != 0 {
+		t.Fatalf("PG = %+v, want chi from main path and eng from sub path 0", streams.PG)
+	}
+	if len(streams.IG) != 1 || streams.IG[0].Codec != "Interactive Graphics" || streams.IG[0].Language != "eng" {
+		t.Fatalf("IG = %+v, want one eng interactive graphics stream", streams.IG)
+	}
+
+	second := playlist.Items[1]
+	if second.ClipID != "00057" || len(second.Streams.Audio) != 0 || len(second.Angles) != 0 {
+		t.Fatalf("Items[1] = %+v, want clip 00057 without streams or angles", second)
+	}
+
+	if len(playlist.SubPaths) != 1 {
+		t.Fatalf("len(SubPaths) = %d, want 1", len(playlist.SubPaths))
+	}
+	subPath := playlist.SubPaths[0]
+	if subPath.Type != 4 || subPath.Repeat || len(subPath.Items) != 1 || subPath.Items[0].ClipID != "00100" || subPath.Items[0].SyncPlayItem != 1 {
+		t.Fatalf("SubPaths[0] = %+v, want type 4 with clip 00100 synced to item 1", subPath)
+	}
+
+	if got := playlist.Duration(); got != 900*time.Second {
+		t.Fatalf("Duration() = %v, want 15m0s", got)
+	}
+	if got := playlist.ClipIDs(); len(got) != 2 || got[0] != "00055" || got[1] != "00057" {
+		t.Fatalf("ClipIDs() = %v, want [00055 00057]", got)
+	}
+
+	chapters := playlist.Chapters()
+	want := []time.Duration{0, 300 * time.Second, 600 * time.Second, 660 * time.Second}
+	if len(chapters) != len(want) {
+		t.Fatalf("Chapters() = %v, want %v", chapters, want)
+	}
+	for index := range want {
+		if chapters[index] != want[index] {
+			t.Fatalf("Chapters() = %v, want %v", chapters, want)
+		}
+	}
+}
+
+// TestParseMPLSKeepsTimingOnlyItems 验证只包含时序字段的精简播放项仍能解析出时长。
+func TestParseMPLSKeepsTimingOnlyItems(t *testing.T) {
+	playlist, err := ParseMPLS(buildTestMPLSFile([][2]uint32{{0, 20 * mplsClockRate}, {0, 40 * mplsClockRate}}))
+	if err != nil {
+		t.Fatalf("ParseMPLS() error: %v", err)
+	}
+	if playlist.Duration() != time.Minute {
+		t.Fatalf("Duration() = %v, want 1m0s", playlist.Duration())
+	}
+	if len(playlist.Items[0].Streams.Video) != 0 || len(playlist.Marks) != 0 {
+		t.Fatalf("Items[0] = %+v, want no stream table", playlist.Items[0])
+	}
+}
+
+// TestParseMPLSRejectsTruncatedMarks 验证章节标记区段越界时返回错误。
+func TestParseMPLSRejectsTruncatedMarks(t *testing.T) {
+	data := buildTestFullMPLSFile()
+	markOffset := binary.BigEndian.Uint32(data[12:16])
+	if _, err := ParseMPLS(data[:markOffset+10]); err == nil {
+		t.Fatal("ParseMPLS(truncated) error = nil, want error")
+	}
+}
+
+// buildTestFullMPLSFile 构造一个包含两个播放项、一个子路径和四个标记的 MPLS 文件：
+// 第一个播放项是带 STN 流表的多角度项（0-600 秒），第二个播放项只有时序信息（1000-1300 秒）。
+func buildTestFullMPLSFile() []byte {
+	stream := func(entry, attributes []byte) []byte {
+		data := append([]byte{byte(len(entry))}, entry...)
+		data = append(data, byte(len(attributes)))
+		return append(data, attributes...)
+	}
+
+	var stn []byte
+	stn = append(stn, 0, 0)          // reserved
+	stn = append(stn, 1, 2, 1, 1)    // video, audio, pg, ig
+	stn = append(stn, 0, 0, 1)       // secondary audio, secondary video, pip pg
+	stn = append(stn, 0, 0, 0, 0, 0) // reserved
+	stn = append(stn, stream([]byte{1, 0x10, 0x11}, []byte{0x1B, 0x61})...)
+	stn = append(stn, stream([]byte{1, 0x11, 0x00}, []byte{0x83, 0x61, 'e', 'n', 'g'})...)
+	stn = append(stn, stream([]byte{1, 0x11, 0x01}, []byte{0x81, 0x31, 'j', 'p', 'n'})...)
+	stn = append(stn, stream([]byte{1, 0x12, 0x00}, []byte{0x90, 'c', 'h', 'i'})...)
+	stn = append(stn, stream([]byte{3, 0, 0x12, 0x01}, []byte{0x90, 'e', 'n', 'g'})...)
+	stn = append(stn, stream([]byte{1, 0x14, 0x00}, []byte{0x91, 'e', 'n', 'g'})...)
+	stn = append(binary.BigEndian.AppendUint16(nil, uint16(len(stn))), stn...)
+
+	var first []byte
+	first = append(first, "00055M2TS"...)
+	first = binary.BigEndian.AppendUint16(first, 0x10|0x01) // multi angle, connection condition 1
+	first = append(first, 0)
+	first = binary.BigEndian.AppendUint32(first, 0)
+	first = binary.BigEndian.AppendUint32(first, 600*mplsClockRate)
+	first = append(first, make([]byte, 8+1+1+2)...)
+	first = append(first, 2, 0)
+	first = append(first, "00056M2TS"...)
+	first = append(first, 0)
+	first = append(first, stn...)
+
+	var second []byte
+	second = append(second, "00057M2TS"...)
+	second = append(second, 0, 0, 0)
+	second = binary.BigEndian.AppendUint32(second, 1000*mplsClockRate)
+	second = binary.BigEndian.AppendUint32(second, 1300*mplsClockRate)
+
+	var subItem []byte
+	subItem = append(subItem, "00100M2TS"...)
+	subItem = append(subItem, 0, 0, 0, 0, 0)
+	subItem = binary.BigEndian.AppendUint32(subItem, 0)
+	subItem = binary.BigEndian.AppendUint32(subItem, 300*mplsClockRate)
+	subItem = binary.BigEndian.AppendUint16(subItem, 1)
+	subItem = binary.BigEndian.AppendUint32(subItem, 0)
+
+	var subPath []byte
+	subPath = append(subPath, 0, 4, 0, 0, 0, 1)
+	subPath = binary.BigEndian.AppendUint16(subPath, uint16(len(subItem)))
+	subPath = append(subPath, subItem...)
+
+	var playlist []byte
+	playlist = append(playlist, 0, 0)
+	playlist = binary.BigEndian.AppendUint16(playlist, 2)
+	playlist = binary.BigEndian.AppendUint16(playlist, 1)
+	for _, item := range [][]byte{first, second} {
+		playlist = binary.BigEndian.AppendUint16(playlist, uint16(len(item)))
+		playlist = append(playlist, item...)
+	}
+	playlist = binary.BigEndian.AppendUint32(playlist, uint32(len(subPath)))
+	playlist = append(playlist, subPath...)
+	playlist = append(binary.BigEndian.AppendUint32(nil, uint32(len(playlist))), playlist...)
+
+	mark := func(markType byte, item uint16, timestamp uint32) []byte {
+		data := []byte{0, markType}
+		data = binary.BigEndian.AppendUint16(data, item)
+		data = binary.BigEndian.AppendUint32(data, timestamp)
+		data = binary.BigEndian.AppendUint16(data, 0xFFFF)
+		return binary.BigEndian.AppendUint32(data, 0)
+	}
+	var marks []byte
+	marks = binary.BigEndian.AppendUint16(marks, 5)
+	marks = append(marks, mark(MPLSMarkEntry, 0, 0)...)
+	marks = append(marks, mark(MPLSMarkEntry, 0, 300*mplsClockRate)...)
+	marks = append(marks, mark(MPLSMarkLinkPoint, 0, 450*mplsClockRate)...)
+	marks = append(marks, mark(MPLSMarkEntry, 1, 1000*mplsClockRate)...)
+	marks = append(marks, mark(MPLSMarkEntry, 1, 1060*mplsClockRate)...)
+	marks = append(binary.BigEndian.AppendUint32(nil, uint32(len(marks))), marks...)
+
+	const playlistOffset = 58
+	data := make([]byte, playlistOffset)
+	copy(data[:8], "MPLS0200")
+	binary.BigEndian.PutUint32(data[8:12], playlistOffset)
+	binary.BigEndian.PutUint32(data[12:16], uint32(playlistOffset+len(playlist)))
+	data = append(data, playlist...)
+	return append(data, marks...)
+}
@@ -5,11 +5,14 @@ package media
 import (
 	"context"
 	"errors"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"minfo/internal/media/isofs"
 )
 
 // SuggestedPath 表示路径联想结果中的一个候选项。
@@ -125,7 +128,7 @@ func withDirSuffix(path string) string {
 	return path + string(filepath.Separator)
 }
 
-// suggestVirtualISOPaths 在挂载后的 ISO 目录中执行路径联想，并把结果重新映射回虚拟 ISO 路径。
+// suggestVirtualISOPaths 在 ISO 内部目录中执行路径联想，并把结果映射为虚拟 ISO 路径。
 func suggestVirtualISOPaths(roots []string, prefix string, limit int) ([]SuggestedPath, string, error) {
 	isoPath, inner, ok := parseVirtualISOPath(prefix)
 	if !ok {
@@ -137,12 +140,6 @@ func suggestVirtualISOPaths(roots []string, prefix string, limit int) ([]Suggest
 		return nil, "", errors.New("path is outside MEDIA_ROOTS")
 	}
 
-	mountDir, cleanup, err := mountISO(context.Background(), isoPath)
-	if err != nil {
-		return nil, "", err
-	}
-	defer cleanup()
-
 	dirInner := inner
 	base := ""
 	if !hasDirectorySuffix(prefix) {
@@ -156,19 +153,11 @@ func suggestVirtualISOPaths(roots []string, prefix string, limit int) ([]Suggest
 		}
 	}
 
-	dirOnDisk := mountDir
-	if dirInner != "/" {
-		dirOnDisk = filepath.Join(mountDir, filepath.FromSlash(strings.TrimPrefix(dirInner, "/")))
-	}
-	dirOnDisk = filepath.Clean(dirOnDisk)
-	if !isSubpath(mountDir, dirOnDisk) {
-		return nil, "", errors.New("path is outside mounted ISO")
-	}
-
-	entries, err := os.ReadDir(dirOnDisk)
+	entries, readDuration, cleanup, err := readVirtualISODir(isoPath, dirInner)
 	if err != nil {
 		return nil, "", err
 	}
+	defer cleanup()
 
 	items := make([]SuggestedPath, 0, len(entries))
 	for _, entry := range entries {
@@ -191,7 +180,7 @@ func suggestVirtualISOPaths(roots []string, prefix string, limit int) ([]Suggest
 				return nil, "", err
 			}
 			item.Size = info.Size()
-			item.Duration = readSuggestedPathDuration(filepath.Join(dirOnDisk, name))
+			item.Duration = readDuration(entryInner)
 		}
 		items = append(items, item)
 		if limit > 0 && len(items) >= limit {
@@ -205,6 +194,43 @@ func suggestVirtualISOPaths(roots []string, prefix string, limit int) ([]Suggest
 	return items, selectedRoot, nil
 }
 
+// readVirtualISODir 列出 ISO 内部目录，并返回按镜像内路径读取 MPLS 时长的函数。
+//
+// 优先直接解析镜像，不需要特权挂载；镜像无法解析时回退为挂载后读取。
+func readVirtualISODir(isoPath, dirInner string) ([]fs.DirEntry, func(inner string) string, func(), error) {
+	if image, err := isofs.Open(isoPath); err == nil {
+		entries, err := image.ReadDir(isoRelativePath(dirInner))
+		if err != nil {
+			image.Close()
+			return nil, nil, func() {}, err
+		}
+		readDuration := func(inner string) string {
+			return readISOMPLSDuration(image, inner)
+		}
+		return entries, readDuration, func() { image.Close() }, nil
+	}
+
+	mountDir, cleanup, err := mountISO(context.Background(), isoPath)
+	if err != nil {
+		return nil, nil, func() {}, err
+	}
+
+	dirOnDisk := filepath.Clean(filepath.Join(mountDir, filepath.FromSlash(strings.TrimPrefix(dirInner, "/"))))
+	if !isSubpath(mountDir, dirOnDisk) {
+		cleanup()
+		return nil, nil, func() {}, errors.New("path is outside mounted ISO")
+	}
+	entries, err := os.ReadDir(dirOnDisk)
+	if err != nil {
+		cleanup()
+		return nil, nil, func() {}, err
+	}
+	readDuration := func(inner string) string {
+		return readSuggestedPathDuration(filepath.Join(mountDir, filepath.FromSlash(strings.TrimPrefix(inner, "/"))))
+	}
+	return entries, readDuration, cleanup, nil
+}
+
 // listDir 会列出目录，并按当前规则返回排序后的结果列表。
 func listDir(dir, base string, limit int) ([]SuggestedPath, error) {
 	entries, err := os.ReadDir(dir)
@@ -255,6 +281,22 @@ func readSuggestedPathDuration(path string) string {
 	return formatMPLSDuration(duration)
 }
 
+// readISOMPLSDuration 在 ISO 内部路径为 MPLS 文件时直接从镜像读取并返回格式化时长。
+func readISOMPLSDuration(image *isofs.Image, inner string) string {
+	if !isMPLSFile(inner) {
+		return ""
+	}
+	data, err := image.ReadFile(isoRelativePath(inner))
+	if err != nil {
+		return ""
+	}
+	duration, err := parseMPLSDuration(data)
+	if err != nil {
+		return ""
+	}
+	return formatMPLSDuration(duration)
+}
+
 // hasDirectorySuffix 会判断目录后缀是否已经存在。
 func hasDirectorySuffix(value string) bool {
 	return strings.HasSuffix(value, string(filepath.Separator)) || strings.HasSuffix(value, "/") || strings.HasSuffix(value, "\\")
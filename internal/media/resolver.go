@@ -31,22 +31,31 @@ type BDInfoSource struct {
 
 // ResolveScreenshotSource 把输入路径解析成可直接交给截图流程的实际视频文件，并返回可能需要的清理函数。
 func ResolveScreenshotSource(ctx context.Context, input string) (string, func(), error) {
+	if IsISOServerURL(input) {
+		return input, func() {}, nil
+	}
 	info, err := os.Stat(input)
 	if err != nil {
 		return "", func() {}, err
 	}
 	if !info.IsDir() {
 		if isISOFile(input) {
-			return resolveVideoFromMountedISO(ctx, input)
+			return resolveVideoFromISO(ctx, input)
 		}
 		if sourcePath, ok := resolveDVDFileScreenshotSource(input); ok {
 			return sourcePath, func() {}, nil
 		}
+		if _, _, ok := resolveBDInfoPlaylistSelection(input); ok {
+			if clip, ok := findBlurayPlaylistClip(osTree{}, input); ok {
+				return clip, func() {}, nil
+			}
+			return "", func() {}, errors.New("playlist does not reference any M2TS clip")
+		}
 		return input, func() {}, nil
 	}
 
-	if bdmvRoot, ok := resolveBDMVRoot(input); ok {
-		m2ts, err := findLargestM2TS(bdmvRoot)
+	if bdmvRoot, ok := resolveBDMVRoot(osTree{}, input); ok {
+		m2ts, err := findBlurayScreenshotClip(osTree{}, bdmvRoot)
 		if err != nil {
 			return "", func() {}, err
 		}
@@ -55,13 +64,13 @@ func ResolveScreenshotSource(ctx context.Context, input string) (string, func(),
 
 	isoPath, err := findISOInDir(input)
 	if err == nil {
-		return resolveVideoFromMountedISO(ctx, isoPath)
+		return resolveVideoFromISO(ctx, isoPath)
 	}
 	if !errors.Is(err, errNoISO) {
 		return "", func() {}, err
 	}
 
-	videoPath, err := resolveScreenshotSourceFromRoot(input)
+	videoPath, err := resolveScreenshotSourceFromRoot(osTree{}, input)
 	if err != nil {
 		return "", func() {}, err
 	}
@@ -70,6 +79,9 @@ func ResolveScreenshotSource(ctx context.Context, input string) (string, func(),
 
 // ResolveMediaInfoCandidates 根据输入路径返回适合 MediaInfo 重试的候选文件列表。
 func ResolveMediaInfoCandidates(ctx context.Context, input string, limit int) ([]string, func(), error) {
+	if IsISOServerURL(input) {
+		return []string{input}, func() {}, nil
+	}
 	info, err := os.Stat(input)
 	if err != nil {
 		return nil, func() {}, err
@@ -78,7 +90,7 @@ func ResolveMediaInfoCandidates(ctx context.Context, input string, limit int) ([
 		return []string{input}, func() {}, nil
 	}
 
-	if dvdRoot, ok := resolveDVDVideoRoot(input); ok {
+	if dvdRoot, ok := resolveDVDVideoRoot(osTree{}, input); ok {
 		target, err := resolveDVDMediaInfoFileFromRoot(dvdRoot)
 		if err != nil {
 			return nil, func() {}, err
@@ -103,6 +115,9 @@ func ResolveMediaInfoCandidates(ctx context.Context, input string, limit int) ([
 
 // ResolveDVDMediaInfoSource 把输入路径解析成 DVD MediaInfo 应该探测的文件或 ISO 路径。
 func ResolveDVDMediaInfoSource(ctx context.Context, input string) (string, func(), error) {
+	if IsISOServerURL(input) {
+		return input, func() {}, nil
+	}
 	info, err := os.Stat(input)
 	if err != nil {
 		return "", func() {}, err
@@ -115,7 +130,7 @@ func ResolveDVDMediaInfoSource(ctx context.Context, input string) (string, func(
 		return input, func() {}, nil
 	}
 
-	if dvdRoot, ok := resolveDVDVideoRoot(input); ok {
+	if dvdRoot, ok := resolveDVDVideoRoot(osTree{}, input); ok {
 		target, err := resolveDVDMediaInfoFileFromRoot(dvdRoot)
 		if err != nil {
 			return "", func() {}, err
@@ -134,8 +149,11 @@ func ResolveDVDMediaInfoSource(ctx context.Context, input string) (string, func(
 	return "", func() {}, errors.New("path does not contain DVD VIDEO_TS content")
 }
 
-// ResolveBDInfoSource 把输入路径解析成 BDInfo 可以扫描的目录，并在需要时附带指定 playlist。
+// ResolveBDInfoSource 把输入路径解析成 BDInfo 可以扫描的目录，并附带用户指定或自动识别出的正片 playlist。
 func ResolveBDInfoSource(ctx context.Context, input string) (BDInfoSource, func(), error) {
+	if IsISOServerURL(input) {
+		return BDInfoSource{}, func() {}, errors.New("BDInfo requires a mounted ISO folder, not a single streamed file")
+	}
 	info, err := os.Stat(input)
 	if err != nil {
 		return BDInfoSource{}, func() {}, err
@@ -150,8 +168,8 @@ func ResolveBDInfoSource(ctx context.Context, input string) (BDInfoSource, func(
 		return BDInfoSource{}, func() {}, errors.New("path must be a folder containing BDMV or ISO, or a MPLS file under BDMV/PLAYLIST")
 	}
 
-	if bdmvRoot, ok := resolveBDInfoRoot(input); ok {
-		return BDInfoSource{Path: bdmvRoot}, func() {}, nil
+	if bdmvRoot, ok := resolveBDInfoRoot(osTree{}, input); ok {
+		return BDInfoSource{Path: bdmvRoot, Playlist: mainBlurayPlaylist(osTree{}, bdmvRoot)}, func() {}, nil
 	}
 
 	isoPath, err := findISOInDir(input)
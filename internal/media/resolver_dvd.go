@@ -14,12 +14,12 @@ import (
 
 // resolveDVDMediaInfoFileFromRoot 从 DVD 目录中挑选最适合交给 MediaInfo 的 IFO 或 VOB 文件。
 func resolveDVDMediaInfoFileFromRoot(root string) (string, error) {
-	dvdRoot, ok := resolveDVDVideoRoot(root)
+	dvdRoot, ok := resolveDVDVideoRoot(osTree{}, root)
 	if !ok {
 		return "", errors.New("VIDEO_TS folder not found")
 	}
 
-	titleVOB, err := findMainDVDTitleSetFirstVOB(dvdRoot)
+	titleVOB, err := findMainDVDTitleSetFirstVOB(osTree{}, dvdRoot)
 	if err == nil {
 		ifoPath := dvdControlIFOPathFromTitleVOB(titleVOB)
 		if ifoPath != "" {
@@ -38,8 +38,10 @@ func resolveDVDMediaInfoFileFromRoot(root string) (string, error) {
 }
 
 // findMainDVDTitleSetFirstVOB 选择主标题集对应的首个 VOB 文件。
-func findMainDVDTitleSetFirstVOB(videoTSDir string) (string, error) {
-	entries, err := os.ReadDir(videoTSDir)
+//
+// 能解析 IFO 时以播放时长最长的标题所在标题集为准；否则退回按标题集 VOB 总大小挑选。
+func findMainDVDTitleSetFirstVOB(tree sourceTree, videoTSDir string) (string, error) {
+	entries, err := tree.ReadDir(videoTSDir)
 	if err != nil {
 		return "", err
 	}
@@ -75,12 +77,20 @@ func findMainDVDTitleSetFirstVOB(videoTSDir string) (string, error) {
 		return "", errors.New("no DVD title VOB files found under VIDEO_TS")
 	}
 
+	preferredTitleSet := 0
+	if title, ok := findMainDVDTitle(tree, videoTSDir); ok {
+		preferredTitleSet = title.TitleSet
+	}
+
 	titleSetSizes := make(map[int]int64, len(items))
 	for _, item := range items {
 		titleSetSizes[item.titleSet] += item.size
 	}
 
 	sort.Slice(items, func(i, j int) bool {
+		if leftPreferred, rightPreferred := items[i].titleSet == preferredTitleSet, items[j].titleSet == preferredTitleSet; leftPreferred != rightPreferred {
+			return leftPreferred
+		}
 		leftTotal := titleSetSizes[items[i].titleSet]
 		rightTotal := titleSetSizes[items[j].titleSet]
 		if leftTotal != rightTotal {
@@ -177,10 +187,10 @@ func parseDVDTitleVOBName(name string) (int, int, bool) {
 }
 
 // findLargestVideoFile 递归查找目录树中体积最大的受支持视频文件。
-func findLargestVideoFile(root string) (string, error) {
+func findLargestVideoFile(tree sourceTree, root string) (string, error) {
 	var largestPath string
 	var largestSize int64
-	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	if err := tree.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
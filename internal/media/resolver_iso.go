@@ -1,14 +1,18 @@
-// Package media 提供 ISO 识别、挂载后解析和目录内 ISO 搜索辅助函数。
+// Package media 提供 ISO 识别、镜像内源选择和目录内 ISO 搜索辅助函数。
 
 package media
 
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"minfo/internal/config"
+	"minfo/internal/media/isofs"
 )
 
 // isISOFile 判断路径是否以 .iso 结尾。
@@ -41,17 +45,17 @@ func findISOInDir(root string) (string, error) {
 	return isoPath, nil
 }
 
-// findLargestM2TS 在 BDMV 或 BDMV/STREAM 下返回体积最大的 M2TS 文件。
-func findLargestM2TS(root string) (string, error) {
+// findLargestM2TS 在 tree 中的 BDMV 或 BDMV/STREAM 下返回体积最大的 M2TS 文件。
+func findLargestM2TS(tree sourceTree, root string) (string, error) {
 	searchRoot := root
 	stream := filepath.Join(root, "STREAM")
-	if info, err := os.Stat(stream); err == nil && info.IsDir() {
+	if info, err := tree.Stat(stream); err == nil && info.IsDir() {
 		searchRoot = stream
 	}
 
 	var largestPath string
 	var largestSize int64
-	err := filepath.WalkDir(searchRoot, func(path string, d fs.DirEntry, err error) error {
+	err := tree.WalkDir(searchRoot, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -77,13 +81,25 @@ func findLargestM2TS(root string) (string, error) {
 	return largestPath, nil
 }
 
-// resolveVideoFromMountedISO 挂载 ISO 并在其中选择截图流程要使用的视频文件。
-func resolveVideoFromMountedISO(ctx context.Context, isoPath string) (string, func(), error) {
+// resolveVideoFromISO 在 ISO 中选择截图流程要使用的视频文件。
+//
+// 选择过程直接读取镜像目录，不需要挂载；选中的文件按 ISO_ACCESS_MODE 挂载或通过本机 HTTP 服务交给截图工具。
+// 镜像无法被纯 Go 解析时回退为挂载后再选择。
+func resolveVideoFromISO(ctx context.Context, isoPath string) (string, func(), error) {
+	if image, err := isofs.Open(isoPath); err == nil {
+		inner, err := resolveScreenshotSourceFromRoot(isoTree{image: image}, "/")
+		image.Close()
+		if err != nil {
+			return "", func() {}, err
+		}
+		return openISOTarget(ctx, isoPath, inner)
+	}
+
 	mountDir, cleanup, err := mountISO(ctx, isoPath)
 	if err != nil {
 		return "", func() {}, err
 	}
-	videoPath, err := resolveScreenshotSourceFromRoot(mountDir)
+	videoPath, err := resolveScreenshotSourceFromRoot(osTree{}, mountDir)
 	if err != nil {
 		cleanup()
 		return "", func() {}, err
@@ -91,16 +107,78 @@ func resolveVideoFromMountedISO(ctx context.Context, isoPath string) (string, fu
 	return videoPath, cleanup, nil
 }
 
-// resolveBDInfoFromMountedISO 挂载 ISO 并返回其中可供 BDInfo 扫描的蓝光根目录。
+// resolveBDInfoFromMountedISO 返回 ISO 中可供 BDInfo 扫描的蓝光根目录和正片 playlist；查找同样先在未挂载的镜像中完成。
 func resolveBDInfoFromMountedISO(ctx context.Context, isoPath string) (BDInfoSource, func(), error) {
+	if image, err := isofs.Open(isoPath); err == nil {
+		tree := isoTree{image: image}
+		inner, ok := resolveBDInfoRoot(tree, "/")
+		playlist := ""
+		if ok {
+			playlist = mainBlurayPlaylist(tree, inner)
+		}
+		image.Close()
+		if !ok {
+			return BDInfoSource{}, func() {}, errors.New("BDMV folder not found in ISO")
+		}
+		root, cleanup, err := mountISOTarget(ctx, isoPath, inner)
+		if err != nil {
+			return BDInfoSource{}, func() {}, err
+		}
+		return BDInfoSource{Path: root, Playlist: playlist}, cleanup, nil
+	}
+
 	mountDir, cleanup, err := mountISO(ctx, isoPath)
 	if err != nil {
 		return BDInfoSource{}, func() {}, err
 	}
-	root, ok := resolveBDInfoRoot(mountDir)
+	root, ok := resolveBDInfoRoot(osTree{}, mountDir)
 	if !ok {
 		cleanup()
 		return BDInfoSource{}, func() {}, errors.New("BDMV folder not found in ISO")
 	}
-	return BDInfoSource{Path: root}, cleanup, nil
+	return BDInfoSource{Path: root, Playlist: mainBlurayPlaylist(osTree{}, root)}, cleanup, nil
+}
+
+// openISOTarget 按 ISO_ACCESS_MODE 把镜像内路径转换成外部工具可以读取的本地路径或本机 HTTP 地址。
+//
+// 目录只能通过挂载访问；auto 模式下挂载失败时，文件会改由本机 HTTP Range 服务提供。
+func openISOTarget(ctx context.Context, isoPath, inner string) (string, func(), error) {
+	switch config.ISOAccessMode {
+	case config.ISOAccessMount:
+		return mountISOTarget(ctx, isoPath, inner)
+	case config.ISOAccessHTTP:
+		return serveISOFile(isoPath, inner)
+	}
+
+	target, cleanup, err := mountISOTarget(ctx, isoPath, inner)
+	if err == nil {
+		return target, cleanup, nil
+	}
+	if url, release, serveErr := serveISOFile(isoPath, inner); serveErr == nil {
+		return url, release, nil
+	}
+	return "", func() {}, err
+}
+
+// mountISOTarget 挂载 ISO，并把镜像内路径映射成挂载点下的实际路径。
+func mountISOTarget(ctx context.Context, isoPath, inner string) (string, func(), error) {
+	mountDir, cleanup, err := mountISO(ctx, isoPath)
+	if err != nil {
+		return "", func() {}, err
+	}
+
+	target := filepath.Clean(filepath.Join(mountDir, filepath.FromSlash(strings.TrimPrefix(inner, "/"))))
+	if !isSubpath(mountDir, target) {
+		cleanup()
+		return "", func() {}, fmt.Errorf("path is outside mounted ISO")
+	}
+	if _, err := os.Stat(target); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("path not found: %v", err)
+	}
+	if err := CheckAllowedPath(target, []string{mountDir}); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("%w: target escapes the mounted ISO", ErrPathNotAllowed)
+	}
+	return target, cleanup, nil
 }
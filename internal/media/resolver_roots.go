@@ -8,8 +8,8 @@ import (
 	"strings"
 )
 
-// resolveBDInfoRoot 返回路径对应的蓝光根目录；它接受光盘根目录、BDMV/PLAYLIST/STREAM 目录。
-func resolveBDInfoRoot(path string) (string, bool) {
+// resolveBDInfoRoot 返回 tree 中路径对应的蓝光根目录；它接受光盘根目录、BDMV/PLAYLIST/STREAM 目录。
+func resolveBDInfoRoot(tree sourceTree, path string) (string, bool) {
 	base := filepath.Base(path)
 	if strings.EqualFold(base, "BDMV") {
 		return filepath.Dir(path), true
@@ -21,33 +21,33 @@ func resolveBDInfoRoot(path string) (string, bool) {
 		return filepath.Dir(filepath.Dir(path)), true
 	}
 	bdmv := filepath.Join(path, "BDMV")
-	if info, err := os.Stat(bdmv); err == nil && info.IsDir() {
+	if info, err := tree.Stat(bdmv); err == nil && info.IsDir() {
 		return path, true
 	}
 	return "", false
 }
 
 // resolveBDMVRoot 返回可用于查找 M2TS 的 BDMV 目录。
-func resolveBDMVRoot(path string) (string, bool) {
+func resolveBDMVRoot(tree sourceTree, path string) (string, bool) {
 	base := filepath.Base(path)
 	if strings.EqualFold(base, "BDMV") || strings.EqualFold(base, "STREAM") {
 		return path, true
 	}
 	bdmv := filepath.Join(path, "BDMV")
-	if info, err := os.Stat(bdmv); err == nil && info.IsDir() {
+	if info, err := tree.Stat(bdmv); err == nil && info.IsDir() {
 		return bdmv, true
 	}
 	return "", false
 }
 
 // resolveDVDVideoRoot 返回路径对应的 VIDEO_TS 目录。
-func resolveDVDVideoRoot(path string) (string, bool) {
+func resolveDVDVideoRoot(tree sourceTree, path string) (string, bool) {
 	base := filepath.Base(path)
 	if strings.EqualFold(base, "VIDEO_TS") {
 		return path, true
 	}
 	videoTS := filepath.Join(path, "VIDEO_TS")
-	if info, err := os.Stat(videoTS); err == nil && info.IsDir() {
+	if info, err := tree.Stat(videoTS); err == nil && info.IsDir() {
 		return videoTS, true
 	}
 	return "", false
@@ -69,7 +69,7 @@ func resolveDVDFileScreenshotSource(path string) (string, bool) {
 	switch strings.ToUpper(filepath.Ext(cleaned)) {
 	case ".VOB":
 		if strings.EqualFold(base, "VIDEO_TS.VOB") {
-			if sourcePath, err := resolveScreenshotSourceFromRoot(filepath.Dir(cleaned)); err == nil {
+			if sourcePath, err := resolveScreenshotSourceFromRoot(osTree{}, filepath.Dir(cleaned)); err == nil {
 				return sourcePath, true
 			}
 		}
@@ -78,7 +78,7 @@ func resolveDVDFileScreenshotSource(path string) (string, bool) {
 		}
 	case ".IFO", ".BUP":
 		if strings.EqualFold(base, "VIDEO_TS.IFO") || strings.EqualFold(base, "VIDEO_TS.BUP") {
-			if sourcePath, err := resolveScreenshotSourceFromRoot(filepath.Dir(cleaned)); err == nil {
+			if sourcePath, err := resolveScreenshotSourceFromRoot(osTree{}, filepath.Dir(cleaned)); err == nil {
 				return sourcePath, true
 			}
 			return "", false
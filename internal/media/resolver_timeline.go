@@ -0,0 +1,126 @@
+// Package media 负责把蓝光输入解析成按播放顺序排列的截图时间线。
+
+package media
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"minfo/internal/media/isofs"
+)
+
+// ScreenshotTimeline 描述蓝光正片播放列表的截图时间线；Segments 为空表示输入不是多片段蓝光，应改用 ResolveScreenshotSource 的单一截图源。
+type ScreenshotTimeline struct {
+	// Playlist 是时间线对应的播放列表文件名。
+	Playlist string
+	Segments []BlurayClipSegment
+
+	// isoPath 非空时，Segments 中的路径是该 ISO 内部的路径，需要通过 OpenSegment 挂载或共享后才能交给外部工具。
+	isoPath string
+}
+
+// Duration 返回时间线上所有播放项的总时长。
+func (t ScreenshotTimeline) Duration() time.Duration {
+	if len(t.Segments) == 0 {
+		return 0
+	}
+	last := t.Segments[len(t.Segments)-1]
+	return last.Start + last.Duration
+}
+
+// Locate 返回时间线上 offset 所在的播放项下标，以及它相对该播放项起点的偏移；offset 超出末尾时落在最后一个播放项。
+func (t ScreenshotTimeline) Locate(offset time.Duration) (int, time.Duration) {
+	if len(t.Segments) == 0 {
+		return -1, 0
+	}
+	for index, segment := range t.Segments {
+		if offset < segment.Start+segment.Duration {
+			return index, max(offset-segment.Start, 0)
+		}
+	}
+	last := len(t.Segments) - 1
+	return last, t.Segments[last].Duration
+}
+
+// OpenSegment 返回第 index 个播放项可交给截图工具读取的路径；ISO 中的片段按 ISO_ACCESS_MODE 挂载或通过本机 HTTP 服务提供。
+func (t ScreenshotTimeline) OpenSegment(ctx context.Context, index int) (string, func(), error) {
+	segment := t.Segments[index]
+	if t.isoPath != "" {
+		return openISOTarget(ctx, t.isoPath, segment.Path)
+	}
+	return segment.Path, func() {}, nil
+}
+
+// ResolveScreenshotTimeline 在输入是蓝光目录、MPLS 文件或蓝光 ISO 时，返回正片（或所选）播放列表的截图时间线。
+// 只有一个播放项、片段缺少 M2TS 或输入不是蓝光时返回空时间线，调用方应回退为单一截图源。
+func ResolveScreenshotTimeline(ctx context.Context, input string) (ScreenshotTimeline, error) {
+	if IsISOServerURL(input) {
+		return ScreenshotTimeline{}, nil
+	}
+	info, err := os.Stat(input)
+	if err != nil {
+		return ScreenshotTimeline{}, err
+	}
+
+	if !info.IsDir() {
+		if isISOFile(input) {
+			return resolveISOScreenshotTimeline(input), nil
+		}
+		if _, _, ok := resolveBDInfoPlaylistSelection(input); ok {
+			bdmvDir := filepath.Dir(filepath.Dir(input))
+			title, ok := readBlurayTitle(osTree{}, bdmvDir, filepath.Base(input), make(map[string]*blurayClipFile))
+			if !ok {
+				return ScreenshotTimeline{}, nil
+			}
+			return newScreenshotTimeline(title, ""), nil
+		}
+		return ScreenshotTimeline{}, nil
+	}
+
+	if bdmvRoot, ok := resolveBDMVRoot(osTree{}, input); ok {
+		if title, ok := findMainBlurayTitle(osTree{}, blurayBDMVDir(bdmvRoot)); ok {
+			return newScreenshotTimeline(title, ""), nil
+		}
+		return ScreenshotTimeline{}, nil
+	}
+	if isoPath, err := findISOInDir(input); err == nil {
+		return resolveISOScreenshotTimeline(isoPath), nil
+	}
+	return ScreenshotTimeline{}, nil
+}
+
+// resolveISOScreenshotTimeline 直接读取镜像目录识别正片播放列表；镜像无法被纯 Go 解析或不是蓝光时返回空时间线。
+func resolveISOScreenshotTimeline(isoPath string) ScreenshotTimeline {
+	image, err := isofs.Open(isoPath)
+	if err != nil {
+		return ScreenshotTimeline{}
+	}
+	defer image.Close()
+
+	tree := isoTree{image: image}
+	bdmvRoot, ok := resolveBDMVRoot(tree, "/")
+	if !ok {
+		return ScreenshotTimeline{}
+	}
+	title, ok := findMainBlurayTitle(tree, blurayBDMVDir(bdmvRoot))
+	if !ok {
+		return ScreenshotTimeline{}
+	}
+	return newScreenshotTimeline(title, isoPath)
+}
+
+// newScreenshotTimeline 用播放列表的播放项构造时间线；少于两个播放项或任一片段没有 M2TS 时返回空时间线。
+func newScreenshotTimeline(title BlurayTitle, isoPath string) ScreenshotTimeline {
+	segments := title.ClipSegments()
+	if len(segments) < 2 {
+		return ScreenshotTimeline{}
+	}
+	for _, segment := range segments {
+		if segment.Path == "" {
+			return ScreenshotTimeline{}
+		}
+	}
+	return ScreenshotTimeline{Playlist: title.Playlist, Segments: segments, isoPath: isoPath}
+}
@@ -5,7 +5,6 @@ package media
 import (
 	"fmt"
 	"io/fs"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -22,8 +21,8 @@ func isVideoFile(path string) bool {
 }
 
 // findVideoFile 优先在当前目录选择体积最大的直接子视频文件；找不到时递归回退。
-func findVideoFile(root string) (string, error) {
-	entries, err := os.ReadDir(root)
+func findVideoFile(tree sourceTree, root string) (string, error) {
+	entries, err := tree.ReadDir(root)
 	if err != nil {
 		return "", err
 	}
@@ -46,7 +45,7 @@ func findVideoFile(root string) (string, error) {
 	if bestPath != "" {
 		return bestPath, nil
 	}
-	return findLargestVideoFile(root)
+	return findLargestVideoFile(tree, root)
 }
 
 // findVideoCandidates 在目录树中找出体积最大的若干视频文件，供 MediaInfo 依次重试。
@@ -94,16 +93,16 @@ func findVideoCandidates(root string, limit int) ([]string, error) {
 	return results, nil
 }
 
-// resolveScreenshotSourceFromRoot 从目录根路径推断截图流程应该使用的视频文件。
-func resolveScreenshotSourceFromRoot(root string) (string, error) {
-	if bdmvRoot, ok := resolveBDMVRoot(root); ok {
-		return findLargestM2TS(bdmvRoot)
+// resolveScreenshotSourceFromRoot 从 tree 中的目录根路径推断截图流程应该使用的视频文件。
+func resolveScreenshotSourceFromRoot(tree sourceTree, root string) (string, error) {
+	if bdmvRoot, ok := resolveBDMVRoot(tree, root); ok {
+		return findBlurayScreenshotClip(tree, bdmvRoot)
 	}
-	if dvdRoot, ok := resolveDVDVideoRoot(root); ok {
-		if titleVOB, err := findMainDVDTitleSetFirstVOB(dvdRoot); err == nil {
+	if dvdRoot, ok := resolveDVDVideoRoot(tree, root); ok {
+		if titleVOB, err := findMainDVDTitleSetFirstVOB(tree, dvdRoot); err == nil {
 			return titleVOB, nil
 		}
-		return findVideoFile(dvdRoot)
+		return findVideoFile(tree, dvdRoot)
 	}
-	return findVideoFile(root)
+	return findVideoFile(tree, root)
 }
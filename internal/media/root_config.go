@@ -0,0 +1,273 @@
+// Package media 提供带标签和权限标记的媒体根目录配置解析。
+
+package media
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"minfo/internal/config"
+)
+
+// Root 描述一个可浏览的媒体根目录，以及在其中允许执行的操作。
+// ReadOnly 表示只允许读取，优先于 AllowTorrent 和 AllowUpload；服务本身从不写入媒体根目录。
+type Root struct {
+	Label        string `json:"label"`
+	Path         string `json:"path"`
+	ReadOnly     bool   `json:"read_only"`
+	AllowTorrent bool   `json:"allow_torrent"`
+	AllowUpload  bool   `json:"allow_upload"`
+}
+
+// Permission 表示对媒体根目录中路径发起的操作类型。
+type Permission string
+
+const (
+	// PermissionRead 表示只读取媒体内容，例如 MediaInfo、BDInfo 和截图打包下载。
+	PermissionRead Permission = "read"
+	// PermissionTorrent 表示为路径制作种子。
+	PermissionTorrent Permission = "torrent"
+	// PermissionUpload 表示把由路径生成的截图上传到外部图床。
+	PermissionUpload Permission = "upload"
+)
+
+// rootFileEntry 是 MEDIA_ROOTS_FILE 中单个根目录的 JSON 结构；未填写的权限默认允许，read_only 为 true 时不开启制种和上传。
+type rootFileEntry struct {
+	Label        string `json:"label"`
+	Path         string `json:"path"`
+	ReadOnly     bool   `json:"read_only"`
+	AllowTorrent *bool  `json:"allow_torrent"`
+	AllowUpload  *bool  `json:"allow_upload"`
+}
+
+// Allows 会判断根目录是否允许执行指定操作。
+func (r Root) Allows(permission Permission) bool {
+	if r.ReadOnly && permission != PermissionRead {
+		return false
+	}
+	switch permission {
+	case PermissionTorrent:
+		return r.AllowTorrent
+	case PermissionUpload:
+		return r.AllowUpload
+	default:
+		return true
+	}
+}
+
+// rootCache 缓存首次解析得到的媒体根目录，避免每次请求都重新读取配置和挂载信息。
+var rootCache struct {
+	mu     sync.Mutex
+	loaded bool
+	roots  []Root
+}
+
+// ConfiguredRoots 返回当前生效的媒体根目录；配置只在首次调用时解析，之后返回缓存的副本，需要重新读取时调用 ReloadConfiguredRoots。
+func ConfiguredRoots() []Root {
+	rootCache.mu.Lock()
+	defer rootCache.mu.Unlock()
+
+	if !rootCache.loaded {
+		rootCache.roots = loadConfiguredRoots()
+		rootCache.loaded = true
+	}
+	return append([]Root(nil), rootCache.roots...)
+}
+
+// ReloadConfiguredRoots 会重新解析媒体根目录配置并替换缓存，返回新的根目录列表。
+func ReloadConfiguredRoots() []Root {
+	roots := loadConfiguredRoots()
+
+	rootCache.mu.Lock()
+	rootCache.roots = roots
+	rootCache.loaded = true
+	rootCache.mu.Unlock()
+	return append([]Root(nil), roots...)
+}
+
+// loadConfiguredRoots 解析媒体根目录配置；优先级依次为 MEDIA_ROOTS_FILE、MEDIA_ROOTS、挂载点自动探测和默认目录。
+func loadConfiguredRoots() []Root {
+	if config.MediaRootsFile != "" {
+		roots, err := loadRootsFile(config.MediaRootsFile)
+		if err == nil && len(roots) > 0 {
+			return roots
+		}
+		log.Printf("invalid MEDIA_ROOTS_FILE=%q: %v; fallback to MEDIA_ROOTS", config.MediaRootsFile, err)
+	}
+	if len(config.MediaRoots) > 0 {
+		if roots := parseRootSpecs(config.MediaRoots); len(roots) > 0 {
+			return roots
+		}
+	}
+	if paths := detectMountedRoots(); len(paths) > 0 {
+		return rootsFromPaths(paths)
+	}
+	return rootsFromPaths([]string{config.DefaultRoot})
+}
+
+// parseRootSpecs 解析形如 Label=/path:flag:flag 的根目录配置，标签和标记都可省略。
+//
+// 支持的标记为 ro、torrent 和 upload；未写任何标记时默认允许制种和上传，
+// 写了标记时只开启列出的权限；ro 表示只允许读取，即使同时写了 torrent 或 upload 也不会开启。
+func parseRootSpecs(specs []string) []Root {
+	roots := make([]Root, 0, len(specs))
+	for _, spec := range specs {
+		root, err := parseRootSpec(spec)
+		if err != nil {
+			log.Printf("invalid MEDIA_ROOTS entry %q: %v", spec, err)
+			continue
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// parseRootSpec 解析单个 MEDIA_ROOTS 条目。
+// 标记从末尾起逐段识别，遇到第一个不是标记的段即停止，其余部分（可以包含冒号）整体作为路径。
+func parseRootSpec(spec string) (Root, error) {
+	label := ""
+	value := strings.TrimSpace(spec)
+	if index := strings.Index(value, "="); index >= 0 {
+		label = strings.TrimSpace(value[:index])
+		value = strings.TrimSpace(value[index+1:])
+	}
+
+	parts := strings.Split(value, ":")
+	var flags []string
+	for len(parts) > 1 && isRootFlag(parts[len(parts)-1]) {
+		flags = append(flags, parts[len(parts)-1])
+		parts = parts[:len(parts)-1]
+	}
+
+	root := newRoot(label, strings.Join(parts, ":"))
+	if !filepath.IsAbs(root.Path) {
+		return Root{}, fmt.Errorf("path must be absolute")
+	}
+	if len(flags) == 0 {
+		return root, nil
+	}
+
+	root.AllowTorrent = false
+	root.AllowUpload = false
+	for _, flag := range flags {
+		switch strings.ToLower(strings.TrimSpace(flag)) {
+		case "ro", "read-only":
+			root.ReadOnly = true
+		case "torrent", "allow-torrent":
+			root.AllowTorrent = true
+		case "upload", "allow-upload":
+			root.AllowUpload = true
+		}
+	}
+	return applyReadOnly(root), nil
+}
+
+// isRootFlag 判断 MEDIA_ROOTS 条目中冒号分隔的一段是否是权限标记。
+func isRootFlag(part string) bool {
+	switch strings.ToLower(strings.TrimSpace(part)) {
+	case "ro", "read-only", "torrent", "allow-torrent", "upload", "allow-upload":
+		return true
+	default:
+		return false
+	}
+}
+
+// loadRootsFile 从 JSON 文件读取根目录列表；出现未知字段时返回错误，避免拼错的权限配置被静默忽略。
+func loadRootsFile(path string) ([]Root, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []rootFileEntry
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	roots := make([]Root, 0, len(entries))
+	for _, entry := range entries {
+		root := newRoot(entry.Label, entry.Path)
+		if !filepath.IsAbs(root.Path) {
+			return nil, fmt.Errorf("root path %q must be absolute", entry.Path)
+		}
+		if entry.AllowTorrent != nil {
+			root.AllowTorrent = *entry.AllowTorrent
+		}
+		if entry.AllowUpload != nil {
+			root.AllowUpload = *entry.AllowUpload
+		}
+		root.ReadOnly = entry.ReadOnly
+		roots = append(roots, applyReadOnly(root))
+	}
+	return roots, nil
+}
+
+// applyReadOnly 会在根目录只读时关闭制种和上传权限，让两种配置格式返回一致的权限。
+func applyReadOnly(root Root) Root {
+	if root.ReadOnly {
+		root.AllowTorrent = false
+		root.AllowUpload = false
+	}
+	return root
+}
+
+// ResolveConfiguredRoots 过滤无效或重复的根目录配置，并按路径排序返回；同一路径只保留第一条配置。
+func ResolveConfiguredRoots(roots []Root) ([]Root, error) {
+	paths, err := ResolveRoots(rootPaths(roots))
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]Root, len(roots))
+	for _, root := range roots {
+		if _, ok := byPath[root.Path]; !ok {
+			byPath[root.Path] = root
+		}
+	}
+	resolved := make([]Root, 0, len(paths))
+	for _, path := range paths {
+		resolved = append(resolved, byPath[path])
+	}
+	return resolved, nil
+}
+
+// rootsFromPaths 把纯路径列表转换成默认权限的根目录配置。
+func rootsFromPaths(paths []string) []Root {
+	roots := make([]Root, 0, len(paths))
+	for _, path := range paths {
+		roots = append(roots, newRoot("", path))
+	}
+	return roots
+}
+
+// newRoot 会构造一个默认允许所有操作的根目录；未指定标签时使用目录名。
+func newRoot(label, path string) Root {
+	path = filepath.Clean(strings.TrimSpace(path))
+	label = strings.TrimSpace(label)
+	if label == "" {
+		label = filepath.Base(path)
+	}
+	return Root{
+		Label:        label,
+		Path:         path,
+		AllowTorrent: true,
+		AllowUpload:  true,
+	}
+}
+
+// rootPaths 返回根目录配置中的路径列表。
+func rootPaths(roots []Root) []string {
+	paths := make([]string, 0, len(roots))
+	for _, root := range roots {
+		paths = append(paths, root.Path)
+	}
+	return paths
+}
@@ -0,0 +1,134 @@
+// Package media 验证媒体根目录配置解析与权限校验。
+
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"minfo/internal/config"
+)
+
+// TestParseRootSpec 验证 MEDIA_ROOTS 条目的标签、路径和权限标记解析。
+func TestParseRootSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Root
+	}{
+		{"/mnt/movies", Root{Label: "movies", Path: "/mnt/movies", AllowTorrent: true, AllowUpload: true}},
+		{"Movies=/mnt/movies/", Root{Label: "Movies", Path: "/mnt/movies", AllowTorrent: true, AllowUpload: true}},
+		{"TV=/mnt/tv:ro", Root{Label: "TV", Path: "/mnt/tv", ReadOnly: true}},
+		{"Remux=/mnt/remux:ro:torrent:upload", Root{Label: "Remux", Path: "/mnt/remux", ReadOnly: true}},
+		{"Remux=/mnt/remux:torrent:upload", Root{Label: "Remux", Path: "/mnt/remux", AllowTorrent: true, AllowUpload: true}},
+		{"Shows=/mnt/a:b/shows:torrent", Root{Label: "Shows", Path: "/mnt/a:b/shows", AllowTorrent: true}},
+		{"/mnt/c:d", Root{Label: "c:d", Path: "/mnt/c:d", AllowTorrent: true, AllowUpload: true}},
+		{"Web=/mnt/web:allow-upload", Root{Label: "Web", Path: "/mnt/web", AllowUpload: true}},
+	}
+
+	for _, test := range tests {
+		got, err := parseRootSpec(test.spec)
+		if err != nil {
+			t.Fatalf("parseRootSpec(%q) error = %v", test.spec, err)
+		}
+		if got != test.want {
+			t.Fatalf("parseRootSpec(%q) = %#v, want %#v", test.spec, got, test.want)
+		}
+	}
+
+	for _, spec := range []string{"Movies=relative/path", "relative:torrent"} {
+		if _, err := parseRootSpec(spec); err == nil {
+			t.Fatalf("parseRootSpec(%q) error = nil, want error", spec)
+		}
+	}
+}
+
+// TestLoadRootsFileDefaultsMissingPermissionsToAllowed 验证配置文件中未填写的制种和上传权限默认允许，read_only 会关闭两者。
+func TestLoadRootsFileDefaultsMissingPermissionsToAllowed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "roots.json")
+	content := `[
+		{"label": "Movies", "path": "/mnt/movies", "read_only": true, "allow_torrent": true},
+		{"label": "TV", "path": "/mnt/tv", "allow_torrent": false},
+		{"label": "Web", "path": "/mnt/web"}
+	]`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	roots, err := loadRootsFile(path)
+	if err != nil {
+		t.Fatalf("loadRootsFile() error = %v", err)
+	}
+	want := []Root{
+		{Label: "Movies", Path: "/mnt/movies", ReadOnly: true},
+		{Label: "TV", Path: "/mnt/tv", AllowUpload: true},
+		{Label: "Web", Path: "/mnt/web", AllowTorrent: true, AllowUpload: true},
+	}
+	if len(roots) != len(want) || roots[0] != want[0] || roots[1] != want[1] || roots[2] != want[2] {
+		t.Fatalf("loadRootsFile() = %#v, want %#v", roots, want)
+	}
+	if roots[0].Allows(PermissionTorrent) || roots[0].Allows(PermissionUpload) || !roots[0].Allows(PermissionRead) {
+		t.Fatalf("read-only root %#v allows torrent or upload", roots[0])
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"label": "TV", "path": "/mnt/tv", "readonly": true}]`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+	if _, err := loadRootsFile(path); err == nil {
+		t.Fatal("loadRootsFile() with unknown field error = nil, want error")
+	}
+}
+
+// TestResolveInputPathWithinEnforcesRootPermissions 验证路径会按最深的所属根目录检查制种和上传权限。
+func TestResolveInputPathWithinEnforcesRootPermissions(t *testing.T) {
+	base := t.TempDir()
+	remux := filepath.Join(base, "remux")
+	if err := os.MkdirAll(remux, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error: %v", err)
+	}
+	movie := filepath.Join(remux, "movie.mkv")
+	if err := os.WriteFile(movie, []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+
+	roots := []Root{
+		newRoot("All", base),
+		{Label: "Remux", Path: remux, AllowUpload: true},
+	}
+	ctx := context.Background()
+	if _, _, err := resolveInputPathWithin(ctx, movie, roots, PermissionRead); err != nil {
+		t.Fatalf("read error = %v, want nil", err)
+	}
+	if _, _, err := resolveInputPathWithin(ctx, movie, roots, PermissionUpload); err != nil {
+		t.Fatalf("upload error = %v, want nil", err)
+	}
+	if _, _, err := resolveInputPathWithin(ctx, movie, roots, PermissionTorrent); !errors.Is(err, ErrPathNotAllowed) {
+		t.Fatalf("torrent error = %v, want ErrPathNotAllowed", err)
+	}
+}
+
+// TestConfiguredRootsCachesUntilReload 验证根目录配置只解析一次，调用 ReloadConfiguredRoots 后才会读取新配置。
+func TestConfiguredRootsCachesUntilReload(t *testing.T) {
+	previousRoots, previousFile := config.MediaRoots, config.MediaRootsFile
+	t.Cleanup(func() {
+		config.MediaRoots, config.MediaRootsFile = previousRoots, previousFile
+		ReloadConfiguredRoots()
+	})
+
+	config.MediaRootsFile = ""
+	config.MediaRoots = []string{"Movies=/mnt/movies"}
+	ReloadConfiguredRoots()
+
+	config.MediaRoots = []string{"TV=/mnt/tv"}
+	if roots := ConfiguredRoots(); len(roots) != 1 || roots[0].Label != "Movies" {
+		t.Fatalf("ConfiguredRoots() = %#v, want cached Movies root", roots)
+	}
+	if roots := ReloadConfiguredRoots(); len(roots) != 1 || roots[0].Label != "TV" {
+		t.Fatalf("ReloadConfiguredRoots() = %#v, want TV root", roots)
+	}
+	if roots := ConfiguredRoots(); len(roots) != 1 || roots[0].Label != "TV" {
+		t.Fatalf("ConfiguredRoots() after reload = %#v, want TV root", roots)
+	}
+}
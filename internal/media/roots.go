@@ -11,12 +11,9 @@ import (
 	"minfo/internal/config"
 )
 
-// MediaRoots 返回当前服务用于路径浏览的根目录列表；优先使用自动探测到的挂载点。
+// MediaRoots 返回当前服务用于路径浏览和输入校验的根目录路径列表，来源见 ConfiguredRoots。
 func MediaRoots() []string {
-	if roots := detectMountedRoots(); len(roots) > 0 {
-		return roots
-	}
-	return []string{config.DefaultRoot}
+	return rootPaths(ConfiguredRoots())
 }
 
 // detectMountedRoots 从 /proc/self/mountinfo 中筛出可作为媒体根目录的顶层挂载点。
@@ -98,6 +95,9 @@ func detectMountedRoots() []string {
 			continue
 		}
 		clean := filepath.Clean(mountPoint)
+		if clean == filepath.Clean(config.DataDir) {
+			continue
+		}
 		if _, ok := seen[clean]; ok {
 			continue
 		}
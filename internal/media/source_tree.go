@@ -0,0 +1,69 @@
+// Package media 提供在本地目录和 ISO 镜像内部统一查找媒体源的目录树抽象。
+
+package media
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"minfo/internal/media/isofs"
+)
+
+// sourceTree 抽象了源选择逻辑需要的目录操作，使同一套规则既能作用于本地目录，也能直接作用于未挂载的 ISO。
+type sourceTree interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
+	WalkDir(root string, fn fs.WalkDirFunc) error
+}
+
+// osTree 直接访问本地文件系统。
+type osTree struct{}
+
+func (osTree) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osTree) ReadDir(name string) ([]fs.DirEntry, error)   { return os.ReadDir(name) }
+func (osTree) ReadFile(name string) ([]byte, error)         { return os.ReadFile(name) }
+func (osTree) WalkDir(root string, fn fs.WalkDirFunc) error { return filepath.WalkDir(root, fn) }
+
+// isoTree 访问 ISO 镜像内部的目录树，路径使用以 / 开头的镜像内路径，例如 /BDMV/STREAM。
+type isoTree struct {
+	image *isofs.Image
+}
+
+func (t isoTree) Stat(name string) (fs.FileInfo, error) {
+	return t.image.Stat(isoRelativePath(name))
+}
+
+func (t isoTree) ReadDir(name string) ([]fs.DirEntry, error) {
+	return t.image.ReadDir(isoRelativePath(name))
+}
+
+func (t isoTree) ReadFile(name string) ([]byte, error) {
+	return t.image.ReadFile(isoRelativePath(name))
+}
+
+func (t isoTree) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return fs.WalkDir(t.image, isoRelativePath(root), func(name string, d fs.DirEntry, err error) error {
+		return fn(isoAbsolutePath(name), d, err)
+	})
+}
+
+// isoRelativePath 把镜像内的绝对路径转换成 fs.FS 使用的相对路径。
+func isoRelativePath(inner string) string {
+	cleaned := path.Clean("/" + strings.TrimPrefix(filepath.ToSlash(inner), "/"))
+	if cleaned == "/" {
+		return "."
+	}
+	return strings.TrimPrefix(cleaned, "/")
+}
+
+// isoAbsolutePath 把 fs.FS 相对路径转换回以 / 开头的镜像内路径。
+func isoAbsolutePath(name string) string {
+	if name == "." || name == "" {
+		return "/"
+	}
+	return "/" + name
+}
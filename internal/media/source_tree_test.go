@@ -0,0 +1,195 @@
+// Package media 验证不挂载 ISO 时的镜像内浏览和源选择逻辑。
+
+package media
+
+import (
+	"encoding/binary"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"minfo/internal/media/isofs"
+)
+
+// TestSuggestVirtualISOPathsReadsImageWithoutMount 验证 ISO 虚拟路径联想直接读取镜像目录和 MPLS 时长。
+func TestSuggestVirtualISOPathsReadsImageWithoutMount(t *testing.T) {
+	root := t.TempDir()
+	isoPath := filepath.Join(root, "disc.iso")
+	writeTestISOImage(t, isoPath, map[string][]byte{
+		"BDMV/PLAYLIST/00800.MPLS": buildTestMPLSFile([][2]uint32{{0, 90 * mplsClockRate}}),
+		"BDMV/PLAYLIST/00001.MPLS": buildTestMPLSFile([][2]uint32{{0, 5 * mplsClockRate}}),
+		"BDMV/STREAM/00001.M2TS":   make([]byte, 10),
+	})
+
+	items, selectedRoot, err := SuggestPaths([]string{root}, "ISO:"+isoPath+"!/BDMV/PLAYLIST/", 0)
+	if err != nil {
+		t.Fatalf("SuggestPaths() error: %v", err)
+	}
+	if selectedRoot != root {
+		t.Fatalf("SuggestPaths() root = %q, want %q", selectedRoot, root)
+	}
+	if len(items) != 2 {
+		t.Fatalf("SuggestPaths() returned %d items, want 2: %+v", len(items), items)
+	}
+	want := SuggestedPath{
+		Path:     "ISO:" + isoPath + "!/BDMV/PLAYLIST/00800.MPLS",
+		Size:     int64(len(buildTestMPLSFile([][2]uint32{{0, 90 * mplsClockRate}}))),
+		Duration: "0:01:30",
+	}
+	if items[1] != want {
+		t.Fatalf("SuggestPaths()[1] = %+v, want %+v", items[1], want)
+	}
+
+	items, _, err = SuggestPaths([]string{root}, "ISO:"+isoPath+"!/bd", 0)
+	if err != nil {
+		t.Fatalf("SuggestPaths(partial) error: %v", err)
+	}
+	if len(items) != 1 || items[0].Path != "ISO:"+isoPath+"!/BDMV/" || !items[0].IsDir {
+		t.Fatalf("SuggestPaths(partial) = %+v, want BDMV directory", items)
+	}
+}
+
+// TestResolveScreenshotSourceFromISOTree 验证截图源选择规则可以直接在镜像目录树上执行。
+func TestResolveScreenshotSourceFromISOTree(t *testing.T) {
+	isoPath := filepath.Join(t.TempDir(), "disc.iso")
+	writeTestISOImage(t, isoPath, map[string][]byte{
+		"BDMV/STREAM/00001.M2TS": make([]byte, 10),
+		"BDMV/STREAM/00800.M2TS": make([]byte, 3000),
+		"BDMV/index.bdmv":        []byte("INDX"),
+	})
+
+	image, err := isofs.Open(isoPath)
+	if err != nil {
+		t.Fatalf("isofs.Open() error: %v", err)
+	}
+	defer image.Close()
+
+	tree := isoTree{image: image}
+	source, err := resolveScreenshotSourceFromRoot(tree, "/")
+	if err != nil {
+		t.Fatalf("resolveScreenshotSourceFromRoot() error: %v", err)
+	}
+	if source != "/BDMV/STREAM/00800.M2TS" {
+		t.Fatalf("resolveScreenshotSourceFromRoot() = %q, want %q", source, "/BDMV/STREAM/00800.M2TS")
+	}
+	if root, ok := resolveBDInfoRoot(tree, "/"); !ok || root != "/" {
+		t.Fatalf("resolveBDInfoRoot() = %q, %v; want %q, true", root, ok, "/")
+	}
+}
+
+// writeTestISOImage 把 files 写成一个最小 ISO9660 镜像；目录按路径自动生成，每个文件占用连续扇区。
+func writeTestISOImage(t *testing.T, isoPath string, files map[string][]byte) {
+	t.Helper()
+
+	const sectorSize = 2048
+	type dirNode struct {
+		sector   uint32
+		children []string
+	}
+
+	dirs := map[string]*dirNode{"": {}}
+	var ensureDir func(dir string)
+	ensureDir = func(dir string) {
+		if _, ok := dirs[dir]; ok {
+			return
+		}
+		parent := path.Dir(dir)
+		if parent == "." {
+			parent = ""
+		}
+		ensureDir(parent)
+		dirs[dir] = &dirNode{}
+		dirs[parent].children = append(dirs[parent].children, dir)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		dir := path.Dir(name)
+		if dir == "." {
+			dir = ""
+		}
+		ensureDir(dir)
+		dirs[dir].children = append(dirs[dir].children, name)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dirNames := make([]string, 0, len(dirs))
+	for name := range dirs {
+		dirNames = append(dirNames, name)
+	}
+	sort.Strings(dirNames)
+
+	next := uint32(18)
+	for _, name := range dirNames {
+		dirs[name].sector = next
+		next++
+	}
+	fileSectors := make(map[string]uint32, len(files))
+	for _, name := range names {
+		fileSectors[name] = next
+		next += uint32((len(files[name]) + sectorSize - 1) / sectorSize)
+		if len(files[name]) == 0 {
+			next++
+		}
+	}
+
+	data := make([]byte, int(next)*sectorSize)
+	record := func(name string, sector, size uint32, dir bool) []byte {
+		length := 33 + len(name)
+		length += length % 2
+		out := make([]byte, length)
+		out[0] = byte(length)
+		binary.LittleEndian.PutUint32(out[2:6], sector)
+		binary.BigEndian.PutUint32(out[6:10], sector)
+		binary.LittleEndian.PutUint32(out[10:14], size)
+		binary.BigEndian.PutUint32(out[14:18], size)
+		if dir {
+			out[25] = 0x02
+		}
+		out[32] = byte(len(name))
+		copy(out[33:], name)
+		return out
+	}
+
+	primary := data[16*sectorSize:]
+	primary[0] = 1
+	copy(primary[1:6], "CD001")
+	binary.LittleEndian.PutUint16(primary[128:130], sectorSize)
+	copy(primary[156:190], record("\x00", dirs[""].sector, sectorSize, true))
+	terminator := data[17*sectorSize:]
+	terminator[0] = 255
+	copy(terminator[1:6], "CD001")
+
+	for _, name := range dirNames {
+		node := dirs[name]
+		parent := path.Dir(name)
+		if parent == "." {
+			parent = ""
+		}
+		if name == "" {
+			parent = ""
+		}
+		pos := int(node.sector) * sectorSize
+		pos += copy(data[pos:], record("\x00", node.sector, sectorSize, true))
+		pos += copy(data[pos:], record("\x01", dirs[parent].sector, sectorSize, true))
+		sort.Strings(node.children)
+		for _, child := range node.children {
+			base := strings.ToUpper(path.Base(child))
+			if sub, ok := dirs[child]; ok {
+				pos += copy(data[pos:], record(base, sub.sector, sectorSize, true))
+				continue
+			}
+			pos += copy(data[pos:], record(base+";1", fileSectors[child], uint32(len(files[child])), false))
+		}
+	}
+	for _, name := range names {
+		copy(data[int(fileSectors[name])*sectorSize:], files[name])
+	}
+
+	if err := os.WriteFile(isoPath, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error: %v", err)
+	}
+}
@@ -9,26 +9,79 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+
+	"minfo/internal/media/isofs"
 )
 
 const virtualISOPrefix = "ISO:"
 
-// ResolveInputPath 解析用户输入的媒体路径；它支持普通路径和 ISO 虚拟路径。
+// ResolveInputPath 解析用户输入的媒体路径；它支持普通路径和 ISO 虚拟路径，并要求路径位于媒体根目录之内。
 func ResolveInputPath(ctx context.Context, input string) (string, func(), error) {
+	return ResolveInputPathFor(ctx, input, PermissionRead)
+}
+
+// ResolveInputPathFor 与 ResolveInputPath 相同，但还要求路径所在的根目录允许 permission 对应的操作。
+func ResolveInputPathFor(ctx context.Context, input string, permission Permission) (string, func(), error) {
+	return resolveInputPathWithin(ctx, input, ConfiguredRoots(), permission)
+}
+
+// ResolveLocalInputPath 与 ResolveInputPath 相同，但不限制媒体根目录，供命令行在本机直接处理任意可读路径。
+// 相对路径（包括虚拟 ISO 路径中的 ISO 文件部分）会先按当前工作目录转换为绝对路径。
+func ResolveLocalInputPath(ctx context.Context, input string) (string, func(), error) {
+	cleaned := strings.TrimSpace(strings.Trim(input, "\""))
+	if isoPath, inner, ok := parseVirtualISOPath(cleaned); ok {
+		if absolute, err := filepath.Abs(isoPath); err == nil {
+			cleaned = virtualISOPrefix + absolute + "!" + inner
+		}
+	} else if cleaned != "" {
+		if absolute, err := filepath.Abs(cleaned); err == nil {
+			cleaned = absolute
+		}
+	}
+	return resolveInputPathWithin(ctx, cleaned, []Root{newRoot("local", string(filepath.Separator))}, PermissionRead)
+}
+
+// CheckInputPathFor 会确认用户输入路径存在，且所在根目录允许 permission 对应的操作；ISO 虚拟路径只读取镜像目录确认内部路径，不会挂载或共享镜像。
+// 后台任务在提交时用它校验输入，等调度器开始执行时再调用 ResolveInputPathFor 打开镜像。
+func CheckInputPathFor(input string, permission Permission) error {
+	_, err := checkInputPathWithin(input, ConfiguredRoots(), permission)
+	return err
+}
+
+// resolveInputPathWithin 按给定的媒体根目录白名单和操作权限解析用户输入路径。
+func resolveInputPathWithin(ctx context.Context, input string, roots []Root, permission Permission) (string, func(), error) {
+	cleaned, err := checkInputPathWithin(input, roots, permission)
+	if err != nil {
+		return "", func() {}, err
+	}
+	if isoPath, inner, ok := parseVirtualISOPath(cleaned); ok {
+		return openISOTarget(ctx, isoPath, inner)
+	}
+	return cleaned, func() {}, nil
+}
+
+// checkInputPathWithin 按给定的媒体根目录白名单和操作权限校验用户输入路径是否存在，并返回清理后的路径。
+func checkInputPathWithin(input string, roots []Root, permission Permission) (string, error) {
 	cleaned := strings.TrimSpace(strings.Trim(input, "\""))
 	if cleaned == "" {
-		return "", func() {}, fmt.Errorf("missing path")
+		return "", fmt.Errorf("missing path")
 	}
 
 	if isVirtualISOPath(cleaned) {
-		return resolveVirtualISOPath(ctx, cleaned)
+		if err := checkVirtualISOPath(cleaned, roots, permission); err != nil {
+			return "", err
+		}
+		return cleaned, nil
 	}
 
 	cleaned = filepath.Clean(cleaned)
+	if err := checkRootPermission(cleaned, roots, permission); err != nil {
+		return "", err
+	}
 	if _, err := os.Stat(cleaned); err != nil {
-		return "", func() {}, fmt.Errorf("path not found: %v", err)
+		return "", fmt.Errorf("path not found: %v", err)
 	}
-	return cleaned, func() {}, nil
+	return cleaned, nil
 }
 
 // isVirtualISOPath 会判断虚拟 ISO 路径是否满足当前条件。
@@ -109,34 +162,25 @@ func buildVirtualISOPath(isoPath, inner string, isDir bool) string {
 	return result
 }
 
-// resolveVirtualISOPath 挂载 ISO 并把虚拟路径解析成容器内可访问的实际路径。
-func resolveVirtualISOPath(ctx context.Context, input string) (string, func(), error) {
+// checkVirtualISOPath 校验 ISO 文件位于媒体根目录内，并通过纯 Go 读取镜像目录确认内部路径存在；它不会挂载 ISO 或启动 HTTP 共享。
+func checkVirtualISOPath(input string, roots []Root, permission Permission) error {
 	isoPath, inner, ok := parseVirtualISOPath(input)
 	if !ok {
-		return "", func() {}, fmt.Errorf("invalid ISO browser path")
+		return fmt.Errorf("invalid ISO browser path")
 	}
-	if _, err := os.Stat(isoPath); err != nil {
-		return "", func() {}, fmt.Errorf("path not found: %v", err)
+	if err := checkRootPermission(isoPath, roots, permission); err != nil {
+		return err
 	}
-
-	mountDir, cleanup, err := mountISO(ctx, isoPath)
-	if err != nil {
-		return "", func() {}, err
+	if _, err := os.Stat(isoPath); err != nil {
+		return fmt.Errorf("path not found: %v", err)
 	}
 
-	target := mountDir
-	if inner != "/" {
-		target = filepath.Join(mountDir, filepath.FromSlash(strings.TrimPrefix(inner, "/")))
-	}
-	target = filepath.Clean(target)
-	if !isSubpath(mountDir, target) {
-		cleanup()
-		return "", func() {}, fmt.Errorf("path is outside mounted ISO")
-	}
-	if _, err := os.Stat(target); err != nil {
-		cleanup()
-		return "", func() {}, fmt.Errorf("path not found: %v", err)
+	if image, err := isofs.Open(isoPath); err == nil {
+		_, statErr := image.Stat(isoRelativePath(inner))
+		image.Close()
+		if statErr != nil {
+			return fmt.Errorf("path not found: %v", statErr)
+		}
 	}
-
-	return target, cleanup, nil
+	return nil
 }
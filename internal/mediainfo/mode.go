@@ -0,0 +1,41 @@
+// Package mediainfo 提供 MediaInfo 输出模式和 JSON 结果的结构化解析。
+
+package mediainfo
+
+import "strings"
+
+// 输出模式常量对应 mediainfo 的 --Output 参数。
+const (
+	ModeText = "text"
+	ModeJSON = "json"
+	ModeXML  = "xml"
+	ModeHTML = "html"
+)
+
+// NormalizeMode 规范化输出模式；空值和不支持的值都按 text 处理。
+func NormalizeMode(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case ModeJSON:
+		return ModeJSON
+	case ModeXML:
+		return ModeXML
+	case ModeHTML:
+		return ModeHTML
+	default:
+		return ModeText
+	}
+}
+
+// OutputArgs 返回生成指定输出模式时需要追加给 mediainfo 的参数；text 模式使用默认输出，不追加参数。
+func OutputArgs(mode string) []string {
+	switch NormalizeMode(mode) {
+	case ModeJSON:
+		return []string{"--Output=JSON"}
+	case ModeXML:
+		return []string{"--Output=XML"}
+	case ModeHTML:
+		return []string{"--Output=HTML"}
+	default:
+		return nil
+	}
+}
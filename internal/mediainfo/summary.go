@@ -0,0 +1,293 @@
+// Package mediainfo 从 mediainfo --Output=JSON 的结果中提取规范化的媒体摘要。
+
+package mediainfo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrNoTracks 表示 JSON 中没有任何可识别的轨道。
+var ErrNoTracks = errors.New("mediainfo JSON contains no tracks")
+
+// Summary 是从 MediaInfo JSON 提取的规范化摘要，供客户端直接渲染和校验，无需解析文本报告。
+type Summary struct {
+	// Container 是容器格式，例如 Matroska、MPEG-4 或 BDAV。
+	Container       string  `json:"container,omitempty"`
+	FileSize        int64   `json:"file_size,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	// Duration 是 h:mm:ss 格式的时长。
+	Duration string `json:"duration,omitempty"`
+	// OverallBitrate 是整体码率，单位 bps。
+	OverallBitrate    int64           `json:"overall_bitrate,omitempty"`
+	Video             []VideoTrack    `json:"video,omitempty"`
+	Audio             []AudioTrack    `json:"audio,omitempty"`
+	Subtitles         []SubtitleTrack `json:"subtitles,omitempty"`
+	AudioLanguages    []string        `json:"audio_languages,omitempty"`
+	SubtitleLanguages []string        `json:"subtitle_languages,omitempty"`
+}
+
+// VideoTrack 表示一条视频轨的规范化信息。
+type VideoTrack struct {
+	ID      string `json:"id,omitempty"`
+	Codec   string `json:"codec,omitempty"`
+	Profile string `json:"profile,omitempty"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	// Resolution 是按画面尺寸和扫描方式归纳的分辨率标签，例如 2160p、1080i。
+	Resolution string  `json:"resolution,omitempty"`
+	FrameRate  float64 `json:"frame_rate,omitempty"`
+	BitDepth   int     `json:"bit_depth,omitempty"`
+	Bitrate    int64   `json:"bitrate,omitempty"`
+	// HDRFormat 是归纳后的 HDR 格式，例如 Dolby Vision / HDR10；SDR 视频为空。
+	HDRFormat string `json:"hdr_format,omitempty"`
+	Language  string `json:"language,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Default   bool   `json:"default,omitempty"`
+}
+
+// AudioTrack 表示一条音轨的规范化信息。
+type AudioTrack struct {
+	ID    string `json:"id,omitempty"`
+	Codec string `json:"codec,omitempty"`
+	// CommercialName 是 mediainfo 给出的商业名称，例如 Dolby TrueHD with Dolby Atmos。
+	CommercialName string `json:"commercial_name,omitempty"`
+	Channels       int    `json:"channels,omitempty"`
+	ChannelLayout  string `json:"channel_layout,omitempty"`
+	SamplingRate   int    `json:"sampling_rate,omitempty"`
+	Bitrate        int64  `json:"bitrate,omitempty"`
+	Language       string `json:"language,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Default        bool   `json:"default,omitempty"`
+}
+
+// SubtitleTrack 表示一条字幕轨的规范化信息。
+type SubtitleTrack struct {
+	ID       string `json:"id,omitempty"`
+	Codec    string `json:"codec,omitempty"`
+	Language string `json:"language,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Default  bool   `json:"default,omitempty"`
+	Forced   bool   `json:"forced,omitempty"`
+}
+
+// payload 描述 mediainfo JSON 中单个 media 节点的原始结构。
+type payload struct {
+	Media *struct {
+		Track []map[string]any `json:"track"`
+	} `json:"media"`
+}
+
+// ParseSummary 解析 mediainfo --Output=JSON 的输出；输入包含多个文件时只取第一个带轨道的文件。
+func ParseSummary(data []byte) (*Summary, error) {
+	payloads, err := decodePayloads(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range payloads {
+		if item.Media == nil || len(item.Media.Track) == 0 {
+			continue
+		}
+		summary := &Summary{}
+		for _, track := range item.Media.Track {
+			summary.addTrack(track)
+		}
+		return summary, nil
+	}
+	return nil, ErrNoTracks
+}
+
+// decodePayloads 兼容单文件输出的对象和多文件输出的数组两种 JSON 形态。
+func decodePayloads(data []byte) ([]payload, error) {
+	var single payload
+	if err := json.Unmarshal(data, &single); err == nil {
+		return []payload{single}, nil
+	}
+
+	var multiple []payload
+	if err := json.Unmarshal(data, &multiple); err == nil {
+		return multiple, nil
+	}
+	return nil, errors.New("unsupported mediainfo JSON shape")
+}
+
+// addTrack 按 @type 把单条轨道合并进摘要。
+func (s *Summary) addTrack(track map[string]any) {
+	switch strings.ToLower(field(track, "@type")) {
+	case "general":
+		s.Container = field(track, "Format")
+		s.FileSize = intField(track, "FileSize")
+		s.DurationSeconds = floatField(track, "Duration")
+		s.Duration = formatDuration(s.DurationSeconds)
+		s.OverallBitrate = intField(track, "OverallBitRate")
+	case "video":
+		video := VideoTrack{
+			ID:        field(track, "ID"),
+			Codec:     field(track, "Format"),
+			Profile:   field(track, "Format_Profile"),
+			Width:     int(intField(track, "Width")),
+			Height:    int(intField(track, "Height")),
+			FrameRate: floatField(track, "FrameRate"),
+			BitDepth:  int(intField(track, "BitDepth")),
+			Bitrate:   intField(track, "BitRate"),
+			HDRFormat: hdrFormat(field(track, "HDR_Format"), field(track, "HDR_Format_Compatibility"), field(track, "transfer_characteristics")),
+			Language:  language(field(track, "Language")),
+			Title:     field(track, "Title"),
+			Default:   boolField(track, "Default"),
+		}
+		video.Resolution = resolutionLabel(video.Width, video.Height, field(track, "ScanType"))
+		s.Video = append(s.Video, video)
+	case "audio":
+		audio := AudioTrack{
+			ID:             field(track, "ID"),
+			Codec:          field(track, "Format"),
+			CommercialName: field(track, "Format_Commercial_IfAny"),
+			Channels:       int(intField(track, "Channels")),
+			ChannelLayout:  field(track, "ChannelLayout"),
+			SamplingRate:   int(intField(track, "SamplingRate")),
+			Bitrate:        intField(track, "BitRate"),
+			Language:       language(field(track, "Language")),
+			Title:          field(track, "Title"),
+			Default:        boolField(track, "Default"),
+		}
+		s.Audio = append(s.Audio, audio)
+		s.AudioLanguages = appendUnique(s.AudioLanguages, audio.Language)
+	case "text":
+		subtitle := SubtitleTrack{
+			ID:       field(track, "ID"),
+			Codec:    field(track, "Format"),
+			Language: language(field(track, "Language")),
+			Title:    field(track, "Title"),
+			Default:  boolField(track, "Default"),
+			Forced:   boolField(track, "Forced"),
+		}
+		s.Subtitles = append(s.Subtitles, subtitle)
+		s.SubtitleLanguages = appendUnique(s.SubtitleLanguages, subtitle.Language)
+	}
+}
+
+// hdrFormat 根据 HDR_Format、兼容性和传输特性归纳出 Dolby Vision、HDR10+、HDR10、HLG 等标签，多个标签用 " / " 连接。
+func hdrFormat(format, compatibility, transfer string) string {
+	combined := strings.ToLower(format + " / " + compatibility)
+	labels := make([]string, 0, 3)
+	if strings.Contains(combined, "dolby vision") {
+		labels = append(labels, "Dolby Vision")
+	}
+	if strings.Contains(combined, "hdr10+") || strings.Contains(combined, "st 2094 app 4") {
+		labels = append(labels, "HDR10+")
+	}
+	if strings.Contains(strings.ReplaceAll(combined, "hdr10+", ""), "hdr10") || strings.Contains(combined, "st 2086") {
+		labels = append(labels, "HDR10")
+	}
+
+	transfer = strings.ToLower(transfer)
+	switch {
+	case strings.Contains(transfer, "hlg") || strings.Contains(transfer, "arib std-b67"):
+		labels = append(labels, "HLG")
+	case len(labels) == 0 && (strings.Contains(transfer, "pq") || strings.Contains(transfer, "st 2084")):
+		labels = append(labels, "PQ")
+	}
+	return strings.Join(labels, " / ")
+}
+
+// resolutionLabel 按常见规格把画面尺寸归纳为 2160p、1080p、720p 等标签，宽银幕裁切的画面按宽度判断。
+func resolutionLabel(width, height int, scanType string) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	lines := height
+	switch {
+	case width >= 3800 || height >= 2100:
+		lines = 2160
+	case width >= 1900 || height >= 1000:
+		lines = 1080
+	case width >= 1260 || height >= 700:
+		lines = 720
+	}
+
+	suffix := "p"
+	switch strings.ToLower(strings.TrimSpace(scanType)) {
+	case "interlaced", "mbaff", "paff":
+		suffix = "i"
+	}
+	return strconv.Itoa(lines) + suffix
+}
+
+// formatDuration 把秒数格式化为 h:mm:ss。
+func formatDuration(seconds float64) string {
+	if seconds <= 0 {
+		return ""
+	}
+	total := int64(math.Round(seconds))
+	return fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// language 规范化语言代码，未标注的语言返回空字符串。
+func language(raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch value {
+	case "", "und", "undefined", "unknown", "n/a":
+		return ""
+	}
+	return value
+}
+
+// appendUnique 在 value 非空且尚未出现时把它追加到 values。
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// field 返回轨道字段的字符串值，缺失时返回空字符串。
+func field(track map[string]any, key string) string {
+	switch value := track[key].(type) {
+	case string:
+		return strings.TrimSpace(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		return ""
+	}
+}
+
+// intField 把轨道字段解析为整数；mediainfo 可能输出 "1920" 或 "5.1" 这类值，小数部分会被截断。
+func intField(track map[string]any, key string) int64 {
+	return int64(floatField(track, key))
+}
+
+// floatField 把轨道字段解析为浮点数；多值字段（例如 "6 / 8"）只取第一个值。
+func floatField(track map[string]any, key string) float64 {
+	raw := field(track, key)
+	if index := strings.Index(raw, "/"); index >= 0 {
+		raw = strings.TrimSpace(raw[:index])
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
+
+// boolField 会判断 Yes/No 形式的轨道字段是否为真。
+func boolField(track map[string]any, key string) bool {
+	switch strings.ToLower(field(track, key)) {
+	case "yes", "true", "1":
+		return true
+	default:
+		return false
+	}
+}
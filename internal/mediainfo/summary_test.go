@@ -0,0 +1,87 @@
+// Package mediainfo 验证 MediaInfo JSON 摘要解析和输出模式。
+
+package mediainfo
+
+import "testing"
+
+const testJSON = `{
+  "creatingLibrary": {"name": "MediaInfoLib", "version": "24.01"},
+  "media": {
+    "@ref": "/media/movie.mkv",
+    "track": [
+      {"@type": "General", "Format": "Matroska", "FileSize": "53687091200", "Duration": "7699.482", "OverallBitRate": "55780000"},
+      {"@type": "Video", "ID": "1", "Format": "HEVC", "Format_Profile": "Main 10", "Width": "3840", "Height": "1608", "FrameRate": "23.976", "BitDepth": "10", "BitRate": "48000000",
+       "HDR_Format": "Dolby Vision / SMPTE ST 2086", "HDR_Format_Compatibility": "HDR10 / HDR10", "transfer_characteristics": "PQ", "ScanType": "Progressive", "Default": "Yes"},
+      {"@type": "Audio", "ID": "2", "Format": "MLP FBA", "Format_Commercial_IfAny": "Dolby TrueHD with Dolby Atmos", "Channels": "8", "ChannelLayout": "L R C LFE Ls Rs Lb Rb", "SamplingRate": "48000", "BitRate": "4500000", "Language": "en", "Default": "Yes"},
+      {"@type": "Audio", "ID": "3", "Format": "AC-3", "Channels": "6", "SamplingRate": "48000", "Language": "EN"},
+      {"@type": "Text", "ID": "4", "Format": "PGS", "Language": "zh-CN", "Title": "简体", "Forced": "No"},
+      {"@type": "Text", "ID": "5", "Format": "UTF-8", "Language": "und", "Forced": "Yes"},
+      {"@type": "Menu"}
+    ]
+  }
+}`
+
+// TestParseSummaryNormalizesTracks 验证容器、时长、码率和各轨道的编码、分辨率、HDR、声道与语言都能规范化提取。
+func TestParseSummaryNormalizesTracks(t *testing.T) {
+	summary, err := ParseSummary([]byte(testJSON))
+	if err != nil {
+		t.Fatalf("ParseSummary() error: %v", err)
+	}
+
+	if summary.Container != "Matroska" || summary.Duration != "2:08:19" || summary.OverallBitrate != 55780000 || summary.FileSize != 53687091200 {
+		t.Fatalf("general = %+v", summary)
+	}
+	if len(summary.Video) != 1 {
+		t.Fatalf("len(Video) = %d, want 1", len(summary.Video))
+	}
+	video := summary.Video[0]
+	if video.Codec != "HEVC" || video.Resolution != "2160p" || video.BitDepth != 10 || video.HDRFormat != "Dolby Vision / HDR10" || !video.Default {
+		t.Fatalf("video = %+v", video)
+	}
+	if len(summary.Audio) != 2 || summary.Audio[0].Channels != 8 || summary.Audio[0].CommercialName != "Dolby TrueHD with Dolby Atmos" || summary.Audio[1].Language != "en" {
+		t.Fatalf("audio = %+v", summary.Audio)
+	}
+	if len(summary.AudioLanguages) != 1 || summary.AudioLanguages[0] != "en" {
+		t.Fatalf("AudioLanguages = %v, want [en]", summary.AudioLanguages)
+	}
+	if len(summary.Subtitles) != 2 || summary.Subtitles[0].Language != "zh-cn" || summary.Subtitles[1].Language != "" || !summary.Subtitles[1].Forced {
+		t.Fatalf("subtitles = %+v", summary.Subtitles)
+	}
+	if len(summary.SubtitleLanguages) != 1 || summary.SubtitleLanguages[0] != "zh-cn" {
+		t.Fatalf("SubtitleLanguages = %v, want [zh-cn]", summary.SubtitleLanguages)
+	}
+
+	if _, err := ParseSummary([]byte(`[{"media": null}, {"media": {"track": [{"@type": "General", "Format": "MPEG-4"}]}}]`)); err != nil {
+		t.Fatalf("ParseSummary(array) error: %v", err)
+	}
+	if _, err := ParseSummary([]byte(`{"media": {"track": []}}`)); err != ErrNoTracks {
+		t.Fatalf("ParseSummary(empty) error = %v, want ErrNoTracks", err)
+	}
+}
+
+// TestHDRFormatAndResolutionLabels 验证 HDR 标签归纳和隔行扫描分辨率标签。
+func TestHDRFormatAndResolutionLabels(t *testing.T) {
+	cases := []struct {
+		format, compatibility, transfer, want string
+	}{
+		{"SMPTE ST 2094 App 4", "HDR10+ Profile B", "PQ", "HDR10+"},
+		{"SMPTE ST 2086", "HDR10", "PQ", "HDR10"},
+		{"", "", "PQ", "PQ"},
+		{"", "", "HLG", "HLG"},
+		{"", "", "BT.709", ""},
+	}
+	for _, tc := range cases {
+		if got := hdrFormat(tc.format, tc.compatibility, tc.transfer); got != tc.want {
+			t.Fatalf("hdrFormat(%q, %q, %q) = %q, want %q", tc.format, tc.compatibility, tc.transfer, got, tc.want)
+		}
+	}
+	if got := resolutionLabel(1920, 1080, "MBAFF"); got != "1080i" {
+		t.Fatalf("resolutionLabel(1080 MBAFF) = %q, want 1080i", got)
+	}
+	if got := resolutionLabel(720, 576, ""); got != "576p" {
+		t.Fatalf("resolutionLabel(576) = %q, want 576p", got)
+	}
+	if NormalizeMode(" XML ") != ModeXML || NormalizeMode("bogus") != ModeText || OutputArgs(ModeText) != nil {
+		t.Fatal("NormalizeMode/OutputArgs mismatch")
+	}
+}
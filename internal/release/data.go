@@ -0,0 +1,94 @@
+// Package release 提供发布描述模板使用的数据模型。
+
+package release
+
+import (
+	"path/filepath"
+	"strings"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/mediainfo"
+)
+
+// Data 是发布描述模板的数据模型，模板中以 {{.Name}}、{{.MediaInfo.Duration}}、{{range .Images}} 等形式引用。
+//
+// 单个任务只会填写自己产出的字段，例如截图任务只有 Images，其余字段为空；模板应使用 with / if 跳过空字段。
+type Data struct {
+	// Name 是发布名称：ISO 取镜像文件名，光盘目录取 BDMV / VIDEO_TS 的上级目录名，普通文件去掉扩展名。
+	Name string
+	// MediaInfo 是 MediaInfo JSON 输出的规范化摘要。
+	MediaInfo *mediainfo.Summary
+	// MediaInfoText 是 MediaInfo 输出原文，格式取决于任务的输出模式。
+	MediaInfoText string
+	// BDInfo 是 BDInfo 报告中 [code] 代码块的内容，不含 [code] 标签。
+	BDInfo string
+	// BDInfoReport 是从 BDInfo 完整报告解析出的结构化结果。
+	BDInfoReport *bdinfo.Report
+	// Images 是上传到图床后的截图，按截图顺序排列。
+	Images []Image
+	// Torrent 是生成的种子信息。
+	Torrent *Torrent
+}
+
+// Image 表示一张已上传的截图。
+type Image struct {
+	// URL 是原图直链，ThumbnailURL 是缩略图直链；图床不提供缩略图时为空。
+	URL          string
+	ThumbnailURL string
+	Filename     string
+	Width        int
+	Height       int
+}
+
+// Torrent 表示生成的种子文件。
+type Torrent struct {
+	// InfoHash 是 v1 info hash（SHA-1 十六进制）；InfoHashV2 只在 v2 或混合种子中存在。
+	InfoHash    string
+	InfoHashV2  string
+	Name        string
+	Size        int64
+	DownloadURL string
+}
+
+// NameFromPath 根据输入路径推导发布名称，支持 ISO:/path/to.iso!/inner 形式的虚拟 ISO 路径。
+//
+// 光盘内部的 BDMV、PLAYLIST、STREAM、VIDEO_TS 及其中的文件会上溯到光盘目录；只去掉常见视频和镜像扩展名，避免截断带点的目录名。
+func NameFromPath(input string) string {
+	cleaned := strings.TrimSpace(strings.Trim(input, "\""))
+	if strings.HasPrefix(cleaned, "ISO:") {
+		cleaned = strings.TrimPrefix(cleaned, "ISO:")
+		if bang := strings.Index(cleaned, "!"); bang >= 0 {
+			cleaned = cleaned[:bang]
+		}
+	}
+	cleaned = filepath.Clean(cleaned)
+
+	if discDirNames[strings.ToUpper(filepath.Base(filepath.Dir(cleaned)))] {
+		cleaned = filepath.Dir(cleaned)
+	}
+	for discDirNames[strings.ToUpper(filepath.Base(cleaned))] {
+		parent := filepath.Dir(cleaned)
+		if parent == cleaned {
+			break
+		}
+		cleaned = parent
+	}
+
+	name := filepath.Base(cleaned)
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	if mediaExtensions[strings.ToLower(filepath.Ext(name))] {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+// discDirNames 是光盘结构中需要上溯到光盘目录的目录名。
+var discDirNames = map[string]bool{"BDMV": true, "PLAYLIST": true, "STREAM": true, "VIDEO_TS": true}
+
+// mediaExtensions 是推导发布名称时会去掉的扩展名。
+var mediaExtensions = map[string]bool{
+	".iso": true, ".mkv": true, ".mp4": true, ".m2ts": true, ".ts": true, ".avi": true,
+	".mov": true, ".wmv": true, ".webm": true, ".m4v": true, ".mpg": true, ".mpeg": true,
+}
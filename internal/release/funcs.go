@@ -0,0 +1,48 @@
+// Package release 提供发布描述模板可用的辅助函数。
+
+package release
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs 是所有模板可用的辅助函数：
+//
+//	join    用分隔符连接字符串列表，例如 {{join .MediaInfo.AudioLanguages ", "}}
+//	bytes   把字节数格式化为 GiB / MiB，例如 {{bytes .Torrent.Size}}
+//	bitrate 把 bps 码率格式化为 Mb/s / kb/s，例如 {{bitrate .MediaInfo.OverallBitrate}}
+//	add     整数相加，常用于 {{range $i, $img := .Images}}{{add $i 1}}{{end}} 生成序号
+//	upper / lower / trim  字符串大小写转换和去除首尾空白
+var templateFuncs = template.FuncMap{
+	"join":    func(values []string, sep string) string { return strings.Join(values, sep) },
+	"bytes":   formatBytes,
+	"bitrate": formatBitrate,
+	"add":     func(a, b int) int { return a + b },
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+}
+
+// formatBytes 把字节数格式化为带二进制单位的可读字符串。
+func formatBytes(value int64) string {
+	switch {
+	case value >= 1<<30:
+		return fmt.Sprintf("%.2f GiB", float64(value)/(1<<30))
+	case value >= 1<<20:
+		return fmt.Sprintf("%.2f MiB", float64(value)/(1<<20))
+	case value >= 1<<10:
+		return fmt.Sprintf("%.2f KiB", float64(value)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", value)
+	}
+}
+
+// formatBitrate 把 bps 码率格式化为 Mb/s 或 kb/s。
+func formatBitrate(value int64) string {
+	if value >= 1_000_000 {
+		return fmt.Sprintf("%.1f Mb/s", float64(value)/1_000_000)
+	}
+	return fmt.Sprintf("%d kb/s", value/1000)
+}
@@ -0,0 +1,128 @@
+// Package release 提供基于 text/template 的发布描述模板渲染，内置 BBCode、Markdown 和 HTML 模板，并支持用户自定义模板。
+
+package release
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// templateExt 是用户模板文件的扩展名，文件名去掉扩展名后即模板名。
+const templateExt = ".tmpl"
+
+// ErrTemplateNotFound 表示请求的模板既不是内置模板，也不在用户模板目录中。
+var ErrTemplateNotFound = errors.New("release template not found")
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Template 描述一个可供选择的发布描述模板。
+type Template struct {
+	Name string `json:"name"`
+	// BuiltIn 表示模板随程序内置；用户模板与内置模板同名时会覆盖内置模板，此时为 false。
+	BuiltIn bool `json:"builtin"`
+}
+
+// NormalizeName 规范化模板名：去除首尾空白并转为小写。
+func NormalizeName(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// List 返回全部可用模板，按名称排序；dir 为用户模板目录，不存在时只返回内置模板。
+func List(dir string) ([]Template, error) {
+	byName := make(map[string]Template)
+	builtin, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range builtin {
+		name := strings.TrimSuffix(entry.Name(), templateExt)
+		byName[name] = Template{Name: name, BuiltIn: true}
+	}
+
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), templateExt)
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), templateExt) || !templateNamePattern.MatchString(name) {
+				continue
+			}
+			byName[name] = Template{Name: name}
+		}
+	}
+
+	templates := make([]Template, 0, len(byName))
+	for _, item := range byName {
+		templates = append(templates, item)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// Lookup 检查模板是否存在并能通过语法解析；模板不存在时返回 ErrTemplateNotFound。
+func Lookup(dir, name string) error {
+	_, err := parse(dir, name)
+	return err
+}
+
+// Render 使用名为 name 的模板渲染 data；用户模板目录 dir 中的同名模板优先于内置模板，每次渲染都会重新读取文件。
+func Render(dir, name string, data Data) (string, error) {
+	tmpl, err := parse(dir, name)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("render template %s: %w", name, err)
+	}
+	return strings.TrimSpace(builder.String()) + "\n", nil
+}
+
+// parse 读取并解析名为 name 的模板。
+func parse(dir, name string) (*template.Template, error) {
+	name = NormalizeName(name)
+	if !templateNamePattern.MatchString(name) {
+		return nil, ErrTemplateNotFound
+	}
+
+	source, err := readTemplate(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// readTemplate 优先读取用户模板目录中的模板文件，不存在时回退到内置模板。
+func readTemplate(dir, name string) (string, error) {
+	if dir != "" {
+		content, err := os.ReadFile(filepath.Join(dir, name+templateExt))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	content, err := builtinTemplates.ReadFile("templates/" + name + templateExt)
+	if err != nil {
+		return "", ErrTemplateNotFound
+	}
+	return string(content), nil
+}
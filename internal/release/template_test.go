@@ -0,0 +1,109 @@
+// Package release 验证发布描述模板的渲染、用户模板覆盖和发布名称推导。
+
+package release
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"minfo/internal/mediainfo"
+)
+
+// testData 返回同时包含 MediaInfo、截图和种子信息的模板数据。
+func testData() Data {
+	return Data{
+		Name:          "Movie.2024.1080p",
+		MediaInfo:     &mediainfo.Summary{Container: "Matroska", Duration: "2:08:19", OverallBitrate: 45305000, SubtitleLanguages: []string{"en", "zh"}},
+		MediaInfoText: "General\nFormat : Matroska",
+		Images: []Image{
+			{URL: "https://img.example/1.png", ThumbnailURL: "https://img.example/t/1.png", Filename: "1.png"},
+			{URL: "https://img.example/2.png", Filename: "2.png"},
+		},
+		Torrent: &Torrent{InfoHash: "0123456789abcdef0123456789abcdef01234567"},
+	}
+}
+
+// TestRenderBuiltinTemplates 验证内置 BBCode、Markdown 和 HTML 模板会输出图片链接、MediaInfo 和 info hash。
+func TestRenderBuiltinTemplates(t *testing.T) {
+	cases := map[string][]string{
+		"bbcode":   {"[quote]\nGeneral", "[url=https://img.example/1.png][img]https://img.example/t/1.png[/img][/url]", "[img]https://img.example/2.png[/img]", "Info Hash: 0123456789abcdef"},
+		"markdown": {"# Movie.2024.1080p", "- Bitrate: 45.3 Mb/s", "- Subtitles: en, zh", "[![1.png](https://img.example/t/1.png)](https://img.example/1.png)", "`0123456789abcdef"},
+		"html":     {"<h1>Movie.2024.1080p</h1>", `<img src="https://img.example/2.png" alt="2.png">`, "<code>0123456789abcdef"},
+	}
+	for name, wants := range cases {
+		output, err := Render("", name, testData())
+		if err != nil {
+			t.Fatalf("Render(%s) error: %v", name, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(output, want) {
+				t.Fatalf("Render(%s) = %q, want it to contain %q", name, output, want)
+			}
+		}
+	}
+
+	output, err := Render("", "bbcode", Data{Images: []Image{{URL: "https://img.example/1.png"}}})
+	if err != nil || strings.Contains(output, "[quote]") || strings.Contains(output, "Info Hash") {
+		t.Fatalf("Render(bbcode, images only) = %q, %v; want only image section", output, err)
+	}
+}
+
+// TestRenderUserTemplates 验证用户模板目录中的模板可被列出并覆盖同名内置模板，未知模板返回 ErrTemplateNotFound。
+func TestRenderUserTemplates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+	}
+	write("tracker-a.tmpl", `{{range $i, $img := .Images}}{{add $i 1}}. {{$img.URL}}{{"\n"}}{{end}}`)
+	write("bbcode.tmpl", `custom {{.Name}}`)
+	write("Bad Name.tmpl", `ignored`)
+
+	templates, err := List(dir)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	var names []string
+	for _, item := range templates {
+		names = append(names, item.Name)
+		if item.Name == "bbcode" && item.BuiltIn {
+			t.Fatal("overridden bbcode template should not be reported as built-in")
+		}
+	}
+	if strings.Join(names, ",") != "bbcode,html,markdown,tracker-a" {
+		t.Fatalf("List() names = %v", names)
+	}
+
+	if output, err := Render(dir, "Tracker-A", testData()); err != nil || output != "1. https://img.example/1.png\n2. https://img.example/2.png\n" {
+		t.Fatalf("Render(tracker-a) = %q, %v", output, err)
+	}
+	if output, err := Render(dir, "bbcode", testData()); err != nil || output != "custom Movie.2024.1080p\n" {
+		t.Fatalf("Render(overridden bbcode) = %q, %v", output, err)
+	}
+	if err := Lookup(dir, "../bbcode"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("Lookup(../bbcode) error = %v, want ErrTemplateNotFound", err)
+	}
+	if err := Lookup(dir, "missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("Lookup(missing) error = %v, want ErrTemplateNotFound", err)
+	}
+}
+
+// TestNameFromPath 验证发布名称会上溯光盘目录并只去掉媒体扩展名。
+func TestNameFromPath(t *testing.T) {
+	cases := map[string]string{
+		"/media/Movie.2024.1080p.BluRay":                          "Movie.2024.1080p.BluRay",
+		"/media/Movie.2024.1080p.BluRay/BDMV/PLAYLIST/00800.mpls": "Movie.2024.1080p.BluRay",
+		"/media/Disc/VIDEO_TS":                                    "Disc",
+		"/media/Movie.2024.mkv":                                   "Movie.2024",
+		"ISO:/media/Movie.2024.iso!/BDMV/STREAM":                  "Movie.2024",
+	}
+	for input, want := range cases {
+		if got := NameFromPath(input); got != want {
+			t.Fatalf("NameFromPath(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Package resultcache 提供按源文件指纹缓存 MediaInfo、BDInfo 结果的磁盘存储。
+
+package resultcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"minfo/internal/bdinfo"
+	"minfo/internal/mediainfo"
+	"minfo/internal/system"
+)
+
+const (
+	// entrySuffix 是缓存条目文件的扩展名。
+	entrySuffix = ".json"
+	// virtualISOPrefix 是虚拟 ISO 路径的前缀，例如 ISO:/movies/disc.iso!/BDMV。
+	virtualISOPrefix = "ISO:"
+)
+
+// Entry 表示一条缓存的探测结果。
+type Entry struct {
+	Key string `json:"key"`
+	// Kind 是产生结果的工具，例如 mediainfo 或 bdinfo。
+	Kind string `json:"kind"`
+	// Mode 是产生 Output 时使用的输出模式，例如 MediaInfo 的 json；与模式无关的结果为空。
+	Mode string `json:"mode,omitempty"`
+	// Source 是用户提交的原始输入路径，供按路径清理缓存时匹配。
+	Source      string             `json:"source"`
+	Fingerprint string             `json:"fingerprint"`
+	Output      string             `json:"output"`
+	BDInfo      *bdinfo.Report     `json:"bdinfo,omitempty"`
+	MediaInfo   *mediainfo.Summary `json:"mediainfo,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+// Store 把每条缓存结果保存为目录下的独立 JSON 文件；nil Store 表示缓存已关闭，所有操作都直接返回。
+type Store struct {
+	mu  sync.Mutex
+	dir string
+	ttl time.Duration
+}
+
+// Key 根据工具种类、输出模式和源文件指纹生成缓存键。
+func Key(kind, mode, fingerprint string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + mode + "\x00" + fingerprint))
+	return hex.EncodeToString(sum[:])
+}
+
+// Open 会在 dir 下打开或创建结果缓存，并删除已经超过 ttl 的条目；ttl 不大于 0 时条目永不过期。
+func Open(dir string, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	store := &Store{dir: dir, ttl: ttl}
+	if _, err := store.Purge(func(entry Entry) bool { return store.expired(entry, time.Now()) }); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Dir 返回缓存条目所在目录。
+func (s *Store) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Get 返回指定键的缓存条目；条目不存在、已过期或无法解析时返回 false，后两种情况会顺带删除文件。
+func (s *Store) Get(key string) (Entry, bool) {
+	if s == nil || !validKey(key) {
+		return Entry{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.entryPath(key)
+	entry, err := readEntry(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			_ = os.Remove(path)
+		}
+		return Entry{}, false
+	}
+	if entry.Key != key || s.expired(entry, time.Now()) {
+		_ = os.Remove(path)
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put 会写入或覆盖一条缓存条目；CreatedAt 为空时使用当前时间。
+func (s *Store) Put(entry Entry) error {
+	if s == nil {
+		return nil
+	}
+	if !validKey(entry.Key) {
+		return errors.New("invalid cache key")
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return system.WriteFileAtomicBytes(s.entryPath(entry.Key), 0o600, content)
+}
+
+// List 返回全部有效缓存条目，按创建时间从新到旧排序。
+func (s *Store) List() ([]Entry, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.readAllLocked()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if !s.expired(entry, now) {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+// Purge 删除所有满足 match 的缓存条目并返回删除数量；match 为 nil 时清空整个缓存，无法解析的条目文件总会被删除。
+func (s *Store) Purge(match func(Entry) bool) (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), entrySuffix) {
+			continue
+		}
+		path := filepath.Join(s.dir, file.Name())
+		if match != nil {
+			if entry, err := readEntry(path); err == nil && !match(entry) {
+				continue
+			}
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// UnderPath 返回匹配 Source 等于 path、位于 path 目录下或指向 path 镜像内部的条目的过滤函数，供 Purge 使用。
+func UnderPath(path string) func(Entry) bool {
+	cleaned := strings.TrimRight(strings.TrimSpace(path), "/")
+	return func(entry Entry) bool {
+		source := strings.TrimRight(entry.Source, "/")
+		return source == cleaned || strings.HasPrefix(source, cleaned+"/") || strings.HasPrefix(source, virtualISOPrefix+cleaned+"!")
+	}
+}
+
+// readAllLocked 读取目录中全部可解析的缓存条目；调用方需持有锁。
+func (s *Store) readAllLocked() ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), entrySuffix) {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// expired 会判断条目在 now 时是否已经超过有效期。
+func (s *Store) expired(entry Entry, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(entry.CreatedAt) > s.ttl
+}
+
+// entryPath 返回缓存键对应的条目文件路径。
+func (s *Store) entryPath(key string) string {
+	return filepath.Join(s.dir, key+entrySuffix)
+}
+
+// readEntry 读取并解析单个缓存条目文件。
+func readEntry(path string) (Entry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// validKey 会判断缓存键是否为 Key 生成的十六进制摘要，避免拼出目录外的文件路径。
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
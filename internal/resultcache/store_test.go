@@ -0,0 +1,70 @@
+// Package resultcache 验证结果缓存的读写、过期和清理。
+
+package resultcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"minfo/internal/bdinfo"
+)
+
+// TestStoreRoundTripsAndPurges 验证条目在重新打开后仍可读取，过期条目不会命中，且可按路径或全部清理。
+func TestStoreRoundTripsAndPurges(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	discKey := Key("bdinfo", "", "disc")
+	if err := store.Put(Entry{Key: discKey, Kind: "bdinfo", Source: "/media/movies/Disc", Output: "report", BDInfo: &bdinfo.Report{DiscLabel: "DISC"}}); err != nil {
+		t.Fatalf("Put(disc) error: %v", err)
+	}
+	isoKey := Key("mediainfo", "text", "iso")
+	if err := store.Put(Entry{Key: isoKey, Kind: "mediainfo", Source: "ISO:/media/movies/Film.iso!/BDMV/STREAM/00001.m2ts", Output: "general"}); err != nil {
+		t.Fatalf("Put(iso) error: %v", err)
+	}
+	staleKey := Key("mediainfo", "text", "stale")
+	if err := store.Put(Entry{Key: staleKey, Kind: "mediainfo", Source: "/media/old.mkv", CreatedAt: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("Put(stale) error: %v", err)
+	}
+	if err := store.Put(Entry{Key: "../escape"}); err == nil {
+		t.Fatal("Put(invalid key) error = nil, want error")
+	}
+
+	reopened, err := Open(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("Open() reopen error: %v", err)
+	}
+	entry, ok := reopened.Get(discKey)
+	if !ok || entry.Output != "report" || entry.BDInfo == nil || entry.BDInfo.DiscLabel != "DISC" {
+		t.Fatalf("Get(disc) = %+v, %v; want cached report", entry, ok)
+	}
+	if _, ok := reopened.Get(staleKey); ok {
+		t.Fatal("Get(stale) hit, want expired entry removed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, staleKey+entrySuffix)); !os.IsNotExist(err) {
+		t.Fatalf("stale entry file still exists: %v", err)
+	}
+
+	purged, err := reopened.Purge(UnderPath("/media/movies/Film.iso"))
+	if err != nil || purged != 1 {
+		t.Fatalf("Purge(Film.iso) = %d, %v; want 1", purged, err)
+	}
+	entries, err := reopened.List()
+	if err != nil || len(entries) != 1 || entries[0].Key != discKey {
+		t.Fatalf("List() = %+v, %v; want only disc entry", entries, err)
+	}
+
+	if purged, err := reopened.Purge(nil); err != nil || purged != 1 {
+		t.Fatalf("Purge(nil) = %d, %v; want 1", purged, err)
+	}
+
+	var disabled *Store
+	if _, ok := disabled.Get(discKey); ok || disabled.Put(Entry{Key: discKey}) != nil {
+		t.Fatal("nil Store should behave as a disabled cache")
+	}
+}
@@ -28,18 +28,21 @@ func (r *screenshotRunner) captureScreenshot(aligned float64, path string) error
 	if err != nil {
 		return err
 	}
-	if info.Size() <= oversizeBytes {
+	if info.Size() <= r.oversizeLimit() {
 		return nil
 	}
 
 	sizeMB := float64(info.Size()) / 1024.0 / 1024.0
-	if r.variant != VariantJPG {
+	switch r.variant {
+	case VariantPNG:
 		r.logf("[提示] %s 大小 %.2fMB，先使用 oxipng 压缩...", filepath.Base(path), sizeMB)
 		r.compressOversizedPNGIfNeeded(path)
 		return nil
+	case VariantWebP:
+		r.logf("[提示] %s 大小 %.2fMB，无损 WebP 超出上限，改用高质量有损 WebP 重拍...", filepath.Base(path), sizeMB)
+	default:
+		r.logf("[提示] %s 大小 %.2fMB，重拍降低质量...", filepath.Base(path), sizeMB)
 	}
-
-	r.logf("[提示] %s 大小 %.2fMB，重拍降低质量...", filepath.Base(path), sizeMB)
 	tempPath := path + ".tmp" + r.settings.Ext
 	r.activeShot.SetPhase(screenshotruntime.ActiveShotPhaseReencode)
 	if err := r.runRenderWithLibplaceboFallback(func() error {
@@ -54,6 +57,10 @@ func (r *screenshotRunner) captureScreenshot(aligned float64, path string) error
 		_ = os.Remove(tempPath)
 		return err
 	}
+	if r.variant == VariantWebP {
+		r.markLossyPNG(path)
+		r.logf("[警告] %s 已改用有损 WebP 编码。若介意画质损失，可换用单张上限更大的图床。", filepath.Base(path))
+	}
 	r.activeShot.SetPhase(screenshotruntime.ActiveShotPhaseRender)
 	return nil
 }
@@ -77,7 +84,7 @@ func (r *screenshotRunner) capturePrimary(aligned float64, path string) error {
 		}
 	}
 
-	filterChain := joinFilters(r.render.ColorChain, r.displayAspectFilter())
+	filterChain := r.renderVideoChain()
 
 	if subFilter := r.buildTextSubtitleFilter(); subFilter != "" {
 		return r.captureTextSubtitleWithOutputArgs(aligned, r.primaryOutputArgs(), path)
@@ -103,10 +110,49 @@ func (r *screenshotRunner) capturePrimary(aligned float64, path string) error {
 
 // captureReencoded 在原始截图过大时用更保守的编码参数重新截图。
 func (r *screenshotRunner) captureReencoded(aligned float64, path string) error {
-	if r.variant == VariantJPG {
+	switch r.variant {
+	case VariantJPG:
 		return r.captureJPGReencoded(aligned, path)
+	case VariantWebP, VariantWebPLossy, VariantAVIF:
+		return r.captureWithOutputArgs(aligned, r.reencodeOutputArgs(), path)
+	default:
+		return r.capturePNGReencoded(aligned, path)
 	}
-	return r.capturePNGReencoded(aligned, path)
+}
+
+// captureWithOutputArgs 会按字幕类型选择渲染方案，并用给定的输出编码参数截图。
+func (r *screenshotRunner) captureWithOutputArgs(aligned float64, outputArgs []string, path string) error {
+	_, fineSecond, coarseHMS := r.splitCaptureTimeline(aligned, r.renderCoarseBack())
+
+	if r.subtitle.Mode == "internal" {
+		switch {
+		case r.isPGSSubtitle():
+			return r.capturePGSBitmapWithOutputArgs(coarseHMS, fineSecond, outputArgs, path)
+		case r.isDVDSubtitle():
+			return r.runFFmpeg(r.internalBitmapCaptureArgs(coarseHMS, fineSecond, outputArgs, path), fineSecond)
+		}
+	}
+
+	if subFilter := r.buildTextSubtitleFilter(); subFilter != "" {
+		return r.captureTextSubtitleWithOutputArgs(aligned, outputArgs, path)
+	}
+
+	args := []string{
+		"-v", "error",
+		"-fflags", "+genpts",
+		"-ss", coarseHMS,
+		"-probesize", r.settings.ProbeSize,
+		"-analyzeduration", r.settings.Analyze,
+		"-i", r.sourcePath,
+		"-ss", screenshottimestamps.FormatFloat(fineSecond),
+		"-map", "0:v:0",
+		"-frames:v", "1",
+		"-y",
+		"-vf", r.renderVideoChain(),
+	}
+	args = append(args, outputArgs...)
+	args = append(args, path)
+	return r.runFFmpeg(args, fineSecond)
 }
 
 // capturePNGReencoded 用 PNG 重拍截图，并在需要时加入色彩空间转换链。
@@ -122,7 +168,7 @@ func (r *screenshotRunner) capturePNGReencoded(aligned float64, path string) err
 		}
 	}
 
-	filterChain := joinFilters(r.render.ColorChain, r.displayAspectFilter())
+	filterChain := r.renderVideoChain()
 	if subFilter := r.buildTextSubtitleFilter(); subFilter != "" {
 		return r.captureTextSubtitleWithOutputArgs(aligned, pngReencodeOutputArgs(), path)
 	}
@@ -178,7 +224,7 @@ func (r *screenshotRunner) captureJPGReencoded(aligned float64, path string) err
 		}
 	}
 
-	filterChain := joinFilters(r.render.ColorChain, r.displayAspectFilter())
+	filterChain := r.renderVideoChain()
 	if subFilter := r.buildTextSubtitleFilter(); subFilter != "" {
 		return r.captureTextSubtitleWithOutputArgs(aligned, []string{
 			"-c:v", "mjpeg",
@@ -207,10 +253,26 @@ func (r *screenshotRunner) captureJPGReencoded(aligned float64, path string) err
 
 // primaryOutputArgs 返回主流程截图所需的输出编码参数。
 func (r *screenshotRunner) primaryOutputArgs() []string {
-	if r.variant == VariantJPG {
+	switch r.variant {
+	case VariantJPG:
 		return []string{"-c:v", "mjpeg", "-q:v", strconv.Itoa(clampJPGQScale(r.settings.JPGQuality))}
+	case VariantWebP:
+		return webpOutputArgs(true, r.settings.Quality)
+	case VariantWebPLossy:
+		return webpOutputArgs(false, r.settings.Quality)
+	case VariantAVIF:
+		return avifOutputArgs(r.settings.Quality)
+	default:
+		return pngReencodeOutputArgs()
+	}
+}
+
+// reencodeOutputArgs 返回 WebP / AVIF 截图超出大小上限后重拍使用的输出编码参数；无损 WebP 会改用有损编码。
+func (r *screenshotRunner) reencodeOutputArgs() []string {
+	if r.variant == VariantAVIF {
+		return avifOutputArgs(r.settings.ReencodeQuality)
 	}
-	return pngReencodeOutputArgs()
+	return webpOutputArgs(false, r.settings.ReencodeQuality)
 }
 
 // splitCaptureTimeline 会把绝对截图时间拆成粗定位、细定位和 ffmpeg 用的 HMS 文本。
@@ -223,5 +223,6 @@ func (r *screenshotRunner) buildInternalBitmapRenderFilterComplex() string {
 		fmt.Sprintf("[0:v:0][0:s:%d]overlay=(W-w)/2:(H-h-10)", r.subtitle.RelativeIndex),
 		r.render.ColorChain,
 		r.displayAspectFilter(),
+		r.outputScaleFilter(),
 	)
 }
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"minfo/internal/system"
@@ -15,7 +16,7 @@ import (
 // compressOversizedPNGIfNeeded 会在 PNG 截图过大时依次尝试无损和有损压缩。
 func (r *screenshotRunner) compressOversizedPNGIfNeeded(path string) {
 	info, err := os.Stat(path)
-	if err != nil || info.Size() <= oversizeBytes {
+	if err != nil || info.Size() <= r.oversizeLimit() {
 		return
 	}
 
@@ -29,7 +30,7 @@ func (r *screenshotRunner) compressOversizedPNGIfNeeded(path string) {
 		return
 	}
 	afterMB := float64(afterInfo.Size()) / 1024.0 / 1024.0
-	if afterInfo.Size() <= oversizeBytes {
+	if afterInfo.Size() <= r.oversizeLimit() {
 		r.logf("[信息] %s 经 oxipng 压缩后大小 %.2fMB。", filepath.Base(path), afterMB)
 		return
 	}
@@ -47,7 +48,7 @@ func (r *screenshotRunner) compressOversizedPNGIfNeeded(path string) {
 	}
 
 	finalMB := float64(finalInfo.Size()) / 1024.0 / 1024.0
-	if finalInfo.Size() <= oversizeBytes {
+	if finalInfo.Size() <= r.oversizeLimit() {
 		r.logf("[信息] %s 经 oxipng + pngquant 压缩后大小 %.2fMB。", filepath.Base(path), finalMB)
 		return
 	}
@@ -55,6 +56,14 @@ func (r *screenshotRunner) compressOversizedPNGIfNeeded(path string) {
 	r.logf("[警告] %s 经 oxipng + pngquant 压缩后仍为 %.2fMB，图床上传可能跳过该文件。", filepath.Base(path), finalMB)
 }
 
+// oversizeLimit 返回当前截图触发压缩兜底的大小上限；未设置时使用默认上限。
+func (r *screenshotRunner) oversizeLimit() int64 {
+	if r.maxBytes <= 0 {
+		return DefaultMaxImageBytes
+	}
+	return r.maxBytes
+}
+
 // compressOxiPNG 会调用 oxipng 对 PNG 截图执行无损压缩。
 func (r *screenshotRunner) compressOxiPNG(path string) error {
 	if strings.TrimSpace(r.tools.OxiPNGBin) == "" {
@@ -115,7 +124,7 @@ func buildPNGQuantCompressionArgs(inputPath, outputPath string) []string {
 	}
 }
 
-// markLossyPNG 会记录当前截图已经经过有损 PNG 压缩。
+// markLossyPNG 会记录当前截图已经经过有损压缩：PNG 经 pngquant 处理，或无损 WebP 超限后改用有损编码。
 func (r *screenshotRunner) markLossyPNG(path string) {
 	if r == nil {
 		return
@@ -154,14 +163,13 @@ func clampJPGQScale(value int) int {
 	return value
 }
 
-// fallbackJPGQScale 为超大 JPG 重拍场景选择更保守的 qscale。
+// fallbackJPGQScale 为超大 JPG 重拍场景选择更保守的 qscale：默认高画质时最多降到 6，预设已指定更低画质时在其基础上再降两档。
 func fallbackJPGQScale(value int) int {
 	value = clampJPGQScale(value)
-	value += 2
-	if value > 6 {
-		return 6
+	if value >= 6 {
+		return clampJPGQScale(value + 2)
 	}
-	return value
+	return min(value+2, 6)
 }
 
 // jpgReencodeOutputArgs 返回 JPG 重拍流程使用的输出编码参数。
@@ -171,3 +179,52 @@ func jpgReencodeOutputArgs(quality int) []string {
 		"-q:v", fmt.Sprintf("%d", quality),
 	}
 }
+
+// webpOutputArgs 返回 libwebp 编码参数；lossless 为 true 时 quality 表示无损压缩力度，否则表示有损质量。
+func webpOutputArgs(lossless bool, quality int) []string {
+	if lossless {
+		return []string{
+			"-c:v", "libwebp",
+			"-lossless", "1",
+			"-compression_level", "4",
+			"-quality", strconv.Itoa(clampPercent(quality)),
+		}
+	}
+	return []string{
+		"-c:v", "libwebp",
+		"-lossless", "0",
+		"-preset", "picture",
+		"-compression_level", "6",
+		"-quality", strconv.Itoa(clampPercent(quality)),
+	}
+}
+
+// avifOutputArgs 返回 libaom-av1 静态图片编码参数；crf 越大体积越小，截图使用 4:4:4 采样保留字幕和细节边缘。
+func avifOutputArgs(crf int) []string {
+	if crf < 0 {
+		crf = 0
+	}
+	if crf > 63 {
+		crf = 63
+	}
+	return []string{
+		"-c:v", "libaom-av1",
+		"-still-picture", "1",
+		"-crf", strconv.Itoa(crf),
+		"-b:v", "0",
+		"-cpu-used", "6",
+		"-row-mt", "1",
+		"-pix_fmt", "yuv444p",
+	}
+}
+
+// clampPercent 将质量参数限制在 0 到 100 之间。
+func clampPercent(value int) int {
+	if value < 0 {
+		return 0
+	}
+	if value > 100 {
+		return 100
+	}
+	return value
+}
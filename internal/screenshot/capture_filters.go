@@ -83,7 +83,7 @@ func (r *screenshotRunner) buildPGSOverlayFilterComplex(videoChain, overlayTail
 
 // buildPGSRenderFilterComplex 会构造截图主流程使用的 PGS 叠加滤镜图。
 func (r *screenshotRunner) buildPGSRenderFilterComplex() string {
-	return r.buildPGSOverlayFilterComplex(joinFilters(r.render.ColorChain, r.displayAspectFilter()), "")
+	return r.buildPGSOverlayFilterComplex(joinFilters(r.render.ColorChain, r.displayAspectFilter()), r.outputScaleFilter())
 }
 
 // buildFilterGraphStep 会为 filter_complex 生成单个具名步骤。
@@ -106,6 +106,7 @@ func (r *screenshotRunner) buildTextSubtitleRenderChain(timelineBase, aligned fl
 			r.render.ColorChain,
 			subFilter,
 			r.displayAspectFilter(),
+			r.outputScaleFilter(),
 		)
 	}
 	return joinFilters(
@@ -114,6 +115,7 @@ func (r *screenshotRunner) buildTextSubtitleRenderChain(timelineBase, aligned fl
 		subFilter,
 		r.render.ColorChain,
 		r.displayAspectFilter(),
+		r.outputScaleFilter(),
 	)
 }
 
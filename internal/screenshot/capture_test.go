@@ -626,3 +626,105 @@ func TestBuildPNGQuantCompressionArgs(t *testing.T) {
 		t.Fatalf("expected input path after arg separator in pngquant args, got %q", joined)
 	}
 }
+
+// TestOutputArgsForWebPAndAVIFVariants 验证 WebP / AVIF 截图使用各自的编码器，超限重拍时无损 WebP 改用有损编码、AVIF 提高 crf。
+func TestOutputArgsForWebPAndAVIFVariants(t *testing.T) {
+	tests := []struct {
+		variant  string
+		primary  string
+		reencode string
+	}{
+		{variant: VariantWebP, primary: "-c:v libwebp -lossless 1", reencode: "-c:v libwebp -lossless 0"},
+		{variant: VariantWebPLossy, primary: "-quality 92", reencode: "-quality 80"},
+		{variant: VariantAVIF, primary: "-c:v libaom-av1 -still-picture 1 -crf 20", reencode: "-crf 30"},
+	}
+
+	for _, tt := range tests {
+		runner := &screenshotRunner{variant: tt.variant, settings: screenshotruntime.VariantSettingsFor(tt.variant)}
+		if primary := strings.Join(runner.primaryOutputArgs(), " "); !strings.Contains(primary, tt.primary) {
+			t.Fatalf("%s primary args = %q, want %q", tt.variant, primary, tt.primary)
+		}
+		if reencode := strings.Join(runner.reencodeOutputArgs(), " "); !strings.Contains(reencode, tt.reencode) {
+			t.Fatalf("%s reencode args = %q, want %q", tt.variant, reencode, tt.reencode)
+		}
+	}
+}
+
+// TestOutputScaleFilterAppliesOnlyToRenderChains 验证预设缩放只追加到渲染链末尾，PGS 在叠加字幕后缩放，探测链保持原生分辨率。
+func TestOutputScaleFilterAppliesOnlyToRenderChains(t *testing.T) {
+	runner := &screenshotRunner{
+		render: screenshotruntime.RenderState{AspectChain: "setsar=1"},
+		output: RenderOptions{MaxWidth: 1920, MaxHeight: 1080, Scaler: ScalerSpline},
+		subtitle: screenshotruntime.SubtitleSelection{
+			Mode:          "internal",
+			RelativeIndex: 1,
+			Codec:         "hdmv_pgs_subtitle",
+		},
+	}
+	scale := "scale='min(1920,iw)':'min(1080,ih)':force_original_aspect_ratio=decrease:flags=spline"
+
+	if chain := runner.renderVideoChain(); chain != "setsar=1,"+scale {
+		t.Fatalf("renderVideoChain() = %q, want aspect fix followed by %q", chain, scale)
+	}
+	if filter := runner.buildPGSRenderFilterComplex(); !strings.Contains(filter, "overlay=(W-w)/2:(H-h-10),"+scale+"[out]") {
+		t.Fatalf("expected scaling after PGS overlay, got %q", filter)
+	}
+	if filter := runner.buildPGSOverlayFilterComplex(runner.displayAspectFilter(), "format=gray"); strings.Contains(filter, "scale='min") {
+		t.Fatalf("expected probe filter without output scaling, got %q", filter)
+	}
+
+	runner.output = RenderOptions{MaxWidth: 1280}
+	if got := runner.outputScaleFilter(); got != "scale='min(1280,iw)':-1:flags=lanczos" {
+		t.Fatalf("width-only outputScaleFilter() = %q", got)
+	}
+	runner.output = RenderOptions{}
+	if got := runner.renderVideoChain(); got != "setsar=1" {
+		t.Fatalf("renderVideoChain() without scaling = %q, want native resolution", got)
+	}
+}
+
+// TestApplyRenderOptionsOverridesQualityAndSizeLimit 验证预设质量覆盖格式默认值并同步推导重拍质量，大小上限取图床与预设中更严格的一个。
+func TestApplyRenderOptionsOverridesQualityAndSizeLimit(t *testing.T) {
+	tests := []struct {
+		variant  string
+		options  RenderOptions
+		primary  string
+		reencode string
+	}{
+		{variant: VariantJPG, options: RenderOptions{JPGQScale: 8}, primary: "-q:v 8"},
+		{variant: VariantWebP, options: RenderOptions{WebPQuality: 85}, primary: "-lossless 1", reencode: "-quality 85"},
+		{variant: VariantWebPLossy, options: RenderOptions{WebPQuality: 70}, primary: "-quality 70", reencode: "-quality 58"},
+		{variant: VariantAVIF, options: RenderOptions{AVIFCRF: 28}, primary: "-crf 28", reencode: "-crf 38"},
+	}
+
+	for _, tt := range tests {
+		runner := &screenshotRunner{variant: tt.variant, settings: screenshotruntime.VariantSettingsFor(tt.variant)}
+		runner.applyRenderOptions(tt.options)
+		if primary := strings.Join(runner.primaryOutputArgs(), " "); !strings.Contains(primary, tt.primary) {
+			t.Fatalf("%s primary args = %q, want %q", tt.variant, primary, tt.primary)
+		}
+		if tt.reencode == "" {
+			continue
+		}
+		if reencode := strings.Join(runner.reencodeOutputArgs(), " "); !strings.Contains(reencode, tt.reencode) {
+			t.Fatalf("%s reencode args = %q, want %q", tt.variant, reencode, tt.reencode)
+		}
+	}
+	if got := fallbackJPGQScale(8); got != 10 {
+		t.Fatalf("fallbackJPGQScale(8) = %d, want 10", got)
+	}
+	if got := fallbackJPGQScale(1); got != 3 {
+		t.Fatalf("fallbackJPGQScale(1) = %d, want 3", got)
+	}
+
+	const hostLimit = 10 << 20
+	if got := limitMaxBytes(hostLimit, RenderOptions{MaxBytes: 5 << 20}).MaxBytes; got != 5<<20 {
+		t.Fatalf("limitMaxBytes with stricter preset = %d", got)
+	}
+	if got := limitMaxBytes(hostLimit, RenderOptions{MaxBytes: 50 << 20}).MaxBytes; got != hostLimit {
+		t.Fatalf("limitMaxBytes with looser preset = %d, want host limit", got)
+	}
+	if got := limitMaxBytes(hostLimit, RenderOptions{}).MaxBytes; got != hostLimit {
+		t.Fatalf("limitMaxBytes without preset = %d, want host limit", got)
+	}
+}
@@ -23,7 +23,7 @@ func ListImageFiles(dir string) ([]string, error) {
 			continue
 		}
 		switch strings.ToLower(filepath.Ext(entry.Name())) {
-		case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif":
 			files = append(files, filepath.Join(dir, entry.Name()))
 		}
 	}
@@ -7,6 +7,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
@@ -22,16 +24,50 @@ type preparedDownload struct {
 
 var preparedDownloads = struct {
 	mu    sync.Mutex
+	dir   string
 	items map[string]preparedDownload
 }{
 	items: map[string]preparedDownload{},
 }
 
+// ConfigureStorageDir 把预下载 ZIP 保存到指定目录，使下载令牌在服务重启后仍可使用，并清理目录中已过期的文件。
+func ConfigureStorageDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().Add(preparedDownloadTTL).After(now) {
+			continue
+		}
+		_ = os.Remove(filepath.Join(dir, entry.Name()))
+	}
+
+	preparedDownloads.mu.Lock()
+	preparedDownloads.dir = dir
+	preparedDownloads.mu.Unlock()
+	return nil
+}
+
 // SavePreparedDownload 将生成好的 ZIP 数据写入临时文件，并返回下载令牌。
 func SavePreparedDownload(data []byte) (string, error) {
 	prunePreparedDownloads(time.Now())
 
-	file, err := os.CreateTemp("", "minfo-download-*.zip")
+	token, err := buildPreparedDownloadToken()
+	if err != nil {
+		return "", err
+	}
+
+	file, err := createPreparedDownloadFile(token)
 	if err != nil {
 		return "", err
 	}
@@ -46,12 +82,6 @@ func SavePreparedDownload(data []byte) (string, error) {
 		return "", err
 	}
 
-	token, err := buildPreparedDownloadToken()
-	if err != nil {
-		_ = os.Remove(file.Name())
-		return "", err
-	}
-
 	preparedDownloads.mu.Lock()
 	preparedDownloads.items[token] = preparedDownload{
 		path:      file.Name(),
@@ -69,6 +99,9 @@ func GetPreparedDownload(token string) (string, error) {
 
 	preparedDownloads.mu.Lock()
 	item, ok := preparedDownloads.items[token]
+	if !ok {
+		item, ok = lookupStoredDownloadLocked(token)
+	}
 	preparedDownloads.mu.Unlock()
 
 	if !ok || !item.expiresAt.After(now) {
@@ -91,6 +124,46 @@ func prunePreparedDownloads(now time.Time) {
 	}
 }
 
+// createPreparedDownloadFile 会为令牌创建 ZIP 文件；配置了存储目录时使用固定文件名以便重启后按令牌找回。
+func createPreparedDownloadFile(token string) (*os.File, error) {
+	preparedDownloads.mu.Lock()
+	dir := preparedDownloads.dir
+	preparedDownloads.mu.Unlock()
+
+	if dir == "" {
+		return os.CreateTemp("", "minfo-download-*.zip")
+	}
+	return os.OpenFile(filepath.Join(dir, token+".zip"), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+}
+
+// lookupStoredDownloadLocked 会在存储目录中按令牌查找重启前保存的 ZIP 文件；调用方需持有锁。
+func lookupStoredDownloadLocked(token string) (preparedDownload, bool) {
+	if preparedDownloads.dir == "" || !isPreparedDownloadToken(token) {
+		return preparedDownload{}, false
+	}
+
+	path := filepath.Join(preparedDownloads.dir, token+".zip")
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return preparedDownload{}, false
+	}
+	item := preparedDownload{
+		path:      path,
+		expiresAt: info.ModTime().Add(preparedDownloadTTL),
+	}
+	preparedDownloads.items[token] = item
+	return item, true
+}
+
+// isPreparedDownloadToken 会判断令牌是否符合 buildPreparedDownloadToken 生成的十六进制格式。
+func isPreparedDownloadToken(token string) bool {
+	if len(token) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
+
 func buildPreparedDownloadToken() (string, error) {
 	buf := make([]byte, 16)
 	if _, err := rand.Read(buf); err != nil {
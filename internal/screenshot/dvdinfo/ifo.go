@@ -0,0 +1,76 @@
+// Package dvdinfo 提供直接解析 VTS IFO 得到 DVD 字幕元数据的逻辑。
+
+package dvdinfo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"minfo/internal/media/dvdifo"
+	screenshotruntime "minfo/internal/screenshot/runtime"
+)
+
+// ProbeIFO 直接解析 VTS_xx_0.IFO，返回主标题的字幕轨、时长和显示宽高比；IFO 无法解析时会尝试同名 BUP。
+//
+// 字幕轨的 StreamID 取主标题 PGC 在当前宽高比下实际使用的物理字幕流（0x20 起始），与 ffprobe 报告的流 ID 一致。
+func ProbeIFO(path string) (screenshotruntime.DVDMediaInfoResult, error) {
+	cleaned := strings.TrimSpace(path)
+	if !strings.EqualFold(filepath.Ext(cleaned), ".ifo") || strings.EqualFold(filepath.Base(cleaned), "VIDEO_TS.IFO") {
+		return screenshotruntime.DVDMediaInfoResult{}, fmt.Errorf("not a title set IFO: %s", cleaned)
+	}
+
+	probePath := cleaned
+	vts, err := readVTS(cleaned)
+	if err != nil {
+		bupPath, ok := BUPPath(cleaned)
+		if !ok {
+			return screenshotruntime.DVDMediaInfoResult{}, err
+		}
+		if vts, err = readVTS(bupPath); err != nil {
+			return screenshotruntime.DVDMediaInfoResult{}, err
+		}
+		probePath = bupPath
+	}
+
+	result := screenshotruntime.DVDMediaInfoResult{
+		DisplayAspectRatio: vts.Video.AspectRatio,
+		ProbePath:          probePath,
+		FromIFO:            true,
+		Tracks:             make([]screenshotruntime.DVDMediaInfoTrack, 0, len(vts.Subpictures)),
+	}
+
+	ttn := vts.LongestTitle()
+	result.Duration = vts.TitleDuration(ttn).Seconds()
+	pgcs := vts.TitlePGCs(ttn)
+	if len(pgcs) == 0 {
+		return result, nil
+	}
+	pgc := vts.PGCs[pgcs[0]-1]
+
+	for index, stream := range vts.Subpictures {
+		if index >= len(pgc.Subpictures) || !pgc.Subpictures[index].Available {
+			continue
+		}
+		streamID := dvdifo.SubpictureStreamID(pgc.Subpictures[index].StreamFor(vts.Video.AspectRatio))
+		result.Tracks = append(result.Tracks, screenshotruntime.DVDMediaInfoTrack{
+			StreamID: streamID,
+			ID:       fmt.Sprintf("0x%02X", streamID),
+			Format:   "RLE",
+			Language: stream.Language,
+			Title:    stream.Description(),
+			Source:   filepath.Base(probePath),
+		})
+	}
+	return result, nil
+}
+
+// readVTS 读取并解析单个 VTS IFO 或 BUP 文件。
+func readVTS(path string) (*dvdifo.VTS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return dvdifo.ParseVTS(data)
+}
@@ -12,14 +12,28 @@ import (
 	"minfo/internal/system"
 )
 
-// Probe 使用 mediainfo 提取 DVD 字幕元数据；必要时会从 BUP 结果补齐缺失语言。
+// Probe 提取 DVD 字幕元数据：优先直接解析 VTS IFO，仍缺语言时用 mediainfo 结果补齐；
+// IFO 不可用时退回 mediainfo，并在必要时从 BUP 结果补齐缺失语言。
 func Probe(ctx context.Context, mediainfoBin, path, probePath string) (screenshotruntime.DVDMediaInfoResult, error) {
+	selectedVOBPath := ResolveVOBPath(path, probePath)
+	primaryPath := ResolveProbePath(path, probePath)
+
+	if result, err := ProbeIFO(primaryPath); err == nil {
+		result.SelectedVOBPath = selectedVOBPath
+		if NeedsLanguageFallback(result) && strings.TrimSpace(mediainfoBin) != "" {
+			if fallback, fallbackErr := probeOnce(ctx, mediainfoBin, primaryPath); fallbackErr == nil {
+				if merged, used := MergeLanguageFallback(result, fallback); used {
+					merged.LanguageFallbackPath = primaryPath
+					result = merged
+				}
+			}
+		}
+		return result, nil
+	}
+
 	if strings.TrimSpace(mediainfoBin) == "" {
 		return screenshotruntime.DVDMediaInfoResult{}, fmt.Errorf("mediainfo not available")
 	}
-
-	selectedVOBPath := ResolveVOBPath(path, probePath)
-	primaryPath := ResolveProbePath(path, probePath)
 	result, err := probeOnce(ctx, mediainfoBin, primaryPath)
 	if err != nil {
 		return screenshotruntime.DVDMediaInfoResult{}, err
@@ -0,0 +1,81 @@
+// Package screenshot 提供按名称选择截图图床的注册表。
+
+package screenshot
+
+import (
+	"fmt"
+	"strings"
+
+	"minfo/internal/config"
+	"minfo/internal/screenshot/imagehost"
+	screenshotpixhost "minfo/internal/screenshot/pixhost"
+)
+
+const (
+	ImageHostPixhost   = "pixhost"
+	ImageHostChevereto = "chevereto"
+	ImageHostImgbox    = "imgbox"
+	ImageHostPtpimg    = "ptpimg"
+	ImageHostS3        = "s3"
+	ImageHostLocal     = "local"
+)
+
+// DefaultMaxImageBytes 是未指定图床时截图阶段压缩超大图片使用的大小上限。
+const DefaultMaxImageBytes = 10 * 1024 * 1024
+
+// ImageHost 表示一个可上传截图的图床后端。
+type ImageHost = imagehost.Host
+
+// imageHostFactories 按名称登记各图床的构造函数；构造函数在缺少必要配置时返回错误。
+var imageHostFactories = map[string]func() (ImageHost, error){
+	ImageHostPixhost:   func() (ImageHost, error) { return screenshotpixhost.New(), nil },
+	ImageHostChevereto: imagehost.NewChevereto,
+	ImageHostImgbox:    imagehost.NewImgbox,
+	ImageHostPtpimg:    imagehost.NewPtpimg,
+	ImageHostS3:        imagehost.NewS3,
+	ImageHostLocal:     imagehost.NewLocal,
+}
+
+// ImageHostNames 返回所有支持的图床名称。
+func ImageHostNames() []string {
+	return []string{ImageHostPixhost, ImageHostChevereto, ImageHostImgbox, ImageHostPtpimg, ImageHostS3, ImageHostLocal}
+}
+
+// NormalizeImageHost 规范化图床名称；空值会使用 IMAGE_HOST 环境变量配置的默认图床（缺省为 pixhost）。
+func NormalizeImageHost(raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == "" {
+		return strings.ToLower(config.Getenv("IMAGE_HOST", ImageHostPixhost))
+	}
+	return value
+}
+
+// LookupImageHost 会按名称创建图床；名称未知或图床缺少必要配置时返回错误。
+func LookupImageHost(name string) (ImageHost, error) {
+	name = NormalizeImageHost(name)
+	factory, ok := imageHostFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported image host %q (supported: %s)", name, strings.Join(ImageHostNames(), ", "))
+	}
+	return factory()
+}
+
+// LookupImageHostForVariant 会按名称创建图床，并确认该图床接受 variant 对应的截图格式，供截图前提前拒绝不兼容的组合。
+func LookupImageHostForVariant(name, variant string) (ImageHost, error) {
+	host, err := LookupImageHost(name)
+	if err != nil {
+		return nil, err
+	}
+	if ext := VariantExtension(variant); !host.AcceptsFormat(ext) {
+		return nil, fmt.Errorf("image host %s does not accept %s screenshots", host.Name(), NormalizeVariant(variant))
+	}
+	return host, nil
+}
+
+// maxImageBytesFor 返回截图阶段应遵守的图片大小上限：图床可用时使用其上限，否则使用默认值。
+func maxImageBytesFor(host ImageHost) int64 {
+	if host == nil || host.MaxUploadBytes() <= 0 {
+		return DefaultMaxImageBytes
+	}
+	return host.MaxUploadBytes()
+}
@@ -0,0 +1,48 @@
+package screenshot
+
+import "testing"
+
+func TestLookupImageHost(t *testing.T) {
+	t.Setenv("IMAGE_HOST", "")
+	t.Setenv("PTPIMG_API_KEY", "")
+
+	host, err := LookupImageHost("")
+	if err != nil || host.Name() != ImageHostPixhost {
+		t.Fatalf("LookupImageHost(\"\") = %v, %v, want pixhost", host, err)
+	}
+	if maxImageBytesFor(host) != DefaultMaxImageBytes {
+		t.Fatalf("maxImageBytesFor(pixhost) = %d", maxImageBytesFor(host))
+	}
+	if _, err := LookupImageHost("unknown"); err == nil {
+		t.Fatal("LookupImageHost(unknown) error = nil")
+	}
+	if _, err := LookupImageHost(ImageHostPtpimg); err == nil {
+		t.Fatal("LookupImageHost(ptpimg) error = nil without PTPIMG_API_KEY")
+	}
+
+	t.Setenv("IMAGE_HOST", " IMGBOX ")
+	host, err = LookupImageHost("")
+	if err != nil || host.Name() != ImageHostImgbox {
+		t.Fatalf("LookupImageHost(\"\") with IMAGE_HOST = %v, %v, want imgbox", host, err)
+	}
+}
+
+func TestLookupImageHostForVariant(t *testing.T) {
+	t.Setenv("IMAGE_HOST", "")
+	t.Setenv("S3_ENDPOINT", "https://s3.example")
+	t.Setenv("S3_BUCKET", "shots")
+	t.Setenv("S3_ACCESS_KEY", "key")
+	t.Setenv("S3_SECRET_KEY", "secret")
+
+	if _, err := LookupImageHostForVariant(ImageHostPixhost, VariantJPG); err != nil {
+		t.Fatalf("LookupImageHostForVariant(pixhost, jpg) error = %v", err)
+	}
+	for _, variant := range []string{VariantWebP, VariantWebPLossy, VariantAVIF} {
+		if _, err := LookupImageHostForVariant(ImageHostPixhost, variant); err == nil {
+			t.Fatalf("LookupImageHostForVariant(pixhost, %s) error = nil, want unsupported format", variant)
+		}
+		if _, err := LookupImageHostForVariant(ImageHostS3, variant); err != nil {
+			t.Fatalf("LookupImageHostForVariant(s3, %s) error = %v", variant, err)
+		}
+	}
+}
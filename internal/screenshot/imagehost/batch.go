@@ -0,0 +1,231 @@
+// Package imagehost 负责图床上传批次的日志、结果和可上传文件整理。
+
+package imagehost
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 图片在上传前被跳过的原因，会作为失败明细返回给调用方。
+const (
+	skipReasonTooLarge       = "exceeds host limit"
+	skipReasonFormatRejected = "format not accepted"
+)
+
+// uploadBatch 维护单次图床上传批次的日志、直链和图片结果。
+type uploadBatch struct {
+	hostName     string
+	onLog        LogHandler
+	onItem       UploadItemHandler
+	logLines     []string
+	links        []string
+	items        []UploadedImage
+	failed       []FailedImage
+	lossySet     map[string]struct{}
+	lossyIndexes []int
+}
+
+// newUploadBatch 会为一次图床上传请求创建新的批次状态容器。
+func newUploadBatch(hostName string, lossyFiles []string, onLog LogHandler, onItem UploadItemHandler) *uploadBatch {
+	lossySet := make(map[string]struct{}, len(lossyFiles))
+	for _, name := range lossyFiles {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		lossySet[name] = struct{}{}
+	}
+
+	return &uploadBatch{
+		hostName:     hostName,
+		onLog:        onLog,
+		onItem:       onItem,
+		logLines:     make([]string, 0),
+		links:        make([]string, 0),
+		items:        make([]UploadedImage, 0),
+		lossySet:     lossySet,
+		lossyIndexes: make([]int, 0, len(lossySet)),
+	}
+}
+
+// logs 会返回当前批次累计的完整上传日志文本。
+func (b *uploadBatch) logs() string {
+	if b == nil {
+		return ""
+	}
+	return strings.Join(b.logLines, "\n")
+}
+
+// appendLog 会追加一条上传日志，并在存在实时回调时同步推送。
+func (b *uploadBatch) appendLog(format string, args ...any) {
+	if b == nil {
+		return
+	}
+	line := fmt.Sprintf(format, args...)
+	b.logLines = append(b.logLines, line)
+	if b.onLog != nil {
+		b.onLog(line)
+	}
+}
+
+// recordRetry 会记录单张图片上传失败、即将在 delay 后进行第 attempt 次尝试的日志。
+func (b *uploadBatch) recordRetry(imagePath string, err error, delay time.Duration, attempt, maxAttempts int) {
+	if b == nil {
+		return
+	}
+	b.appendLog("上传失败: %s (%s)，%s 后重试 (%d/%d)", filepath.Base(imagePath), err.Error(), delay, attempt, maxAttempts)
+}
+
+// recordFailure 会记录单张图片最终上传失败的日志和失败明细。
+func (b *uploadBatch) recordFailure(imagePath string, err error) {
+	if b == nil {
+		return
+	}
+	b.failed = append(b.failed, FailedImage{Path: imagePath, Filename: filepath.Base(imagePath), Error: err.Error()})
+	b.appendLog("上传失败: %s (%s)", filepath.Base(imagePath), err.Error())
+}
+
+// recordSkipped 会记录一张因图床限制未上传的图片，并写入失败明细。
+func (b *uploadBatch) recordSkipped(imagePath, reason string) {
+	if b == nil {
+		return
+	}
+	b.failed = append(b.failed, FailedImage{Path: imagePath, Filename: filepath.Base(imagePath), Error: reason})
+	b.appendLog("跳过: %s (%s)", filepath.Base(imagePath), reason)
+}
+
+// recordNotAttempted 会把因代理无效或任务取消而没有完成上传的图片记入失败明细，只写一条汇总日志。
+func (b *uploadBatch) recordNotAttempted(imagePaths []string, err error) {
+	if b == nil || len(imagePaths) == 0 {
+		return
+	}
+	for _, imagePath := range imagePaths {
+		b.failed = append(b.failed, FailedImage{Path: imagePath, Filename: filepath.Base(imagePath), Error: err.Error()})
+	}
+	b.appendLog("未完成上传: %d 张 (%s)", len(imagePaths), err.Error())
+}
+
+// recordSuccess 会记录单张图片上传成功后的直链、缩略图、元数据和实时回调。
+func (b *uploadBatch) recordSuccess(imagePath, directURL, thumbnailURL string) {
+	if b == nil {
+		return
+	}
+	if _, ok := b.lossySet[filepath.Base(imagePath)]; ok {
+		b.lossyIndexes = append(b.lossyIndexes, len(b.links))
+	}
+
+	b.links = append(b.links, directURL)
+	item := buildUploadedImage(imagePath, directURL, thumbnailURL)
+	b.items = append(b.items, item)
+	if b.onItem != nil {
+		b.onItem(item)
+	}
+	b.appendLog("已上传: %s", filepath.Base(imagePath))
+}
+
+// finalize 会输出批次摘要，并生成最终返回给调用方的整理结果。
+func (b *uploadBatch) finalize(total int) (Result, error) {
+	if b == nil {
+		return Result{}, errors.New("image host upload batch is nil")
+	}
+
+	b.appendLog("")
+	b.appendLog("处理完成! 成功: %d/%d", len(b.links), total)
+	if len(b.failed) > 0 {
+		b.appendLog("失败: %d 张，可稍后只重试失败的图片", len(b.failed))
+	}
+	result := b.result()
+	if len(b.links) == 0 {
+		return result, fmt.Errorf("%s upload completed but returned no links", b.hostName)
+	}
+	result.Output = strings.Join(extractDirectLinks(strings.Join(b.links, "\n")), "\n")
+	return result, nil
+}
+
+// result 会返回批次当前的日志、成功图片和失败明细；提前返回时也通过它保证已跳过和未完成的图片都出现在 Failed 中。
+func (b *uploadBatch) result() Result {
+	if b == nil {
+		return Result{}
+	}
+	return Result{
+		Logs:         b.logs(),
+		Items:        b.items,
+		Failed:       b.failed,
+		LossyIndexes: b.lossyIndexes,
+	}
+}
+
+// buildUploadedImage 会根据本地文件、原图直链和缩略图构建一条可返回给前端的图片记录。
+func buildUploadedImage(imagePath, directURL, thumbnailURL string) UploadedImage {
+	item := UploadedImage{
+		URL:          strings.TrimSpace(directURL),
+		ThumbnailURL: strings.TrimSpace(thumbnailURL),
+		Filename:     filepath.Base(imagePath),
+	}
+
+	info, err := os.Stat(imagePath)
+	if err == nil && !info.IsDir() && info.Size() > 0 {
+		item.Size = info.Size()
+	}
+	item.Width, item.Height = readImageDimensions(imagePath)
+	return item
+}
+
+// readImageDimensions 从本地原图读取尺寸；失败时保持零值，由前端隐藏该元数据。
+func readImageDimensions(path string) (int, int) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return readModernImageDimensions(path, detectImageFileFormat(path))
+	}
+	return config.Width, config.Height
+}
+
+// collectUploadableImages 从路径列表中筛出图床可接受的图片，并按文件名排序；超过大小上限或格式不被接受的图片会记入批次的失败明细。
+func collectUploadableImages(paths []string, maxUploadBytes int64, accepts func(format string) bool, batch *uploadBatch) []string {
+	candidates := make([]string, 0, len(paths))
+	for _, path := range paths {
+		ok, reason := checkUploadableImage(path, maxUploadBytes, accepts)
+		if reason != "" {
+			batch.recordSkipped(path, reason)
+		}
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, path)
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+// checkUploadableImage 检查文件是否存在、大小合理，且文件头是 accepts 接受的图片格式。
+// 文件不存在或为空时直接返回 false；超过大小上限或格式不被接受时同时返回跳过原因。
+func checkUploadableImage(path string, maxUploadBytes int64, accepts func(format string) bool) (bool, string) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() <= 0 {
+		return false, ""
+	}
+	if maxUploadBytes > 0 && info.Size() > maxUploadBytes {
+		return false, skipReasonTooLarge
+	}
+
+	format := detectImageFileFormat(path)
+	if format == "" || (accepts != nil && !accepts(format)) {
+		return false, skipReasonFormatRejected
+	}
+	return true, ""
+}
@@ -1,4 +1,4 @@
-package pixhost
+package imagehost
 
 import (
 	"image"
@@ -0,0 +1,83 @@
+// Package imagehost 提供兼容 Chevereto API 的图床上传实现。
+
+package imagehost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"minfo/internal/config"
+)
+
+// defaultCheveretoMaxBytes 是 Chevereto 图床未配置上限时使用的单张图片大小上限。
+const defaultCheveretoMaxBytes = 10 * 1024 * 1024
+
+// chevereto 通过 Chevereto /api/1/upload 接口上传图片。
+type chevereto struct {
+	endpoint string
+	apiKey   string
+	maxBytes int64
+}
+
+// cheveretoResponse 描述 Chevereto JSON 响应中当前流程实际使用的字段。
+type cheveretoResponse struct {
+	StatusCode int `json:"status_code"`
+	Image      struct {
+		URL   string `json:"url"`
+		Thumb struct {
+			URL string `json:"url"`
+		} `json:"thumb"`
+	} `json:"image"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewChevereto 会根据 CHEVERETO_URL 和 CHEVERETO_API_KEY 创建 Chevereto 图床；缺少配置时返回错误。
+func NewChevereto() (Host, error) {
+	baseURL := strings.TrimRight(config.Getenv("CHEVERETO_URL", ""), "/")
+	apiKey := config.Getenv("CHEVERETO_API_KEY", "")
+	if baseURL == "" || apiKey == "" {
+		return nil, errMissingSetting("chevereto", "CHEVERETO_URL", "CHEVERETO_API_KEY")
+	}
+	return &chevereto{
+		endpoint: baseURL + "/api/1/upload",
+		apiKey:   apiKey,
+		maxBytes: int64(config.IntFromEnv("CHEVERETO_MAX_BYTES", defaultCheveretoMaxBytes)),
+	}, nil
+}
+
+func (h *chevereto) Name() string { return "chevereto" }
+
+func (h *chevereto) MaxUploadBytes() int64 { return h.maxBytes }
+
+// AcceptsFormat 接受传统格式以及 WebP、AVIF；AVIF 需要 Chevereto V4。
+func (h *chevereto) AcceptsFormat(format string) bool {
+	return acceptsFormat(format, FormatPNG, FormatJPG, FormatGIF, FormatWebP, FormatAVIF)
+}
+
+// Upload 会以 source 字段上传图片；API Key 同时放在请求头和表单中，以兼容 V3 与 V4 接口。
+func (h *chevereto) Upload(ctx context.Context, client *http.Client, imagePath string) (Link, error) {
+	body, contentType, err := multipartImageBody("source", imagePath, [][2]string{{"key", h.apiKey}, {"format", "json"}})
+	if err != nil {
+		return Link{}, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, body)
+	if err != nil {
+		return Link{}, err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", contentType)
+	request.Header.Set("X-API-Key", h.apiKey)
+
+	var payload cheveretoResponse
+	if err := doJSON(client, request, h.Name(), &payload); err != nil {
+		return Link{}, err
+	}
+	if message := strings.TrimSpace(payload.Error.Message); message != "" {
+		return Link{}, fmt.Errorf("chevereto: %s", message)
+	}
+	return newLink(h.Name(), payload.Image.URL, payload.Image.Thumb.URL)
+}
@@ -0,0 +1,121 @@
+// Package imagehost 负责各图床共用的 HTTP 客户端、表单构造、响应解析和 URL 校验细节。
+
+package imagehost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxResponseBytes 限制读取图床响应体的大小，避免异常响应占用过多内存。
+const maxResponseBytes = 4 << 20
+
+// NewHTTPClient 构造图床上传使用的 HTTP 客户端，并按选项设置代理。
+func NewHTTPClient(options UploadOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	proxyURL := strings.TrimSpace(options.ProxyURL)
+	if proxyURL != "" {
+		parsed, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// NormalizeHTTPURL 会校验图床返回的 URL 是否是可直接用于前端展示的 HTTP 或 HTTPS 地址。
+func NormalizeHTTPURL(hostName, raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return "", fmt.Errorf("%s URL is empty", hostName)
+	}
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return "", err
+	}
+	result := parsed.String()
+	if !hasHTTPPrefix(result) {
+		return "", fmt.Errorf("%s URL is invalid", hostName)
+	}
+	return result, nil
+}
+
+// newLink 会校验直链和缩略图地址；缩略图为空时回退为直链。
+func newLink(hostName, directURL, thumbnailURL string) (Link, error) {
+	direct, err := NormalizeHTTPURL(hostName, directURL)
+	if err != nil {
+		return Link{}, err
+	}
+	if strings.TrimSpace(thumbnailURL) == "" {
+		return Link{URL: direct, ThumbnailURL: direct}, nil
+	}
+	thumbnail, err := NormalizeHTTPURL(hostName, thumbnailURL)
+	if err != nil {
+		return Link{}, err
+	}
+	return Link{URL: direct, ThumbnailURL: thumbnail}, nil
+}
+
+// multipartImageBody 会把图片文件和附加字段写入 multipart 表单，返回请求体和 Content-Type。
+func multipartImageBody(fileField, imagePath string, fields [][2]string) (*bytes.Buffer, string, error) {
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, field := range fields {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, "", err
+		}
+	}
+	part, err := writer.CreateFormFile(fileField, filepath.Base(imagePath))
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, writer.FormDataContentType(), nil
+}
+
+// doJSON 会发送请求并把 2xx 响应解码到 payload；非 2xx 响应会转换为带状态码的错误。
+func doJSON(client *http.Client, request *http.Request, hostName string, payload any) error {
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return NewStatusError(hostName, response)
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return fmt.Errorf("decode %s response: %w", hostName, err)
+	}
+	return nil
+}
+
+// errMissingSetting 会生成图床缺少必要配置时的错误。
+func errMissingSetting(hostName string, names ...string) error {
+	return errors.New(hostName + " is not configured: set " + strings.Join(names, ", "))
+}
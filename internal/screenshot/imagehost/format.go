@@ -0,0 +1,160 @@
+// Package imagehost 负责识别截图图片格式，并读取标准库无法解码的 WebP / AVIF 尺寸。
+
+package imagehost
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+)
+
+// 图片格式以小写带点的扩展名表示，与截图文件扩展名一致。
+const (
+	FormatPNG  = ".png"
+	FormatJPG  = ".jpg"
+	FormatGIF  = ".gif"
+	FormatWebP = ".webp"
+	FormatAVIF = ".avif"
+)
+
+// classicFormats 是几乎所有图床都接受的传统图片格式。
+var classicFormats = []string{FormatPNG, FormatJPG, FormatGIF}
+
+// avifSearchBytes 限制查找 AVIF ispe 尺寸属性时读取的文件头长度。
+const avifSearchBytes = 64 << 10
+
+// DetectImageFormat 会根据文件头识别图片格式；无法识别时返回空字符串。
+func DetectImageFormat(header []byte) string {
+	if isAVIFHeader(header) {
+		return FormatAVIF
+	}
+	switch http.DetectContentType(header) {
+	case "image/png":
+		return FormatPNG
+	case "image/jpeg":
+		return FormatJPG
+	case "image/gif":
+		return FormatGIF
+	case "image/webp":
+		return FormatWebP
+	default:
+		return ""
+	}
+}
+
+// ContentTypeForFormat 返回图片格式对应的 MIME 类型；未知格式返回 application/octet-stream。
+func ContentTypeForFormat(format string) string {
+	switch format {
+	case FormatPNG:
+		return "image/png"
+	case FormatJPG:
+		return "image/jpeg"
+	case FormatGIF:
+		return "image/gif"
+	case FormatWebP:
+		return "image/webp"
+	case FormatAVIF:
+		return "image/avif"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// detectImageFileFormat 会读取文件头识别本地图片格式；读取失败或无法识别时返回空字符串。
+func detectImageFileFormat(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return ""
+	}
+	return DetectImageFormat(header[:n])
+}
+
+// acceptsFormat 判断 format 是否在图床允许的格式列表中。
+func acceptsFormat(format string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if format == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// isAVIFHeader 判断文件头是否是 brand 为 avif / avis 的 ISO BMFF ftyp box。
+func isAVIFHeader(header []byte) bool {
+	if len(header) < 12 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+	brand := string(header[8:12])
+	return brand == "avif" || brand == "avis"
+}
+
+// readModernImageDimensions 会从 WebP 的 VP8 / VP8L / VP8X 头或 AVIF 的 ispe 属性中读取图片尺寸；失败时返回零值。
+func readModernImageDimensions(path, format string) (int, int) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	header := make([]byte, avifSearchBytes)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, 0
+	}
+	header = header[:n]
+
+	switch format {
+	case FormatWebP:
+		return webpDimensions(header)
+	case FormatAVIF:
+		return avifDimensions(header)
+	default:
+		return 0, 0
+	}
+}
+
+// webpDimensions 会解析 RIFF 容器中第一个图像块记录的画布尺寸。
+func webpDimensions(header []byte) (int, int) {
+	if len(header) < 30 || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WEBP" {
+		return 0, 0
+	}
+	switch string(header[12:16]) {
+	case "VP8 ":
+		width := int(binary.LittleEndian.Uint16(header[26:28]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(header[28:30]) & 0x3fff)
+		return width, height
+	case "VP8L":
+		if header[20] != 0x2f {
+			return 0, 0
+		}
+		bits := binary.LittleEndian.Uint32(header[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1
+	case "VP8X":
+		width := int(header[24]) | int(header[25])<<8 | int(header[26])<<16
+		height := int(header[27]) | int(header[28])<<8 | int(header[29])<<16
+		return width + 1, height + 1
+	default:
+		return 0, 0
+	}
+}
+
+// avifDimensions 会查找第一个 ispe（图像空间尺寸）属性并读取其中的宽高。
+func avifDimensions(header []byte) (int, int) {
+	index := bytes.Index(header, []byte("ispe"))
+	if index < 0 || len(header) < index+16 {
+		return 0, 0
+	}
+	width := binary.BigEndian.Uint32(header[index+8 : index+12])
+	height := binary.BigEndian.Uint32(header[index+12 : index+16])
+	return int(width), int(height)
+}
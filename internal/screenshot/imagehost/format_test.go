@@ -0,0 +1,77 @@
+package imagehost
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func webpVP8LHeader(width, height int) []byte {
+	header := make([]byte, 32)
+	copy(header[0:], "RIFF")
+	copy(header[8:], "WEBP")
+	copy(header[12:], "VP8L")
+	header[20] = 0x2f
+	binary.LittleEndian.PutUint32(header[21:25], uint32(width-1)|uint32(height-1)<<14)
+	return header
+}
+
+func avifHeader(width, height int) []byte {
+	header := []byte{0, 0, 0, 20, 'f', 't', 'y', 'p', 'a', 'v', 'i', 'f', 0, 0, 0, 0, 'm', 'i', 'f', '1'}
+	header = append(header, 0, 0, 0, 20, 'i', 's', 'p', 'e', 0, 0, 0, 0)
+	header = binary.BigEndian.AppendUint32(header, uint32(width))
+	return binary.BigEndian.AppendUint32(header, uint32(height))
+}
+
+func TestDetectImageFormatAndModernDimensions(t *testing.T) {
+	vp8x := make([]byte, 32)
+	copy(vp8x[0:], "RIFF")
+	copy(vp8x[8:], "WEBP")
+	copy(vp8x[12:], "VP8X")
+	vp8x[24], vp8x[25] = 0xff, 0x0e // 3839
+	vp8x[27], vp8x[28] = 0x6f, 0x08 // 2159
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		width  int
+		height int
+	}{
+		{name: "webp lossless", data: webpVP8LHeader(3840, 2160), format: FormatWebP, width: 3840, height: 2160},
+		{name: "webp extended", data: vp8x, format: FormatWebP, width: 3840, height: 2160},
+		{name: "avif", data: avifHeader(1920, 1080), format: FormatAVIF, width: 1920, height: 1080},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectImageFormat(tt.data); got != tt.format {
+				t.Fatalf("DetectImageFormat() = %q, want %q", got, tt.format)
+			}
+			path := filepath.Join(t.TempDir(), "shot"+tt.format)
+			if err := os.WriteFile(path, tt.data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if width, height := readImageDimensions(path); width != tt.width || height != tt.height {
+				t.Fatalf("readImageDimensions() = %dx%d, want %dx%d", width, height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestUploadImagesSkipsFormatsTheHostRejects(t *testing.T) {
+	t.Setenv("PTPIMG_API_KEY", "key")
+	host, err := NewPtpimg()
+	if err != nil {
+		t.Fatalf("NewPtpimg() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "shot.webp")
+	if err := os.WriteFile(path, webpVP8LHeader(16, 9), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := UploadImages(context.Background(), host, []string{path}, nil, UploadOptions{}, nil, nil, nil); err == nil {
+		t.Fatal("UploadImages() error = nil, want no uploadable screenshots for webp on ptpimg")
+	}
+}
@@ -0,0 +1,181 @@
+// Package imagehost 提供 imgbox 风格、基于网页表单会话的匿名图床上传实现。
+
+package imagehost
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"minfo/internal/config"
+)
+
+const (
+	defaultImgboxURL      = "https://imgbox.com"
+	defaultImgboxMaxBytes = 10 * 1024 * 1024
+)
+
+// csrfTokenPattern 用于从 imgbox 首页的 meta 标签中提取 CSRF token，兼容两种属性顺序。
+var csrfTokenPattern = regexp.MustCompile(`<meta\s+(?:content="([^"]+)"\s+name="csrf-token"|name="csrf-token"\s+content="([^"]+)")`)
+
+// imgbox 先打开首页获取 Cookie 和 CSRF token，再申请上传令牌并逐张提交表单。
+// 同一个实例内的上传共享一次会话，会话在首次上传时建立。
+type imgbox struct {
+	baseURL  string
+	maxBytes int64
+
+	mu      sync.Mutex
+	session *imgboxSession
+}
+
+// imgboxSession 保存一次 imgbox 上传会话的 Cookie、CSRF token 和上传令牌。
+type imgboxSession struct {
+	client      *http.Client
+	csrfToken   string
+	tokenID     jsonText
+	tokenSecret jsonText
+}
+
+// imgboxTokenResponse 描述 /ajax/token/generate 响应中当前流程使用的字段。
+type imgboxTokenResponse struct {
+	TokenID     jsonText `json:"token_id"`
+	TokenSecret jsonText `json:"token_secret"`
+}
+
+// imgboxUploadResponse 描述 /upload/process 响应中当前流程使用的字段。
+type imgboxUploadResponse struct {
+	Files []struct {
+		OriginalURL  string `json:"original_url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	} `json:"files"`
+}
+
+// jsonText 可以同时解码 JSON 字符串和数字，imgbox 的令牌字段在不同版本中类型不一致。
+type jsonText string
+
+func (t *jsonText) UnmarshalJSON(data []byte) error {
+	*t = jsonText(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// NewImgbox 会根据可选的 IMGBOX_URL 创建 imgbox 风格图床；该图床无需账号配置。
+func NewImgbox() (Host, error) {
+	return &imgbox{
+		baseURL:  strings.TrimRight(config.Getenv("IMGBOX_URL", defaultImgboxURL), "/"),
+		maxBytes: int64(config.IntFromEnv("IMGBOX_MAX_BYTES", defaultImgboxMaxBytes)),
+	}, nil
+}
+
+func (h *imgbox) Name() string { return "imgbox" }
+
+func (h *imgbox) MaxUploadBytes() int64 { return h.maxBytes }
+
+// AcceptsFormat 只接受 imgbox 支持的 PNG、JPG 和 GIF。
+func (h *imgbox) AcceptsFormat(format string) bool { return acceptsFormat(format, classicFormats...) }
+
+// Upload 会在需要时建立上传会话，再以 files[] 字段提交单张图片。
+func (h *imgbox) Upload(ctx context.Context, client *http.Client, imagePath string) (Link, error) {
+	session, err := h.ensureSession(ctx, client)
+	if err != nil {
+		return Link{}, err
+	}
+
+	body, contentType, err := multipartImageBody("files[]", imagePath, [][2]string{
+		{"token_id", string(session.tokenID)},
+		{"token_secret", string(session.tokenSecret)},
+		{"content_type", "1"},
+		{"thumbnail_size", "350r"},
+		{"gallery_id", "null"},
+		{"gallery_secret", "null"},
+		{"comments_enabled", "0"},
+	})
+	if err != nil {
+		return Link{}, err
+	}
+	request, err := h.newRequest(ctx, session, http.MethodPost, "/upload/process", body)
+	if err != nil {
+		return Link{}, err
+	}
+	request.Header.Set("Content-Type", contentType)
+
+	var payload imgboxUploadResponse
+	if err := doJSON(session.client, request, h.Name(), &payload); err != nil {
+		return Link{}, err
+	}
+	if len(payload.Files) == 0 {
+		return Link{}, errors.New("imgbox response is missing files")
+	}
+	return newLink(h.Name(), payload.Files[0].OriginalURL, payload.Files[0].ThumbnailURL)
+}
+
+// ensureSession 会复用已建立的会话，或打开首页并申请新的上传令牌。
+func (h *imgbox) ensureSession(ctx context.Context, client *http.Client) (*imgboxSession, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.session != nil {
+		return h.session, nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	session := &imgboxSession{client: &http.Client{Transport: client.Transport, Timeout: client.Timeout, Jar: jar}}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, h.baseURL+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := session.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	page, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBytes))
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	matches := csrfTokenPattern.FindSubmatch(page)
+	if matches == nil {
+		return nil, errors.New("imgbox page is missing csrf token")
+	}
+	session.csrfToken = string(matches[1]) + string(matches[2])
+
+	form := url.Values{"gallery": {"false"}, "gallery_title": {""}, "comments_enabled": {"0"}}
+	request, err = h.newRequest(ctx, session, http.MethodPost, "/ajax/token/generate", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token imgboxTokenResponse
+	if err := doJSON(session.client, request, h.Name(), &token); err != nil {
+		return nil, err
+	}
+	if token.TokenID == "" || token.TokenSecret == "" {
+		return nil, errors.New("imgbox token response is missing token_id or token_secret")
+	}
+	session.tokenID = token.TokenID
+	session.tokenSecret = token.TokenSecret
+	h.session = session
+	return session, nil
+}
+
+// newRequest 会创建带 CSRF token 和 AJAX 请求头的 imgbox 请求。
+func (h *imgbox) newRequest(ctx context.Context, session *imgboxSession, method, path string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, h.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Referer", h.baseURL+"/")
+	request.Header.Set("X-CSRF-Token", session.csrfToken)
+	request.Header.Set("X-Requested-With", "XMLHttpRequest")
+	return request, nil
+}
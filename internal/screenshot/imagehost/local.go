@@ -0,0 +1,167 @@
+// Package imagehost 提供把截图保存在 minfo 自身并通过 /i/ 路径对外提供的本地图床实现。
+
+package imagehost
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"minfo/internal/config"
+	"minfo/internal/system"
+)
+
+const (
+	// LocalImagePathPrefix 是本地图床图片对外提供的 URL 路径前缀。
+	LocalImagePathPrefix = "/i/"
+
+	defaultLocalMaxBytes = 50 * 1024 * 1024
+)
+
+// local 把图片按内容哈希保存到本地图床目录，并同时生成缩略图；重复上传同一图片会复用已有文件。
+type local struct {
+	baseURL  string
+	secret   string
+	ttl      time.Duration
+	maxBytes int64
+	now      func() time.Time
+}
+
+// NewLocal 会根据 LOCAL_IMAGE_URL 创建本地图床；设置 LOCAL_IMAGE_TTL 时还需要 LOCAL_IMAGE_SECRET 为链接签名。
+// 本地图床目录由服务启动时的 ConfigureLocalStore 指定，未配置时返回错误。
+func NewLocal() (Host, error) {
+	if localStoreDir() == "" {
+		return nil, errors.New("local image store is not configured")
+	}
+	baseURL := strings.TrimRight(config.Getenv("LOCAL_IMAGE_URL", ""), "/")
+	if baseURL == "" {
+		return nil, errMissingSetting("local", "LOCAL_IMAGE_URL")
+	}
+	if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, errors.New("invalid LOCAL_IMAGE_URL: " + baseURL)
+	}
+	ttl := localImageTTL()
+	secret := LocalImageSecret()
+	if ttl > 0 && secret == "" {
+		return nil, errMissingSetting("local", "LOCAL_IMAGE_SECRET")
+	}
+	return &local{
+		baseURL:  baseURL,
+		secret:   secret,
+		ttl:      ttl,
+		maxBytes: int64(config.IntFromEnv("LOCAL_IMAGE_MAX_BYTES", defaultLocalMaxBytes)),
+		now:      time.Now,
+	}, nil
+}
+
+// LocalImageSecret 返回校验本地图床链接签名使用的密钥；未设置 LOCAL_IMAGE_TTL 时链接不过期，返回空字符串。
+func LocalImageSecret() string {
+	if localImageTTL() <= 0 {
+		return ""
+	}
+	return config.Getenv("LOCAL_IMAGE_SECRET", "")
+}
+
+// localImageTTL 返回本地图床链接的有效期；未设置时为 0，表示链接永久有效。
+func localImageTTL() time.Duration {
+	if config.Getenv("LOCAL_IMAGE_TTL", "") == "" {
+		return 0
+	}
+	return config.DurationFromEnv("LOCAL_IMAGE_TTL", 0)
+}
+
+func (h *local) Name() string { return "local" }
+
+func (h *local) MaxUploadBytes() int64 { return h.maxBytes }
+
+// AcceptsFormat 接受 PNG、JPG、WebP 和 AVIF 截图。
+func (h *local) AcceptsFormat(format string) bool {
+	return acceptsFormat(format, FormatPNG, FormatJPG, FormatWebP, FormatAVIF)
+}
+
+// Upload 会把图片复制到本地图床目录并生成缩略图，返回 /i/<hash>.<ext> 形式的直链和缩略图地址。
+func (h *local) Upload(ctx context.Context, _ *http.Client, imagePath string) (Link, error) {
+	if err := ctx.Err(); err != nil {
+		return Link{}, err
+	}
+	dir := localStoreDir()
+	if dir == "" {
+		return Link{}, errors.New("local image store is not configured")
+	}
+
+	file, err := os.Open(imagePath)
+	if err != nil {
+		return Link{}, err
+	}
+	defer file.Close()
+
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Link{}, err
+	}
+	ext := DetectImageFormat(header[:n])
+	if !h.AcceptsFormat(ext) {
+		return Link{}, errors.New("local image host only accepts png, jpg, webp or avif images")
+	}
+
+	hash := sha256.New()
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return Link{}, err
+	}
+	if _, err := io.Copy(hash, file); err != nil {
+		return Link{}, err
+	}
+	id := hex.EncodeToString(hash.Sum(nil))[:32]
+	name := id + ext
+	thumbnailName := id + localThumbnailSuffix
+
+	imageFile := filepath.Join(dir, name)
+	if _, err := os.Stat(imageFile); err != nil {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return Link{}, err
+		}
+		if err := system.WriteFileAtomic(imageFile, 0o644, func(out io.Writer) error {
+			_, err := io.Copy(out, file)
+			return err
+		}); err != nil {
+			return Link{}, err
+		}
+	}
+	if ext == FormatWebP || ext == FormatAVIF {
+		// 标准库无法解码 WebP / AVIF，这两种格式直接以原图作为缩略图。
+		direct := h.publicURL(name)
+		return newLink(h.Name(), direct, direct)
+	}
+	thumbnailFile := filepath.Join(dir, thumbnailName)
+	if _, err := os.Stat(thumbnailFile); err != nil {
+		if err := writeLocalThumbnail(imageFile, thumbnailFile); err != nil {
+			return Link{}, err
+		}
+	}
+	return newLink(h.Name(), h.publicURL(name), h.publicURL(thumbnailName))
+}
+
+// publicURL 会拼出图片的对外地址；启用有效期时附带 expires 和 signature 查询参数。
+// 到期时间在上传时写入链接，之后不会续期；链接过期后重新上传同一图片会复用已保存的文件，并返回新签名的链接。
+func (h *local) publicURL(name string) string {
+	link := h.baseURL + LocalImagePathPrefix + name
+	if h.ttl <= 0 {
+		return link
+	}
+	expiresAt := h.now().Add(h.ttl).Unix()
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expiresAt, 10)},
+		"signature": {signLocalImage(h.secret, name, expiresAt)},
+	}
+	return link + "?" + query.Encode()
+}
@@ -0,0 +1,152 @@
+// Package imagehost 提供本地图床的持久化存储、签名校验和缩略图生成。
+
+package imagehost
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"minfo/internal/system"
+)
+
+const (
+	// localThumbnailMaxSide 是本地图床缩略图长边的最大像素数，与 Pixhost 的 max_th_size 保持一致。
+	localThumbnailMaxSide = 420
+	localThumbnailQuality = 85
+	localThumbnailSuffix  = ".th.jpg"
+)
+
+var (
+	// ErrLocalImageNotFound 表示请求的本地图床图片不存在或名称无效。
+	ErrLocalImageNotFound = errors.New("image not found")
+	// ErrLocalImageExpired 表示本地图床链接签名无效或已过期。
+	ErrLocalImageExpired = errors.New("image link is invalid or expired")
+)
+
+// localImageNamePattern 匹配本地图床对外暴露的文件名：内容哈希加原图扩展名，或缩略图后缀。
+var localImageNamePattern = regexp.MustCompile(`^[0-9a-f]{32}(\.png|\.jpg|\.webp|\.avif|\.th\.jpg)$`)
+
+// localStore 保存本地图床目录；未配置时本地图床不可用。
+var localStore = struct {
+	mu  sync.RWMutex
+	dir string
+}{}
+
+// ConfigureLocalStore 会把本地图床图片保存到指定目录，目录在服务重启后继续提供已上传的图片。
+func ConfigureLocalStore(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	localStore.mu.Lock()
+	localStore.dir = dir
+	localStore.mu.Unlock()
+	return nil
+}
+
+// localStoreDir 返回已配置的本地图床目录。
+func localStoreDir() string {
+	localStore.mu.RLock()
+	defer localStore.mu.RUnlock()
+	return localStore.dir
+}
+
+// OpenLocalImage 会校验文件名和可选的过期签名，并打开本地图床中的图片。
+// secret 为空时不校验签名；否则 expires 必须是未过期的 Unix 时间戳，signature 必须与之匹配。
+func OpenLocalImage(name, expires, signature, secret string, now time.Time) (*os.File, error) {
+	dir := localStoreDir()
+	if dir == "" || !localImageNamePattern.MatchString(name) {
+		return nil, ErrLocalImageNotFound
+	}
+	if secret != "" {
+		expiresAt, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || now.Unix() > expiresAt || !hmac.Equal([]byte(signature), []byte(signLocalImage(secret, name, expiresAt))) {
+			return nil, ErrLocalImageExpired
+		}
+	}
+
+	file, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrLocalImageNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// signLocalImage 会计算本地图床文件名和过期时间的 HMAC-SHA256 签名。
+func signLocalImage(secret, name string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(name + "\n" + strconv.FormatInt(expiresAt, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// writeLocalThumbnail 会把原图按长边缩放到缩略图尺寸，并编码为 JPEG 写入 path。
+func writeLocalThumbnail(sourcePath, path string) error {
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	source, _, err := image.Decode(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	thumbnail := scaleImage(source, localThumbnailMaxSide)
+	return system.WriteFileAtomic(path, 0o644, func(out io.Writer) error {
+		return jpeg.Encode(out, thumbnail, &jpeg.Options{Quality: localThumbnailQuality})
+	})
+}
+
+// scaleImage 会按区域平均把图片缩小到长边不超过 maxSide；原图更小时保持原尺寸。
+func scaleImage(source image.Image, maxSide int) image.Image {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	targetWidth, targetHeight := width, height
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			targetWidth, targetHeight = maxSide, max(1, height*maxSide/width)
+		} else {
+			targetWidth, targetHeight = max(1, width*maxSide/height), maxSide
+		}
+	}
+
+	target := image.NewRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	for y := 0; y < targetHeight; y++ {
+		top := bounds.Min.Y + y*height/targetHeight
+		bottom := max(top+1, bounds.Min.Y+(y+1)*height/targetHeight)
+		for x := 0; x < targetWidth; x++ {
+			left := bounds.Min.X + x*width/targetWidth
+			right := max(left+1, bounds.Min.X+(x+1)*width/targetWidth)
+
+			var r, g, b, a, count uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					cr, cg, cb, ca := source.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					count++
+				}
+			}
+			target.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+	return target
+}
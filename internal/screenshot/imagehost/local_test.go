@@ -0,0 +1,143 @@
+package imagehost
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalUploadStoresImageAndSignsLinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := ConfigureLocalStore(dir); err != nil {
+		t.Fatalf("ConfigureLocalStore() error = %v", err)
+	}
+	t.Cleanup(func() {
+		localStore.mu.Lock()
+		localStore.dir = ""
+		localStore.mu.Unlock()
+	})
+	t.Setenv("LOCAL_IMAGE_URL", "https://minfo.example/")
+	t.Setenv("LOCAL_IMAGE_TTL", "1h")
+	t.Setenv("LOCAL_IMAGE_SECRET", "")
+	if _, err := NewLocal(); err == nil {
+		t.Fatal("NewLocal() error = nil, want missing secret error")
+	}
+	t.Setenv("LOCAL_IMAGE_SECRET", "secret")
+
+	host, err := NewLocal()
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	host.(*local).now = func() time.Time { return now }
+
+	link, err := host.Upload(context.Background(), nil, writeTestPNG(t))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	direct, err := url.Parse(link.URL)
+	if err != nil || direct.Host != "minfo.example" || !strings.HasPrefix(direct.Path, LocalImagePathPrefix) || path.Ext(direct.Path) != ".png" {
+		t.Fatalf("URL = %q, want https://minfo.example/i/<hash>.png", link.URL)
+	}
+	thumbnail, err := url.Parse(link.ThumbnailURL)
+	if err != nil || !strings.HasSuffix(thumbnail.Path, localThumbnailSuffix) {
+		t.Fatalf("ThumbnailURL = %q, want thumbnail suffix", link.ThumbnailURL)
+	}
+
+	name := strings.TrimPrefix(direct.Path, LocalImagePathPrefix)
+	expires, signature := direct.Query().Get("expires"), direct.Query().Get("signature")
+	file, err := OpenLocalImage(name, expires, signature, LocalImageSecret(), now)
+	if err != nil {
+		t.Fatalf("OpenLocalImage() error = %v", err)
+	}
+	file.Close()
+	if _, err := OpenLocalImage(name, expires, signature, LocalImageSecret(), now.Add(2*time.Hour)); !errors.Is(err, ErrLocalImageExpired) {
+		t.Fatalf("OpenLocalImage(expired) error = %v, want ErrLocalImageExpired", err)
+	}
+	if _, err := OpenLocalImage(name, expires, "bad", LocalImageSecret(), now); !errors.Is(err, ErrLocalImageExpired) {
+		t.Fatalf("OpenLocalImage(bad signature) error = %v, want ErrLocalImageExpired", err)
+	}
+	if _, err := OpenLocalImage("../"+name, expires, signature, LocalImageSecret(), now); !errors.Is(err, ErrLocalImageNotFound) {
+		t.Fatalf("OpenLocalImage(traversal) error = %v, want ErrLocalImageNotFound", err)
+	}
+
+	thumbFile, err := os.Open(filepath.Join(dir, strings.TrimPrefix(thumbnail.Path, LocalImagePathPrefix)))
+	if err != nil {
+		t.Fatalf("open thumbnail: %v", err)
+	}
+	defer thumbFile.Close()
+	if _, err := jpeg.DecodeConfig(thumbFile); err != nil {
+		t.Fatalf("thumbnail is not a jpeg: %v", err)
+	}
+}
+
+func TestLocalUploadUsesOriginalAsWebPThumbnailAndResignsOnReupload(t *testing.T) {
+	dir := t.TempDir()
+	if err := ConfigureLocalStore(dir); err != nil {
+		t.Fatalf("ConfigureLocalStore() error = %v", err)
+	}
+	t.Cleanup(func() {
+		localStore.mu.Lock()
+		localStore.dir = ""
+		localStore.mu.Unlock()
+	})
+	t.Setenv("LOCAL_IMAGE_URL", "https://minfo.example")
+	t.Setenv("LOCAL_IMAGE_TTL", "1h")
+	t.Setenv("LOCAL_IMAGE_SECRET", "secret")
+
+	host, err := NewLocal()
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	host.(*local).now = func() time.Time { return now }
+
+	imagePath := filepath.Join(t.TempDir(), "shot.webp")
+	if err := os.WriteFile(imagePath, webpVP8LHeader(4, 4), 0o644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+	link, err := host.Upload(context.Background(), nil, imagePath)
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if link.ThumbnailURL != link.URL || !strings.Contains(link.URL, ".webp?") {
+		t.Fatalf("link = %+v, want the signed original as thumbnail", link)
+	}
+
+	now = now.Add(2 * time.Hour)
+	resigned, err := host.Upload(context.Background(), nil, imagePath)
+	if err != nil {
+		t.Fatalf("Upload() again error = %v", err)
+	}
+	first, _ := url.Parse(link.URL)
+	second, _ := url.Parse(resigned.URL)
+	if first.Path != second.Path || first.Query().Get("expires") == second.Query().Get("expires") {
+		t.Fatalf("re-upload URL = %q, want same file %q with a new expiry", resigned.URL, link.URL)
+	}
+	name := strings.TrimPrefix(second.Path, LocalImagePathPrefix)
+	file, err := OpenLocalImage(name, second.Query().Get("expires"), second.Query().Get("signature"), LocalImageSecret(), now)
+	if err != nil {
+		t.Fatalf("OpenLocalImage() error = %v", err)
+	}
+	file.Close()
+}
+
+func TestScaleImageKeepsAspectRatio(t *testing.T) {
+	source := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
+	bounds := scaleImage(source, localThumbnailMaxSide).Bounds()
+	if bounds.Dx() != 420 || bounds.Dy() != 236 {
+		t.Fatalf("scaled size = %dx%d, want 420x236", bounds.Dx(), bounds.Dy())
+	}
+	small := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	if bounds := scaleImage(small, localThumbnailMaxSide).Bounds(); bounds.Dx() != 100 || bounds.Dy() != 50 {
+		t.Fatalf("small image size = %dx%d, want unchanged", bounds.Dx(), bounds.Dy())
+	}
+}
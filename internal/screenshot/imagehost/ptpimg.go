@@ -0,0 +1,73 @@
+// Package imagehost 提供 ptpimg 风格、以 API Key 鉴权的图床上传实现。
+
+package imagehost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"minfo/internal/config"
+)
+
+const (
+	defaultPtpimgURL      = "https://ptpimg.me"
+	defaultPtpimgMaxBytes = 10 * 1024 * 1024
+)
+
+// ptpimg 通过 upload.php 接口上传图片，并按返回的 code 和扩展名拼出直链。
+type ptpimg struct {
+	baseURL  string
+	apiKey   string
+	maxBytes int64
+}
+
+// ptpimgResponse 描述 ptpimg 上传响应数组中的单个元素。
+type ptpimgResponse struct {
+	Code string `json:"code"`
+	Ext  string `json:"ext"`
+}
+
+// NewPtpimg 会根据 PTPIMG_API_KEY 和可选的 PTPIMG_URL 创建 ptpimg 图床；缺少 API Key 时返回错误。
+func NewPtpimg() (Host, error) {
+	apiKey := config.Getenv("PTPIMG_API_KEY", "")
+	if apiKey == "" {
+		return nil, errMissingSetting("ptpimg", "PTPIMG_API_KEY")
+	}
+	return &ptpimg{
+		baseURL:  strings.TrimRight(config.Getenv("PTPIMG_URL", defaultPtpimgURL), "/"),
+		apiKey:   apiKey,
+		maxBytes: int64(config.IntFromEnv("PTPIMG_MAX_BYTES", defaultPtpimgMaxBytes)),
+	}, nil
+}
+
+func (h *ptpimg) Name() string { return "ptpimg" }
+
+func (h *ptpimg) MaxUploadBytes() int64 { return h.maxBytes }
+
+// AcceptsFormat 只接受 ptpimg 支持的 PNG、JPG 和 GIF。
+func (h *ptpimg) AcceptsFormat(format string) bool { return acceptsFormat(format, classicFormats...) }
+
+// Upload 会以 file-upload[0] 字段上传图片；ptpimg 不提供缩略图，缩略图地址与直链相同。
+func (h *ptpimg) Upload(ctx context.Context, client *http.Client, imagePath string) (Link, error) {
+	body, contentType, err := multipartImageBody("file-upload[0]", imagePath, [][2]string{{"api_key", h.apiKey}})
+	if err != nil {
+		return Link{}, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL+"/upload.php", body)
+	if err != nil {
+		return Link{}, err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Content-Type", contentType)
+
+	var payload []ptpimgResponse
+	if err := doJSON(client, request, h.Name(), &payload); err != nil {
+		return Link{}, err
+	}
+	if len(payload) == 0 || strings.TrimSpace(payload[0].Code) == "" || strings.TrimSpace(payload[0].Ext) == "" {
+		return Link{}, errors.New("ptpimg response is missing code or ext")
+	}
+	return newLink(h.Name(), h.baseURL+"/"+payload[0].Code+"."+payload[0].Ext, "")
+}
@@ -0,0 +1,116 @@
+// Package imagehost 负责单张图片上传失败后的指数退避重试。
+
+package imagehost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts 是单张图片默认的最大上传次数，包含首次上传。
+	DefaultMaxAttempts = 3
+
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// StatusError 表示图床返回了非 2xx 状态码；RetryAfter 来自响应的 Retry-After 头，缺失时为 0。
+type StatusError struct {
+	Host       string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned HTTP %d", e.Host, e.StatusCode)
+}
+
+// NewStatusError 会根据图床响应创建 StatusError，并解析其中的 Retry-After 头。
+func NewStatusError(hostName string, response *http.Response) *StatusError {
+	return &StatusError{
+		Host:       hostName,
+		StatusCode: response.StatusCode,
+		RetryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter 会解析秒数或 HTTP 日期形式的 Retry-After 头；无法解析时返回 0。
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// isRetryableUploadError 判断上传错误是否值得重试：429、5xx 和网络层错误会重试，其余 4xx 和响应格式错误不会。
+func isRetryableUploadError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retryDelay 返回第 attempt 次失败后的等待时间：按 1s、2s、4s… 指数增长，图床给出 Retry-After 时优先使用，均不超过 30s。
+func retryDelay(attempt int, err error) time.Duration {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, retryMaxDelay)
+	}
+	delay := retryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// sleepContext 会等待 delay 或直到 ctx 结束；测试可替换为不等待的实现。
+var sleepContext = func(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// uploadWithRetry 会上传单张图片，遇到可重试的错误时按指数退避重试，最多尝试 maxAttempts 次。
+func uploadWithRetry(ctx context.Context, host Host, client *http.Client, imagePath string, maxAttempts int, batch *uploadBatch) (Link, error) {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		link, err := host.Upload(ctx, client, imagePath)
+		if err == nil || attempt >= maxAttempts || !isRetryableUploadError(err) {
+			return link, err
+		}
+		delay := retryDelay(attempt, err)
+		batch.recordRetry(imagePath, err, delay, attempt+1, maxAttempts)
+		if err := sleepContext(ctx, delay); err != nil {
+			return Link{}, err
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package imagehost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type scriptedHost struct {
+	errors map[string][]error
+	calls  map[string]int
+}
+
+func (h *scriptedHost) Name() string              { return "scripted" }
+func (h *scriptedHost) MaxUploadBytes() int64     { return 0 }
+func (h *scriptedHost) AcceptsFormat(string) bool { return true }
+
+func (h *scriptedHost) Upload(_ context.Context, _ *http.Client, imagePath string) (Link, error) {
+	name := filepath.Base(imagePath)
+	call := h.calls[name]
+	h.calls[name]++
+	if script := h.errors[name]; call < len(script) && script[call] != nil {
+		return Link{}, script[call]
+	}
+	return Link{URL: "https://img.example/" + name}, nil
+}
+
+func TestUploadImagesRetriesTransientErrorsAndReportsFailures(t *testing.T) {
+	var delays []time.Duration
+	previous := sleepContext
+	sleepContext = func(_ context.Context, delay time.Duration) error {
+		delays = append(delays, delay)
+		return nil
+	}
+	t.Cleanup(func() { sleepContext = previous })
+
+	first := writeTestPNG(t)
+	second := filepath.Join(filepath.Dir(first), "shot 02.png")
+	data, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	if err := os.WriteFile(second, data, 0o644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+
+	host := &scriptedHost{
+		errors: map[string][]error{
+			"shot 01.png": {
+				&StatusError{Host: "scripted", StatusCode: http.StatusServiceUnavailable},
+				&StatusError{Host: "scripted", StatusCode: http.StatusTooManyRequests, RetryAfter: 5 * time.Second},
+			},
+			"shot 02.png": {&StatusError{Host: "scripted", StatusCode: http.StatusBadRequest}},
+		},
+		calls: map[string]int{},
+	}
+	result, err := UploadImages(context.Background(), host, []string{first, second}, nil, UploadOptions{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("UploadImages() error = %v", err)
+	}
+	if host.calls["shot 01.png"] != 3 || host.calls["shot 02.png"] != 1 {
+		t.Fatalf("calls = %v, want 3 attempts for transient errors and 1 for HTTP 400", host.calls)
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != 5*time.Second {
+		t.Fatalf("delays = %v, want [1s 5s]", delays)
+	}
+	if len(result.Items) != 1 || result.Items[0].Filename != "shot 01.png" {
+		t.Fatalf("items = %+v, want shot 01.png", result.Items)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Path != second || result.Failed[0].Error != "scripted returned HTTP 400" {
+		t.Fatalf("failed = %+v, want shot 02.png with HTTP 400", result.Failed)
+	}
+}
+
+type limitedHost struct {
+	scriptedHost
+	maxBytes int64
+}
+
+func (h *limitedHost) MaxUploadBytes() int64            { return h.maxBytes }
+func (h *limitedHost) AcceptsFormat(format string) bool { return format == FormatPNG }
+
+func TestUploadImagesReportsImagesSkippedByHostLimits(t *testing.T) {
+	small := writeTestPNG(t)
+	data, err := os.ReadFile(small)
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	dir := filepath.Dir(small)
+	large := filepath.Join(dir, "shot 02.png")
+	if err := os.WriteFile(large, append(data, make([]byte, 64)...), 0o644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+	text := filepath.Join(dir, "shot 03.png")
+	if err := os.WriteFile(text, []byte("not an image"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	host := &limitedHost{scriptedHost: scriptedHost{calls: map[string]int{}}, maxBytes: int64(len(data))}
+	result, err := UploadImages(context.Background(), host, []string{small, large, text}, nil, UploadOptions{}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("UploadImages() error = %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Filename != "shot 01.png" {
+		t.Fatalf("items = %+v, want shot 01.png", result.Items)
+	}
+	want := []FailedImage{
+		{Path: large, Filename: "shot 02.png", Error: "exceeds host limit"},
+		{Path: text, Filename: "shot 03.png", Error: "format not accepted"},
+	}
+	if len(result.Failed) != len(want) || result.Failed[0] != want[0] || result.Failed[1] != want[1] {
+		t.Fatalf("failed = %+v, want %+v", result.Failed, want)
+	}
+	if host.calls["shot 02.png"] != 0 || host.calls["shot 03.png"] != 0 {
+		t.Fatalf("calls = %v, want skipped images not uploaded", host.calls)
+	}
+}
+
+// writeLimitedTestImages 会写入一张可上传的 PNG、一张超过大小上限的 PNG 和 extra 张可上传副本，返回全部路径和上限字节数。
+func writeLimitedTestImages(t *testing.T, extra int) ([]string, int64) {
+	t.Helper()
+	small := writeTestPNG(t)
+	data, err := os.ReadFile(small)
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	dir := filepath.Dir(small)
+	large := filepath.Join(dir, "shot 02.png")
+	if err := os.WriteFile(large, append(data, make([]byte, 64)...), 0o644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+	paths := []string{small, large}
+	for index := 0; index < extra; index++ {
+		path := filepath.Join(dir, fmt.Sprintf("shot %02d.png", index+3))
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("write image: %v", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, int64(len(data))
+}
+
+func TestUploadImagesReportsSkippedAndPendingImagesOnInvalidProxy(t *testing.T) {
+	paths, maxBytes := writeLimitedTestImages(t, 0)
+	host := &limitedHost{scriptedHost: scriptedHost{calls: map[string]int{}}, maxBytes: maxBytes}
+
+	result, err := UploadImages(context.Background(), host, paths, nil, UploadOptions{ProxyURL: "://bad"}, nil, nil, nil)
+	if err == nil {
+		t.Fatal("UploadImages() error = nil, want invalid proxy error")
+	}
+	if len(host.calls) != 0 {
+		t.Fatalf("calls = %v, want no uploads with an invalid proxy", host.calls)
+	}
+	if len(result.Failed) != 2 || result.Failed[0].Path != paths[1] || result.Failed[0].Error != "exceeds host limit" ||
+		result.Failed[1].Path != paths[0] || result.Failed[1].Error != err.Error() {
+		t.Fatalf("failed = %+v, want skipped shot 02.png and unattempted shot 01.png", result.Failed)
+	}
+}
+
+// cancelingHost 会在第一次上传成功后取消批次的 ctx。
+type cancelingHost struct {
+	limitedHost
+	cancel context.CancelFunc
+}
+
+func (h *cancelingHost) Upload(ctx context.Context, client *http.Client, imagePath string) (Link, error) {
+	link, err := h.limitedHost.Upload(ctx, client, imagePath)
+	h.cancel()
+	return link, err
+}
+
+func TestUploadImagesReportsSkippedAndPendingImagesOnCancel(t *testing.T) {
+	paths, maxBytes := writeLimitedTestImages(t, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	host := &cancelingHost{limitedHost: limitedHost{scriptedHost: scriptedHost{calls: map[string]int{}}, maxBytes: maxBytes}, cancel: cancel}
+
+	result, err := UploadImages(ctx, host, paths, nil, UploadOptions{}, nil, nil, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("UploadImages() error = %v, want context.Canceled", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Filename != "shot 01.png" {
+		t.Fatalf("items = %+v, want shot 01.png uploaded before cancel", result.Items)
+	}
+	want := []FailedImage{
+		{Path: paths[1], Filename: "shot 02.png", Error: "exceeds host limit"},
+		{Path: paths[2], Filename: "shot 03.png", Error: context.Canceled.Error()},
+		{Path: paths[3], Filename: "shot 04.png", Error: context.Canceled.Error()},
+	}
+	if len(result.Failed) != len(want) || result.Failed[0] != want[0] || result.Failed[1] != want[1] || result.Failed[2] != want[2] {
+		t.Fatalf("failed = %+v, want %+v", result.Failed, want)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":     0,
+		"7":    7 * time.Second,
+		"soon": 0,
+		now.Add(90 * time.Second).Format(http.TimeFormat): 90 * time.Second,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Package imagehost 提供上传到 S3 兼容对象存储桶的图床实现，请求使用 AWS Signature V4 签名。
+
+package imagehost
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"minfo/internal/config"
+)
+
+const (
+	defaultS3Region   = "us-east-1"
+	defaultS3Prefix   = "minfo/"
+	defaultS3MaxBytes = 50 * 1024 * 1024
+)
+
+// s3Bucket 以路径风格地址把图片 PUT 到存储桶，对象名由内容哈希和原文件名组成，重复上传同一图片会得到同一地址。
+type s3Bucket struct {
+	endpoint  *url.URL
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	publicURL string
+	prefix    string
+	acl       string
+	maxBytes  int64
+	now       func() time.Time
+}
+
+// NewS3 会根据 S3_ENDPOINT、S3_BUCKET、S3_ACCESS_KEY 和 S3_SECRET_KEY 创建 S3 兼容图床；缺少配置时返回错误。
+func NewS3() (Host, error) {
+	rawEndpoint := strings.TrimRight(config.Getenv("S3_ENDPOINT", ""), "/")
+	bucket := config.Getenv("S3_BUCKET", "")
+	accessKey := config.Getenv("S3_ACCESS_KEY", "")
+	secretKey := config.Getenv("S3_SECRET_KEY", "")
+	if rawEndpoint == "" || bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, errMissingSetting("s3", "S3_ENDPOINT", "S3_BUCKET", "S3_ACCESS_KEY", "S3_SECRET_KEY")
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT: %q", rawEndpoint)
+	}
+
+	prefix := strings.Trim(config.Getenv("S3_PREFIX", defaultS3Prefix), "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &s3Bucket{
+		endpoint:  endpoint,
+		bucket:    bucket,
+		region:    config.Getenv("S3_REGION", defaultS3Region),
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimRight(config.Getenv("S3_PUBLIC_URL", ""), "/"),
+		prefix:    prefix,
+		acl:       config.Getenv("S3_ACL", ""),
+		maxBytes:  int64(config.IntFromEnv("S3_MAX_BYTES", defaultS3MaxBytes)),
+		now:       time.Now,
+	}, nil
+}
+
+func (h *s3Bucket) Name() string { return "s3" }
+
+func (h *s3Bucket) MaxUploadBytes() int64 { return h.maxBytes }
+
+// AcceptsFormat 接受所有可识别的图片格式；对象存储不限制文件类型。
+func (h *s3Bucket) AcceptsFormat(format string) bool { return format != "" }
+
+// Upload 会把图片上传为公开可读对象；配置了 S3_PUBLIC_URL 时直链使用该地址，否则使用端点上的对象地址。
+func (h *s3Bucket) Upload(ctx context.Context, client *http.Client, imagePath string) (Link, error) {
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		return Link{}, err
+	}
+	sum := sha256.Sum256(data)
+	payloadHash := hex.EncodeToString(sum[:])
+	key := h.prefix + payloadHash[:16] + "-" + filepath.Base(imagePath)
+
+	objectURL := *h.endpoint
+	objectURL.Path = path.Join("/", h.endpoint.Path, h.bucket, key)
+	objectURL.RawPath = s3EscapePath(objectURL.Path)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), bytes.NewReader(data))
+	if err != nil {
+		return Link{}, err
+	}
+	request.ContentLength = int64(len(data))
+	request.Header.Set("Content-Type", ContentTypeForFormat(DetectImageFormat(data)))
+	if h.acl != "" {
+		request.Header.Set("X-Amz-Acl", h.acl)
+	}
+	signS3Request(request, payloadHash, h.region, h.accessKey, h.secretKey, h.now())
+
+	response, err := client.Do(request)
+	if err != nil {
+		return Link{}, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBytes))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return Link{}, NewStatusError(h.Name(), response)
+	}
+
+	publicURL := objectURL.String()
+	if h.publicURL != "" {
+		publicURL = h.publicURL + "/" + s3EscapePath(key)
+	}
+	return newLink(h.Name(), publicURL, "")
+}
+
+// signS3Request 会按 AWS Signature V4 为请求补充 Host、x-amz-date、x-amz-content-sha256 和 Authorization 请求头。
+// 签名覆盖 Host 和全部 x-amz-*、Content-Type、Range 请求头。
+func signS3Request(request *http.Request, payloadHash, region, accessKey, secretKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQuery(request.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/s3/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), day)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath 会按 Signature V4 的规则编码对象路径：除未保留字符和 / 以外的字节都转为百分号编码。
+func s3EscapePath(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~', b == '/':
+			builder.WriteByte(b)
+		default:
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
+
+// canonicalQuery 会按 Signature V4 的要求排序并编码查询参数。
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		vals := append([]string(nil), values[key]...)
+		sort.Strings(vals)
+		for _, value := range vals {
+			parts = append(parts, strings.ReplaceAll(url.QueryEscape(key), "+", "%20")+"="+strings.ReplaceAll(url.QueryEscape(value), "+", "%20"))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// hmacSHA256 会返回 data 使用 key 计算的 HMAC-SHA256 摘要。
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,70 @@
+// Package imagehost 定义截图图床的统一上传接口，以及各图床共用的结果模型和回调类型。
+
+package imagehost
+
+import (
+	"context"
+	"net/http"
+
+	screenshotruntime "minfo/internal/screenshot/runtime"
+	"minfo/internal/taskprogress"
+)
+
+// Host 表示一个可上传截图的图床后端。
+type Host interface {
+	// Name 返回图床名称，用于日志和错误信息。
+	Name() string
+	// MaxUploadBytes 返回该图床允许上传的单张图片大小上限；小于等于 0 表示不限制。
+	MaxUploadBytes() int64
+	// AcceptsFormat 判断图床是否接受指定格式的图片，format 为 FormatPNG 等小写带点的扩展名。
+	AcceptsFormat(format string) bool
+	// Upload 使用给定的 HTTP 客户端上传单张图片，并返回原图直链和缩略图地址。
+	Upload(ctx context.Context, client *http.Client, imagePath string) (Link, error)
+}
+
+// Link 表示单张图片上传后图床返回的原图直链和缩略图地址；缩略图缺失时可与直链相同。
+type Link struct {
+	URL          string
+	ThumbnailURL string
+}
+
+// UploadedImage 表示一次图床上传后返回的单张图片结果。
+type UploadedImage struct {
+	URL          string
+	ThumbnailURL string
+	Filename     string
+	Size         int64
+	Width        int
+	Height       int
+}
+
+// UploadItemHandler 处理图床上传过程中单张已完成图片的实时回调。
+type UploadItemHandler func(item UploadedImage)
+
+// LogHandler 处理上传流程产生的单行实时日志。
+type LogHandler = screenshotruntime.LineHandler
+
+// ProgressHandler 处理上传流程发出的结构化进度事件。
+type ProgressHandler = taskprogress.Handler
+
+// FailedImage 表示一张重试后仍上传失败的图片。
+type FailedImage struct {
+	Path     string
+	Filename string
+	Error    string
+}
+
+// UploadOptions 表示图床上传时的可选运行参数；MaxAttempts 为 0 时使用 DefaultMaxAttempts。
+type UploadOptions struct {
+	ProxyURL    string
+	MaxAttempts int
+}
+
+// Result 表示一次图床上传批次返回的直链、日志、成功图片和失败图片。
+type Result struct {
+	Output       string
+	Logs         string
+	Items        []UploadedImage
+	Failed       []FailedImage
+	LossyIndexes []int
+}
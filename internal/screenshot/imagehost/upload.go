@@ -0,0 +1,95 @@
+// Package imagehost 负责图床上传流程编排与结果汇总。
+
+package imagehost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"minfo/internal/taskprogress"
+)
+
+// UploadImages 会按图床的大小上限过滤可上传图片，逐个上传到指定图床，每处理完一张图片都会发出一条上传阶段进度事件。
+// 单张图片失败时按指数退避重试；超过大小上限或格式不被接受的图片不会上传，与重试后仍失败的图片一起记入 Result.Failed；只有全部失败时才返回错误。
+// 代理无效或 ctx 被取消而提前返回时，Result.Failed 同样包含已跳过的图片和尚未完成上传的图片，便于之后只重试失败部分。
+func UploadImages(ctx context.Context, host Host, files, lossyFiles []string, options UploadOptions, onLog LogHandler, onProgress ProgressHandler, onItem UploadItemHandler) (Result, error) {
+	if host == nil {
+		return Result{}, errors.New("image host is not configured")
+	}
+	batch := newUploadBatch(host.Name(), lossyFiles, onLog, onItem)
+	images := collectUploadableImages(files, host.MaxUploadBytes(), host.AcceptsFormat, batch)
+	if len(images) == 0 {
+		batch.appendLog("警告: 未找到有效图片文件")
+		return batch.result(), errors.New("no uploadable screenshots were found")
+	}
+	skipped := len(batch.failed)
+
+	batch.appendLog("开始上传 %d 个文件到 %s...", len(images), host.Name())
+	onProgress.Emit(taskprogress.Step(taskprogress.StageUpload, 0, len(images), fmt.Sprintf("开始上传 %d 张截图。", len(images))))
+	client, err := NewHTTPClient(options)
+	if err != nil {
+		batch.appendLog("代理设置无效: %s", err.Error())
+		batch.recordNotAttempted(images, err)
+		return batch.result(), err
+	}
+	for index, imagePath := range images {
+		link, err := uploadWithRetry(ctx, host, client, imagePath, options.MaxAttempts, batch)
+		if ctx.Err() != nil {
+			pending := images[index:]
+			if err == nil {
+				batch.recordSuccess(imagePath, link.URL, link.ThumbnailURL)
+				pending = images[index+1:]
+			}
+			batch.recordNotAttempted(pending, ctx.Err())
+			return batch.result(), ctx.Err()
+		}
+		if err != nil {
+			batch.recordFailure(imagePath, err)
+		} else {
+			batch.recordSuccess(imagePath, link.URL, link.ThumbnailURL)
+		}
+		onProgress.Emit(taskprogress.Step(taskprogress.StageUpload, index+1, len(images), fmt.Sprintf("已处理 %d/%d 张截图上传。", index+1, len(images))))
+	}
+
+	result, err := batch.finalize(len(images) + skipped)
+	onProgress.Emit(taskprogress.Percent(taskprogress.StageUpload, 100, "上传已完成，正在整理图床链接。"))
+	return result, err
+}
+
+// extractDirectLinks 会从多行文本中提取以 http 开头的直链结果。
+func extractDirectLinks(output string) []string {
+	links := make([]string, 0)
+	for _, line := range splitLines(output) {
+		if hasHTTPPrefix(line) {
+			links = append(links, line)
+		}
+	}
+	return links
+}
+
+func splitLines(text string) []string {
+	lines := make([]string, 0)
+	start := 0
+	for index := 0; index <= len(text); index++ {
+		if index < len(text) && text[index] != '\n' {
+			continue
+		}
+		line := text[start:index]
+		for len(line) > 0 && (line[len(line)-1] == '\r' || line[len(line)-1] == '\n') {
+			line = line[:len(line)-1]
+		}
+		for len(line) > 0 && (line[0] == '\r' || line[0] == '\n') {
+			line = line[1:]
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		start = index + 1
+	}
+	return lines
+}
+
+func hasHTTPPrefix(value string) bool {
+	return len(value) >= len("http://") && (value[:7] == "http://" || (len(value) >= len("https://") && value[:8] == "https://"))
+}
@@ -5,6 +5,8 @@ package screenshot
 import (
 	"strconv"
 	"strings"
+
+	screenshotruntime "minfo/internal/screenshot/runtime"
 )
 
 // NormalizeMode 规范化截图接口的 mode；未知值会回落为 zip。
@@ -17,16 +19,30 @@ func NormalizeMode(raw string) string {
 	}
 }
 
-// NormalizeVariant 规范化截图输出格式；未知值会回落为 png。
+// NormalizeVariant 规范化截图输出格式；webp 表示无损 WebP，未知值会回落为 png。
 func NormalizeVariant(raw string) string {
-	switch strings.ToLower(strings.TrimSpace(raw)) {
-	case VariantJPG:
+	switch value := strings.ToLower(strings.TrimSpace(raw)); value {
+	case VariantJPG, VariantWebP, VariantWebPLossy, VariantAVIF:
+		return value
+	case "jpeg":
 		return VariantJPG
+	case "webp-lossless":
+		return VariantWebP
 	default:
 		return VariantPNG
 	}
 }
 
+// VariantNames 返回所有支持的截图输出格式。
+func VariantNames() []string {
+	return []string{VariantPNG, VariantJPG, VariantWebP, VariantWebPLossy, VariantAVIF}
+}
+
+// VariantExtension 返回截图输出格式对应的文件扩展名。
+func VariantExtension(variant string) string {
+	return screenshotruntime.VariantSettingsFor(NormalizeVariant(variant)).Ext
+}
+
 // NormalizeSubtitleMode 规范化字幕模式；off 和 none 类输入会关闭字幕，其余值使用自动模式。
 func NormalizeSubtitleMode(raw string) string {
 	switch strings.ToLower(strings.TrimSpace(raw)) {
@@ -51,25 +67,33 @@ func NormalizeHDRProcessor(raw string) string {
 func NormalizeCount(raw string) int {
 	value := strings.TrimSpace(raw)
 	if value == "" {
-		return normalizeScreenshotCount(0)
+		return clampScreenshotCount(0, maxScreenshotCount)
 	}
 
 	count, err := strconv.Atoi(value)
 	if err != nil {
-		return normalizeScreenshotCount(0)
+		return clampScreenshotCount(0, maxScreenshotCount)
 	}
-	return normalizeScreenshotCount(count)
+	return clampScreenshotCount(count, maxScreenshotCount)
 }
 
-// normalizeScreenshotCount 规范化内部流程使用的截图数量。
+// normalizeScreenshotCount 规范化内部流程使用的截图数量；调用方已按请求来源限制过数量，这里只兜底预设允许的最大值。
 func normalizeScreenshotCount(count int) int {
+	return clampScreenshotCount(count, MaxPresetScreenshotCount)
+}
+
+// clampScreenshotCount 会把截图数量限制在 1 到 limit 之间，0 表示使用默认数量。
+func clampScreenshotCount(count, limit int) int {
+	if limit < minScreenshotCount || limit > MaxPresetScreenshotCount {
+		limit = MaxPresetScreenshotCount
+	}
 	switch {
 	case count == 0:
 		return defaultScreenshotCount
 	case count < minScreenshotCount:
 		return minScreenshotCount
-	case count > maxScreenshotCount:
-		return maxScreenshotCount
+	case count > limit:
+		return limit
 	default:
 		return count
 	}
@@ -22,3 +22,28 @@ func TestNormalizeHDRProcessor(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeVariant(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantExt string
+	}{
+		{input: "", want: VariantPNG, wantExt: ".png"},
+		{input: "JPEG", want: VariantJPG, wantExt: ".jpg"},
+		{input: "webp", want: VariantWebP, wantExt: ".webp"},
+		{input: "webp-lossless", want: VariantWebP, wantExt: ".webp"},
+		{input: " WebP-Lossy ", want: VariantWebPLossy, wantExt: ".webp"},
+		{input: "avif", want: VariantAVIF, wantExt: ".avif"},
+		{input: "bmp", want: VariantPNG, wantExt: ".png"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeVariant(tt.input); got != tt.want {
+			t.Fatalf("NormalizeVariant(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+		if got := VariantExtension(tt.input); got != tt.wantExt {
+			t.Fatalf("VariantExtension(%q) = %q, want %q", tt.input, got, tt.wantExt)
+		}
+	}
+}
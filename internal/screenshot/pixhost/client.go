@@ -1,4 +1,4 @@
-// Package pixhost 负责 Pixhost API 请求和直链归一化细节。
+// Package pixhost 提供截图上传到 Pixhost 的图床实现，负责 API 请求和直链归一化细节。
 
 package pixhost
 
@@ -7,7 +7,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -18,6 +17,7 @@ import (
 	"strings"
 
 	"minfo/internal/config"
+	"minfo/internal/screenshot/imagehost"
 )
 
 const apiURL = "https://api.pixhost.to/images"
@@ -31,23 +31,40 @@ type apiResponse struct {
 	ThURL   string `json:"th_url"`
 }
 
-// endpoint 会返回本轮上传应使用的 Pixhost API 地址。
-func endpoint() string {
-	return config.Getenv("PIXHOST_API_URL", apiURL)
+// maxUploadBytes 是 Pixhost 允许上传的单张图片大小上限。
+const maxUploadBytes = 10 * 1024 * 1024
+
+// Host 把截图上传到 Pixhost，并把返回的缩略图地址改写为原图直链。
+type Host struct {
+	apiURL string
+}
+
+// New 会创建使用 PIXHOST_API_URL（缺省为官方 API）的 Pixhost 图床。
+func New() *Host {
+	return &Host{apiURL: config.Getenv("PIXHOST_API_URL", apiURL)}
+}
+
+func (h *Host) Name() string { return "pixhost" }
+
+func (h *Host) MaxUploadBytes() int64 { return maxUploadBytes }
+
+// AcceptsFormat 只接受 Pixhost 支持的 PNG、JPG 和 GIF。
+func (h *Host) AcceptsFormat(format string) bool {
+	switch format {
+	case imagehost.FormatPNG, imagehost.FormatJPG, imagehost.FormatGIF:
+		return true
+	default:
+		return false
+	}
 }
 
-// newHTTPClient 构造 Pixhost 上传使用的 HTTP 客户端。
-func newHTTPClient(options UploadOptions) (*http.Client, error) {
-	transport := http.DefaultTransport.(*http.Transport).Clone()
-	proxyURL := strings.TrimSpace(options.ProxyURL)
-	if proxyURL != "" {
-		parsed, err := url.Parse(proxyURL)
-		if err != nil {
-			return nil, err
-		}
-		transport.Proxy = http.ProxyURL(parsed)
-	}
-	return &http.Client{Transport: transport}, nil
+// Upload 会上传单张图片到 Pixhost。
+func (h *Host) Upload(ctx context.Context, client *http.Client, imagePath string) (imagehost.Link, error) {
+	directURL, thumbnailURL, err := uploadSingleImage(ctx, client, h.apiURL, imagePath)
+	if err != nil {
+		return imagehost.Link{}, err
+	}
+	return imagehost.Link{URL: directURL, ThumbnailURL: thumbnailURL}, nil
 }
 
 // uploadSingleImage 上传单张图片到 Pixhost，并返回原图直链和 Pixhost 缩略图地址。
@@ -96,7 +113,7 @@ func uploadSingleImage(ctx context.Context, client *http.Client, apiURL, imagePa
 		return "", "", err
 	}
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return "", "", fmt.Errorf("pixhost returned HTTP %d", response.StatusCode)
+		return "", "", imagehost.NewStatusError("pixhost", response)
 	}
 
 	var payload apiResponse
@@ -0,0 +1,198 @@
+// Package screenshot 提供服务端保存的截图预设：每个预设是预设目录下的一个 JSON 文件，用于固化站点对截图数量、尺寸、质量和体积的要求。
+
+package screenshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// presetExt 是预设文件的扩展名，文件名去掉扩展名后即预设名。
+const presetExt = ".json"
+
+// 支持的输出缩放算法，取值与 ffmpeg scale 过滤器的 flags 一致。
+const (
+	ScalerBilinear = "bilinear"
+	ScalerBicubic  = "bicubic"
+	ScalerLanczos  = "lanczos"
+	ScalerSpline   = "spline"
+	ScalerArea     = "area"
+	ScalerNeighbor = "neighbor"
+)
+
+// ErrPresetNotFound 表示请求的截图预设不在预设目录中。
+var ErrPresetNotFound = errors.New("screenshot preset not found")
+
+var presetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Preset 描述一个截图预设；值为零的字段表示沿用请求参数或格式默认值。
+// Count 和 Variant 设置后优先于请求中的截图数量和格式，其余字段会转换为 RenderOptions。
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Count       int    `json:"count,omitempty"`
+	Variant     string `json:"variant,omitempty"`
+	MaxWidth    int    `json:"max_width,omitempty"`
+	MaxHeight   int    `json:"max_height,omitempty"`
+	Scaler      string `json:"scaler,omitempty"`
+	JPGQScale   int    `json:"jpg_qscale,omitempty"`
+	WebPQuality int    `json:"webp_quality,omitempty"`
+	AVIFCRF     int    `json:"avif_crf,omitempty"`
+	MaxBytes    int64  `json:"max_bytes,omitempty"`
+}
+
+// NormalizePresetName 规范化预设名：去除首尾空白并转为小写。
+func NormalizePresetName(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// ScalerNames 返回所有支持的输出缩放算法。
+func ScalerNames() []string {
+	return []string{ScalerBilinear, ScalerBicubic, ScalerLanczos, ScalerSpline, ScalerArea, ScalerNeighbor}
+}
+
+// NormalizeScaler 规范化输出缩放算法；空值和未知值会回落为 lanczos。
+func NormalizeScaler(raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	for _, name := range ScalerNames() {
+		if value == name {
+			return value
+		}
+	}
+	return ScalerLanczos
+}
+
+// ListPresets 返回预设目录 dir 中全部可用的截图预设，按名称排序；目录不存在时返回空列表，无效的预设文件会被跳过。
+func ListPresets(dir string) ([]Preset, error) {
+	if dir == "" {
+		return []Preset{}, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Preset{}, nil
+		}
+		return nil, err
+	}
+
+	presets := make([]Preset, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), presetExt)
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), presetExt) || !presetNamePattern.MatchString(name) {
+			continue
+		}
+		preset, err := LoadPreset(dir, name)
+		if err != nil {
+			continue
+		}
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// LoadPreset 读取并校验预设目录 dir 中名为 name 的预设，每次调用都会重新读取文件；预设不存在时返回 ErrPresetNotFound。
+func LoadPreset(dir, name string) (Preset, error) {
+	name = NormalizePresetName(name)
+	if dir == "" || !presetNamePattern.MatchString(name) {
+		return Preset{}, ErrPresetNotFound
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, name+presetExt))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Preset{}, ErrPresetNotFound
+		}
+		return Preset{}, err
+	}
+
+	var preset Preset
+	if err := json.Unmarshal(content, &preset); err != nil {
+		return Preset{}, fmt.Errorf("parse preset %s: %w", name, err)
+	}
+	preset.Name = name
+	if err := preset.validate(); err != nil {
+		return Preset{}, fmt.Errorf("invalid preset %s: %w", name, err)
+	}
+	return preset, nil
+}
+
+// validate 检查预设字段是否在允许范围内，并规范化格式和缩放算法。
+func (p *Preset) validate() error {
+	if p.Count < 0 || p.Count > MaxPresetScreenshotCount {
+		return fmt.Errorf("count must be between 1 and %d", MaxPresetScreenshotCount)
+	}
+	if p.Variant != "" {
+		variant := NormalizeVariant(p.Variant)
+		if variant == VariantPNG && !strings.EqualFold(strings.TrimSpace(p.Variant), VariantPNG) {
+			return fmt.Errorf("unsupported variant %q", p.Variant)
+		}
+		p.Variant = variant
+	}
+	if p.MaxWidth < 0 || p.MaxHeight < 0 {
+		return errors.New("max_width and max_height must not be negative")
+	}
+	if p.Scaler != "" {
+		scaler := NormalizeScaler(p.Scaler)
+		if scaler != strings.ToLower(strings.TrimSpace(p.Scaler)) {
+			return fmt.Errorf("unsupported scaler %q", p.Scaler)
+		}
+		p.Scaler = scaler
+	}
+	if p.JPGQScale < 0 || p.JPGQScale > 31 {
+		return errors.New("jpg_qscale must be between 1 and 31")
+	}
+	if p.WebPQuality < 0 || p.WebPQuality > 100 {
+		return errors.New("webp_quality must be between 1 and 100")
+	}
+	if p.AVIFCRF < 0 || p.AVIFCRF > 63 {
+		return errors.New("avif_crf must be between 1 and 63")
+	}
+	if p.MaxBytes < 0 {
+		return errors.New("max_bytes must not be negative")
+	}
+	return nil
+}
+
+// CountLimit 返回使用该预设时允许的最大截图数量：预设数量超过默认上限时放宽到预设数量。
+func (p Preset) CountLimit() int {
+	if p.Count > maxScreenshotCount {
+		return p.Count
+	}
+	return maxScreenshotCount
+}
+
+// ResolveCount 返回使用该预设时的截图数量：预设设置了数量时以预设为准，否则按默认范围规范化请求中的数量。
+func (p Preset) ResolveCount(raw string) int {
+	if p.Count > 0 {
+		return p.Count
+	}
+	return NormalizeCount(raw)
+}
+
+// ResolveVariant 返回使用该预设时的截图格式：预设设置了格式时以预设为准，否则规范化请求中的格式。
+func (p Preset) ResolveVariant(raw string) string {
+	if p.Variant != "" {
+		return p.Variant
+	}
+	return NormalizeVariant(raw)
+}
+
+// RenderOptions 返回预设对应的截图渲染参数。
+func (p Preset) RenderOptions() RenderOptions {
+	return RenderOptions{
+		MaxWidth:    p.MaxWidth,
+		MaxHeight:   p.MaxHeight,
+		Scaler:      p.Scaler,
+		JPGQScale:   p.JPGQScale,
+		WebPQuality: p.WebPQuality,
+		AVIFCRF:     p.AVIFCRF,
+		MaxBytes:    p.MaxBytes,
+	}
+}
@@ -0,0 +1,81 @@
+package screenshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePresetFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPreset(t *testing.T) {
+	dir := t.TempDir()
+	writePresetFile(t, dir, "tracker.json", `{"name": "ignored", "count": 40, "variant": "JPEG", "max_width": 1920, "max_height": 1080, "scaler": " Bicubic ", "jpg_qscale": 2}`)
+
+	preset, err := LoadPreset(dir, " Tracker ")
+	if err != nil {
+		t.Fatalf("LoadPreset() error = %v", err)
+	}
+	if preset.Name != "tracker" || preset.Count != 40 || preset.Variant != VariantJPG || preset.Scaler != ScalerBicubic {
+		t.Fatalf("LoadPreset() = %+v", preset)
+	}
+	if preset.ResolveCount("4") != 40 || preset.ResolveVariant("png") != VariantJPG || preset.CountLimit() != 40 {
+		t.Fatalf("preset should override count and variant: %+v", preset)
+	}
+	if got := (Preset{}).ResolveCount("25"); got != maxScreenshotCount {
+		t.Fatalf("ResolveCount without preset count = %d, want %d", got, maxScreenshotCount)
+	}
+
+	if _, err := LoadPreset(dir, "missing"); !errors.Is(err, ErrPresetNotFound) {
+		t.Fatalf("LoadPreset(missing) error = %v, want ErrPresetNotFound", err)
+	}
+	if _, err := LoadPreset(dir, "../tracker"); !errors.Is(err, ErrPresetNotFound) {
+		t.Fatalf("LoadPreset(../tracker) error = %v, want ErrPresetNotFound", err)
+	}
+}
+
+func TestLoadPresetRejectsInvalidFields(t *testing.T) {
+	tests := map[string]string{
+		"count":   `{"count": 41}`,
+		"variant": `{"variant": "bmp"}`,
+		"scaler":  `{"scaler": "magic"}`,
+		"quality": `{"webp_quality": 101}`,
+		"crf":     `{"avif_crf": 64}`,
+		"size":    `{"max_width": -1}`,
+		"syntax":  `{"count": `,
+	}
+	for name, content := range tests {
+		dir := t.TempDir()
+		writePresetFile(t, dir, "bad.json", content)
+		if _, err := LoadPreset(dir, "bad"); err == nil || errors.Is(err, ErrPresetNotFound) {
+			t.Fatalf("%s: LoadPreset() error = %v, want validation error", name, err)
+		}
+	}
+}
+
+func TestListPresets(t *testing.T) {
+	dir := t.TempDir()
+	writePresetFile(t, dir, "ptp.json", `{"count": 20}`)
+	writePresetFile(t, dir, "hdb.json", `{"variant": "png", "max_width": 1920}`)
+	writePresetFile(t, dir, "broken.json", `{"count": 99}`)
+	writePresetFile(t, dir, "notes.txt", `{}`)
+
+	presets, err := ListPresets(dir)
+	if err != nil {
+		t.Fatalf("ListPresets() error = %v", err)
+	}
+	if len(presets) != 2 || presets[0].Name != "hdb" || presets[1].Name != "ptp" {
+		t.Fatalf("ListPresets() = %+v, want hdb and ptp", presets)
+	}
+
+	presets, err = ListPresets(filepath.Join(dir, "missing"))
+	if err != nil || len(presets) != 0 {
+		t.Fatalf("ListPresets(missing) = %+v, %v, want empty", presets, err)
+	}
+}
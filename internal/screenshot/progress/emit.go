@@ -4,18 +4,18 @@ package progress
 
 import "minfo/internal/taskprogress"
 
-// EmitStepLog 会通过实时日志回调输出一条统一格式的步骤进度日志。
-func EmitStepLog(onLog LineHandler, stage string, current, total int, detail string) {
-	if onLog == nil {
-		return
+// EmitStep 会输出一条步骤进度：结构化事件交给 onProgress，同时在日志中保留一行统一格式的可读文本。
+func EmitStep(onLog LineHandler, onProgress Handler, stage string, current, total int, detail string) {
+	onProgress.Emit(taskprogress.Step(stage, current, total, detail))
+	if onLog != nil {
+		onLog(taskprogress.FormatStep(stage, current, total, detail))
 	}
-	onLog(taskprogress.FormatStep(stage, current, total, detail))
 }
 
-// EmitPercentLog 会通过实时日志回调输出一条带百分比的进度日志。
-func EmitPercentLog(onLog LineHandler, stage string, percent float64, detail string) {
-	if onLog == nil {
-		return
+// EmitPercent 会输出一条百分比进度：结构化事件交给 onProgress，同时在日志中保留一行统一格式的可读文本。
+func EmitPercent(onLog LineHandler, onProgress Handler, stage string, percent float64, detail string) {
+	onProgress.Emit(taskprogress.Percent(stage, percent, detail))
+	if onLog != nil {
+		onLog(taskprogress.FormatPercent(stage, percent, detail))
 	}
-	onLog(taskprogress.FormatPercent(stage, percent, detail))
 }
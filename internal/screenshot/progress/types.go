@@ -2,7 +2,13 @@
 
 package progress
 
-import screenshotruntime "minfo/internal/screenshot/runtime"
+import (
+	screenshotruntime "minfo/internal/screenshot/runtime"
+	"minfo/internal/taskprogress"
+)
 
 // LineHandler 处理进度子模块产生的单行实时日志。
 type LineHandler = screenshotruntime.LineHandler
+
+// Handler 处理截图流程发出的结构化进度事件。
+type Handler = taskprogress.Handler
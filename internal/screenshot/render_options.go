@@ -0,0 +1,95 @@
+// Package screenshot 负责把截图预设的渲染参数应用到运行器：输出缩放、编码质量覆盖和单张大小上限。
+
+package screenshot
+
+import (
+	"fmt"
+
+	screenshotruntime "minfo/internal/screenshot/runtime"
+)
+
+// applyRenderOptions 会把渲染参数写入运行器，并按当前格式覆盖默认编码质量。
+func (r *screenshotRunner) applyRenderOptions(options RenderOptions) {
+	r.output = options
+	r.maxBytes = options.MaxBytes
+	r.settings = applyQualityOverrides(r.settings, r.variant, options)
+}
+
+// applyQualityOverrides 会用渲染参数中的质量覆盖格式默认值，并同步推导超出大小上限后重拍使用的质量。
+// 无损 WebP 的 WebPQuality 只影响超限后改用的有损编码质量。
+func applyQualityOverrides(settings screenshotruntime.VariantSettings, variant string, options RenderOptions) screenshotruntime.VariantSettings {
+	switch variant {
+	case VariantJPG:
+		if options.JPGQScale > 0 {
+			settings.JPGQuality = clampJPGQScale(options.JPGQScale)
+		}
+	case VariantWebP:
+		if options.WebPQuality > 0 {
+			settings.ReencodeQuality = clampPercent(options.WebPQuality)
+		}
+	case VariantWebPLossy:
+		if options.WebPQuality > 0 {
+			settings.Quality = clampPercent(options.WebPQuality)
+			settings.ReencodeQuality = max(settings.Quality-12, 1)
+		}
+	case VariantAVIF:
+		if options.AVIFCRF > 0 {
+			settings.Quality = min(options.AVIFCRF, 63)
+			settings.ReencodeQuality = min(settings.Quality+10, 63)
+		}
+	}
+	return settings
+}
+
+// limitMaxBytes 会把渲染参数的单张大小上限收紧为它与图床上限中更严格的一个；渲染参数未设置上限时直接使用图床上限。
+func limitMaxBytes(hostLimit int64, options RenderOptions) RenderOptions {
+	if options.MaxBytes <= 0 || (hostLimit > 0 && hostLimit < options.MaxBytes) {
+		options.MaxBytes = hostLimit
+	}
+	return options
+}
+
+// outputScaleFilter 返回当前截图任务的输出缩放过滤器；未设置最大宽高时返回空字符串。
+func (r *screenshotRunner) outputScaleFilter() string {
+	return buildOutputScaleFilter(r.output)
+}
+
+// buildOutputScaleFilter 会构造只缩小不放大、保持画面比例的 scale 过滤器。
+func buildOutputScaleFilter(options RenderOptions) string {
+	flags := NormalizeScaler(options.Scaler)
+	switch {
+	case options.MaxWidth > 0 && options.MaxHeight > 0:
+		return fmt.Sprintf("scale='min(%d,iw)':'min(%d,ih)':force_original_aspect_ratio=decrease:flags=%s", options.MaxWidth, options.MaxHeight, flags)
+	case options.MaxWidth > 0:
+		return fmt.Sprintf("scale='min(%d,iw)':-1:flags=%s", options.MaxWidth, flags)
+	case options.MaxHeight > 0:
+		return fmt.Sprintf("scale=-1:'min(%d,ih)':flags=%s", options.MaxHeight, flags)
+	default:
+		return ""
+	}
+}
+
+// renderVideoChain 返回不含字幕叠加的截图主渲染链：色彩转换、显示比例修正和输出缩放。
+func (r *screenshotRunner) renderVideoChain() string {
+	return joinFilters(r.render.ColorChain, r.displayAspectFilter(), r.outputScaleFilter())
+}
+
+// logRenderOptions 会在设置了输出缩放时输出缩放目标和算法摘要。
+func (r *screenshotRunner) logRenderOptions() {
+	if r.outputScaleFilter() == "" {
+		return
+	}
+	r.logf("[信息] 输出缩放：最大 %s（%s）。", describeMaxSize(r.output.MaxWidth, r.output.MaxHeight), NormalizeScaler(r.output.Scaler))
+}
+
+// describeMaxSize 会把最大宽高格式化为日志文本，未限制的一边显示为 *。
+func describeMaxSize(width, height int) string {
+	widthText, heightText := "*", "*"
+	if width > 0 {
+		widthText = fmt.Sprint(width)
+	}
+	if height > 0 {
+		heightText = fmt.Sprint(height)
+	}
+	return widthText + "x" + heightText
+}
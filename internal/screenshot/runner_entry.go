@@ -12,6 +12,7 @@ import (
 	screenshotruntime "minfo/internal/screenshot/runtime"
 	screenshotsource "minfo/internal/screenshot/source"
 	screenshottimestamps "minfo/internal/screenshot/timestamps"
+	"minfo/internal/taskprogress"
 )
 
 type resolvedScreenshotSources struct {
@@ -20,37 +21,48 @@ type resolvedScreenshotSources struct {
 	cleanup          func()
 }
 
-// runEngineScreenshotsWithLiveLogs 会解析输入源、生成随机时间点，并启动带实时日志的截图引擎流程。
-func runEngineScreenshotsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, onLog LogHandler) (ScreenshotsResult, error) {
-	sources, err := resolveScreenshotSources(ctx, inputPath, onLog)
+// runEngineScreenshotsWithLiveLogs 会解析输入源、生成随机时间点，并启动带实时日志和进度事件的截图引擎流程。
+func runEngineScreenshotsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, options RenderOptions, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	if timeline := resolveScreenshotTimeline(ctx, inputPath); len(timeline.Segments) > 0 {
+		seconds := screenshottimestamps.BuildRandomSeconds(timeline.Duration().Seconds(), normalizeScreenshotCount(count))
+		return runTimelineScreenshots(ctx, timeline, outputDir, variant, subtitleMode, hdrProcessor, seconds, options, onLog, onProgress)
+	}
+
+	sources, err := resolveScreenshotSources(ctx, inputPath, onLog, onProgress)
 	if err != nil {
 		return ScreenshotsResult{}, err
 	}
 	defer sources.cleanup()
 
-	timestamps, err := generateScreenshotTimestamps(ctx, sources.sourcePath, count, onLog)
+	timestamps, err := generateScreenshotTimestamps(ctx, sources.sourcePath, count, onLog, onProgress)
 	if err != nil {
 		return ScreenshotsResult{}, err
 	}
 
-	return runScreenshotsFromSource(ctx, sources.sourcePath, sources.dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, onLog)
+	return runScreenshotsFromSource(ctx, sources.sourcePath, sources.dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, 0, options, onLog, onProgress)
 }
 
 // runEngineScreenshotsAtTimestampsWithLiveLogs 会解析输入源，并按指定时间点执行截图流程。
-func runEngineScreenshotsAtTimestampsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, onLog LogHandler) (ScreenshotsResult, error) {
-	sources, err := resolveScreenshotSources(ctx, inputPath, onLog)
+func runEngineScreenshotsAtTimestampsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, options RenderOptions, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	if timeline := resolveScreenshotTimeline(ctx, inputPath); len(timeline.Segments) > 0 {
+		if seconds, err := timelineSeconds(timestamps); err == nil {
+			return runTimelineScreenshots(ctx, timeline, outputDir, variant, subtitleMode, hdrProcessor, seconds, options, onLog, onProgress)
+		}
+	}
+
+	sources, err := resolveScreenshotSources(ctx, inputPath, onLog, onProgress)
 	if err != nil {
 		return ScreenshotsResult{}, err
 	}
 	defer sources.cleanup()
 
-	return runScreenshotsFromSource(ctx, sources.sourcePath, sources.dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, onLog)
+	return runScreenshotsFromSource(ctx, sources.sourcePath, sources.dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, 0, options, onLog, onProgress)
 }
 
 // resolveScreenshotSources 会把外部输入路径解析为截图主媒体源和 DVD 附加探测源。
-func resolveScreenshotSources(ctx context.Context, inputPath string, onLog LogHandler) (resolvedScreenshotSources, error) {
-	screenshotprogress.EmitStepLog(onLog, "启动", 1, 3, "正在解析截图输入源。")
-	screenshotprogress.EmitStepLog(onLog, "启动", 2, 3, "正在定位 DVD 附加元数据源。")
+func resolveScreenshotSources(ctx context.Context, inputPath string, onLog LogHandler, onProgress ProgressHandler) (resolvedScreenshotSources, error) {
+	screenshotprogress.EmitStep(onLog, onProgress, taskprogress.StageBootstrap, 1, 3, "正在解析截图输入源。")
+	screenshotprogress.EmitStep(onLog, onProgress, taskprogress.StageBootstrap, 2, 3, "正在定位 DVD 附加元数据源。")
 
 	sourcePath, cleanupSource, err := media.ResolveScreenshotSource(ctx, inputPath)
 	if err != nil {
@@ -74,11 +86,11 @@ func resolveScreenshotSources(ctx context.Context, inputPath string, onLog LogHa
 }
 
 // generateScreenshotTimestamps 会在入口阶段输出统一进度，并生成本轮随机截图时间点。
-func generateScreenshotTimestamps(ctx context.Context, sourcePath string, count int, onLog LogHandler) ([]string, error) {
+func generateScreenshotTimestamps(ctx context.Context, sourcePath string, count int, onLog LogHandler, onProgress ProgressHandler) ([]string, error) {
 	detail := "正在估算影片时长并生成随机截图时间点。"
-	screenshotprogress.EmitStepLog(onLog, "启动", 3, 3, detail)
+	screenshotprogress.EmitStep(onLog, onProgress, taskprogress.StageBootstrap, 3, 3, detail)
 	stopHeartbeat := screenshotprogress.StartHeartbeat(ctx, func(elapsed time.Duration) {
-		screenshotprogress.EmitPercentLog(onLog, "启动", screenshotprogress.SubtitleHeartbeatStepPercent(elapsed), screenshotprogress.SubtitleHeartbeatDetail(detail, elapsed))
+		screenshotprogress.EmitPercent(onLog, onProgress, taskprogress.StageBootstrap, screenshotprogress.SubtitleHeartbeatStepPercent(elapsed), screenshotprogress.SubtitleHeartbeatDetail(detail, elapsed))
 	})
 	timestamps, err := screenshottimestamps.RandomTimestampsForSource(ctx, sourcePath, normalizeScreenshotCount(count))
 	stopHeartbeat()
@@ -88,12 +100,17 @@ func generateScreenshotTimestamps(ctx context.Context, sourcePath string, count
 	return timestamps, nil
 }
 
-// runScreenshotsFromSource 会基于已经解析好的媒体源创建运行器，并执行一次完整截图任务。
-func runScreenshotsFromSource(ctx context.Context, sourcePath, dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, onLog LogHandler) (ScreenshotsResult, error) {
+// runScreenshotsFromSource 会基于已经解析好的媒体源创建运行器，并执行一次完整截图任务；options.MaxBytes 是单张截图触发压缩兜底的大小上限。
+// timelineOffset 是媒体源在播放列表时间线上的起点秒数，只用于让输出文件名对应整条时间线上的时间。
+func runScreenshotsFromSource(ctx context.Context, sourcePath, dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, timelineOffset float64, options RenderOptions, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
 	runner := newScreenshotRunner(ctx, sourcePath, dvdMediaInfoPath, outputDir, variant, subtitleMode, hdrProcessor, onLog)
+	runner.onProgress = onProgress
+	runner.timelineOffset = timelineOffset
+	runner.applyRenderOptions(options)
 	defer runner.cleanupTemporarySubtitleResources()
 
 	runner.logRuntimeBootstrap()
+	runner.logRenderOptions()
 	if err := runner.init(timestamps); err != nil {
 		return ScreenshotsResult{Logs: runner.logs()}, err
 	}
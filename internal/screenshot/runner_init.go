@@ -274,9 +274,9 @@ func (r *screenshotRunner) ensureSubtitleIndex() []screenshotruntime.SubtitleSpa
 	return r.subtitleFlow().EnsureIndex()
 }
 
-// ensureDVDMediaInfoResult 在 DVD 场景下只探测一次 mediainfo 结果，并缓存供后续字幕与比例逻辑复用。
+// ensureDVDMediaInfoResult 在 DVD 场景下只探测一次 IFO / mediainfo 结果，并缓存供后续字幕与比例逻辑复用。
 func (r *screenshotRunner) ensureDVDMediaInfoResult() (screenshotruntime.DVDMediaInfoResult, bool, error) {
-	if r == nil || strings.TrimSpace(r.tools.MediaInfoBin) == "" {
+	if r == nil {
 		return screenshotruntime.DVDMediaInfoResult{}, false, nil
 	}
 	if !screenshotsource.LooksLikeDVDSource(r.sourcePath) {
@@ -26,13 +26,15 @@ func (r *screenshotRunner) logf(format string, args ...interface{}) {
 	r.logger.Addf(format, args...)
 }
 
-// logProgress 会写入一条稳定格式的进度日志，供上层推导阶段型进度。
+// logProgress 会发出一条阶段 step 进度事件，并在日志中保留对应的可读文本。
 func (r *screenshotRunner) logProgress(stage string, current, total int, detail string) {
+	r.onProgress.Emit(taskprogress.Step(stage, current, total, detail))
 	r.logf("%s", taskprogress.FormatStep(stage, current, total, detail))
 }
 
-// logProgressPercent 会写入一条带百分比的进度日志，适合外部工具实时进度。
+// logProgressPercent 会发出一条百分比进度事件，适合外部工具实时进度，并在日志中保留对应的可读文本。
 func (r *screenshotRunner) logProgressPercent(stage string, percent float64, detail string) {
+	r.onProgress.Emit(taskprogress.Percent(stage, percent, detail))
 	r.logf("%s", taskprogress.FormatPercent(stage, percent, detail))
 }
 
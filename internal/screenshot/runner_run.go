@@ -93,7 +93,7 @@ func (r *screenshotRunner) prepareScreenshotCapture(requested float64, state *sc
 		return screenshotCapturePlan{}, false
 	}
 
-	outputName := screenshottimestamps.UniqueScreenshotName(aligned, r.settings.Ext, state.usedNames)
+	outputName := screenshottimestamps.UniqueScreenshotName(aligned+r.timelineOffset, r.settings.Ext, state.usedNames)
 	outputPath := filepath.Join(r.outputDir, outputName)
 	r.logf("[信息] 截图: 请求 %s → 对齐 %s → 输出 %s -> %s",
 		screenshottimestamps.SecToHMSMS(requested),
@@ -0,0 +1,148 @@
+// Package screenshot 负责把多片段蓝光播放列表的截图时间点分配到各个片段并汇总结果。
+
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"minfo/internal/media"
+	screenshotruntime "minfo/internal/screenshot/runtime"
+	screenshottimestamps "minfo/internal/screenshot/timestamps"
+)
+
+// timelineSegmentShots 描述落在同一播放项内的截图时间点，timestamps 已换算为播放项内的相对时间。
+type timelineSegmentShots struct {
+	index      int
+	offset     int
+	timestamps []string
+}
+
+// resolveScreenshotTimeline 会尝试把输入解析成多片段蓝光播放列表时间线；解析失败时返回空时间线，由调用方回退到单一截图源。
+func resolveScreenshotTimeline(ctx context.Context, inputPath string) media.ScreenshotTimeline {
+	timeline, err := media.ResolveScreenshotTimeline(ctx, inputPath)
+	if err != nil {
+		return media.ScreenshotTimeline{}
+	}
+	return timeline
+}
+
+// timelineSeconds 会把请求里的 HH:MM:SS 时间点转换为整条时间线上的整秒值。
+func timelineSeconds(timestamps []string) ([]int, error) {
+	requested, err := screenshottimestamps.ParseRequestedTimestamps(timestamps)
+	if err != nil {
+		return nil, err
+	}
+	seconds := make([]int, 0, len(requested))
+	for _, value := range requested {
+		seconds = append(seconds, screenshottimestamps.ScreenshotSecond(value))
+	}
+	return seconds, nil
+}
+
+// planTimelineShots 会把整条时间线上的截图秒数按播放顺序分配到各播放项。
+// 播放项的入点通常就是片段起点，因此相对时间按片段开头计算；入点不在片段开头时截图位置会略有偏差。
+func planTimelineShots(timeline media.ScreenshotTimeline, seconds []int) []timelineSegmentShots {
+	plans := make([]timelineSegmentShots, 0, len(timeline.Segments))
+	byIndex := make(map[int]int, len(timeline.Segments))
+	for _, second := range seconds {
+		index, local := timeline.Locate(time.Duration(second) * time.Second)
+		if index < 0 {
+			continue
+		}
+		localSecond := int(local / time.Second)
+		position, ok := byIndex[index]
+		if !ok {
+			position = len(plans)
+			byIndex[index] = position
+			plans = append(plans, timelineSegmentShots{index: index, offset: second - localSecond})
+		}
+		plans[position].timestamps = append(plans[position].timestamps, screenshottimestamps.FormatTimestamp(localSecond))
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].index < plans[j].index })
+	return plans
+}
+
+// runTimelineScreenshots 会按播放项依次截图，并把各片段的输出合并到 outputDir；单个片段失败时继续处理其余片段，全部失败才返回错误。
+func runTimelineScreenshots(ctx context.Context, timeline media.ScreenshotTimeline, outputDir, variant, subtitleMode, hdrProcessor string, seconds []int, options RenderOptions, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	logger := screenshotruntime.NewLogger(onLog)
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return ScreenshotsResult{}, err
+	}
+	if err := clearDir(outputDir); err != nil {
+		return ScreenshotsResult{}, err
+	}
+
+	plans := planTimelineShots(timeline, seconds)
+	logger.Addf("[信息] 蓝光播放列表 %s 共 %d 个片段，总时长 %s，截图将分布在 %d 个片段中。",
+		timeline.Playlist,
+		len(timeline.Segments),
+		screenshottimestamps.SecToHMS(timeline.Duration().Seconds()),
+		len(plans),
+	)
+
+	logs := []string{logger.Text()}
+	appendLogs := func(text string) {
+		if text != "" {
+			logs = append(logs, text)
+		}
+	}
+	files := make([]string, 0, len(seconds))
+	lossy := make([]string, 0)
+	var lastErr error
+	for _, plan := range plans {
+		segment := timeline.Segments[plan.index]
+		sourcePath, cleanup, err := timeline.OpenSegment(ctx, plan.index)
+		if err != nil {
+			lastErr = err
+			appendLogs(fmt.Sprintf("[失败] 片段 %s 无法打开：%s", segment.Clip, err.Error()))
+			continue
+		}
+
+		segmentDir := filepath.Join(outputDir, ".clip-"+segment.Clip)
+		result, err := runScreenshotsFromSource(ctx, sourcePath, "", segmentDir, variant, subtitleMode, hdrProcessor, plan.timestamps, float64(plan.offset), options, onLog, onProgress)
+		cleanup()
+		appendLogs(result.Logs)
+		if err == nil {
+			var moved []string
+			moved, err = moveScreenshotFiles(result.Files, outputDir)
+			files = append(files, moved...)
+		}
+		_ = os.RemoveAll(segmentDir)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		lossy = append(lossy, result.LossyPNGFiles...)
+	}
+
+	text := strings.TrimSpace(strings.Join(logs, "\n"))
+	if len(files) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("no screenshots were generated")
+		}
+		return ScreenshotsResult{Logs: text}, lastErr
+	}
+	sort.Strings(files)
+	sort.Strings(lossy)
+	return ScreenshotsResult{Files: files, Logs: text, LossyPNGFiles: lossy}, nil
+}
+
+// moveScreenshotFiles 会把片段目录中的截图移动到 outputDir，并返回移动后的路径。
+func moveScreenshotFiles(files []string, outputDir string) ([]string, error) {
+	moved := make([]string, 0, len(files))
+	for _, file := range files {
+		target := filepath.Join(outputDir, filepath.Base(file))
+		if err := os.Rename(file, target); err != nil {
+			return moved, err
+		}
+		moved = append(moved, target)
+	}
+	return moved, nil
+}
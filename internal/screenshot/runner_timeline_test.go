@@ -0,0 +1,28 @@
+package screenshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"minfo/internal/media"
+)
+
+// TestPlanTimelineShotsSpreadsSecondsAcrossSegments 会验证整条时间线上的秒数按播放项拆分并换算为片段内时间。
+func TestPlanTimelineShotsSpreadsSecondsAcrossSegments(t *testing.T) {
+	timeline := media.ScreenshotTimeline{Segments: []media.BlurayClipSegment{
+		{Clip: "00010", Start: 0, Duration: 10 * time.Minute},
+		{Clip: "00011", Start: 10 * time.Minute, Duration: 5 * time.Minute},
+		{Clip: "00012", Start: 15 * time.Minute, Duration: 20 * time.Minute},
+	}}
+
+	plans := planTimelineShots(timeline, []int{120, 1500, 700, 1000})
+	want := []timelineSegmentShots{
+		{index: 0, offset: 0, timestamps: []string{"00:02:00"}},
+		{index: 1, offset: 600, timestamps: []string{"00:01:40"}},
+		{index: 2, offset: 900, timestamps: []string{"00:10:00", "00:01:40"}},
+	}
+	if !reflect.DeepEqual(plans, want) {
+		t.Fatalf("planTimelineShots() = %+v, want %+v", plans, want)
+	}
+}
@@ -17,9 +17,13 @@ type screenshotRunner struct {
 	subtitleMode     string
 	hdrProcessor     string
 	requested        []float64
+	timelineOffset   float64
 	settings         screenshotruntime.VariantSettings
 	tools            screenshotruntime.Toolchain
 	logger           screenshotruntime.Logger
+	onProgress       ProgressHandler
+	maxBytes         int64
+	output           RenderOptions
 	lossyPNGFiles    map[string]struct{}
 	media            screenshotruntime.MediaState
 	render           screenshotruntime.RenderState
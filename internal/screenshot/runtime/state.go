@@ -31,7 +31,7 @@ type RenderState struct {
 	ColorChain           string
 }
 
-// DVDMediaInfoTrack 表示 mediainfo 探测到的一条 DVD 字幕轨。
+// DVDMediaInfoTrack 表示 mediainfo 或 IFO 解析得到的一条 DVD 字幕轨。
 type DVDMediaInfoTrack struct {
 	StreamID int
 	ID       string
@@ -41,7 +41,7 @@ type DVDMediaInfoTrack struct {
 	Source   string
 }
 
-// DVDMediaInfoResult 表示一次 DVD mediainfo 探测返回的结果。
+// DVDMediaInfoResult 表示一次 DVD 字幕元数据探测（IFO 解析或 mediainfo）返回的结果。
 type DVDMediaInfoResult struct {
 	Duration             float64
 	DisplayAspectRatio   string
@@ -49,6 +49,8 @@ type DVDMediaInfoResult struct {
 	ProbePath            string
 	SelectedVOBPath      string
 	LanguageFallbackPath string
+	// FromIFO 表示结果直接来自 VTS IFO 解析，而不是 mediainfo。
+	FromIFO bool
 }
 
 // SubtitleState 维护截图流程运行时的字幕索引、缓存和补充元数据。
@@ -4,17 +4,21 @@ package runtime
 import "strings"
 
 // VariantSettings 描述单种截图输出格式对应的探测、搜索和编码参数。
+// Quality 和 ReencodeQuality 是 WebP / AVIF 首次编码和超出大小上限后重拍使用的质量参数：
+// WebP 为 0-100 的 -quality（无损模式下表示压缩力度），AVIF 为 0-63 的 -crf，数值越大体积越小。
 type VariantSettings struct {
-	Ext            string
-	ProbeSize      string
-	Analyze        string
-	CoarseBackText int
-	CoarseBackPGS  int
-	RenderBackText int
-	RenderBackPGS  int
-	SearchBack     float64
-	SearchForward  float64
-	JPGQuality     int
+	Ext             string
+	ProbeSize       string
+	Analyze         string
+	CoarseBackText  int
+	CoarseBackPGS   int
+	RenderBackText  int
+	RenderBackPGS   int
+	SearchBack      float64
+	SearchForward   float64
+	JPGQuality      int
+	Quality         int
+	ReencodeQuality int
 }
 
 // VariantSettingsFor 会根据输出格式选择对应的探测、搜索和编码参数。
@@ -33,6 +37,48 @@ func VariantSettingsFor(variant string) VariantSettings {
 			SearchForward:  8,
 			JPGQuality:     1,
 		}
+	case "webp":
+		return VariantSettings{
+			Ext:             ".webp",
+			ProbeSize:       "150M",
+			Analyze:         "150M",
+			CoarseBackText:  3,
+			CoarseBackPGS:   12,
+			RenderBackText:  1,
+			RenderBackPGS:   2,
+			SearchBack:      6,
+			SearchForward:   10,
+			Quality:         75,
+			ReencodeQuality: 92,
+		}
+	case "webp-lossy":
+		return VariantSettings{
+			Ext:             ".webp",
+			ProbeSize:       "100M",
+			Analyze:         "100M",
+			CoarseBackText:  2,
+			CoarseBackPGS:   8,
+			RenderBackText:  1,
+			RenderBackPGS:   2,
+			SearchBack:      4,
+			SearchForward:   8,
+			Quality:         92,
+			ReencodeQuality: 80,
+		}
+	case "avif":
+		return VariantSettings{
+			Ext:             ".avif",
+			ProbeSize:       "100M",
+			Analyze:         "100M",
+			CoarseBackText:  2,
+			CoarseBackPGS:   8,
+			RenderBackText:  1,
+			RenderBackPGS:   2,
+			SearchBack:      4,
+			SearchForward:   8,
+			Quality:         20,
+			ReencodeQuality: 30,
+		}
 	default:
 		return VariantSettings{
 			Ext:            ".png",
@@ -6,11 +6,9 @@ import (
 	"context"
 	"strings"
 
-	screenshotpixhost "minfo/internal/screenshot/pixhost"
+	"minfo/internal/screenshot/imagehost"
 )
 
-const oversizeBytes = 10 * 1024 * 1024
-
 // RunScreenshots 执行截图流程并仅返回生成的文件列表。
 func RunScreenshots(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int) ([]string, error) {
 	result, err := RunScreenshotsWithLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count)
@@ -22,17 +20,31 @@ func RunScreenshots(ctx context.Context, inputPath, outputDir, variant, subtitle
 
 // RunScreenshotsWithLogs 执行截图流程并返回文件列表与完整日志。
 func RunScreenshotsWithLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int) (ScreenshotsResult, error) {
-	return RunScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, nil)
+	return RunScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, nil, nil)
+}
+
+// RunScreenshotsWithLiveLogs 会执行截图流程，并把实时日志和结构化进度事件通过回调逐条暴露给调用方。
+func RunScreenshotsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	return RunScreenshotsWithRenderOptions(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, RenderOptions{}, onLog, onProgress)
+}
+
+// RunScreenshotsWithRenderOptions 会按指定渲染参数执行截图流程，并实时暴露日志和进度事件。
+func RunScreenshotsWithRenderOptions(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, render RenderOptions, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	return runEngineScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, limitMaxBytes(DefaultMaxImageBytes, render), onLog, onProgress)
 }
 
-// RunScreenshotsWithLiveLogs 会执行截图流程，并把实时日志通过回调逐行暴露给调用方。
-func RunScreenshotsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, onLog LogHandler) (ScreenshotsResult, error) {
-	return runEngineScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, onLog)
+// RunScreenshotsForUploadWithLiveLogs 会按上传选项中的渲染参数和图床大小上限执行截图流程，供截图后再调用 UploadScreenshots 的流程使用。
+func RunScreenshotsForUploadWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, options UploadOptions, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	host, err := LookupImageHostForVariant(options.Host, variant)
+	if err != nil {
+		return ScreenshotsResult{}, err
+	}
+	return runEngineScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, limitMaxBytes(maxImageBytesFor(host), options.Render), onLog, onProgress)
 }
 
 // RunScreenshotsAtTimestampsWithLiveLogs 会按指定时间点执行截图流程。
-func RunScreenshotsAtTimestampsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, onLog LogHandler) (ScreenshotsResult, error) {
-	return runEngineScreenshotsAtTimestampsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, onLog)
+func RunScreenshotsAtTimestampsWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, onLog LogHandler, onProgress ProgressHandler) (ScreenshotsResult, error) {
+	return runEngineScreenshotsAtTimestampsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, limitMaxBytes(DefaultMaxImageBytes, RenderOptions{}), onLog, onProgress)
 }
 
 // RunUpload 执行截图加上传流程并仅返回直链输出。
@@ -51,47 +63,65 @@ func RunUploadWithLogs(ctx context.Context, inputPath, outputDir, variant, subti
 
 // RunUploadWithLiveLogs 会执行截图加上传流程，并把实时日志通过回调逐行暴露给调用方。
 func RunUploadWithLiveLogs(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, onLog LogHandler) (UploadResult, error) {
-	return RunUploadWithLiveEvents(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, onLog, nil)
+	return RunUploadWithLiveEvents(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, onLog, nil, nil)
 }
 
 // RunUploadWithLiveLogsWithOptions 会按指定上传选项执行截图加上传流程。
 func RunUploadWithLiveLogsWithOptions(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, options UploadOptions, onLog LogHandler) (UploadResult, error) {
-	return RunUploadWithLiveEventsWithOptions(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, options, onLog, nil)
+	return RunUploadWithLiveEventsWithOptions(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, options, onLog, nil, nil)
 }
 
-// RunUploadWithLiveEvents 会执行截图加上传流程，并把实时日志和已完成图片逐步暴露给调用方。
-func RunUploadWithLiveEvents(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, onLog LogHandler, onItem UploadItemHandler) (UploadResult, error) {
-	return RunUploadWithLiveEventsWithOptions(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, UploadOptions{}, onLog, onItem)
+// RunUploadWithLiveEvents 会执行截图加上传流程，并把实时日志、进度事件和已完成图片逐步暴露给调用方。
+func RunUploadWithLiveEvents(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, onLog LogHandler, onProgress ProgressHandler, onItem UploadItemHandler) (UploadResult, error) {
+	return RunUploadWithLiveEventsWithOptions(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, UploadOptions{}, onLog, onProgress, onItem)
 }
 
 // RunUploadWithLiveEventsWithOptions 会按指定上传选项执行截图加上传流程，并逐步暴露实时事件。
-func RunUploadWithLiveEventsWithOptions(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, options UploadOptions, onLog LogHandler, onItem UploadItemHandler) (UploadResult, error) {
-	screenshotResult, err := runEngineScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, onLog)
+func RunUploadWithLiveEventsWithOptions(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, count int, options UploadOptions, onLog LogHandler, onProgress ProgressHandler, onItem UploadItemHandler) (UploadResult, error) {
+	host, err := LookupImageHostForVariant(options.Host, variant)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	screenshotResult, err := runEngineScreenshotsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, count, limitMaxBytes(maxImageBytesFor(host), options.Render), onLog, onProgress)
 	if err != nil {
 		return UploadResult{Logs: screenshotResult.Logs}, err
 	}
 
-	return uploadScreenshotResult(ctx, screenshotResult, options, onLog, onItem)
+	return uploadScreenshotResult(ctx, host, screenshotResult, options, onLog, onProgress, onItem)
 }
 
 // RunUploadAtTimestampsWithLiveEventsWithOptions 会按指定时间点截图并上传。
-func RunUploadAtTimestampsWithLiveEventsWithOptions(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, options UploadOptions, onLog LogHandler, onItem UploadItemHandler) (UploadResult, error) {
-	screenshotResult, err := runEngineScreenshotsAtTimestampsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, onLog)
+func RunUploadAtTimestampsWithLiveEventsWithOptions(ctx context.Context, inputPath, outputDir, variant, subtitleMode, hdrProcessor string, timestamps []string, options UploadOptions, onLog LogHandler, onProgress ProgressHandler, onItem UploadItemHandler) (UploadResult, error) {
+	host, err := LookupImageHostForVariant(options.Host, variant)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	screenshotResult, err := runEngineScreenshotsAtTimestampsWithLiveLogs(ctx, inputPath, outputDir, variant, subtitleMode, hdrProcessor, timestamps, limitMaxBytes(maxImageBytesFor(host), options.Render), onLog, onProgress)
 	if err != nil {
 		return UploadResult{Logs: screenshotResult.Logs}, err
 	}
 
-	return uploadScreenshotResult(ctx, screenshotResult, options, onLog, onItem)
+	return uploadScreenshotResult(ctx, host, screenshotResult, options, onLog, onProgress, onItem)
+}
+
+// UploadScreenshots 会把已生成的截图上传到选项指定的图床，供需要同时保留本地截图文件的调用方使用。
+func UploadScreenshots(ctx context.Context, screenshotResult ScreenshotsResult, options UploadOptions, onLog LogHandler, onProgress ProgressHandler, onItem UploadItemHandler) (UploadResult, error) {
+	host, err := LookupImageHost(options.Host)
+	if err != nil {
+		return UploadResult{Logs: screenshotResult.Logs, LossyPNGFiles: screenshotResult.LossyPNGFiles}, err
+	}
+	return uploadScreenshotResult(ctx, host, screenshotResult, options, onLog, onProgress, onItem)
 }
 
-// uploadScreenshotResult 会上传截图结果，并合并截图与上传阶段日志。
-func uploadScreenshotResult(ctx context.Context, screenshotResult ScreenshotsResult, options UploadOptions, onLog LogHandler, onItem UploadItemHandler) (UploadResult, error) {
-	uploadResult, err := screenshotpixhost.UploadImagesWithOptions(ctx, screenshotResult.Files, screenshotResult.LossyPNGFiles, oversizeBytes, options, onLog, onItem)
+// uploadScreenshotResult 会把截图结果上传到指定图床，并合并截图与上传阶段日志。
+func uploadScreenshotResult(ctx context.Context, host ImageHost, screenshotResult ScreenshotsResult, options UploadOptions, onLog LogHandler, onProgress ProgressHandler, onItem UploadItemHandler) (UploadResult, error) {
+	uploadResult, err := imagehost.UploadImages(ctx, host, screenshotResult.Files, screenshotResult.LossyPNGFiles, imagehost.UploadOptions{ProxyURL: options.ProxyURL}, onLog, onProgress, onItem)
 	logs := mergeUploadLogs(screenshotResult.Logs, uploadResult.Logs)
 	if err != nil {
 		return UploadResult{
 			Logs:            logs,
 			Items:           uploadResult.Items,
+			Failed:          uploadResult.Failed,
 			LossyPNGFiles:   screenshotResult.LossyPNGFiles,
 			LossyPNGIndexes: uploadResult.LossyIndexes,
 		}, err
@@ -100,6 +130,7 @@ func uploadScreenshotResult(ctx context.Context, screenshotResult ScreenshotsRes
 		Output:          uploadResult.Output,
 		Logs:            logs,
 		Items:           uploadResult.Items,
+		Failed:          uploadResult.Failed,
 		LossyPNGFiles:   screenshotResult.LossyPNGFiles,
 		LossyPNGIndexes: uploadResult.LossyIndexes,
 	}, nil
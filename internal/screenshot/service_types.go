@@ -3,22 +3,29 @@
 package screenshot
 
 import (
-	screenshotpixhost "minfo/internal/screenshot/pixhost"
+	"minfo/internal/screenshot/imagehost"
 	screenshotruntime "minfo/internal/screenshot/runtime"
+	"minfo/internal/taskprogress"
 )
 
 const (
 	defaultScreenshotCount = 4
 	minScreenshotCount     = 1
 	maxScreenshotCount     = 10
+
+	// MaxPresetScreenshotCount 是截图预设允许设置的最大截图数量。
+	MaxPresetScreenshotCount = 40
 )
 
 const (
 	ModeZip   = "zip"
 	ModeLinks = "links"
 
-	VariantPNG = "png"
-	VariantJPG = "jpg"
+	VariantPNG       = "png"
+	VariantJPG       = "jpg"
+	VariantWebP      = "webp"
+	VariantWebPLossy = "webp-lossy"
+	VariantAVIF      = "avif"
 
 	SubtitleModeAuto = "auto"
 	SubtitleModeOff  = "off"
@@ -36,22 +43,46 @@ type ScreenshotsResult struct {
 }
 
 // UploadedImage 表示一次图床上传后返回的单张图片结果。
-type UploadedImage = screenshotpixhost.UploadedImage
+type UploadedImage = imagehost.UploadedImage
 
 // UploadItemHandler 处理图床上传过程中单张已完成图片的实时回调。
-type UploadItemHandler = screenshotpixhost.UploadItemHandler
+type UploadItemHandler = imagehost.UploadItemHandler
+
+// FailedUpload 表示一张重试后仍上传失败的截图，Path 指向本地截图文件。
+type FailedUpload = imagehost.FailedImage
+
+// UploadOptions 表示图床上传时的可选运行参数；Host 为空时使用默认图床，Render 是上传前截图阶段使用的渲染参数。
+type UploadOptions struct {
+	ProxyURL string
+	Host     string
+	Render   RenderOptions
+}
 
-// UploadOptions 表示图床上传时的可选运行参数。
-type UploadOptions = screenshotpixhost.UploadOptions
+// RenderOptions 表示截图渲染阶段的可选参数，通常来自截图预设；零值表示原生分辨率、格式默认质量和图床大小上限。
+// MaxWidth / MaxHeight 是输出画面的最大宽高，只缩小不放大；Scaler 是缩放算法；
+// JPGQScale、WebPQuality 和 AVIFCRF 覆盖对应格式的默认编码质量；MaxBytes 是单张截图的大小上限，只能比图床上限更严格。
+type RenderOptions struct {
+	MaxWidth    int
+	MaxHeight   int
+	Scaler      string
+	JPGQScale   int
+	WebPQuality int
+	AVIFCRF     int
+	MaxBytes    int64
+}
 
-// UploadResult 表示一次截图上传流程返回的直链文本和日志。
+// UploadResult 表示一次截图上传流程返回的直链文本、日志、成功图片和失败图片。
 type UploadResult struct {
 	Output          string
 	Logs            string
 	Items           []UploadedImage
+	Failed          []FailedUpload
 	LossyPNGFiles   []string
 	LossyPNGIndexes []int
 }
 
 // LogHandler 处理截图流程产生的单行实时日志。
 type LogHandler = screenshotruntime.LineHandler
+
+// ProgressHandler 处理截图与上传流程直接发出的结构化进度事件。
+type ProgressHandler = taskprogress.Handler
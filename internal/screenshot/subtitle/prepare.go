@@ -11,7 +11,7 @@ import (
 
 // PreloadDVDMediaInfo 会在 DVD 场景下提前读取 mediainfo，并输出对应阶段进度避免前端长时间停在等待状态。
 func (r *Runner) PreloadDVDMediaInfo() {
-	if r == nil || !source.LooksLikeDVDSource(r.dvdProbeSource()) || r.state().HasDVDMediaInfoResult || strings.TrimSpace(r.Tools.MediaInfoBin) == "" {
+	if r == nil || !source.LooksLikeDVDSource(r.dvdProbeSource()) || r.state().HasDVDMediaInfoResult {
 		return
 	}
 
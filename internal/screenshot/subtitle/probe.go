@@ -126,10 +126,6 @@ func (r *Runner) probeBlurayFFprobe(playlist string) ([]screenshotruntime.Subtit
 }
 
 func (r *Runner) probeDVDMediaInfo() (screenshotruntime.DVDMediaInfoResult, bool) {
-	if strings.TrimSpace(r.Tools.MediaInfoBin) == "" {
-		return screenshotruntime.DVDMediaInfoResult{}, false
-	}
-
 	result, ok, err := r.ensureDVDMediaInfoResult()
 	if !ok {
 		if err != nil {
@@ -142,6 +138,17 @@ func (r *Runner) probeDVDMediaInfo() (screenshotruntime.DVDMediaInfoResult, bool
 		return screenshotruntime.DVDMediaInfoResult{}, false
 	}
 
+	if result.FromIFO {
+		r.logf("[信息] 已直接解析 DVD IFO：IFO=%s | VOB=%s",
+			timestamps.DisplayProbeValue(result.ProbePath),
+			timestamps.DisplayProbeValue(result.SelectedVOBPath),
+		)
+		if strings.TrimSpace(result.LanguageFallbackPath) != "" {
+			r.logf("[信息] DVD IFO 语言回退：IFO 缺语言，已用 mediainfo 补齐：%s", result.LanguageFallbackPath)
+		}
+		return result, true
+	}
+
 	r.logf("[信息] 已调用 mediainfo(DVD)：IFO=%s | VOB=%s",
 		timestamps.DisplayProbeValue(result.ProbePath),
 		timestamps.DisplayProbeValue(result.SelectedVOBPath),
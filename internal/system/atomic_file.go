@@ -0,0 +1,44 @@
+// Package system 提供原子写文件等本地文件系统辅助能力。
+
+package system
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic 会先把 write 的输出写入同目录下的临时文件，设置权限后再重命名为 path，
+// 避免并发读取方看到写了一半的文件；任何一步失败都会删除临时文件。
+func WriteFileAtomic(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if err := write(temp); err != nil {
+		temp.Close()
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err := os.Chmod(temp.Name(), perm); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		_ = os.Remove(temp.Name())
+		return err
+	}
+	return nil
+}
+
+// WriteFileAtomicBytes 会以 WriteFileAtomic 的方式把 content 写入 path。
+func WriteFileAtomicBytes(path string, perm os.FileMode, content []byte) error {
+	return WriteFileAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+}
@@ -0,0 +1,40 @@
+package system
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteFileAtomicReplacesFileAndCleansUpOnError 验证成功时整体替换目标文件并设置权限，失败时保留原文件且不留下临时文件。
+func TestWriteFileAtomicReplacesFileAndCleansUpOnError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "record.json")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := WriteFileAtomicBytes(path, 0o644, []byte("new")); err != nil {
+		t.Fatalf("WriteFileAtomicBytes() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o644 {
+		t.Fatalf("Stat() = %v, %v; want mode 0644", info, err)
+	}
+
+	failure := errors.New("encode failed")
+	err = WriteFileAtomic(path, 0o644, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("WriteFileAtomic() error = %v, want %v", err, failure)
+	}
+	content, _ := os.ReadFile(path)
+	entries, _ := os.ReadDir(dir)
+	if string(content) != "new" || len(entries) != 1 {
+		t.Fatalf("content = %q, entries = %d; want original file and no temp files", content, len(entries))
+	}
+}
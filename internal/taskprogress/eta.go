@@ -0,0 +1,56 @@
+// Package taskprogress 为进度事件补充阶段耗时与剩余时间估算。
+
+package taskprogress
+
+import (
+	"sync"
+	"time"
+)
+
+// minETAElapsed 是开始推算剩余时间前阶段至少需要运行的时长，避免刚开始时的估算剧烈抖动。
+const minETAElapsed = 2 * time.Second
+
+type stageClock struct {
+	startedAt    time.Time
+	lastFraction float64
+}
+
+// WithETA 会包装进度回调，为每条事件补上阶段已耗时和按当前完成比例线性外推的剩余时间。
+//
+// 阶段计时从该阶段第一条事件开始；完成比例回落时（例如逐张渲染的百分比重新从 0 开始）会重新计时。
+func WithETA(handler Handler) Handler {
+	return withETAClock(handler, time.Now)
+}
+
+// withETAClock 与 WithETA 相同，但允许测试注入时钟。
+func withETAClock(handler Handler, now func() time.Time) Handler {
+	if handler == nil {
+		return nil
+	}
+
+	var mu sync.Mutex
+	clocks := make(map[string]*stageClock)
+	return func(event Event) {
+		mu.Lock()
+		current := now()
+		fraction := event.Fraction()
+		clock, ok := clocks[event.Stage]
+		if !ok || (fraction >= 0 && fraction < clock.lastFraction) {
+			clock = &stageClock{startedAt: current}
+			clocks[event.Stage] = clock
+		}
+		if fraction >= 0 {
+			clock.lastFraction = fraction
+		}
+		elapsed := current.Sub(clock.startedAt)
+		mu.Unlock()
+
+		event.ElapsedMS = elapsed.Milliseconds()
+		event.ETAMS = 0
+		if fraction > 0 && fraction < 1 && elapsed >= minETAElapsed {
+			remaining := time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+			event.ETAMS = remaining.Milliseconds()
+		}
+		handler(event)
+	}
+}
@@ -1,4 +1,4 @@
-// Package taskprogress 提供截图任务进度日志的统一格式与解析能力。
+// Package taskprogress 提供截图任务进度事件与进度日志的统一格式。
 
 package taskprogress
 
@@ -28,11 +28,52 @@ const (
 )
 
 // Event 表示一条可被格式化或解析的统一进度事件。
+//
+// ElapsedMS 和 ETAMS 由 WithETA 根据阶段已耗时推算，生产者无需填写。
 type Event struct {
-	Kind    Kind
-	Stage   string
-	Current int
-	Total   int
-	Percent float64
-	Detail  string
+	Kind      Kind    `json:"kind"`
+	Stage     string  `json:"stage,omitempty"`
+	Current   int     `json:"current,omitempty"`
+	Total     int     `json:"total,omitempty"`
+	Percent   float64 `json:"percent,omitempty"`
+	Detail    string  `json:"detail,omitempty"`
+	ElapsedMS int64   `json:"elapsed_ms,omitempty"`
+	ETAMS     int64   `json:"eta_ms,omitempty"`
+}
+
+// Handler 处理生产者直接发出的结构化进度事件。
+type Handler func(event Event)
+
+// Step 会构造一条 current/total 型进度事件。
+func Step(stage string, current, total int, detail string) Event {
+	return Event{Kind: KindStep, Stage: stage, Current: current, Total: total, Detail: detail}
+}
+
+// Percent 会构造一条百分比型进度事件，百分比会被限制在 0-100。
+func Percent(stage string, percent float64, detail string) Event {
+	return Event{Kind: KindPercent, Stage: stage, Percent: clampPercent(percent), Detail: detail}
+}
+
+// Emit 会在回调存在时发送一条进度事件。
+func (h Handler) Emit(event Event) {
+	if h == nil {
+		return
+	}
+	h(event)
+}
+
+// Fraction 返回事件在所属阶段内的完成比例；无法判断时返回 -1。
+func (e Event) Fraction() float64 {
+	switch e.Kind {
+	case KindPercent:
+		return clampPercent(e.Percent) / 100
+	case KindStep:
+		if e.Total <= 0 {
+			return -1
+		}
+		current := min(max(e.Current, 0), e.Total)
+		return float64(current) / float64(e.Total)
+	default:
+		return -1
+	}
 }
@@ -1,47 +1,44 @@
 package taskprogress
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
-// TestFormatStepAndParseLogLine 验证 step 型进度日志可被统一格式化并解析回结构化事件。
-func TestFormatStepAndParseLogLine(t *testing.T) {
-	line := FormatStep(StageSubtitle, 1, 3, "正在扫描全片字幕索引。")
-
-	event, ok := ParseLogLine(line)
-	if !ok {
-		t.Fatal("ParseLogLine() returned ok=false")
-	}
-	if event.Kind != KindStep {
-		t.Fatalf("Kind = %q, want %q", event.Kind, KindStep)
+// TestFormatStepAndPercent 验证 step 型和 percent 型进度事件会被格式化为统一的日志文本。
+func TestFormatStepAndPercent(t *testing.T) {
+	if line := FormatStep(StageSubtitle, 1, 3, " 正在扫描全片字幕索引。 "); line != "[进度] 字幕 1/3: 正在扫描全片字幕索引。" {
+		t.Fatalf("FormatStep() = %q", line)
 	}
-	if event.Stage != StageSubtitle {
-		t.Fatalf("Stage = %q, want %q", event.Stage, StageSubtitle)
+	if line := FormatPercent(StageRender, 47, "正在渲染第 1/4 张截图：00_05_41.png"); line != "[进度] 渲染 47%: 正在渲染第 1/4 张截图：00_05_41.png" {
+		t.Fatalf("FormatPercent() = %q", line)
 	}
-	if event.Current != 1 || event.Total != 3 {
-		t.Fatalf("Current/Total = %d/%d, want 1/3", event.Current, event.Total)
-	}
-	if event.Detail != "正在扫描全片字幕索引。" {
-		t.Fatalf("Detail = %q, want subtitle detail", event.Detail)
+	if line := FormatPercent(StageRender, 47.26, "渲染中。"); line != "[进度] 渲染 47.3%: 渲染中。" {
+		t.Fatalf("FormatPercent(fraction) = %q", line)
 	}
 }
 
-// TestFormatPercentAndParseLogLine 验证 percent 型进度日志可被统一格式化并解析回结构化事件。
-func TestFormatPercentAndParseLogLine(t *testing.T) {
-	line := FormatPercent(StageRender, 47, "正在渲染第 1/4 张截图：00_05_41.png")
+// TestWithETAEstimatesRemainingTimeFromStageElapsed 验证剩余时间按阶段已耗时线性外推，并在比例回落时重新计时。
+func TestWithETAEstimatesRemainingTimeFromStageElapsed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	var got []Event
+	handler := withETAClock(func(event Event) { got = append(got, event) }, func() time.Time { return now })
 
-	event, ok := ParseLogLine(line)
-	if !ok {
-		t.Fatal("ParseLogLine() returned ok=false")
-	}
-	if event.Kind != KindPercent {
-		t.Fatalf("Kind = %q, want %q", event.Kind, KindPercent)
+	handler(Step(StageUpload, 0, 4, "开始上传。"))
+	now = start.Add(10 * time.Second)
+	handler(Step(StageUpload, 1, 4, "已上传 1 张。"))
+	if got[1].ElapsedMS != 10000 || got[1].ETAMS != 30000 {
+		t.Fatalf("elapsed/eta = %d/%d, want 10000/30000", got[1].ElapsedMS, got[1].ETAMS)
 	}
-	if event.Stage != StageRender {
-		t.Fatalf("Stage = %q, want %q", event.Stage, StageRender)
-	}
-	if event.Percent != 47 {
-		t.Fatalf("Percent = %.1f, want 47", event.Percent)
-	}
-	if event.Detail != "正在渲染第 1/4 张截图：00_05_41.png" {
-		t.Fatalf("Detail = %q, want render detail", event.Detail)
+
+	handler(Percent(StageRender, 50, "渲染中。"))
+	if got[2].ElapsedMS != 0 || got[2].ETAMS != 0 {
+		t.Fatalf("new stage elapsed/eta = %d/%d, want 0/0", got[2].ElapsedMS, got[2].ETAMS)
+	}
+	now = now.Add(4 * time.Second)
+	handler(Percent(StageRender, 10, "下一张渲染中。"))
+	if got[3].ElapsedMS != 0 {
+		t.Fatalf("ElapsedMS = %d, want clock restarted after fraction dropped", got[3].ElapsedMS)
 	}
 }
@@ -0,0 +1,173 @@
+// Package torrent decodes generated .torrent files to report their name, size and info hash.
+
+package torrent
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ErrInvalidMetaInfo reports a .torrent file that is not a bencoded dictionary with an info dictionary.
+var ErrInvalidMetaInfo = errors.New("invalid torrent metainfo")
+
+// MetaInfo contains the identifying fields of a generated .torrent file.
+type MetaInfo struct {
+	// InfoHash is the hex SHA-1 of the bencoded info dictionary (BitTorrent v1).
+	InfoHash string
+	// InfoHashV2 is the hex SHA-256 of the info dictionary; it is only set for v2 or hybrid torrents.
+	InfoHashV2  string
+	Name        string
+	TotalSize   int64
+	PieceLength int64
+	Private     bool
+}
+
+// ReadMetaInfo decodes the .torrent file at path and computes its info hash.
+func ReadMetaInfo(path string) (MetaInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MetaInfo{}, err
+	}
+	return ParseMetaInfo(data)
+}
+
+// ParseMetaInfo decodes bencoded torrent metadata and computes its info hash from the raw info dictionary bytes.
+func ParseMetaInfo(data []byte) (MetaInfo, error) {
+	if len(data) == 0 || data[0] != 'd' {
+		return MetaInfo{}, ErrInvalidMetaInfo
+	}
+
+	var rawInfo []byte
+	var info map[string]any
+	pos := 1
+	for pos < len(data) && data[pos] != 'e' {
+		key, next, err := decodeBencodeString(data, pos)
+		if err != nil {
+			return MetaInfo{}, err
+		}
+		value, end, err := decodeBencode(data, next)
+		if err != nil {
+			return MetaInfo{}, err
+		}
+		if key == "info" {
+			dict, ok := value.(map[string]any)
+			if !ok {
+				return MetaInfo{}, ErrInvalidMetaInfo
+			}
+			rawInfo, info = data[next:end], dict
+		}
+		pos = end
+	}
+	if info == nil {
+		return MetaInfo{}, ErrInvalidMetaInfo
+	}
+
+	sum := sha1.Sum(rawInfo)
+	meta := MetaInfo{
+		InfoHash:    hex.EncodeToString(sum[:]),
+		PieceLength: bencodeInt(info["piece length"]),
+		Private:     bencodeInt(info["private"]) == 1,
+	}
+	meta.Name, _ = info["name"].(string)
+	if bencodeInt(info["meta version"]) == 2 {
+		sum := sha256.Sum256(rawInfo)
+		meta.InfoHashV2 = hex.EncodeToString(sum[:])
+	}
+
+	meta.TotalSize = bencodeInt(info["length"])
+	if files, ok := info["files"].([]any); ok {
+		for _, file := range files {
+			if entry, ok := file.(map[string]any); ok {
+				meta.TotalSize += bencodeInt(entry["length"])
+			}
+		}
+	}
+	return meta, nil
+}
+
+// decodeBencode decodes one bencoded value starting at pos and returns it with the offset just past it.
+// Strings decode to string, integers to int64, lists to []any and dictionaries to map[string]any.
+func decodeBencode(data []byte, pos int) (any, int, error) {
+	if pos >= len(data) {
+		return nil, pos, ErrInvalidMetaInfo
+	}
+	switch data[pos] {
+	case 'i':
+		end := pos + 1
+		for end < len(data) && data[end] != 'e' {
+			end++
+		}
+		if end >= len(data) {
+			return nil, pos, ErrInvalidMetaInfo
+		}
+		value, err := strconv.ParseInt(string(data[pos+1:end]), 10, 64)
+		if err != nil {
+			return nil, pos, fmt.Errorf("%w: %v", ErrInvalidMetaInfo, err)
+		}
+		return value, end + 1, nil
+	case 'l':
+		var list []any
+		pos++
+		for pos < len(data) && data[pos] != 'e' {
+			value, next, err := decodeBencode(data, pos)
+			if err != nil {
+				return nil, pos, err
+			}
+			list = append(list, value)
+			pos = next
+		}
+		if pos >= len(data) {
+			return nil, pos, ErrInvalidMetaInfo
+		}
+		return list, pos + 1, nil
+	case 'd':
+		dict := make(map[string]any)
+		pos++
+		for pos < len(data) && data[pos] != 'e' {
+			key, next, err := decodeBencodeString(data, pos)
+			if err != nil {
+				return nil, pos, err
+			}
+			value, end, err := decodeBencode(data, next)
+			if err != nil {
+				return nil, pos, err
+			}
+			dict[key] = value
+			pos = end
+		}
+		if pos >= len(data) {
+			return nil, pos, ErrInvalidMetaInfo
+		}
+		return dict, pos + 1, nil
+	default:
+		return decodeBencodeString(data, pos)
+	}
+}
+
+// decodeBencodeString decodes a length-prefixed bencoded byte string starting at pos.
+func decodeBencodeString(data []byte, pos int) (string, int, error) {
+	colon := pos
+	for colon < len(data) && data[colon] >= '0' && data[colon] <= '9' {
+		colon++
+	}
+	if colon == pos || colon >= len(data) || data[colon] != ':' {
+		return "", pos, ErrInvalidMetaInfo
+	}
+	length, err := strconv.Atoi(string(data[pos:colon]))
+	if err != nil || length < 0 || colon+1+length > len(data) {
+		return "", pos, ErrInvalidMetaInfo
+	}
+	start := colon + 1
+	return string(data[start : start+length]), start + length, nil
+}
+
+// bencodeInt returns value as int64 when it is a decoded bencode integer, or 0 otherwise.
+func bencodeInt(value any) int64 {
+	number, _ := value.(int64)
+	return number
+}
@@ -0,0 +1,31 @@
+package torrent
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestParseMetaInfoComputesInfoHash(t *testing.T) {
+	info := "d5:filesld6:lengthi100e4:pathl5:a.mkveed6:lengthi23e4:pathl5:b.srteee4:name5:Movie12:piece lengthi4194304e7:privatei1ee"
+	data := []byte("d8:announce15:http://tracker/10:created by5:mkbrr4:info" + info + "e")
+
+	meta, err := ParseMetaInfo(data)
+	if err != nil {
+		t.Fatalf("ParseMetaInfo() error: %v", err)
+	}
+	sum := sha1.Sum([]byte(info))
+	if meta.InfoHash != hex.EncodeToString(sum[:]) {
+		t.Fatalf("InfoHash = %s, want SHA-1 of the raw info dictionary", meta.InfoHash)
+	}
+	if meta.Name != "Movie" || meta.TotalSize != 123 || meta.PieceLength != 4<<20 || !meta.Private || meta.InfoHashV2 != "" {
+		t.Fatalf("meta = %+v", meta)
+	}
+
+	for _, bad := range []string{"", "le", "d4:name5:Moviee", "d4:infod4:name5:Movie"} {
+		if _, err := ParseMetaInfo([]byte(bad)); !errors.Is(err, ErrInvalidMetaInfo) {
+			t.Fatalf("ParseMetaInfo(%q) error = %v, want ErrInvalidMetaInfo", bad, err)
+		}
+	}
+}
@@ -1,5 +1,6 @@
 // Package torrent wraps mkbrr for BitTorrent metainfo generation.
-// It does not encode torrent metadata itself.
+// It does not encode torrent metadata itself; ReadMetaInfo only decodes
+// the generated file to report its name, size and info hash.
 package torrent
 
 import (
@@ -14,6 +15,7 @@ import (
 	"strings"
 
 	"minfo/internal/system"
+	"minfo/internal/taskprogress"
 )
 
 const (
@@ -22,6 +24,13 @@ const (
 	MaxPieceLength     = int64(128 << 20)
 )
 
+// Progress stages reported by ParseProgressLine and Create.
+const (
+	StagePrepare = "准备"
+	StageHashing = "正在哈希"
+	StageDone    = "完成"
+)
+
 var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
 var percentPattern = regexp.MustCompile(`(?i)hashing pieces.*?([0-9]{1,3})%`)
 
@@ -45,8 +54,15 @@ type Progress struct {
 	Done    bool
 }
 
+// Event converts a parsed mkbrr progress update into a structured progress event.
+func (p Progress) Event() taskprogress.Event {
+	return taskprogress.Percent(p.Stage, p.Percent, p.Detail)
+}
+
 // Create runs mkbrr and writes the generated .torrent to outputPath.
-func Create(ctx context.Context, input, outputPath string, options Options, onLine system.OutputLineHandler) (string, error) {
+// When onProgress is set, mkbrr progress lines are delivered as structured
+// events instead of being forwarded to onLine.
+func Create(ctx context.Context, input, outputPath string, options Options, onLine system.OutputLineHandler, onProgress taskprogress.Handler) (string, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -61,7 +77,7 @@ func Create(ctx context.Context, input, outputPath string, options Options, onLi
 		return "", err
 	}
 
-	stdout, stderr, err := system.RunCommandLive(ctx, bin, onLine, args...)
+	stdout, stderr, err := system.RunCommandLive(ctx, bin, progressLineHandler(onLine, onProgress), args...)
 	if err != nil {
 		return "", fmt.Errorf("%s", system.BestErrorMessage(err, stderr, stdout))
 	}
@@ -71,6 +87,22 @@ func Create(ctx context.Context, input, outputPath string, options Options, onLi
 	return TorrentFilename(input, options.Name), nil
 }
 
+// progressLineHandler routes mkbrr progress lines to onProgress and all other output to onLine.
+func progressLineHandler(onLine system.OutputLineHandler, onProgress taskprogress.Handler) system.OutputLineHandler {
+	if onProgress == nil {
+		return onLine
+	}
+	return func(stream, line string) {
+		if progress, ok := ParseProgressLine(line); ok {
+			onProgress(progress.Event())
+			return
+		}
+		if onLine != nil {
+			onLine(stream, line)
+		}
+	}
+}
+
 // BuildMkbrrArgs converts user-facing options into mkbrr CLI arguments.
 func BuildMkbrrArgs(input, outputPath string, options Options) ([]string, error) {
 	if strings.TrimSpace(input) == "" {
@@ -122,7 +154,7 @@ func ParseProgressLine(line string) (Progress, bool) {
 
 	if strings.Contains(cleaned, "Hashing pieces") {
 		progress := Progress{
-			Stage:  "正在哈希",
+			Stage:  StageHashing,
 			Detail: "正在计算 torrent 分块哈希。",
 		}
 		if match := percentPattern.FindStringSubmatch(cleaned); len(match) == 2 {
@@ -134,10 +166,10 @@ func ParseProgressLine(line string) (Progress, bool) {
 		return progress, true
 	}
 	if strings.Contains(cleaned, "Files being hashed") || strings.Contains(cleaned, "Concurrency:") {
-		return Progress{Percent: 1, Stage: "准备", Detail: "正在整理待制种文件。"}, true
+		return Progress{Percent: 1, Stage: StagePrepare, Detail: "正在整理待制种文件。"}, true
 	}
 	if strings.HasPrefix(cleaned, "Wrote ") {
-		return Progress{Percent: 100, Stage: "完成", Detail: "种子文件已生成。", Done: true}, true
+		return Progress{Percent: 100, Stage: StageDone, Detail: "种子文件已生成。", Done: true}, true
 	}
 	return Progress{}, false
 }
@@ -182,7 +182,8 @@ export function useMediaActions(path, screenshotVariant, screenshotSubtitleMode,
                     error.logEntriesPrinted = currentLogEntries.length > 0 && printedLogCount >= currentLogEntries.length;
                     throw error;
                 }
-                case "failed": {
+                case "failed":
+                case "interrupted": {
                     const error = buildAsyncJobError(job);
                     error.logEntriesPrinted = currentLogEntries.length > 0 && printedLogCount >= currentLogEntries.length;
                     throw error;
@@ -1045,7 +1046,7 @@ function normalizeTargetPath(value) {
 }
 
 function isTerminalTaskStatus(status) {
-    return status === "succeeded" || status === "failed" || status === "canceled";
+    return status === "succeeded" || status === "failed" || status === "canceled" || status === "interrupted";
 }
 
 function buildAsyncJobError(job = {}) {
@@ -47,6 +47,7 @@ export function buildEntries(items) {
         let explicitDir = false;
         let size = 0;
         let duration = "";
+        let label = "";
 
         if (typeof raw === "string") {
             rawPath = raw.trim();
@@ -56,6 +57,7 @@ export function buildEntries(items) {
             const parsedSize = Number.parseInt(`${raw.size ?? ""}`.trim(), 10);
             size = Number.isFinite(parsedSize) && parsedSize > 0 ? parsedSize : 0;
             duration = typeof raw.duration === "string" ? raw.duration.trim() : "";
+            label = typeof raw.label === "string" ? raw.label.trim() : "";
         }
 
         if (rawPath === "") {
@@ -65,7 +67,7 @@ export function buildEntries(items) {
         const clean = cleanPath(rawPath);
         result.push({
             path: clean,
-            name: getEntryName(rawPath),
+            name: label || getEntryName(rawPath),
             isDir,
             isISO: !isDir && isISOFilePath(clean),
             isMPLS: !isDir && isMPLSFilePath(clean),